@@ -0,0 +1,191 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"splitExpense/config"
+	"splitExpense/orchestrator"
+	"splitExpense/terminal"
+	"text/tabwriter"
+)
+
+const userUsage = "usage: splitExpense user create -name <name> -email <email> [-verified] | verify <id|email> | reset-password <id|email>"
+
+// passwordEnv hands the user commands a password without a flag, which would
+// show up in the process list and the shell history
+const passwordEnv = "SPLITEXPENSE_PASSWORD"
+
+// readPassword takes the password from SPLITEXPENSE_PASSWORD, or reads a line
+// from stdin, prompting without echo when stdin is a terminal
+func readPassword(prompt string) (string, error) {
+	if password, ok := os.LookupEnv(passwordEnv); ok {
+		return password, nil
+	}
+	password, err := terminal.ReadSecret(os.Stdin, bufio.NewReader(os.Stdin), prompt)
+	if err != nil {
+		return "", fmt.Errorf("reading the password, or set %s: %w", passwordEnv, err)
+	}
+	return password, nil
+}
+
+// runUser handles `splitExpense user create|verify|reset-password`
+func runUser(cfg *config.Config, args []string) error {
+	if len(args) == 0 {
+		return errors.New(userUsage)
+	}
+	ctx := context.Background()
+
+	switch args[0] {
+	case "create":
+		fs := flag.NewFlagSet("user create", flag.ContinueOnError)
+		name := fs.String("name", "", "display name")
+		email := fs.String("email", "", "login email")
+		verified := fs.Bool("verified", false, "create the user already verified")
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+		password, err := readPassword("initial password: ")
+		if err != nil {
+			return err
+		}
+		app := orchestrator.NewExpenseApp(cfg)
+		user, err := app.AdminCreateUser(ctx, *name, *email, password, *verified)
+		if err != nil {
+			return err
+		}
+		return printJSON(user)
+	case "verify":
+		if len(args) != 2 {
+			return errors.New(userUsage)
+		}
+		app := orchestrator.NewExpenseApp(cfg)
+		user, err := app.VerifyUser(ctx, args[1])
+		if err != nil {
+			return err
+		}
+		fmt.Printf("verified %s <%s>\n", user.Name, user.Email)
+		return nil
+	case "reset-password":
+		if len(args) != 2 {
+			return errors.New(userUsage)
+		}
+		password, err := readPassword("new password: ")
+		if err != nil {
+			return err
+		}
+		app := orchestrator.NewExpenseApp(cfg)
+		if err := app.ResetPassword(ctx, args[1], password); err != nil {
+			return err
+		}
+		fmt.Printf("reset the password of %s\n", args[1])
+		return nil
+	default:
+		return fmt.Errorf("unknown user command %q", args[0])
+	}
+}
+
+// runGroup handles `splitExpense group inspect <id>`
+func runGroup(cfg *config.Config, args []string) error {
+	if len(args) != 2 || args[0] != "inspect" {
+		return errors.New("usage: splitExpense group inspect <id>")
+	}
+	app := orchestrator.NewExpenseApp(cfg)
+	inspection, err := app.InspectGroup(context.Background(), args[1])
+	if err != nil {
+		return err
+	}
+
+	g := inspection.Group
+	fmt.Printf("%s (%s), version %d, created %s\n", g.Name, g.Id, g.Version, g.CreatedAt.Format("2006-01-02 15:04:05"))
+	fmt.Printf("admin %s, %d expenses, %d unsettled\n\n", g.Admin, inspection.Expenses, inspection.UnsettledExpenses)
+	tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "MEMBER\tEMAIL\tOWED\tBORROWED\tNET")
+	for _, m := range inspection.Members {
+		fmt.Fprintf(tw, "%s\t%s\t%.2f\t%.2f\t%.2f\n", m.User.ID, m.User.Email, m.Owed, m.Borrowed, m.Net)
+	}
+	return tw.Flush()
+}
+
+// runRecomputeBalances handles `splitExpense recompute-balances`, rebuilding
+// the ledger balance projection from the recorded events. It refuses to run
+// without EVENT_SOURCING, the events stop when the server runs without the
+// ledger and would rebuild balances the live tables have moved past.
+func runRecomputeBalances(cfg *config.Config) error {
+	if !cfg.EventSourcing {
+		return errors.New("recompute-balances rebuilds the ledger balances, set EVENT_SOURCING=true to run it")
+	}
+	app := orchestrator.NewExpenseApp(cfg)
+	replayed, err := app.RecomputeBalances(context.Background())
+	if err != nil {
+		return err
+	}
+	fmt.Printf("recomputed balances from %d events\n", replayed)
+	return nil
+}
+
+// runCheckIntegrity handles `splitExpense check-integrity [-json]`, it fails
+// when any issue is found so it can gate scripts
+func runCheckIntegrity(cfg *config.Config, args []string) error {
+	fs := flag.NewFlagSet("check-integrity", flag.ContinueOnError)
+	asJSON := fs.Bool("json", false, "print the issues as JSON")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	app := orchestrator.NewExpenseApp(cfg)
+	issues, err := app.CheckIntegrity(context.Background())
+	if err != nil {
+		return err
+	}
+
+	if *asJSON {
+		if err := printJSON(issues); err != nil {
+			return err
+		}
+	} else {
+		for _, issue := range issues {
+			fmt.Printf("%-17s %s  %s\n", issue.Check, issue.Subject, issue.Detail)
+		}
+	}
+	if len(issues) > 0 {
+		return fmt.Errorf("found %d integrity issues", len(issues))
+	}
+	if !*asJSON {
+		fmt.Println("no integrity issues found")
+	}
+	return nil
+}
+
+// runSeed handles `splitExpense seed`, demo data for a fresh instance
+func runSeed(cfg *config.Config, args []string) error {
+	fs := flag.NewFlagSet("seed", flag.ContinueOnError)
+	opts := orchestrator.SeedOptions{}
+	fs.IntVar(&opts.Users, "users", 4, "users to create, all friends with each other")
+	fs.IntVar(&opts.Groups, "groups", 2, "groups every seeded user joins")
+	fs.IntVar(&opts.Expenses, "expenses", 12, "expenses spread over the groups")
+	fs.StringVar(&opts.Password, "password", "Seed@Pass123", "password of the seeded users")
+	fs.StringVar(&opts.EmailDomain, "domain", "example.com", "email domain of the seeded users")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	app := orchestrator.NewExpenseApp(cfg)
+	report, err := app.Seed(context.Background(), opts)
+	if err != nil {
+		return err
+	}
+	return printJSON(report)
+}
+
+func printJSON(value any) error {
+	out, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(out))
+	return nil
+}
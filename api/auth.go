@@ -1,8 +1,13 @@
 package apiServer
 
 import (
+	"bytes"
+	"context"
 	"errors"
+	"fmt"
+	"net/http"
 	"splitExpense/expense"
+	"splitExpense/storage"
 	"strings"
 
 	"github.com/gin-gonic/gin"
@@ -47,13 +52,14 @@ func Authenticate(c *gin.Context) {
 		if strings.HasPrefix(authHeader, "Bearer ") {
 			tokenStr = strings.TrimPrefix(authHeader, "Bearer ")
 		} else {
-			c.AbortWithError(401, err)
+			abortWithStatus(c, 401, errors.New("missing token, log in first"))
 			return
 		}
 	}
 	claims, err := expense.ParseToken(tokenStr)
 	if err != nil {
-		c.AbortWithError(401, err)
+		abortWithStatus(c, 401, fmt.Errorf("invalid token: %w", err))
+		return
 	}
 	user := expense.User{ID: claims.UserID, Name: claims.Name, Email: claims.Email, IsVerified: claims.IsVerified}
 	if expense.ShouldRefreshToken(claims) {
@@ -62,5 +68,143 @@ func Authenticate(c *gin.Context) {
 	}
 	c.Set(CtxUser, user)
 	c.Set(CtxUserId, user.ID)
+	// storage decorators such as the event ledger read the acting user from the request context
+	c.Request = c.Request.WithContext(expense.WithActor(c.Request.Context(), user.ID))
 	c.Next()
 }
+
+var errRequestFailed = errors.New("request failed")
+
+// userTransactions runs a request in a transaction bound to its user, the orchestrator in the server
+type userTransactions interface {
+	RunAsUser(ctx context.Context, userId string, fn func(ctx context.Context) error) error
+}
+
+// RowLevelSecurity runs the rest of an authenticated request in one
+// transaction bound to the user, so the database policies decide which groups
+// and expenses it can touch. The transaction is rolled back when the handler
+// fails. Unauthenticated requests pass through.
+func RowLevelSecurity(o userTransactions) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userId, err := CtxGetUserId(c)
+		if err != nil {
+			c.Next()
+			return
+		}
+		// the response of a write is held back until the transaction commits, a
+		// client must not see a success for writes that were rolled back. Reads
+		// stream, so exports of large groups are not built in memory, and read
+		// one snapshot, so exports paging twice see the same expenses.
+		ctx := c.Request.Context()
+		writer := c.Writer
+		var buffer *bufferedWriter
+		if c.Request.Method != http.MethodGet && c.Request.Method != http.MethodHead {
+			buffer = newBufferedWriter(writer)
+			c.Writer = buffer
+		} else {
+			ctx = storage.ReadSnapshot(ctx)
+		}
+		err = o.RunAsUser(ctx, userId, func(ctx context.Context) error {
+			c.Request = c.Request.WithContext(ctx)
+			c.Next()
+			if c.IsAborted() || c.Writer.Status() >= 400 {
+				return errRequestFailed
+			}
+			return nil
+		})
+		c.Writer = writer
+		if err != nil && !errors.Is(err, errRequestFailed) {
+			if writer.Written() {
+				// a streamed read is already out, the commit failure can only be logged
+				c.Error(err)
+				return
+			}
+			abortWithError(c, err)
+			return
+		}
+		if buffer == nil {
+			return
+		}
+		if err := buffer.flushTo(writer); err != nil {
+			c.Error(err)
+		}
+	}
+}
+
+// bufferedWriter keeps the status, headers and body of a response until
+// flushTo sends them
+type bufferedWriter struct {
+	gin.ResponseWriter
+	header http.Header
+	status int
+	body   bytes.Buffer
+	// written is set once the handler sends the header or any of the body
+	written bool
+}
+
+func newBufferedWriter(w gin.ResponseWriter) *bufferedWriter {
+	return &bufferedWriter{ResponseWriter: w, header: w.Header().Clone(), status: w.Status()}
+}
+
+func (b *bufferedWriter) Header() http.Header {
+	return b.header
+}
+
+func (b *bufferedWriter) WriteHeader(code int) {
+	if code > 0 && !b.written {
+		b.status = code
+	}
+}
+
+func (b *bufferedWriter) WriteHeaderNow() {
+	b.written = true
+}
+
+func (b *bufferedWriter) Write(p []byte) (int, error) {
+	b.written = true
+	return b.body.Write(p)
+}
+
+func (b *bufferedWriter) WriteString(s string) (int, error) {
+	b.written = true
+	return b.body.WriteString(s)
+}
+
+func (b *bufferedWriter) Status() int {
+	return b.status
+}
+
+func (b *bufferedWriter) Size() int {
+	if !b.written {
+		return -1
+	}
+	return b.body.Len()
+}
+
+func (b *bufferedWriter) Written() bool {
+	return b.written
+}
+
+// Flush is a no-op, nothing leaves before the commit
+func (b *bufferedWriter) Flush() {}
+
+// flushTo sends the buffered response to w. A response that was not written
+// only passes on its headers and status, the error middleware may still answer.
+func (b *bufferedWriter) flushTo(w gin.ResponseWriter) error {
+	header := w.Header()
+	for key := range header {
+		if _, ok := b.header[key]; !ok {
+			header.Del(key)
+		}
+	}
+	for key, values := range b.header {
+		header[key] = values
+	}
+	w.WriteHeader(b.status)
+	if !b.written {
+		return nil
+	}
+	w.WriteHeaderNow()
+	_, err := w.Write(b.body.Bytes())
+	return err
+}
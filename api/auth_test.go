@@ -0,0 +1,84 @@
+package apiServer
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestBufferedWriterHoldsResponse(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	recorder := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(recorder)
+	c.Header(RequestIdHeader, "request")
+
+	writer := c.Writer
+	buffer := newBufferedWriter(writer)
+	c.Writer = buffer
+	c.Header("Location", "/expense/1")
+	c.JSON(201, gin.H{"id": "1"})
+	c.Writer.Flush()
+
+	if writer.Written() || recorder.Body.Len() > 0 {
+		t.Fatalf("response sent before flushTo: %q", recorder.Body.String())
+	}
+	if !c.Writer.Written() || c.Writer.Status() != 201 {
+		t.Fatalf("buffered writer: got written %v status %d, want true 201", c.Writer.Written(), c.Writer.Status())
+	}
+
+	if err := buffer.flushTo(writer); err != nil {
+		t.Fatalf("flushTo: %v", err)
+	}
+	if recorder.Code != 201 || recorder.Body.String() != `{"id":"1"}` {
+		t.Fatalf("flushed response: got %d %q", recorder.Code, recorder.Body.String())
+	}
+	if recorder.Header().Get("Location") != "/expense/1" || recorder.Header().Get(RequestIdHeader) != "request" {
+		t.Fatalf("flushed headers: got %v", recorder.Header())
+	}
+}
+
+func TestBufferedWriterDiscardedOnFailure(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	recorder := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(recorder)
+
+	writer := c.Writer
+	c.Writer = newBufferedWriter(writer)
+	c.Header("Location", "/expense/1")
+	c.JSON(201, gin.H{"id": "1"})
+
+	// a failed commit drops the buffer and answers with the error instead
+	c.Writer = writer
+	c.AbortWithStatusJSON(500, gin.H{"message": "internal error"})
+	if recorder.Code != 500 || recorder.Header().Get("Location") != "" {
+		t.Fatalf("failed commit: got %d with headers %v", recorder.Code, recorder.Header())
+	}
+}
+
+func TestRowLevelSecurityStreamsReads(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	for _, method := range []string{"GET", "POST"} {
+		t.Run(method, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			var sentBeforeCommit bool
+			r := gin.New()
+			r.Handle(method, "/export.csv",
+				func(c *gin.Context) { c.Set(CtxUserId, testUser) },
+				RowLevelSecurity(&userTx{}),
+				func(c *gin.Context) {
+					c.String(200, "date,description\n")
+					c.Writer.Flush()
+					sentBeforeCommit = w.Body.Len() > 0
+				})
+			r.ServeHTTP(w, httptest.NewRequest(method, "/export.csv", nil))
+
+			if want := method == "GET"; sentBeforeCommit != want {
+				t.Fatalf("response sent before the commit: got %v, want %v", sentBeforeCommit, want)
+			}
+			if w.Code != 200 || w.Body.String() != "date,description\n" {
+				t.Fatalf("response: got %d %q", w.Code, w.Body.String())
+			}
+		})
+	}
+}
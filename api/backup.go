@@ -0,0 +1,73 @@
+package apiServer
+
+import (
+	"bytes"
+	"fmt"
+	"splitExpense/config"
+	"splitExpense/orchestrator"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ExportAccountHandler struct {
+	o orchestrator.ExpenseAppImpl
+}
+
+func (h *ExportAccountHandler) Method() Method {
+	return GET
+}
+
+func (h *ExportAccountHandler) Path() string {
+	return Path("/backup/account")
+}
+
+func (h *ExportAccountHandler) Handle(c *gin.Context, cfg *config.Config) {
+	userId, err := CtxGetUserId(c)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	// the archive is built in memory so a failure can still be reported
+	var buf bytes.Buffer
+	if err := h.o.ExportAccountBackup(c.Request.Context(), userId, &buf); err != nil {
+		abortWithError(c, err)
+		return
+	}
+	sendArchive(c, "account", buf.Bytes())
+}
+
+type ExportGroupHandler struct {
+	o orchestrator.ExpenseAppImpl
+}
+
+func (h *ExportGroupHandler) Method() Method {
+	return GET
+}
+
+func (h *ExportGroupHandler) Path() string {
+	return Path("/backup/group/:id")
+}
+
+func (h *ExportGroupHandler) Handle(c *gin.Context, cfg *config.Config) {
+	userId, err := CtxGetUserId(c)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	var buf bytes.Buffer
+	err = h.o.ExportGroupBackup(c.Request.Context(), userId, c.Param("id"), &buf)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+	sendArchive(c, "group", buf.Bytes())
+}
+
+func sendArchive(c *gin.Context, kind string, data []byte) {
+	name := fmt.Sprintf("splitexpense-%s-%s.zip", kind, time.Now().UTC().Format("20060102-150405"))
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, name))
+	c.Data(200, "application/zip", data)
+}
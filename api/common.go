@@ -1,5 +1,12 @@
 package apiServer
 
+import (
+	"splitExpense/expense"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
 type SplitJson struct {
 	Type            string
 	TotalAmount     float64
@@ -8,3 +15,15 @@ type SplitJson struct {
 	ShareSplit      map[string]int
 	UnitSplit       map[string]float64
 }
+
+// includeArchived reads the includeArchived query parameter of history endpoints
+func includeArchived(c *gin.Context) bool {
+	include, _ := strconv.ParseBool(c.Query("includeArchived"))
+	return include
+}
+
+// pageRequest reads the cursor and limit query parameters of list endpoints
+func pageRequest(c *gin.Context) expense.PageRequest {
+	limit, _ := strconv.Atoi(c.Query("limit"))
+	return expense.PageRequest{Cursor: c.Query("cursor"), Limit: limit}
+}
@@ -0,0 +1,186 @@
+package apiServer
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"splitExpense/expense"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+)
+
+const CtxRequestId = "request_id"
+
+// RequestIdHeader carries the request id, taken from the client when it sends one
+const RequestIdHeader = "X-Request-Id"
+
+// ErrorResponse is the body of every failed request
+type ErrorResponse struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	// Fields says what is wrong with each request field of a validation error
+	Fields    map[string]string `json:"fields,omitempty"`
+	RequestId string            `json:"requestId,omitempty"`
+	// CurrentVersion is set on version conflicts so clients can refetch and merge
+	CurrentVersion int `json:"currentVersion,omitempty"`
+}
+
+// httpError fixes the status of an error that has no AppError type, such as a
+// missing token or a body over the size limit
+type httpError struct {
+	status int
+	err    error
+}
+
+func (e *httpError) Error() string {
+	return e.err.Error()
+}
+
+func (e *httpError) Unwrap() error {
+	return e.err
+}
+
+// abortWithError stops the request, ErrorMiddleware answers it from err
+func abortWithError(c *gin.Context, err error) {
+	c.Error(err)
+	c.Abort()
+}
+
+// abortWithStatus stops the request with status unless err maps to a status of its own
+func abortWithStatus(c *gin.Context, status int, err error) {
+	abortWithError(c, &httpError{status: status, err: err})
+}
+
+func RequestIdMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestId := c.GetHeader(RequestIdHeader)
+		if requestId == "" || len(requestId) > 128 {
+			requestId = uuid.NewString()
+		}
+		c.Set(CtxRequestId, requestId)
+		c.Header(RequestIdHeader, requestId)
+		c.Next()
+	}
+}
+
+// ErrorMiddleware answers a request that recorded an error and wrote nothing,
+// with the status of the error and an ErrorResponse. Handlers only record the
+// error, once a streamed response has started it is left to the logs.
+func ErrorMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+		status, body := errorResponse(c, c.Errors.Last().Err)
+		c.AbortWithStatusJSON(status, body)
+	}
+}
+
+// errorResponse maps err to a status and body, for handlers that add to the
+// body of the error middleware. Server errors only reach the logs, under the
+// request id the client is given.
+func errorResponse(c *gin.Context, err error) (int, *ErrorResponse) {
+	status, body := statusOf(err)
+	body.RequestId = c.GetString(CtxRequestId)
+	if status >= 500 {
+		log.Printf("request %s failed with %d: %v", body.RequestId, status, err)
+	}
+	return status, body
+}
+
+func statusOf(err error) (int, *ErrorResponse) {
+	var conflict *expense.VersionConflictError
+	if errors.As(err, &conflict) {
+		return 409, &ErrorResponse{Code: "ConflictError", Message: conflict.Error(), CurrentVersion: conflict.CurrentVersion}
+	}
+	var unsettled *expense.UnsettledGroupError
+	if errors.As(err, &unsettled) {
+		return 409, &ErrorResponse{Code: "ConflictError", Message: unsettled.Error()}
+	}
+	// service errors keep the storage error, a missing row is not found
+	if errors.Is(err, sql.ErrNoRows) {
+		return 404, &ErrorResponse{Code: "NotFoundError", Message: "not found"}
+	}
+
+	var appErr *expense.AppError
+	if errors.As(err, &appErr) {
+		body := &ErrorResponse{Code: appErr.Type, Message: appErr.Message, Fields: appErr.Fields}
+		switch appErr.Type {
+		case "ValidationError", "InvalidQueryError":
+			return 400, body
+		case "NotFoundError":
+			return 404, body
+		case "ForbiddenError":
+			return 403, body
+		case "ConflictError":
+			return 409, body
+		default:
+			// the message of a service error is the storage error
+			body.Message = "internal error"
+			body.Fields = nil
+			return 500, body
+		}
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) {
+		return 504, &ErrorResponse{Code: "TimeoutError", Message: "the request took too long"}
+	}
+	var maxBytes *http.MaxBytesError
+	if errors.As(err, &maxBytes) {
+		return 413, &ErrorResponse{Code: "ValidationError", Message: fmt.Sprintf("request body is over %d bytes", maxBytes.Limit)}
+	}
+
+	status := 500
+	var statusErr *httpError
+	if errors.As(err, &statusErr) {
+		status = statusErr.status
+	}
+	// errors without a type may carry internals, only client errors are shown
+	if status >= 500 {
+		return status, &ErrorResponse{Code: "ServiceError", Message: http.StatusText(status)}
+	}
+	code := map[int]string{401: "UnauthorizedError", 403: "ForbiddenError", 404: "NotFoundError", 409: "ConflictError"}[status]
+	if code == "" {
+		code = "ValidationError"
+	}
+	return status, &ErrorResponse{Code: code, Message: err.Error()}
+}
+
+// bindError turns a failed ShouldBindJSON into a ValidationError naming the bad fields
+func bindError(err error) error {
+	var maxBytes *http.MaxBytesError
+	if errors.As(err, &maxBytes) {
+		return err
+	}
+	var fieldErrs validator.ValidationErrors
+	if errors.As(err, &fieldErrs) {
+		fields := map[string]string{}
+		for _, fe := range fieldErrs {
+			fields[fe.Field()] = fieldMessage(fe)
+		}
+		return expense.ErrFieldValidation("invalid request", fields)
+	}
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		return expense.ErrFieldValidation("invalid request", map[string]string{typeErr.Field: "should be a " + typeErr.Type.String()})
+	}
+	var syntaxErr *json.SyntaxError
+	if errors.As(err, &syntaxErr) {
+		return expense.ErrValidation(fmt.Sprintf("malformed JSON at offset %d", syntaxErr.Offset))
+	}
+	if errors.Is(err, io.EOF) {
+		return expense.ErrValidation("request body is empty")
+	}
+	if errors.Is(err, io.ErrUnexpectedEOF) {
+		return expense.ErrValidation("malformed JSON, the body ends early")
+	}
+	return expense.ErrValidation(err.Error())
+}
@@ -0,0 +1,31 @@
+package apiServer
+
+import (
+	"errors"
+	"testing"
+
+	"splitExpense/expense"
+)
+
+func TestStatusOfHidesServerErrors(t *testing.T) {
+	dbErr := errors.New(`pq: relation "expense" does not exist`)
+	tests := []struct {
+		name    string
+		err     error
+		status  int
+		message string
+	}{
+		{"service error", expense.ErrServiceCause(dbErr), 500, "internal error"},
+		{"untyped error", dbErr, 500, "Internal Server Error"},
+		{"validation error", expense.ErrValidation("amount should be positive"), 400, "amount should be positive"},
+		{"not found", expense.ErrNotFound("group not found"), 404, "group not found"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, body := statusOf(tt.err)
+			if status != tt.status || body.Message != tt.message {
+				t.Fatalf("statusOf: got (%d, %q), want (%d, %q)", status, body.Message, tt.status, tt.message)
+			}
+		})
+	}
+}
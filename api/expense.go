@@ -2,10 +2,13 @@ package apiServer
 
 import (
 	"errors"
+	"fmt"
+	"maps"
 	"splitExpense/config"
 	"splitExpense/expense"
 	"splitExpense/orchestrator"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
@@ -26,38 +29,37 @@ func (h *CreateOrUpdateExpenseRouteHandler) Handle(c *gin.Context, cfg *config.C
 
 	userId, err := CtxGetUserId(c)
 	if err != nil {
-		c.AbortWithError(500, err)
+		abortWithError(c, err)
+		return
 	}
 
-	type CreateOrUpdateExpenseRequest struct {
-		ID          string               `json:"id"`
-		Description string               `json:"description"`
-		Amount      float64              `json:"amount"`
-		Split       expense.SplitWrapper `json:"split"`
-		Payee       expense.PayerWrapper `json:"payee"`
-		GroupId     string               `json:"groupId"`
-	}
-	var req CreateOrUpdateExpenseRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.AbortWithError(400, err)
+	var req ExpenseRequest
+	if err := bindRequest(c, &req); err != nil {
+		abortWithError(c, err)
+		return
 	}
 
 	var updatedExpense *expense.Expense
 	if req.ID != "" {
 		// update request
-		updatedExpense, err = h.orchestrator.UpdateExpense(userId, expense.Expense{
-			ID:          req.ID,
-			Description: req.Description,
-			Amount:      req.Amount,
-			SplitW:      req.Split,
-			PayeeW:      req.Payee,
+		updatedExpense, err = h.orchestrator.UpdateExpense(c.Request.Context(), userId, expense.Expense{
+			ID:             req.ID,
+			Description:    req.Description,
+			Category:       req.Category,
+			Amount:         req.Amount,
+			SplitW:         req.Split,
+			PayeeW:         req.Payee,
+			GroupId:        req.GroupId,
+			IsGroupExpense: len(req.GroupId) > 0,
+			Version:        req.Version,
 		})
 
 	} else {
 
 		// create request
-		updatedExpense, err = h.orchestrator.CreateExpense(userId, expense.ExpenseCreate{
+		updatedExpense, err = h.orchestrator.CreateExpense(c.Request.Context(), userId, expense.ExpenseCreate{
 			Description:    req.Description,
+			Category:       req.Category,
 			Amount:         req.Amount,
 			SplitW:         req.Split,
 			PayeeW:         req.Payee,
@@ -68,7 +70,7 @@ func (h *CreateOrUpdateExpenseRouteHandler) Handle(c *gin.Context, cfg *config.C
 	}
 
 	if err != nil {
-		c.AbortWithError(500, err)
+		abortWithError(c, err)
 		return
 	}
 
@@ -76,6 +78,129 @@ func (h *CreateOrUpdateExpenseRouteHandler) Handle(c *gin.Context, cfg *config.C
 
 }
 
+// BulkExpenseResult is the outcome of the expense at Index of a bulk request
+type BulkExpenseResult struct {
+	Index   int              `json:"index"`
+	Expense *expense.Expense `json:"expense,omitempty"`
+	Error   *ErrorResponse   `json:"error,omitempty"`
+}
+
+type BulkExpenseResponse struct {
+	Created int                 `json:"created"`
+	Results []BulkExpenseResult `json:"results"`
+}
+
+type CreateExpensesRouteHandler struct {
+	orchestrator orchestrator.ExpenseAppImpl
+}
+
+func (h *CreateExpensesRouteHandler) Method() Method {
+	return POST
+}
+
+func (h *CreateExpensesRouteHandler) Path() string {
+	return Path("/expenses/bulk")
+}
+
+// Handle creates every expense of the body as POST /expense would. It answers
+// 201 when all were created, 207 with the errors of the others in best effort
+// mode, and an error listing the results when nothing was created.
+func (h *CreateExpensesRouteHandler) Handle(c *gin.Context, cfg *config.Config) {
+	userId, err := CtxGetUserId(c)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	var req BulkExpenseRequest
+	if err := bindRequest(c, &req); err != nil {
+		abortWithError(c, err)
+		return
+	}
+	atomic := req.Mode != "bestEffort"
+
+	response := BulkExpenseResponse{Results: make([]BulkExpenseResult, len(req.Expenses))}
+	creates := []expense.ExpenseCreate{}
+	positions := []int{}
+	for i, item := range req.Expenses {
+		response.Results[i].Index = i
+		// the expense is checked as a create, an id is reported along with its other fields
+		id := item.ID
+		item.ID = ""
+		err := checkRequest(&item)
+		if id != "" {
+			fields := map[string]string{}
+			var appErr *expense.AppError
+			if errors.As(err, &appErr) {
+				maps.Copy(fields, appErr.Fields)
+			}
+			fields["id"] = "should be empty, bulk requests only create expenses"
+			err = expense.ErrFieldValidation("invalid request", fields)
+		}
+		if err != nil {
+			_, response.Results[i].Error = statusOf(err)
+			continue
+		}
+		creates = append(creates, expense.ExpenseCreate{
+			Description:    item.Description,
+			Category:       item.Category,
+			Amount:         item.Amount,
+			SplitW:         item.Split,
+			PayeeW:         item.Payee,
+			IsGroupExpense: len(item.GroupId) > 0,
+			GroupId:        item.GroupId,
+		})
+		positions = append(positions, i)
+	}
+
+	if len(creates) == 0 || atomic && len(creates) < len(req.Expenses) {
+		abortWithResults(c, nothingCreated(len(req.Expenses)-len(creates), len(req.Expenses)), response)
+		return
+	}
+	results, err := h.orchestrator.CreateExpenses(c.Request.Context(), userId, creates, atomic)
+	if err != nil && results == nil {
+		abortWithError(c, err)
+		return
+	}
+	for j, result := range results {
+		i := positions[j]
+		response.Results[i].Expense = result.Expense
+		if result.Err != nil {
+			_, response.Results[i].Error = statusOf(result.Err)
+		}
+		if result.Expense != nil {
+			response.Created++
+		}
+	}
+	if err != nil {
+		abortWithResults(c, err, response)
+		return
+	}
+	if response.Created == 0 {
+		abortWithResults(c, nothingCreated(len(req.Expenses), len(req.Expenses)), response)
+		return
+	}
+
+	if response.Created == len(req.Expenses) {
+		c.JSON(201, response)
+		return
+	}
+	c.JSON(207, response)
+}
+
+func nothingCreated(invalid int, total int) error {
+	return expense.ErrValidation(fmt.Sprintf("%d of %d expenses are invalid, nothing was created", invalid, total))
+}
+
+// abortWithResults answers err with the results of a bulk request that created nothing
+func abortWithResults(c *gin.Context, err error, response BulkExpenseResponse) {
+	status, body := errorResponse(c, err)
+	c.AbortWithStatusJSON(status, struct {
+		*ErrorResponse
+		BulkExpenseResponse
+	}{body, response})
+}
+
 type DeleteExpenseRouteHandler struct {
 	orchestrator orchestrator.ExpenseAppImpl
 }
@@ -91,13 +216,13 @@ func (d *DeleteExpenseRouteHandler) Path() string {
 func (d *DeleteExpenseRouteHandler) Handle(c *gin.Context, cfg *config.Config) {
 	userId, err := CtxGetUserId(c)
 	if err != nil {
-		c.Status(500)
+		abortWithError(c, err)
 		return
 	}
 
-	_, err = d.orchestrator.DeleteExpense(userId, c.Param("id"))
+	_, err = d.orchestrator.DeleteExpense(c.Request.Context(), userId, c.Param("id"))
 	if err != nil {
-		c.AbortWithError(500, errors.Join(errors.New("could not delete expense"), err))
+		abortWithError(c, fmt.Errorf("could not delete expense: %w", err))
 		return
 	}
 
@@ -119,13 +244,13 @@ func (h *SettleExpenseHandler) Path() string {
 func (h *SettleExpenseHandler) Handle(c *gin.Context, cfg *config.Config) {
 	userId, err := CtxGetUserId(c)
 	if err != nil {
-		c.Status(500)
+		abortWithError(c, err)
 		return
 	}
 
-	_, err = h.orchestrator.SettleExpense(userId, c.Param("id"))
+	_, err = h.orchestrator.SettleExpense(c.Request.Context(), userId, c.Param("id"))
 	if err != nil {
-		c.AbortWithError(500, err)
+		abortWithError(c, err)
 		return
 	}
 
@@ -147,18 +272,117 @@ func (h *UserExpensesHandler) Path() string {
 func (h *UserExpensesHandler) Handle(c *gin.Context, cfg *config.Config) {
 	userId, err := CtxGetUserId(c)
 	if err != nil {
-		c.AbortWithError(500, err)
+		abortWithError(c, err)
 		return
 	}
 
+	// pageNumber is only kept for older clients, new clients page with cursor
 	pageNumber := c.Query("pageNumber")
-	page, _ := strconv.Atoi(pageNumber)
+	if pageNumber != "" && c.Query("cursor") == "" {
+		page, _ := strconv.Atoi(pageNumber)
 
-	history, err := h.o.GetUserExpenseHistory(userId, page)
+		history, err := h.o.GetUserExpenseHistory(c.Request.Context(), userId, page)
+		if err != nil {
+			abortWithError(c, err)
+			return
+		}
+
+		c.JSON(200, history)
+		return
+	}
+
+	history, err := h.o.GetUserExpenseHistoryPage(c.Request.Context(), userId, pageRequest(c))
 	if err != nil {
-		c.AbortWithError(500, err)
+		abortWithError(c, err)
 		return
 	}
 
 	c.JSON(200, history)
 }
+
+type SearchExpensesHandler struct {
+	o orchestrator.ExpenseAppImpl
+}
+
+func (h *SearchExpensesHandler) Method() Method {
+	return GET
+}
+
+func (h *SearchExpensesHandler) Path() string {
+	return Path("/expenses/search")
+}
+
+func (h *SearchExpensesHandler) Handle(c *gin.Context, cfg *config.Config) {
+	userId, err := CtxGetUserId(c)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	search, err := expenseSearch(c)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	results, err := h.o.SearchExpenses(c.Request.Context(), userId, search, pageRequest(c))
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	c.JSON(200, results)
+}
+
+// expenseSearch reads the search filters from the query string. Amounts are
+// decimals, from and to are dates (2006-01-02) or RFC 3339 timestamps and a
+// plain date for to includes the whole day.
+func expenseSearch(c *gin.Context) (expense.ExpenseSearch, error) {
+	search := expense.ExpenseSearch{
+		Query:         c.Query("q"),
+		GroupId:       c.Query("groupId"),
+		ParticipantId: c.Query("participantId"),
+		PayerId:       c.Query("payerId"),
+		Status:        expense.ExpenseStatus(c.Query("status")),
+		Category:      c.Query("category"),
+
+		IncludeArchived: includeArchived(c),
+	}
+
+	for param, target := range map[string]**float64{"minAmount": &search.MinAmount, "maxAmount": &search.MaxAmount} {
+		if value := c.Query(param); value != "" {
+			amount, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return search, expense.ErrInvalidQuery("invalid " + param)
+			}
+			*target = &amount
+		}
+	}
+
+	if value := c.Query("from"); value != "" {
+		from, _, err := parseSearchTime(value)
+		if err != nil {
+			return search, expense.ErrInvalidQuery("invalid from")
+		}
+		search.CreatedFrom = &from
+	}
+	if value := c.Query("to"); value != "" {
+		to, isDate, err := parseSearchTime(value)
+		if err != nil {
+			return search, expense.ErrInvalidQuery("invalid to")
+		}
+		if isDate {
+			to = to.AddDate(0, 0, 1)
+		}
+		search.CreatedBefore = &to
+	}
+	return search, nil
+}
+
+func parseSearchTime(value string) (time.Time, bool, error) {
+	if t, err := time.Parse(time.DateOnly, value); err == nil {
+		return t, true, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	return t, false, err
+}
@@ -0,0 +1,167 @@
+package apiServer
+
+import (
+	"fmt"
+	"splitExpense/config"
+	"splitExpense/expense"
+	"splitExpense/export"
+	"splitExpense/orchestrator"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ExportGroupCSVHandler struct {
+	o orchestrator.ExpenseAppImpl
+}
+
+func (h *ExportGroupCSVHandler) Method() Method {
+	return GET
+}
+
+func (h *ExportGroupCSVHandler) Path() string {
+	return Path("/group/:id/export.csv")
+}
+
+func (h *ExportGroupCSVHandler) Handle(c *gin.Context, cfg *config.Config) {
+	userId, err := CtxGetUserId(c)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	filter, err := exportFilter(c)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	w := &attachment{c: c, name: "group", ext: "csv", contentType: "text/csv; charset=utf-8"}
+	err = h.o.ExportGroupCSV(c.Request.Context(), userId, c.Param("id"), filter, w)
+	abortExport(c, w, err)
+}
+
+type ExportExpensesCSVHandler struct {
+	o orchestrator.ExpenseAppImpl
+}
+
+func (h *ExportExpensesCSVHandler) Method() Method {
+	return GET
+}
+
+func (h *ExportExpensesCSVHandler) Path() string {
+	return Path("/expenses/export.csv")
+}
+
+func (h *ExportExpensesCSVHandler) Handle(c *gin.Context, cfg *config.Config) {
+	userId, err := CtxGetUserId(c)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	filter, err := exportFilter(c)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	w := &attachment{c: c, name: "expenses", ext: "csv", contentType: "text/csv; charset=utf-8"}
+	err = h.o.ExportExpensesCSV(c.Request.Context(), userId, filter, w)
+	abortExport(c, w, err)
+}
+
+// ExportJournalHandler serves /expenses/export.ledger and /expenses/export.beancount,
+// the query takes the filters of the CSV export, currency and account, the
+// account the user's payments come from
+type ExportJournalHandler struct {
+	o      orchestrator.ExpenseAppImpl
+	syntax export.Syntax
+}
+
+func (h *ExportJournalHandler) Method() Method {
+	return GET
+}
+
+func (h *ExportJournalHandler) Path() string {
+	return Path("/expenses/export." + string(h.syntax))
+}
+
+func (h *ExportJournalHandler) Handle(c *gin.Context, cfg *config.Config) {
+	userId, err := CtxGetUserId(c)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	filter, err := exportFilter(c)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+	opts := export.JournalOptions{Syntax: h.syntax, Currency: c.Query("currency"), FundingAccount: c.Query("account")}
+
+	w := &attachment{c: c, name: "expenses", ext: string(h.syntax), contentType: "text/plain; charset=utf-8"}
+	err = h.o.ExportExpensesJournal(c.Request.Context(), userId, filter, opts, w)
+	abortExport(c, w, err)
+}
+
+// exportFilter reads from, to, status and includeArchived, dates as for expense search
+func exportFilter(c *gin.Context) (orchestrator.ExportFilter, error) {
+	filter := orchestrator.ExportFilter{
+		Status:          expense.ExpenseStatus(c.Query("status")),
+		IncludeArchived: includeArchived(c),
+	}
+	if value := c.Query("from"); value != "" {
+		from, _, err := parseSearchTime(value)
+		if err != nil {
+			return filter, expense.ErrInvalidQuery("invalid from")
+		}
+		filter.From = &from
+	}
+	if value := c.Query("to"); value != "" {
+		to, isDate, err := parseSearchTime(value)
+		if err != nil {
+			return filter, expense.ErrInvalidQuery("invalid to")
+		}
+		if isDate {
+			to = to.AddDate(0, 0, 1)
+		}
+		filter.Before = &to
+	}
+	return filter, nil
+}
+
+// abortExport answers an export error with a status while nothing has been
+// sent, once rows are streamed the error can only be recorded
+func abortExport(c *gin.Context, w *attachment, err error) {
+	if err == nil {
+		return
+	}
+	if w.started {
+		c.Error(err)
+		return
+	}
+	abortWithError(c, err)
+}
+
+// attachment streams an export to the response, the download headers are
+// only sent with the first write so a failure before it still gets a status
+type attachment struct {
+	c           *gin.Context
+	name        string
+	ext         string
+	contentType string
+	started     bool
+}
+
+func (w *attachment) Write(p []byte) (int, error) {
+	if !w.started {
+		w.started = true
+		name := fmt.Sprintf("splitexpense-%s-%s.%s", w.name, time.Now().UTC().Format("20060102-150405"), w.ext)
+		w.c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, name))
+		w.c.Header("Content-Type", w.contentType)
+		w.c.Status(200)
+	}
+	return w.c.Writer.Write(p)
+}
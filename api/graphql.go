@@ -0,0 +1,65 @@
+package apiServer
+
+import (
+	"net/http"
+	"splitExpense/config"
+	"splitExpense/graph"
+	"splitExpense/graphql"
+	"splitExpense/orchestrator"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxGraphQLRequest bounds the body of a query, the depth and complexity
+// limits of the schema bound what it may select
+const maxGraphQLRequest = 64 << 10
+
+type GraphQLHandler struct {
+	o orchestrator.ExpenseAppImpl
+}
+
+func (h *GraphQLHandler) Method() Method {
+	return POST
+}
+
+func (h *GraphQLHandler) Path() string {
+	return Path("/graphql")
+}
+
+// Handle runs a query as the authenticated user. Field errors come back next to
+// the partial data with a 200, a query that does not parse or validate is a 400.
+func (h *GraphQLHandler) Handle(c *gin.Context, cfg *config.Config) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxGraphQLRequest)
+	var req graphql.Request
+	if err := c.ShouldBindJSON(&req); err != nil {
+		abortWithError(c, bindError(err))
+		return
+	}
+	userId, err := CtxGetUserId(c)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	result := graph.Execute(c.Request.Context(), h.o, userId, req)
+	if result.RequestFailed() {
+		c.JSON(400, result)
+		return
+	}
+	c.JSON(200, result)
+}
+
+type GraphQLSchemaHandler struct{}
+
+func (h *GraphQLSchemaHandler) Method() Method {
+	return GET
+}
+
+func (h *GraphQLSchemaHandler) Path() string {
+	return Path("/graphql")
+}
+
+// Handle prints the schema, introspection queries are not supported
+func (h *GraphQLSchemaHandler) Handle(c *gin.Context, cfg *config.Config) {
+	c.String(200, graph.Schema.SDL())
+}
@@ -3,6 +3,7 @@ package apiServer
 import (
 	"errors"
 	"splitExpense/config"
+	"splitExpense/expense"
 	"splitExpense/orchestrator"
 
 	"github.com/gin-gonic/gin"
@@ -23,23 +24,27 @@ func (h *JoinGroupRouteHandler) Path() string {
 
 func (h *JoinGroupRouteHandler) Handle(c *gin.Context, cfg *config.Config) {
 	// decode and validation
-	type JoinGroupRequest struct {
-		MemberId string `json:"new_member_id" binding:"required"`
-	}
 	var req JoinGroupRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.AbortWithError(400, err)
+	if err := bindRequest(c, &req); err != nil {
+		abortWithError(c, err)
+		return
 	}
 
 	userId, err := CtxGetUserId(c)
 	if err != nil {
-		c.AbortWithError(500, err)
+		abortWithError(c, err)
+		return
 	}
 
 	// orchestrator call
-	ok, err := h.orchestrator.JoinGroup(userId, req.MemberId, c.Param("id"))
-	if err != nil || !ok {
-		c.AbortWithError(400, err)
+	ok, err := h.orchestrator.JoinGroup(c.Request.Context(), userId, req.MemberId, c.Param("id"))
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+	if !ok {
+		abortWithError(c, expense.ErrConflict("user could not join the group"))
+		return
 	}
 
 	// response
@@ -61,30 +66,68 @@ func (h *CreateGroupRouteHandler) Path() string {
 
 func (h *CreateGroupRouteHandler) Handle(c *gin.Context, cfg *config.Config) {
 	// decode and validation
-	type CreateGroupRequest struct {
-		Name        string `json:"name" binding:"required"`
-		Description string `json:"description"`
-	}
-
 	var req CreateGroupRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.AbortWithError(400, err)
+	if err := bindRequest(c, &req); err != nil {
+		abortWithError(c, err)
+		return
 	}
 	userId, err := CtxGetUserId(c)
 	if err != nil {
-		c.Status(500)
+		abortWithError(c, err)
 		return
 	}
 
 	// orchestrator call
-	group, err := h.orchestrator.CreateGroup(userId, req.Name, req.Description)
+	group, err := h.orchestrator.CreateGroup(c.Request.Context(), userId, req.Name, req.Description)
 	if err != nil {
-		c.AbortWithError(400, err)
+		abortWithError(c, err)
+		return
 	}
 	// response
 	c.JSON(201, group)
 }
 
+// RouteHandler implementation for UpdateGroup
+type UpdateGroupRouteHandler struct {
+	orchestrator orchestrator.ExpenseAppImpl
+}
+
+func (h *UpdateGroupRouteHandler) Method() Method {
+	return PUT
+}
+
+func (h *UpdateGroupRouteHandler) Path() string {
+	return Path("/group/:id")
+}
+
+func (h *UpdateGroupRouteHandler) Handle(c *gin.Context, cfg *config.Config) {
+	// decode and validation
+	var req UpdateGroupRequest
+	if err := bindRequest(c, &req); err != nil {
+		abortWithError(c, err)
+		return
+	}
+	userId, err := CtxGetUserId(c)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	// orchestrator call
+	group, err := h.orchestrator.UpdateGroup(c.Request.Context(), userId, expense.Group{
+		Id:          c.Param("id"),
+		Name:        req.Name,
+		Description: req.Description,
+		Version:     req.Version,
+	})
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+	// response
+	c.JSON(200, group)
+}
+
 // RouteHandler implementation for CreateGroup
 type LeaveGroupRouteHandler struct {
 	orchestrator orchestrator.ExpenseAppImpl
@@ -101,14 +144,12 @@ func (h *LeaveGroupRouteHandler) Path() string {
 func (h *LeaveGroupRouteHandler) Handle(c *gin.Context, cfg *config.Config) {
 	userId, err := CtxGetUserId(c)
 	if err != nil {
-		c.Status(500)
+		abortWithError(c, err)
 		return
 	}
-	// orchestrator call
-	_, err = h.orchestrator.LeaveGroup(userId, c.Param("id"))
-
-	if err != nil {
-		c.JSON(500, gin.H{"error": err.Error()})
+	// orchestrator call, LeaveGroup returns *AppError so it is not compared as an error interface
+	if _, appErr := h.orchestrator.LeaveGroup(c.Request.Context(), userId, c.Param("id")); appErr != nil {
+		abortWithError(c, appErr)
 		return
 	}
 	// response
@@ -131,21 +172,59 @@ func (h *DeleteGroupRouteHandler) Path() string {
 func (h *DeleteGroupRouteHandler) Handle(c *gin.Context, cfg *config.Config) {
 	groupId := c.Param("id")
 	if groupId == "" {
-		c.AbortWithError(400, errors.New("invalid group id"))
+		abortWithError(c, expense.ErrValidation("invalid group id"))
 		return
 	}
 
 	userId, err := CtxGetUserId(c)
 	if err != nil {
-		c.AbortWithError(500, err)
+		abortWithError(c, err)
+		return
+	}
+
+	policy := expense.GroupDeletePolicy(c.Query("policy"))
+	report, err := h.o.DeleteGroup(c.Request.Context(), userId, groupId, policy)
+	var unsettled *expense.UnsettledGroupError
+	if errors.As(err, &unsettled) {
+		// the report says what deleting with the archive policy would affect
+		status, body := errorResponse(c, err)
+		c.AbortWithStatusJSON(status, struct {
+			*ErrorResponse
+			Report expense.GroupDeletion `json:"report"`
+		}{body, unsettled.Report})
+		return
+	}
+	if err != nil {
+		abortWithError(c, err)
 		return
 	}
 
-	ok, err := h.o.DeleteGroup(userId, groupId)
+	c.JSON(201, report)
+}
+
+type GroupExpensesHandler struct {
+	o orchestrator.ExpenseAppImpl
+}
+
+func (h *GroupExpensesHandler) Method() Method {
+	return GET
+}
+
+func (h *GroupExpensesHandler) Path() string {
+	return Path("/group/:id/expenses")
+}
+
+func (h *GroupExpensesHandler) Handle(c *gin.Context, cfg *config.Config) {
+	userId, err := CtxGetUserId(c)
 	if err != nil {
-		c.AbortWithError(500, err)
+		abortWithError(c, err)
 		return
 	}
 
-	c.JSON(201, gin.H{"deleted": ok})
+	history, err := h.o.GetGroupExpenses(c.Request.Context(), userId, c.Param("id"), includeArchived(c), pageRequest(c))
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+	c.JSON(200, history)
 }
@@ -0,0 +1,136 @@
+package apiServer
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"splitExpense/expense"
+	"splitExpense/orchestrator"
+	"splitExpense/storage"
+
+	"github.com/gin-gonic/gin"
+)
+
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// IdempotentReplayedHeader is set on responses replayed for a reused key
+const IdempotentReplayedHeader = "Idempotent-Replayed"
+
+// maxIdempotentRequest bounds the bodies read to identify a request, the largest routes take are imports
+const maxIdempotentRequest = maxImportSize
+
+// maxIdempotentResponse bounds the responses kept for replay, bigger ones release the key
+const maxIdempotentResponse = 1 << 20
+
+// Idempotency makes retried requests safe. A request with an Idempotency-Key
+// header runs once per key and user, later requests with the key get the
+// stored response back, or are rejected when their method, path or body
+// differ. Keys expire after IDEMPOTENCY_KEY_TTL. Requests that fail, with an
+// error or a 5xx, release the key so they can be retried.
+//
+// Pre goes after Authenticate in the PreHandlers of a route.
+type Idempotency struct {
+	o idempotencyKeys
+}
+
+// idempotencyKeys keeps the reservations and responses of Idempotency, the orchestrator in the server
+type idempotencyKeys interface {
+	IdempotencyEnabled() bool
+	ReserveIdempotencyKey(ctx context.Context, userId string, key string, requestHash string) (*storage.IdempotencyRecord, bool, error)
+	CompleteIdempotencyKey(ctx context.Context, userId string, key string, status int, contentType string, response []byte) error
+	ReleaseIdempotencyKey(ctx context.Context, userId string, key string) error
+}
+
+func NewIdempotency(o orchestrator.ExpenseAppImpl) *Idempotency {
+	return &Idempotency{o: &o}
+}
+
+// Pre reserves the key and stores the response of the request, or answers
+// with the stored response of the first request
+func (i *Idempotency) Pre(c *gin.Context) {
+	key := c.GetHeader(IdempotencyKeyHeader)
+	userId, err := CtxGetUserId(c)
+	if key == "" || err != nil || !i.o.IdempotencyEnabled() {
+		c.Next()
+		return
+	}
+	if len(key) > 255 {
+		abortWithError(c, expense.ErrFieldValidation("invalid Idempotency-Key", map[string]string{IdempotencyKeyHeader: "should have at most 255 characters"}))
+		return
+	}
+
+	// the body is read once to identify the request, handlers read it again
+	body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxIdempotentRequest))
+	if err != nil {
+		abortWithStatus(c, 400, err)
+		return
+	}
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+	hash := sha256.New()
+	io.WriteString(hash, c.Request.Method+" "+c.Request.URL.RequestURI()+"\n")
+	hash.Write(body)
+	requestHash := hex.EncodeToString(hash.Sum(nil))
+
+	ctx := c.Request.Context()
+	record, reserved, err := i.o.ReserveIdempotencyKey(ctx, userId, key, requestHash)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+	if !reserved {
+		switch {
+		case record.RequestHash != requestHash:
+			abortWithStatus(c, 422, errors.New("Idempotency-Key was already used with a different request"))
+		case record.Status == 0:
+			abortWithError(c, expense.ErrConflict("a request with this Idempotency-Key is still running"))
+		default:
+			c.Header(IdempotentReplayedHeader, "true")
+			c.Data(record.Status, record.ContentType, record.Response)
+			c.Abort()
+		}
+		return
+	}
+
+	recorder := &responseRecorder{ResponseWriter: c.Writer}
+	c.Writer = recorder
+	c.Next()
+
+	// the response is complete once the handlers return, row level security
+	// has flushed it by then. Aborted requests, a failed commit among them,
+	// and server errors release the key.
+	status := c.Writer.Status()
+	if c.IsAborted() || len(c.Errors) > 0 || status >= 500 || recorder.body.Len() > maxIdempotentResponse {
+		if err := i.o.ReleaseIdempotencyKey(context.WithoutCancel(ctx), userId, key); err != nil {
+			c.Error(err)
+		}
+		return
+	}
+	err = i.o.CompleteIdempotencyKey(context.WithoutCancel(ctx), userId, key, status, c.Writer.Header().Get("Content-Type"), recorder.body.Bytes())
+	if err != nil {
+		c.Error(err)
+	}
+}
+
+// responseRecorder keeps a copy of the response body for replays
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (r *responseRecorder) Write(p []byte) (int, error) {
+	if r.body.Len() <= maxIdempotentResponse {
+		r.body.Write(p)
+	}
+	return r.ResponseWriter.Write(p)
+}
+
+func (r *responseRecorder) WriteString(s string) (int, error) {
+	if r.body.Len() <= maxIdempotentResponse {
+		r.body.WriteString(s)
+	}
+	return r.ResponseWriter.WriteString(s)
+}
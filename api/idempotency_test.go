@@ -0,0 +1,236 @@
+package apiServer
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"splitExpense/storage"
+
+	"github.com/gin-gonic/gin"
+)
+
+// memoryKeys reserves keys in a map, a reservation is atomic as the unique key of the table makes it
+type memoryKeys struct {
+	mu      sync.Mutex
+	records map[string]*storage.IdempotencyRecord
+}
+
+func (m *memoryKeys) IdempotencyEnabled() bool {
+	return true
+}
+
+func (m *memoryKeys) ReserveIdempotencyKey(ctx context.Context, userId string, key string, requestHash string) (*storage.IdempotencyRecord, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if record, ok := m.records[userId+key]; ok {
+		copied := *record
+		return &copied, false, nil
+	}
+	m.records[userId+key] = &storage.IdempotencyRecord{RequestHash: requestHash}
+	return nil, true, nil
+}
+
+func (m *memoryKeys) CompleteIdempotencyKey(ctx context.Context, userId string, key string, status int, contentType string, response []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	record := m.records[userId+key]
+	record.Status, record.ContentType, record.Response = status, contentType, response
+	return nil
+}
+
+func (m *memoryKeys) ReleaseIdempotencyKey(ctx context.Context, userId string, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.records, userId+key)
+	return nil
+}
+
+// userTx runs a request as a row level security transaction would, failing
+// the commit with commitErr
+type userTx struct {
+	commitErr error
+}
+
+func (u *userTx) RunAsUser(ctx context.Context, userId string, fn func(ctx context.Context) error) error {
+	if err := fn(ctx); err != nil {
+		return err
+	}
+	return u.commitErr
+}
+
+// idempotentServer routes POST /expense through Idempotency to handle, which
+// counts its calls. A non nil tx puts row level security in front of handle
+// as attachRoutes does.
+func idempotentServer(handle gin.HandlerFunc, tx *userTx) (*gin.Engine, *atomic.Int32) {
+	gin.SetMode(gin.TestMode)
+	calls := &atomic.Int32{}
+	idempotency := &Idempotency{o: &memoryKeys{records: map[string]*storage.IdempotencyRecord{}}}
+	handlers := []gin.HandlerFunc{
+		func(c *gin.Context) { c.Set(CtxUserId, testUser) },
+		idempotency.Pre,
+	}
+	if tx != nil {
+		handlers = append(handlers, RowLevelSecurity(tx))
+	}
+	handlers = append(handlers, func(c *gin.Context) {
+		calls.Add(1)
+		handle(c)
+	})
+	r := gin.New()
+	r.Use(ErrorMiddleware())
+	r.POST("/expense", handlers...)
+	return r, calls
+}
+
+func postWithKey(r *gin.Engine, key string, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("POST", "/expense", strings.NewReader(body))
+	req.Header.Set(IdempotencyKeyHeader, key)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestIdempotencyReplaysSameRequest(t *testing.T) {
+	var created atomic.Int32
+	r, calls := idempotentServer(func(c *gin.Context) {
+		c.JSON(201, gin.H{"id": created.Add(1)})
+	}, nil)
+
+	first := postWithKey(r, "key", `{"amount": 10}`)
+	second := postWithKey(r, "key", `{"amount": 10}`)
+	if first.Code != 201 || first.Body.String() != `{"id":1}` {
+		t.Fatalf("first request: got %d %s", first.Code, first.Body.String())
+	}
+	if second.Code != 201 || second.Body.String() != first.Body.String() {
+		t.Fatalf("replay: got %d %s, want %d %s", second.Code, second.Body.String(), first.Code, first.Body.String())
+	}
+	if second.Header().Get(IdempotentReplayedHeader) != "true" || first.Header().Get(IdempotentReplayedHeader) != "" {
+		t.Fatalf("%s header: got %q on the first and %q on the replay", IdempotentReplayedHeader, first.Header().Get(IdempotentReplayedHeader), second.Header().Get(IdempotentReplayedHeader))
+	}
+	if second.Header().Get("Content-Type") != first.Header().Get("Content-Type") {
+		t.Fatalf("replayed Content-Type: got %q, want %q", second.Header().Get("Content-Type"), first.Header().Get("Content-Type"))
+	}
+	if calls.Load() != 1 {
+		t.Fatalf("handler ran %d times, want once", calls.Load())
+	}
+
+	if other := postWithKey(r, "other key", `{"amount": 10}`); other.Body.String() != `{"id":2}` {
+		t.Fatalf("another key: got %d %s, want a new expense", other.Code, other.Body.String())
+	}
+}
+
+func TestIdempotencyRejectsDifferentRequest(t *testing.T) {
+	r, calls := idempotentServer(func(c *gin.Context) {
+		c.JSON(201, gin.H{})
+	}, nil)
+
+	if first := postWithKey(r, "key", `{"amount": 10}`); first.Code != 201 {
+		t.Fatalf("first request: got %d %s", first.Code, first.Body.String())
+	}
+	second := postWithKey(r, "key", `{"amount": 20}`)
+	if second.Code != 422 || !strings.Contains(second.Body.String(), "different request") {
+		t.Fatalf("different body: got %d %s, want 422", second.Code, second.Body.String())
+	}
+	if calls.Load() != 1 {
+		t.Fatalf("handler ran %d times, want once", calls.Load())
+	}
+}
+
+func TestIdempotencyReleasesFailedRequest(t *testing.T) {
+	fail := true
+	r, calls := idempotentServer(func(c *gin.Context) {
+		if fail {
+			abortWithStatus(c, 503, errors.New("database unavailable"))
+			return
+		}
+		c.JSON(201, gin.H{})
+	}, nil)
+
+	if first := postWithKey(r, "key", `{}`); first.Code != 503 {
+		t.Fatalf("failed request: got %d %s", first.Code, first.Body.String())
+	}
+	fail = false
+	if retry := postWithKey(r, "key", `{}`); retry.Code != 201 || retry.Header().Get(IdempotentReplayedHeader) != "" {
+		t.Fatalf("retry: got %d %s, want the handler to run again", retry.Code, retry.Body.String())
+	}
+	if calls.Load() != 2 {
+		t.Fatalf("handler ran %d times, want twice", calls.Load())
+	}
+}
+
+func TestIdempotencyRunsConcurrentRequestsOnce(t *testing.T) {
+	started, release := make(chan struct{}), make(chan struct{})
+	r, calls := idempotentServer(func(c *gin.Context) {
+		close(started)
+		<-release
+		c.JSON(201, gin.H{"id": 1})
+	}, nil)
+
+	const requests = 8
+	codes := make(chan int, requests)
+	go func() {
+		codes <- postWithKey(r, "key", `{"amount": 10}`).Code
+	}()
+	<-started
+
+	// the first request holds the key while the others arrive
+	var wg sync.WaitGroup
+	for i := 1; i < requests; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			codes <- postWithKey(r, "key", `{"amount": 10}`).Code
+		}()
+	}
+	wg.Wait()
+	close(release)
+
+	counts := map[int]int{}
+	for i := 0; i < requests; i++ {
+		counts[<-codes]++
+	}
+	if counts[201] != 1 || counts[409] != requests-1 {
+		t.Fatalf("statuses: got %v, want one 201 and %d 409", counts, requests-1)
+	}
+	if calls.Load() != 1 {
+		t.Fatalf("handler ran %d times, want once", calls.Load())
+	}
+	if replay := postWithKey(r, "key", `{"amount": 10}`); replay.Code != 201 || replay.Header().Get(IdempotentReplayedHeader) != "true" {
+		t.Fatalf("request after the first completed: got %d, want a replay", replay.Code)
+	}
+}
+
+func TestIdempotencyReplaysWithRowLevelSecurity(t *testing.T) {
+	tx := &userTx{}
+	var created atomic.Int32
+	r, calls := idempotentServer(func(c *gin.Context) {
+		c.JSON(201, gin.H{"id": created.Add(1)})
+	}, tx)
+
+	// a failed commit answers 500 and frees the key for the retry
+	tx.commitErr = errors.New("could not serialize access")
+	if failed := postWithKey(r, "key", `{"amount": 10}`); failed.Code != 500 {
+		t.Fatalf("failed commit: got %d %s, want 500", failed.Code, failed.Body.String())
+	}
+	tx.commitErr = nil
+
+	first := postWithKey(r, "key", `{"amount": 10}`)
+	second := postWithKey(r, "key", `{"amount": 10}`)
+	if first.Code != 201 || first.Body.String() != `{"id":2}` {
+		t.Fatalf("first request: got %d %s", first.Code, first.Body.String())
+	}
+	if second.Code != 201 || second.Body.String() != first.Body.String() || second.Header().Get(IdempotentReplayedHeader) != "true" {
+		t.Fatalf("replay: got %d %q, want the first response replayed", second.Code, second.Body.String())
+	}
+	if second.Header().Get("Content-Type") != first.Header().Get("Content-Type") {
+		t.Fatalf("replayed Content-Type: got %q, want %q", second.Header().Get("Content-Type"), first.Header().Get("Content-Type"))
+	}
+	if calls.Load() != 2 {
+		t.Fatalf("handler ran %d times, want twice", calls.Load())
+	}
+}
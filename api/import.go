@@ -0,0 +1,106 @@
+package apiServer
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"splitExpense/config"
+	"splitExpense/expense"
+	"splitExpense/importer"
+	"splitExpense/orchestrator"
+	"splitExpense/service"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxImportSize bounds uploaded CSV files
+const maxImportSize = 16 << 20
+
+type ImportExpensesHandler struct {
+	o orchestrator.ExpenseAppImpl
+}
+
+func (h *ImportExpensesHandler) Method() Method {
+	return POST
+}
+
+func (h *ImportExpensesHandler) Path() string {
+	return Path("/import")
+}
+
+// Handle reads a CSV from the body or the multipart field "file". The query
+// takes format (splitwise or csv), mapping for csv files, groupId, alias as
+// name=who for each name to match by hand and commit=true to create the
+// expenses, without commit the import is only previewed.
+func (h *ImportExpensesHandler) Handle(c *gin.Context, cfg *config.Config) {
+	userId, err := CtxGetUserId(c)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	format := importer.Format(c.DefaultQuery("format", string(importer.FormatSplitwise)))
+	mapping, err := importer.ParseMapping(c.Query("mapping"))
+	if err != nil {
+		abortWithError(c, expense.ErrFieldValidation(err.Error(), map[string]string{"mapping": err.Error()}))
+		return
+	}
+	opts := orchestrator.ImportOptions{GroupId: c.Query("groupId"), Aliases: map[string]string{}}
+	opts.Commit, _ = strconv.ParseBool(c.Query("commit"))
+	for _, alias := range c.QueryArray("alias") {
+		name, who, ok := strings.Cut(alias, "=")
+		if !ok {
+			abortWithError(c, expense.ErrFieldValidation("alias should be name=who, got "+alias, map[string]string{"alias": "should be name=who"}))
+			return
+		}
+		opts.Aliases[strings.TrimSpace(name)] = strings.TrimSpace(who)
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxImportSize)
+	body := io.Reader(c.Request.Body)
+	if c.ContentType() == "multipart/form-data" {
+		file, err := c.FormFile("file")
+		if err != nil {
+			abortWithStatus(c, 400, err)
+			return
+		}
+		f, err := file.Open()
+		if err != nil {
+			abortWithStatus(c, 400, err)
+			return
+		}
+		defer f.Close()
+		body = f
+	}
+	file, err := importer.Read(body, format, mapping)
+	var maxBytes *http.MaxBytesError
+	if err != nil && !errors.As(err, &maxBytes) {
+		err = expense.ErrValidation(err.Error())
+	}
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	report, err := h.o.ImportExpenses(c.Request.Context(), userId, file, opts)
+	if err != nil && report != nil {
+		// the report lists the problems that stopped the import
+		status, body := errorResponse(c, err)
+		c.AbortWithStatusJSON(status, struct {
+			*ErrorResponse
+			Report *service.ImportReport `json:"report"`
+		}{body, report})
+		return
+	}
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+	if report.Committed {
+		c.JSON(201, report)
+		return
+	}
+	c.JSON(200, report)
+}
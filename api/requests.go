@@ -0,0 +1,256 @@
+package apiServer
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"splitExpense/expense"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+)
+
+// Request bodies declare their checks with binding tags, checks that span
+// several fields are the rules method. Both run before the orchestrator and
+// a failed request lists every bad field.
+
+type SignUpRequest struct {
+	Name     string `json:"name" binding:"required,min=2"`
+	Email    string `json:"email" binding:"required,email"`
+	Password string `json:"password" binding:"required,password"`
+}
+
+type LoginRequest struct {
+	Email    string `json:"email" binding:"required,email"`
+	Password string `json:"password" binding:"required"`
+}
+
+type AddFriendRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+type CreateGroupRequest struct {
+	Name        string `json:"name" binding:"required,min=2"`
+	Description string `json:"description"`
+}
+
+type UpdateGroupRequest struct {
+	Name        string `json:"name" binding:"required,min=2"`
+	Description string `json:"description"`
+	Version     int    `json:"version" binding:"required,gte=1"`
+}
+
+type JoinGroupRequest struct {
+	MemberId string `json:"new_member_id" binding:"required,uuid"`
+}
+
+// ExpenseRequest creates an expense, or updates it when ID is set
+type ExpenseRequest struct {
+	ID          string               `json:"id" binding:"omitempty,uuid"`
+	Description string               `json:"description"`
+	Category    string               `json:"category"`
+	Amount      float64              `json:"amount" binding:"required,gte=1"`
+	Split       expense.SplitWrapper `json:"split"`
+	Payee       expense.PayerWrapper `json:"payee"`
+	GroupId     string               `json:"groupId" binding:"omitempty,uuid"`
+	Version     int                  `json:"version" binding:"gte=0"`
+}
+
+// rules checks the split and payers add up to the amount, as each split type counts it
+func (r *ExpenseRequest) rules(fields map[string]string) {
+	if r.ID != "" && r.Version == 0 {
+		fields["version"] = "is required to update an expense"
+	}
+
+	switch split := r.Split.Split.(type) {
+	case nil:
+		fields["split"] = "is required"
+	case *expense.EqualSplit:
+		if len(split.Payee) == 0 {
+			fields["split.equalSplit"] = "should name at least one user"
+		} else if problem := distinctUsers(split.Payee); problem != "" {
+			fields["split.equalSplit"] = problem
+		}
+		if !sameAmount(split.TotalAmount, r.Amount) {
+			fields["split.totalAmount"] = "should be the expense amount"
+		}
+	case *expense.UnitSplit:
+		if problem := amountsProblem(split.PayeeAmountSplit, r.Amount, "the expense amount"); problem != "" {
+			fields["split.unitSplit"] = problem
+		}
+	case *expense.PercentageSplit:
+		if problem := amountsProblem(split.PercentageSplitMap, 100, "100"); problem != "" {
+			fields["split.percentageSplit"] = problem
+		}
+		if !sameAmount(split.TotalAmount, r.Amount) {
+			fields["split.totalAmount"] = "should be the expense amount"
+		}
+	case *expense.ShareSplit:
+		shares := map[string]float64{}
+		for userId, share := range split.SplitMap {
+			shares[userId] = float64(share)
+		}
+		if problem := amountsProblem(shares, 0, ""); problem != "" {
+			fields["split.shareSplit"] = problem
+		}
+		if !sameAmount(split.TotalAmount, r.Amount) {
+			fields["split.totalAmount"] = "should be the expense amount"
+		}
+	}
+
+	if r.Payee.Payer == nil {
+		fields["payee"] = "is required"
+	} else if problem := amountsProblem(r.Payee.Payer.GetPayers(), r.Amount, "the expense amount"); problem != "" {
+		fields["payee.payerSplit"] = problem
+	}
+}
+
+// BulkExpenseRequest creates several expenses, each is validated on its own
+// so a best effort request can create the valid ones
+type BulkExpenseRequest struct {
+	Expenses []ExpenseRequest `json:"expenses" binding:"required,min=1,max=100"`
+	// Mode atomic, the default, creates all expenses or none, bestEffort creates the valid ones
+	Mode string `json:"mode" binding:"omitempty,oneof=atomic bestEffort"`
+}
+
+// amountsProblem says what is wrong with amounts by user, they should add up
+// to total unless totalName is empty
+func amountsProblem(amounts map[string]float64, total float64, totalName string) string {
+	if len(amounts) == 0 {
+		return "should name at least one user"
+	}
+	var sum float64
+	userIds := make([]string, 0, len(amounts))
+	for userId, amount := range amounts {
+		if amount <= 0 {
+			return "should be positive for every user"
+		}
+		sum += amount
+		userIds = append(userIds, userId)
+	}
+	if problem := idsProblem(userIds); problem != "" {
+		return problem
+	}
+	if totalName != "" && !sameAmount(sum, total) {
+		return "should add up to " + totalName
+	}
+	return ""
+}
+
+// distinctUsers checks a list of users has no repeats
+func distinctUsers(ids []string) string {
+	seen := map[string]bool{}
+	for _, id := range ids {
+		if seen[id] {
+			return "should not repeat a user"
+		}
+		seen[id] = true
+	}
+	return idsProblem(ids)
+}
+
+func idsProblem(ids []string) string {
+	for _, id := range ids {
+		if uuid.Validate(id) != nil {
+			return "should name users by id"
+		}
+	}
+	return ""
+}
+
+// sameAmount compares amounts to the cent
+func sameAmount(a, b float64) bool {
+	return math.Round(a*100) == math.Round(b*100)
+}
+
+// ruled is a request with rules beyond its binding tags
+type ruled interface {
+	rules(fields map[string]string)
+}
+
+// bindRequest decodes the JSON body into req and runs its binding tags and
+// rules. The ValidationError names every failing field, the body stays
+// readable for later handlers.
+func bindRequest(c *gin.Context, req any) error {
+	return requestError(c.ShouldBindBodyWithJSON(req), req)
+}
+
+// checkRequest runs the binding tags and rules of a request decoded as part
+// of a bigger one
+func checkRequest(req any) error {
+	return requestError(binding.Validator.ValidateStruct(req), req)
+}
+
+// requestError adds the rules of req to the error of binding it
+func requestError(err error, req any) error {
+	var fieldErrs validator.ValidationErrors
+	if err != nil && !errors.As(err, &fieldErrs) {
+		return bindError(err)
+	}
+
+	fields := map[string]string{}
+	for _, fe := range fieldErrs {
+		fields[fe.Field()] = fieldMessage(fe)
+	}
+	if r, ok := req.(ruled); ok {
+		r.rules(fields)
+	}
+	if len(fields) > 0 {
+		return expense.ErrFieldValidation("invalid request", fields)
+	}
+	return nil
+}
+
+func fieldMessage(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "email":
+		return "should be an email address as localpart@domain.tld"
+	case "password":
+		return expense.PasswordPolicy
+	case "uuid":
+		return "should be an id"
+	case "min":
+		if fe.Kind() == reflect.String {
+			return "should have at least " + fe.Param() + " characters"
+		}
+		return "should be at least " + fe.Param()
+	case "max":
+		if fe.Kind() == reflect.String {
+			return "should have at most " + fe.Param() + " characters"
+		}
+		return "should be at most " + fe.Param()
+	case "gte":
+		return "should be at least " + fe.Param()
+	case "gt":
+		return "should be more than " + fe.Param()
+	case "oneof":
+		return "should be one of " + strings.ReplaceAll(fe.Param(), " ", ", ")
+	}
+	if fe.Param() != "" {
+		return "failed " + fe.Tag() + "=" + fe.Param()
+	}
+	return "failed " + fe.Tag()
+}
+
+func init() {
+	v, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		return
+	}
+	// field errors name the json field the client sent
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "" || name == "-" {
+			return field.Name
+		}
+		return name
+	})
+	v.RegisterValidation("password", func(fl validator.FieldLevel) bool {
+		return expense.ValidPassword(fl.Field().String())
+	})
+}
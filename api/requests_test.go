@@ -0,0 +1,207 @@
+package apiServer
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"splitExpense/config"
+	"splitExpense/expense"
+	"splitExpense/orchestrator"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	testUser   = "6f1c9a1e-2b64-4c43-9d52-0d7e6a3c1b01"
+	testFriend = "0b2f4c3d-8e91-4a7b-a6c5-2d1e3f4a5b02"
+)
+
+// post sends body to the handler as an authenticated user would, invalid
+// bodies are answered before the orchestrator is called
+func post(t *testing.T, handler RouteHandler, body string) (int, ErrorResponse, []byte) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(ErrorMiddleware())
+	r.POST("/", func(c *gin.Context) {
+		c.Set(CtxUserId, testUser)
+		handler.Handle(c, &config.Config{})
+	})
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("POST", "/", strings.NewReader(body)))
+
+	var response ErrorResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("decode %s: %v", w.Body.String(), err)
+	}
+	return w.Code, response, w.Body.Bytes()
+}
+
+func TestInvalidRequestsListEveryField(t *testing.T) {
+	o := orchestrator.ExpenseAppImpl{}
+	tests := []struct {
+		name    string
+		handler RouteHandler
+		body    string
+		fields  map[string]string
+	}{
+		{
+			name:    "empty sign up",
+			handler: &userSignUpHandler{orchestrator: o},
+			body:    `{}`,
+			fields:  map[string]string{"name": "is required", "email": "is required", "password": "is required"},
+		},
+		{
+			name:    "sign up",
+			handler: &userSignUpHandler{orchestrator: o},
+			body:    `{"name": "a", "email": "nobody", "password": "short"}`,
+			fields: map[string]string{
+				"name":     "should have at least 2 characters",
+				"email":    "should be an email address as localpart@domain.tld",
+				"password": expense.PasswordPolicy,
+			},
+		},
+		{
+			name:    "login",
+			handler: &LoginRouteHandler{orchestrator: o},
+			body:    `{"email": "nobody"}`,
+			fields:  map[string]string{"email": "should be an email address as localpart@domain.tld", "password": "is required"},
+		},
+		{
+			name:    "update group",
+			handler: &UpdateGroupRouteHandler{orchestrator: o},
+			body:    `{"name": "a", "version": 0}`,
+			fields:  map[string]string{"name": "should have at least 2 characters", "version": "is required"},
+		},
+		{
+			name:    "join group",
+			handler: &JoinGroupRouteHandler{orchestrator: o},
+			body:    `{"new_member_id": "bob"}`,
+			fields:  map[string]string{"new_member_id": "should be an id"},
+		},
+		{
+			name:    "empty expense",
+			handler: &CreateOrUpdateExpenseRouteHandler{orchestrator: o},
+			body:    `{}`,
+			fields:  map[string]string{"amount": "is required", "split": "is required", "payee": "is required"},
+		},
+		{
+			name:    "expense fields",
+			handler: &CreateOrUpdateExpenseRouteHandler{orchestrator: o},
+			body:    `{"id": "1", "groupId": "group", "amount": 0.5, "version": -1}`,
+			fields: map[string]string{
+				"id":      "should be an id",
+				"groupId": "should be an id",
+				"amount":  "should be at least 1",
+				"version": "should be at least 0",
+				"split":   "is required",
+				"payee":   "is required",
+			},
+		},
+		{
+			name:    "update without version",
+			handler: &CreateOrUpdateExpenseRouteHandler{orchestrator: o},
+			body: `{"id": "` + testFriend + `", "amount": 10,
+				"split": {"type": "equal", "totalAmount": 10, "equalSplit": ["` + testUser + `"]},
+				"payee": {"type": "single", "payerSplit": {"` + testUser + `": 10}}}`,
+			fields: map[string]string{"version": "is required to update an expense"},
+		},
+		{
+			name:    "equal split",
+			handler: &CreateOrUpdateExpenseRouteHandler{orchestrator: o},
+			body: `{"amount": 30,
+				"split": {"type": "equal", "totalAmount": 20, "equalSplit": ["` + testUser + `", "` + testUser + `"]},
+				"payee": {"type": "multi", "payerSplit": {"` + testUser + `": 10, "` + testFriend + `": 10}}}`,
+			fields: map[string]string{
+				"split.equalSplit":  "should not repeat a user",
+				"split.totalAmount": "should be the expense amount",
+				"payee.payerSplit":  "should add up to the expense amount",
+			},
+		},
+		{
+			name:    "unit split",
+			handler: &CreateOrUpdateExpenseRouteHandler{orchestrator: o},
+			body: `{"amount": 30,
+				"split": {"type": "unit", "unitSplit": {"bob": 10, "alice": 20}},
+				"payee": {"type": "multi", "payerSplit": {"` + testUser + `": 40, "` + testFriend + `": -10}}}`,
+			fields: map[string]string{
+				"split.unitSplit":  "should name users by id",
+				"payee.payerSplit": "should be positive for every user",
+			},
+		},
+		{
+			name:    "percentage split",
+			handler: &CreateOrUpdateExpenseRouteHandler{orchestrator: o},
+			body: `{"amount": 30,
+				"split": {"type": "percentage", "totalAmount": 30, "percentageSplit": {"` + testUser + `": 50, "` + testFriend + `": 40}},
+				"payee": {"type": "single", "payerSplit": {"` + testUser + `": 30}}}`,
+			fields: map[string]string{"split.percentageSplit": "should add up to 100"},
+		},
+		{
+			name:    "share split",
+			handler: &CreateOrUpdateExpenseRouteHandler{orchestrator: o},
+			body: `{"amount": 30,
+				"split": {"type": "share", "totalAmount": 30, "shareSplit": {}},
+				"payee": {"type": "single", "payerSplit": {"` + testUser + `": 30}}}`,
+			fields: map[string]string{"split.shareSplit": "should name at least one user"},
+		},
+		{
+			name:    "bulk",
+			handler: &CreateExpensesRouteHandler{orchestrator: o},
+			body:    `{"expenses": [], "mode": "fast"}`,
+			fields:  map[string]string{"expenses": "should be at least 1", "mode": "should be one of atomic, bestEffort"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, response, body := post(t, tt.handler, tt.body)
+			if status != 400 || response.Code != "ValidationError" {
+				t.Fatalf("got %d %s, want 400 ValidationError", status, body)
+			}
+			if !reflect.DeepEqual(response.Fields, tt.fields) {
+				t.Fatalf("fields: got %v, want %v", response.Fields, tt.fields)
+			}
+		})
+	}
+}
+
+func TestInvalidBulkExpensesListFieldsPerExpense(t *testing.T) {
+	body := `{"expenses": [
+		{"amount": 10,
+			"split": {"type": "equal", "totalAmount": 10, "equalSplit": ["` + testUser + `"]},
+			"payee": {"type": "single", "payerSplit": {"` + testUser + `": 10}}},
+		{"id": "` + testFriend + `", "amount": 0},
+		{"amount": 10, "groupId": "group",
+			"split": {"type": "equal", "totalAmount": 10, "equalSplit": ["` + testUser + `"]},
+			"payee": {"type": "single", "payerSplit": {"` + testUser + `": 5}}}
+	]}`
+	status, _, raw := post(t, &CreateExpensesRouteHandler{orchestrator: orchestrator.ExpenseAppImpl{}}, body)
+	var response BulkExpenseResponse
+	if err := json.Unmarshal(raw, &response); err != nil {
+		t.Fatalf("decode %s: %v", raw, err)
+	}
+	if status != 400 || response.Created != 0 || len(response.Results) != 3 {
+		t.Fatalf("got %d %s, want 400 with three results", status, raw)
+	}
+
+	want := []map[string]string{
+		nil,
+		{"id": "should be empty, bulk requests only create expenses", "amount": "is required", "split": "is required", "payee": "is required"},
+		{"groupId": "should be an id", "payee.payerSplit": "should add up to the expense amount"},
+	}
+	for i, fields := range want {
+		result := response.Results[i]
+		if fields == nil {
+			if result.Error != nil {
+				t.Fatalf("result %d: got error %+v, want none", i, result.Error)
+			}
+			continue
+		}
+		if result.Error == nil || !reflect.DeepEqual(result.Error.Fields, fields) {
+			t.Fatalf("result %d: got %+v, want fields %v", i, result.Error, fields)
+		}
+	}
+}
@@ -2,15 +2,21 @@ package apiServer
 
 import (
 	"context"
+	"log"
 	"splitExpense/config"
+	"splitExpense/export"
+	"splitExpense/migrations"
 	"splitExpense/orchestrator"
+	rpcServer "splitExpense/rpc"
+	"splitExpense/storage"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 )
 
-func Start() {
+func Start(cfg *config.Config) {
 	opts := gin.OptionFunc(func(e *gin.Engine) {
 		e.RedirectFixedPath = true
 		e.RedirectTrailingSlash = true
@@ -20,24 +26,66 @@ func Start() {
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
 	r.Use(CORSMiddleware())
-	cfg := &config.Config{
-		// Fill with actual config values or load from env
-		DatabaseHost:     "localhost",
-		DatabasePort:     "5432",
-		DatabaseUser:     "postgres",
-		DatabasePassword: "postgres",
-		DatabaseName:     "postgres",
-		DatabaseSSLMode:  "disable",
-		Environment:      config.EnvironmentDevelopment,
-	}
+	r.Use(RequestIdMiddleware())
+	r.Use(ErrorMiddleware())
+	r.Use(RequestTimeoutMiddleware(cfg.RequestTimeout))
 	ctx := context.Background()
 
+	if cfg.AutoMigrate {
+		if err := autoMigrate(ctx, cfg); err != nil {
+			log.Fatal("failed to apply migrations: ", err)
+		}
+	}
+
 	// Only create orchestrator, let it handle service dependencies internally
-	app := orchestrator.NewExpenseApp(ctx, cfg)
+	app := orchestrator.NewExpenseApp(cfg)
+	if _, err := app.BootstrapLedger(ctx); err != nil {
+		log.Fatal("failed to bootstrap the event ledger: ", err)
+	}
+	app.RunArchival(ctx)
+	app.RunOutboxDispatcher(ctx)
+	app.RunIdempotencyKeyPruning(ctx)
 
 	attachRoutes(r, app, cfg)
 
-	r.Run(":8888")
+	if cfg.GRPCAddr != "" {
+		go func() {
+			if err := rpcServer.Serve(cfg, app); err != nil {
+				log.Fatal("gRPC server failed: ", err)
+			}
+		}()
+	}
+
+	r.Run(cfg.Addr)
+}
+
+func autoMigrate(ctx context.Context, cfg *config.Config) error {
+	pg, err := storage.NewPostgresDB(cfg)
+	if err != nil {
+		return err
+	}
+	defer pg.Close()
+
+	applied, err := migrations.Up(ctx, pg)
+	for _, m := range applied {
+		log.Printf("applied migration %d_%s", m.Version, m.Name)
+	}
+	return err
+}
+
+// RequestTimeoutMiddleware bounds the request context handed to the orchestrator,
+// queries are also cancelled when the client disconnects.
+func RequestTimeoutMiddleware(timeout time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if timeout <= 0 {
+			c.Next()
+			return
+		}
+		ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+		defer cancel()
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
 }
 
 func CORSMiddleware() gin.HandlerFunc {
@@ -47,8 +95,9 @@ func CORSMiddleware() gin.HandlerFunc {
 		// Set specific origin or allow only known ones
 		c.Writer.Header().Set("Access-Control-Allow-Origin", origin)
 		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, ngrok-skip-browser-warning")
+		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, ngrok-skip-browser-warning, X-Request-Id, Idempotency-Key")
 		c.Writer.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
+		c.Writer.Header().Set("Access-Control-Expose-Headers", "X-Request-Id, Idempotent-Replayed")
 		c.Writer.Header().Set("Access-Control-Max-Age", "86400")
 
 		if c.Request.Method == "OPTIONS" {
@@ -86,10 +135,13 @@ type ApiHandler struct {
 }
 
 func routeMap(o orchestrator.ExpenseAppImpl) []ApiHandler {
+	// mutating routes answer retries with an Idempotency-Key from the first response
+	idempotency := NewIdempotency(o)
+
 	return []ApiHandler{
 		{
 			handle:      &SettleExpenseHandler{orchestrator: o},
-			PreHandlers: []gin.HandlerFunc{Authenticate},
+			PreHandlers: []gin.HandlerFunc{Authenticate, idempotency.Pre},
 		},
 		{
 			handle: &LoginRouteHandler{orchestrator: o},
@@ -99,7 +151,7 @@ func routeMap(o orchestrator.ExpenseAppImpl) []ApiHandler {
 		},
 		{
 			handle:      &DeleteExpenseRouteHandler{orchestrator: o},
-			PreHandlers: []gin.HandlerFunc{Authenticate},
+			PreHandlers: []gin.HandlerFunc{Authenticate, idempotency.Pre},
 		},
 		{
 			handle:      &FetchGroupDetailsHandler{o: o},
@@ -107,20 +159,32 @@ func routeMap(o orchestrator.ExpenseAppImpl) []ApiHandler {
 		},
 		{
 			handle:      &CreateGroupRouteHandler{orchestrator: o},
-			PreHandlers: []gin.HandlerFunc{Authenticate},
+			PreHandlers: []gin.HandlerFunc{Authenticate, idempotency.Pre},
+		},
+		{
+			handle:      &UpdateGroupRouteHandler{orchestrator: o},
+			PreHandlers: []gin.HandlerFunc{Authenticate, idempotency.Pre},
 		},
 		{
 			handle:      &CreateOrUpdateExpenseRouteHandler{orchestrator: o},
-			PreHandlers: []gin.HandlerFunc{Authenticate},
+			PreHandlers: []gin.HandlerFunc{Authenticate, idempotency.Pre},
+		},
+		{
+			handle:      &CreateExpensesRouteHandler{orchestrator: o},
+			PreHandlers: []gin.HandlerFunc{Authenticate, idempotency.Pre},
 		},
 
 		{
 			handle:      &JoinGroupRouteHandler{orchestrator: o},
-			PreHandlers: []gin.HandlerFunc{Authenticate},
+			PreHandlers: []gin.HandlerFunc{Authenticate, idempotency.Pre},
+		},
+		{
+			handle:      &LeaveGroupRouteHandler{orchestrator: o},
+			PreHandlers: []gin.HandlerFunc{Authenticate, idempotency.Pre},
 		},
 		{
 			handle:      &AddFriendHandler{o: o},
-			PreHandlers: []gin.HandlerFunc{Authenticate},
+			PreHandlers: []gin.HandlerFunc{Authenticate, idempotency.Pre},
 		},
 		{
 			handle:      &UserHomeHandler{o: o},
@@ -130,16 +194,67 @@ func routeMap(o orchestrator.ExpenseAppImpl) []ApiHandler {
 			handle:      &GetFriendsHandler{o: o},
 			PreHandlers: []gin.HandlerFunc{Authenticate},
 		},
+		{
+			handle:      &GetGroupsHandler{o: o},
+			PreHandlers: []gin.HandlerFunc{Authenticate},
+		},
+		{
+			handle:      &GroupExpensesHandler{o: o},
+			PreHandlers: []gin.HandlerFunc{Authenticate},
+		},
 		{
 			handle: &UserExpensesHandler{
 				o: o,
 			},
 			PreHandlers: []gin.HandlerFunc{Authenticate},
 		},
+		{
+			handle:      &SearchExpensesHandler{o: o},
+			PreHandlers: []gin.HandlerFunc{Authenticate},
+		},
 		{
 			handle:      &DeleteGroupRouteHandler{o: o},
+			PreHandlers: []gin.HandlerFunc{Authenticate, idempotency.Pre},
+		},
+		{
+			handle:      &CacheStatsHandler{o: o},
+			PreHandlers: []gin.HandlerFunc{Authenticate},
+		},
+		{
+			handle:      &ExportAccountHandler{o: o},
+			PreHandlers: []gin.HandlerFunc{Authenticate},
+		},
+		{
+			handle:      &ExportGroupHandler{o: o},
+			PreHandlers: []gin.HandlerFunc{Authenticate},
+		},
+		{
+			handle:      &ExportGroupCSVHandler{o: o},
 			PreHandlers: []gin.HandlerFunc{Authenticate},
 		},
+		{
+			handle:      &ExportExpensesCSVHandler{o: o},
+			PreHandlers: []gin.HandlerFunc{Authenticate},
+		},
+		{
+			handle:      &ExportJournalHandler{o: o, syntax: export.SyntaxLedger},
+			PreHandlers: []gin.HandlerFunc{Authenticate},
+		},
+		{
+			handle:      &ExportJournalHandler{o: o, syntax: export.SyntaxBeancount},
+			PreHandlers: []gin.HandlerFunc{Authenticate},
+		},
+		{
+			handle:      &ImportExpensesHandler{o: o},
+			PreHandlers: []gin.HandlerFunc{Authenticate, idempotency.Pre},
+		},
+		{
+			handle:      &GraphQLHandler{o: o},
+			PreHandlers: []gin.HandlerFunc{Authenticate, idempotency.Pre},
+		},
+		{
+			handle: &GraphQLSchemaHandler{},
+		},
 	}
 }
 
@@ -156,6 +271,9 @@ func attachRoutes(g *gin.Engine, orchestrator orchestrator.ExpenseAppImpl, cfg *
 		}
 		handlers := []gin.HandlerFunc{}
 		handlers = append(handlers, h.PreHandlers...)
+		if cfg.RowLevelSecurity {
+			handlers = append(handlers, RowLevelSecurity(&orchestrator))
+		}
 		handlers = append(handlers, routeHandler(h.handle))
 		handlers = append(handlers, h.PostHandlers...)
 
@@ -0,0 +1,25 @@
+package apiServer
+
+import (
+	"splitExpense/config"
+	"splitExpense/orchestrator"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CacheStatsHandler struct {
+	o orchestrator.ExpenseAppImpl
+}
+
+func (h *CacheStatsHandler) Method() Method {
+	return GET
+}
+
+func (h *CacheStatsHandler) Path() string {
+	return Path("/stats/cache")
+}
+
+// Handle reports hits, misses and evictions per storage cache, empty when caching is disabled
+func (h *CacheStatsHandler) Handle(c *gin.Context, cfg *config.Config) {
+	c.JSON(200, h.o.CacheStats())
+}
@@ -1,7 +1,6 @@
 package apiServer
 
 import (
-	"errors"
 	"path/filepath"
 	"splitExpense/config"
 	"splitExpense/expense"
@@ -28,26 +27,22 @@ func (h *LoginRouteHandler) Path() string {
 
 func (h *LoginRouteHandler) Handle(c *gin.Context, cfg *config.Config) {
 	// decode and validation
-
-	type LoginRequest struct {
-		Email    string `json:"email"`
-		Password string `json:"password"`
-	}
-
 	var req LoginRequest
-	if err := c.ShouldBindBodyWithJSON(&req); err != nil {
-		c.AbortWithError(400, err)
+	if err := bindRequest(c, &req); err != nil {
+		abortWithError(c, err)
+		return
 	}
 
 	// orchestrator call
-	user, err := h.orchestrator.Login(req.Email, req.Password)
+	user, err := h.orchestrator.Login(c.Request.Context(), req.Email, req.Password)
 	if err != nil {
-		c.AbortWithError(400, err)
+		abortWithError(c, err)
 		return
 	}
 	token, err := expense.GenerateToken(*user)
 	if err != nil {
-		c.AbortWithError(400, err)
+		abortWithError(c, err)
+		return
 	}
 	c.SetCookie("token", token, 3600, "/", "", false, true)
 
@@ -68,22 +63,25 @@ func (a *AddFriendHandler) Path() string {
 }
 
 func (a *AddFriendHandler) Handle(c *gin.Context, cfg *config.Config) {
-	type AddFriendRequest struct {
-		Email string `json:"email" binding:"required"`
-	}
 	var req AddFriendRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.AbortWithError(400, err)
+	if err := bindRequest(c, &req); err != nil {
+		abortWithError(c, err)
 		return
 	}
 	userId, err := CtxGetUserId(c)
 	if err != nil {
-		c.AbortWithError(500, err)
+		abortWithError(c, err)
+		return
 	}
 
-	ok, err := a.o.AddFriend(userId, req.Email)
-	if err != nil || !ok {
-		c.AbortWithError(500, err)
+	ok, err := a.o.AddFriend(c.Request.Context(), userId, req.Email)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+	if !ok {
+		abortWithError(c, expense.ErrConflict("friend could not be added"))
+		return
 	}
 	c.JSON(201, gin.H{})
 
@@ -104,12 +102,14 @@ func (a *UserHomeHandler) Path() string {
 func (a *UserHomeHandler) Handle(c *gin.Context, cfg *config.Config) {
 	userId, err := CtxGetUserId(c)
 	if err != nil {
-		c.AbortWithError(500, err)
+		abortWithError(c, err)
+		return
 	}
 
-	userHome, err := a.o.GetUserHome(userId)
+	userHome, err := a.o.GetUserHome(c.Request.Context(), userId)
 	if err != nil {
-		c.AbortWithError(500, err)
+		abortWithError(c, err)
+		return
 	}
 	c.JSON(200, userHome)
 }
@@ -127,18 +127,19 @@ func (a *FetchGroupDetailsHandler) Path() string {
 func (a *FetchGroupDetailsHandler) Handle(c *gin.Context, cfg *config.Config) {
 	groupId := c.Param("id")
 	if groupId == "" {
-		c.AbortWithError(400, errors.New("invalid group id"))
+		abortWithError(c, expense.ErrValidation("invalid group id"))
 		return
 	}
 
 	userId, err := CtxGetUserId(c)
 	if err != nil {
-		c.AbortWithError(400, err)
+		abortWithError(c, err)
+		return
 	}
 
-	group, err := a.o.GetGroupDetail(userId, groupId)
+	group, err := a.o.GetGroupDetail(c.Request.Context(), userId, groupId)
 	if err != nil {
-		c.AbortWithStatus(400)
+		abortWithError(c, err)
 		return
 	}
 	c.JSON(200, group)
@@ -159,13 +160,50 @@ func (h *GetFriendsHandler) Path() string {
 func (h *GetFriendsHandler) Handle(c *gin.Context, cfg *config.Config) {
 	userId, err := CtxGetUserId(c)
 	if err != nil {
-		c.AbortWithError(500, err)
+		abortWithError(c, err)
+		return
+	}
+	// without cursor or limit the full list is returned, as older clients expect
+	if c.Query("cursor") == "" && c.Query("limit") == "" {
+		friends, err := h.o.GetFriends(c.Request.Context(), userId)
+		if err != nil {
+			abortWithError(c, err)
+			return
+		}
+		c.JSON(200, friends)
 		return
 	}
-	friends, err := h.o.GetFriends(userId)
+
+	friends, err := h.o.GetFriendsPage(c.Request.Context(), userId, pageRequest(c))
 	if err != nil {
-		c.AbortWithError(500, err)
+		abortWithError(c, err)
 		return
 	}
 	c.JSON(200, friends)
 }
+
+type GetGroupsHandler struct {
+	o orchestrator.ExpenseAppImpl
+}
+
+func (h *GetGroupsHandler) Method() Method {
+	return GET
+}
+
+func (h *GetGroupsHandler) Path() string {
+	return Path("/user/groups")
+}
+
+func (h *GetGroupsHandler) Handle(c *gin.Context, cfg *config.Config) {
+	userId, err := CtxGetUserId(c)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+	groups, err := h.o.GetGroups(c.Request.Context(), userId, pageRequest(c))
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+	c.JSON(200, groups)
+}
@@ -7,12 +7,6 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-type signUpRequest struct {
-	Name     string `json:"name"`
-	Email    string `json:"email"`
-	Password string `json:"password"`
-}
-
 type userSignUpHandler struct {
 	orchestrator orchestrator.ExpenseAppImpl
 }
@@ -27,17 +21,16 @@ func (u *userSignUpHandler) Path() string {
 
 func (u *userSignUpHandler) Handle(c *gin.Context, cfg *config.Config) {
 	// decode and validation
-	var req signUpRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(400, gin.H{"error": "invalid request"})
+	var req SignUpRequest
+	if err := bindRequest(c, &req); err != nil {
+		abortWithError(c, err)
 		return
 	}
 
 	// orchestrator call
-	user, err := u.orchestrator.UserSignup(req.Name, req.Email, req.Password)
+	user, err := u.orchestrator.UserSignup(c.Request.Context(), req.Name, req.Email, req.Password)
 	if err != nil {
-		c.AbortWithError(500, err)
-		c.JSON(500, gin.H{"error": "could not create user"})
+		abortWithError(c, err)
 		return
 	}
 
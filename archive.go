@@ -0,0 +1,21 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"splitExpense/config"
+	"splitExpense/orchestrator"
+)
+
+// runArchive handles `splitExpense archive`, one archival pass for cron style scheduling
+func runArchive(cfg *config.Config) error {
+	if cfg.ArchiveAfterMonths <= 0 {
+		return errors.New("set ARCHIVE_AFTER_MONTHS to archive settled expenses")
+	}
+
+	app := orchestrator.NewExpenseApp(cfg)
+	moved, err := app.ArchiveSettledExpenses(context.Background())
+	fmt.Printf("archived %d settled expenses\n", moved)
+	return err
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"splitExpense/backup"
+	"splitExpense/config"
+	"splitExpense/orchestrator"
+)
+
+// runBackup handles `splitExpense backup export (-account <id|email> | -group <id>) -o <file>`
+// and `splitExpense backup import -i <file> [-as <id|email>]`
+func runBackup(cfg *config.Config, args []string) error {
+	if len(args) == 0 {
+		return errors.New("usage: splitExpense backup export (-account <id|email> | -group <id>) -o <file> | import -i <file> [-as <id|email>]")
+	}
+	ctx := context.Background()
+
+	switch args[0] {
+	case "export":
+		fs := flag.NewFlagSet("backup export", flag.ContinueOnError)
+		account := fs.String("account", "", "export this user's account, by id or email")
+		group := fs.String("group", "", "export this group")
+		out := fs.String("o", "", "archive to write")
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+		if (*account == "") == (*group == "") || *out == "" {
+			return errors.New("backup export needs -o and one of -account or -group")
+		}
+		kind, subject := backup.KindAccount, *account
+		if *group != "" {
+			kind, subject = backup.KindGroup, *group
+		}
+
+		f, err := os.Create(*out)
+		if err != nil {
+			return err
+		}
+		app := orchestrator.NewExpenseApp(cfg)
+		if err := app.ExportBackup(ctx, kind, subject, f); err != nil {
+			f.Close()
+			os.Remove(*out)
+			return err
+		}
+		if err := f.Close(); err != nil {
+			return err
+		}
+		fmt.Printf("exported %s %s to %s\n", kind, subject, *out)
+		return nil
+	case "import":
+		fs := flag.NewFlagSet("backup import", flag.ContinueOnError)
+		in := fs.String("i", "", "archive to read")
+		as := fs.String("as", "", "existing user, by id or email, that replaces the archived account owner")
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+		if *in == "" {
+			return errors.New("backup import needs -i")
+		}
+		data, err := os.ReadFile(*in)
+		if err != nil {
+			return err
+		}
+
+		app := orchestrator.NewExpenseApp(cfg)
+		report, err := app.RestoreBackupAs(ctx, data, *as)
+		if err != nil {
+			return err
+		}
+		out, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(out))
+		return nil
+	default:
+		return fmt.Errorf("unknown backup command %q", args[0])
+	}
+}
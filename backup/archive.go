@@ -0,0 +1,212 @@
+// Package backup moves a group, or a whole account with its friends and
+// groups, between instances. An archive is a zip of JSON files described by
+// manifest.json, which records the format version and a SHA-256 checksum of
+// every file so a damaged or edited archive is refused on restore.
+package backup
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"splitExpense/expense"
+)
+
+const (
+	Format = "splitexpense-backup"
+	// Version is bumped whenever a file changes incompatibly
+	Version = 1
+
+	manifestFile = "manifest.json"
+	// maxEntrySize guards against archives that inflate without bound
+	maxEntrySize = 256 << 20
+)
+
+type Kind string
+
+const (
+	KindAccount Kind = "account"
+	KindGroup   Kind = "group"
+)
+
+type Manifest struct {
+	Format  string `json:"format"`
+	Version int    `json:"version"`
+	Kind    Kind   `json:"kind"`
+	// Subject is the exported user or group id on the source instance
+	Subject   string    `json:"subject"`
+	CreatedAt time.Time `json:"createdAt"`
+	Files     []File    `json:"files"`
+}
+
+type File struct {
+	Name    string `json:"name"`
+	SHA256  string `json:"sha256"`
+	Size    int64  `json:"size"`
+	Records int    `json:"records"`
+}
+
+// User is exported without credentials or verification, restored users sign
+// in after a password reset
+type User struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+type Membership struct {
+	GroupId string `json:"groupId"`
+	UserId  string `json:"userId"`
+}
+
+type Friendship struct {
+	UserId   string `json:"userId"`
+	FriendId string `json:"friendId"`
+}
+
+// Snapshot is the content of an archive
+type Snapshot struct {
+	Users       []User            `json:"users"`
+	Groups      []expense.Group   `json:"groups"`
+	Memberships []Membership      `json:"memberships"`
+	Friends     []Friendship      `json:"friends"`
+	Expenses    []expense.Expense `json:"expenses"`
+	// History holds the ledger events of the exported groups and expenses, when the source records them
+	History []expense.Event `json:"history"`
+}
+
+// files lists the archive entries of a snapshot in the order they are written
+func (s *Snapshot) files() []struct {
+	name    string
+	records int
+	value   any
+} {
+	return []struct {
+		name    string
+		records int
+		value   any
+	}{
+		{"users.json", len(s.Users), &s.Users},
+		{"groups.json", len(s.Groups), &s.Groups},
+		{"memberships.json", len(s.Memberships), &s.Memberships},
+		{"friends.json", len(s.Friends), &s.Friends},
+		{"expenses.json", len(s.Expenses), &s.Expenses},
+		{"history.json", len(s.History), &s.History},
+	}
+}
+
+// Write encodes snapshot as an archive of the given kind and subject
+func Write(w io.Writer, kind Kind, subject string, snapshot *Snapshot) error {
+	zw := zip.NewWriter(w)
+	manifest := Manifest{Format: Format, Version: Version, Kind: kind, Subject: subject, CreatedAt: time.Now().UTC()}
+
+	for _, f := range snapshot.files() {
+		data, err := json.MarshalIndent(f.value, "", "  ")
+		if err != nil {
+			return fmt.Errorf("encoding %s: %w", f.name, err)
+		}
+		if err := writeEntry(zw, f.name, data); err != nil {
+			return err
+		}
+		sum := sha256.Sum256(data)
+		manifest.Files = append(manifest.Files, File{Name: f.name, SHA256: hex.EncodeToString(sum[:]), Size: int64(len(data)), Records: f.records})
+	}
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := writeEntry(zw, manifestFile, data); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+func writeEntry(zw *zip.Writer, name string, data []byte) error {
+	fw, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = fw.Write(data)
+	return err
+}
+
+// Read decodes an archive after checking its format, version and checksums
+func Read(r io.ReaderAt, size int64) (*Manifest, *Snapshot, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, nil, fmt.Errorf("not a backup archive: %w", err)
+	}
+	entries := map[string]*zip.File{}
+	for _, f := range zr.File {
+		entries[f.Name] = f
+	}
+
+	manifestData, err := readEntry(entries, manifestFile)
+	if err != nil {
+		return nil, nil, err
+	}
+	var manifest Manifest
+	if err := json.Unmarshal(manifestData, &manifest); err != nil {
+		return nil, nil, fmt.Errorf("reading manifest: %w", err)
+	}
+	if manifest.Format != Format {
+		return nil, nil, fmt.Errorf("unknown archive format %q", manifest.Format)
+	}
+	if manifest.Version < 1 || manifest.Version > Version {
+		return nil, nil, fmt.Errorf("archive version %d is not supported, this instance reads up to version %d", manifest.Version, Version)
+	}
+	if manifest.Kind != KindAccount && manifest.Kind != KindGroup {
+		return nil, nil, fmt.Errorf("unknown archive kind %q", manifest.Kind)
+	}
+
+	described := map[string]File{}
+	for _, f := range manifest.Files {
+		described[f.Name] = f
+	}
+	snapshot := &Snapshot{}
+	for _, f := range snapshot.files() {
+		file, ok := described[f.name]
+		if !ok {
+			return nil, nil, fmt.Errorf("manifest does not describe %s", f.name)
+		}
+		data, err := readEntry(entries, f.name)
+		if err != nil {
+			return nil, nil, err
+		}
+		sum := sha256.Sum256(data)
+		if hex.EncodeToString(sum[:]) != file.SHA256 || int64(len(data)) != file.Size {
+			return nil, nil, fmt.Errorf("checksum mismatch for %s, the archive is damaged or was modified", f.name)
+		}
+		if err := json.Unmarshal(data, f.value); err != nil {
+			return nil, nil, fmt.Errorf("decoding %s: %w", f.name, err)
+		}
+	}
+	return &manifest, snapshot, nil
+}
+
+func readEntry(entries map[string]*zip.File, name string) ([]byte, error) {
+	f, ok := entries[name]
+	if !ok {
+		return nil, fmt.Errorf("archive is missing %s", name)
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	var buf bytes.Buffer
+	n, err := io.Copy(&buf, io.LimitReader(rc, maxEntrySize+1))
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", name, err)
+	}
+	if n > maxEntrySize {
+		return nil, fmt.Errorf("%s is larger than %d bytes", name, maxEntrySize)
+	}
+	return buf.Bytes(), nil
+}
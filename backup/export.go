@@ -0,0 +1,190 @@
+package backup
+
+import (
+	"context"
+
+	"splitExpense/expense"
+
+	lodash "github.com/samber/lo"
+)
+
+// exporter accumulates a snapshot, each user, group and expense is added once
+type exporter struct {
+	storage  expense.Storage
+	events   expense.EventStore
+	snapshot *Snapshot
+	users    map[string]bool
+	groups   map[string]bool
+	expenses map[string]bool
+}
+
+func newExporter(storage expense.Storage, events expense.EventStore) *exporter {
+	return &exporter{
+		storage:  storage,
+		events:   events,
+		snapshot: &Snapshot{Users: []User{}, Groups: []expense.Group{}, Memberships: []Membership{}, Friends: []Friendship{}, Expenses: []expense.Expense{}, History: []expense.Event{}},
+		users:    map[string]bool{},
+		groups:   map[string]bool{},
+		expenses: map[string]bool{},
+	}
+}
+
+// ExportAccount snapshots userId with their friends, groups and
+// every expense they take part in, archived ones included. events may be nil
+// when the source does not record a ledger.
+func ExportAccount(ctx context.Context, storage expense.Storage, events expense.EventStore, userId string) (*Snapshot, error) {
+	e := newExporter(storage, events)
+	owner, err := storage.FetchUserById(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+	e.addUser(*owner)
+
+	friends, err := storage.GetFriends(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+	for _, friend := range friends {
+		e.addUser(friend)
+		e.snapshot.Friends = append(e.snapshot.Friends, Friendship{UserId: userId, FriendId: friend.ID})
+	}
+
+	groups, err := storage.FetchGroupsByUser(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+	for _, group := range groups {
+		if err := e.addGroup(ctx, group); err != nil {
+			return nil, err
+		}
+	}
+
+	page := expense.PageRequest{Limit: expense.MaxPageLimit}
+	for {
+		stored, err := storage.SearchExpenses(ctx, userId, expense.ExpenseSearch{IncludeArchived: true}, page)
+		if err != nil {
+			return nil, err
+		}
+		for _, exp := range stored.Expenses {
+			e.addExpense(exp)
+		}
+		if !stored.HasMore {
+			break
+		}
+		page.Cursor = stored.NextCursor
+	}
+
+	return e.finish(ctx)
+}
+
+// ExportGroup snapshots a group with its members and all of its expenses
+func ExportGroup(ctx context.Context, storage expense.Storage, events expense.EventStore, groupId string) (*Snapshot, error) {
+	e := newExporter(storage, events)
+	group, err := storage.FetchGroupById(ctx, groupId)
+	if err != nil {
+		return nil, err
+	}
+	if err := e.addGroup(ctx, *group); err != nil {
+		return nil, err
+	}
+	return e.finish(ctx)
+}
+
+func (e *exporter) addUser(user expense.User) {
+	if e.users[user.ID] {
+		return
+	}
+	e.users[user.ID] = true
+	e.snapshot.Users = append(e.snapshot.Users, User{ID: user.ID, Name: user.Name, Email: user.Email})
+}
+
+func (e *exporter) addGroup(ctx context.Context, group expense.Group) error {
+	if e.groups[group.Id] {
+		return nil
+	}
+	e.groups[group.Id] = true
+	e.snapshot.Groups = append(e.snapshot.Groups, group)
+
+	members, err := e.storage.FetchGroupMembers(ctx, group.Id)
+	if err != nil {
+		return err
+	}
+	for _, member := range members {
+		e.addUser(member)
+		e.snapshot.Memberships = append(e.snapshot.Memberships, Membership{GroupId: group.Id, UserId: member.ID})
+	}
+
+	page := expense.PageRequest{Limit: expense.MaxPageLimit}
+	for {
+		stored, err := e.storage.FetchGroupExpensesPage(ctx, group.Id, "", true, page)
+		if err != nil {
+			return err
+		}
+		for _, exp := range stored.Expenses {
+			e.addExpense(exp)
+		}
+		if !stored.HasMore {
+			return nil
+		}
+		page.Cursor = stored.NextCursor
+	}
+}
+
+func (e *exporter) addExpense(exp expense.Expense) {
+	if e.expenses[exp.ID] {
+		return
+	}
+	e.expenses[exp.ID] = true
+	e.snapshot.Expenses = append(e.snapshot.Expenses, exp)
+}
+
+// finish adds the users expenses refer to that are neither friends nor members
+// any more, such as people who left a group, and the ledger history
+func (e *exporter) finish(ctx context.Context) (*Snapshot, error) {
+	for _, exp := range e.snapshot.Expenses {
+		for _, userId := range referencedUsers(exp) {
+			if e.users[userId] {
+				continue
+			}
+			user, err := e.storage.FetchUserById(ctx, userId)
+			if err != nil {
+				return nil, err
+			}
+			e.addUser(*user)
+		}
+	}
+
+	if e.events == nil {
+		return e.snapshot, nil
+	}
+	for _, group := range e.snapshot.Groups {
+		events, err := e.events.LoadAggregateEvents(ctx, expense.AggregateGroup, group.Id)
+		if err != nil {
+			return nil, err
+		}
+		e.snapshot.History = append(e.snapshot.History, events...)
+	}
+	for _, exp := range e.snapshot.Expenses {
+		events, err := e.events.LoadAggregateEvents(ctx, expense.AggregateExpense, exp.ID)
+		if err != nil {
+			return nil, err
+		}
+		e.snapshot.History = append(e.snapshot.History, events...)
+	}
+	return e.snapshot, nil
+}
+
+// referencedUsers lists everyone an expense names: creator, settler, payers and payees
+func referencedUsers(exp expense.Expense) []string {
+	userIds := []string{exp.CreatedBy}
+	if exp.SettledBy != "" {
+		userIds = append(userIds, exp.SettledBy)
+	}
+	if exp.PayeeW.Payer != nil {
+		userIds = append(userIds, lodash.Keys(exp.PayeeW.Payer.GetPayers())...)
+	}
+	if exp.SplitW.Split != nil {
+		userIds = append(userIds, lodash.Keys(exp.SplitW.Split.GetPayeeSplit())...)
+	}
+	return lodash.Uniq(userIds)
+}
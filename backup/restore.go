@@ -0,0 +1,216 @@
+package backup
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"splitExpense/expense"
+
+	"github.com/google/uuid"
+	lodash "github.com/samber/lo"
+)
+
+type RestoreOptions struct {
+	// AsUserId restores the archive subject of an account export as this
+	// existing user, the one an operator names with backup import -as
+	AsUserId string
+}
+
+type Report struct {
+	UsersCreated int `json:"usersCreated"`
+	Groups       int `json:"groups"`
+	Memberships  int `json:"memberships"`
+	Friends      int `json:"friends"`
+	Expenses     int `json:"expenses"`
+	// IdMap maps every archived user, group and expense id to its id here
+	IdMap map[string]string `json:"idMap"`
+}
+
+// Restore writes a snapshot in one transaction. Groups and expenses always get
+// new ids, users are created unverified and without a usable password. An
+// archived email that is already registered here fails the restore, only the
+// account owner can be restored as an existing user with opts.AsUserId. The
+// history is kept in the archive only, a ledger here records the restore as
+// new events.
+func Restore(ctx context.Context, storage expense.Storage, manifest *Manifest, snapshot *Snapshot, opts RestoreOptions) (*Report, error) {
+	var report *Report
+	err := storage.RunInTx(ctx, func(ctx context.Context) error {
+		report = &Report{IdMap: map[string]string{}}
+		r := &restorer{storage: storage, report: report}
+
+		asUser := func(user User) string {
+			if manifest.Kind == KindAccount && user.ID == manifest.Subject {
+				return opts.AsUserId
+			}
+			return ""
+		}
+		registered := []string{}
+		for _, user := range snapshot.Users {
+			if asUser(user) != "" {
+				continue
+			}
+			_, err := storage.FetchUserByEmail(ctx, user.Email)
+			if err == nil {
+				registered = append(registered, user.Email)
+			} else if !errors.Is(err, sql.ErrNoRows) {
+				return err
+			}
+		}
+		if len(registered) > 0 {
+			return expense.ErrConflict("already registered here, nothing was restored: " + strings.Join(registered, ", "))
+		}
+
+		for _, user := range snapshot.Users {
+			if err := r.user(ctx, user, asUser(user)); err != nil {
+				return fmt.Errorf("restoring user %s: %w", user.Email, err)
+			}
+		}
+		for _, group := range snapshot.Groups {
+			if err := r.group(ctx, group); err != nil {
+				return fmt.Errorf("restoring group %s: %w", group.Name, err)
+			}
+		}
+		for _, m := range snapshot.Memberships {
+			if _, err := storage.AddUserInGroup(ctx, r.id(m.UserId), r.id(m.GroupId)); err != nil {
+				return fmt.Errorf("restoring membership of group %s: %w", m.GroupId, err)
+			}
+			report.Memberships++
+		}
+		for _, f := range snapshot.Friends {
+			if _, err := storage.AddFriend(ctx, r.id(f.UserId), r.id(f.FriendId)); err != nil {
+				return fmt.Errorf("restoring friend %s: %w", f.FriendId, err)
+			}
+			report.Friends++
+		}
+		for _, exp := range snapshot.Expenses {
+			if err := r.expense(ctx, exp); err != nil {
+				return fmt.Errorf("restoring expense %s: %w", exp.ID, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+type restorer struct {
+	storage expense.Storage
+	report  *Report
+}
+
+// id maps an archived id, ids the archive does not define are kept
+func (r *restorer) id(archived string) string {
+	if id, ok := r.report.IdMap[archived]; ok {
+		return id
+	}
+	return archived
+}
+
+func (r *restorer) user(ctx context.Context, user User, asUser string) error {
+	if asUser != "" {
+		r.report.IdMap[user.ID] = asUser
+		return nil
+	}
+
+	created, err := r.storage.CreateUser(ctx, expense.User{
+		ID:    uuid.New().String(),
+		Name:  user.Name,
+		Email: user.Email,
+	})
+	if err != nil {
+		return err
+	}
+	r.report.IdMap[user.ID] = created.ID
+	r.report.UsersCreated++
+	return nil
+}
+
+func (r *restorer) group(ctx context.Context, group expense.Group) error {
+	archivedId := group.Id
+	group.Id = uuid.New().String()
+	group.Admin = r.id(group.Admin)
+	group.Version = 0
+	saved, err := r.storage.CreateOrUpdateGroup(ctx, group)
+	if err != nil {
+		return err
+	}
+	r.report.IdMap[archivedId] = saved.Id
+	r.report.Groups++
+	return nil
+}
+
+func (r *restorer) expense(ctx context.Context, exp expense.Expense) error {
+	archivedId := exp.ID
+	exp.ID = uuid.New().String()
+	exp.Version = 0
+	exp.CreatedBy = r.id(exp.CreatedBy)
+	if exp.SettledBy != "" {
+		exp.SettledBy = r.id(exp.SettledBy)
+	}
+	if exp.GroupId != "" {
+		exp.GroupId = r.id(exp.GroupId)
+	}
+	payer, err := remapPayer(exp.PayeeW.Payer, r.id)
+	if err != nil {
+		return err
+	}
+	exp.PayeeW.Payer = payer
+	split, err := remapSplit(exp.SplitW.Split, r.id)
+	if err != nil {
+		return err
+	}
+	exp.SplitW.Split = split
+
+	saved, err := r.storage.CreateOrUpdateExpense(ctx, exp)
+	if err != nil {
+		return err
+	}
+	for _, userId := range participants(*saved) {
+		if _, err := r.storage.AddExpenseMapping(ctx, saved.ID, userId); err != nil {
+			return err
+		}
+	}
+	r.report.IdMap[archivedId] = saved.ID
+	r.report.Expenses++
+	return nil
+}
+
+// participants are the creator, payers and payees, the users an expense is mapped to
+func participants(exp expense.Expense) []string {
+	return lodash.Union([]string{exp.CreatedBy}, lodash.Keys(exp.PayeeW.Payer.GetPayers()), lodash.Keys(exp.SplitW.Split.GetPayeeSplit()))
+}
+
+func remapPayer(payer expense.Payer, id func(string) string) (expense.Payer, error) {
+	switch p := payer.(type) {
+	case *expense.SinglePayer:
+		return &expense.SinglePayer{Payer: id(p.Payer), Amount: p.Amount}, nil
+	case *expense.MultiPayer:
+		return &expense.MultiPayer{Payers: remapKeys(p.Payers, id)}, nil
+	default:
+		return nil, fmt.Errorf("unknown payer type %T", payer)
+	}
+}
+
+func remapSplit(split expense.Split, id func(string) string) (expense.Split, error) {
+	switch s := split.(type) {
+	case *expense.EqualSplit:
+		return &expense.EqualSplit{Payee: lodash.Map(s.Payee, func(u string, _ int) string { return id(u) }), TotalAmount: s.TotalAmount}, nil
+	case *expense.UnitSplit:
+		return &expense.UnitSplit{PayeeAmountSplit: remapKeys(s.PayeeAmountSplit, id)}, nil
+	case *expense.PercentageSplit:
+		return &expense.PercentageSplit{PercentageSplitMap: remapKeys(s.PercentageSplitMap, id), TotalAmount: s.TotalAmount}, nil
+	case *expense.ShareSplit:
+		return &expense.ShareSplit{SplitMap: remapKeys(s.SplitMap, id), TotalAmount: s.TotalAmount}, nil
+	default:
+		return nil, fmt.Errorf("unknown split type %T", split)
+	}
+}
+
+func remapKeys[V any](m map[string]V, id func(string) string) map[string]V {
+	return lodash.MapKeys(m, func(_ V, k string) string { return id(k) })
+}
@@ -0,0 +1,192 @@
+// Package cache provides an expense.Storage decorator that keeps the hottest
+// lookups, users, groups, group members and friends, in bounded LRU caches
+// with a TTL. Writes through the decorator invalidate the entries they touch,
+// the TTL bounds how stale an entry changed by another process can get.
+package cache
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"splitExpense/expense"
+)
+
+type txKey struct{}
+
+// pending collects the invalidations of a transaction, they are repeated once
+// it ends so readers cannot cache what the transaction replaced
+type pending struct {
+	invalidations []func()
+}
+
+type Storage struct {
+	expense.Storage
+	users   *lru[string, expense.User]
+	groups  *lru[string, expense.Group]
+	members *lru[string, []expense.User]
+	friends *lru[string, []expense.User]
+}
+
+// NewStorage caches up to size entries of each kind for ttl in front of storage
+func NewStorage(storage expense.Storage, size int, ttl time.Duration) *Storage {
+	return &Storage{
+		Storage: storage,
+		users:   newLRU[string, expense.User](size, ttl),
+		groups:  newLRU[string, expense.Group](size, ttl),
+		members: newLRU[string, []expense.User](size, ttl),
+		friends: newLRU[string, []expense.User](size, ttl),
+	}
+}
+
+// Stats reports the hits, misses and evictions of each cache by name
+func (s *Storage) Stats() map[string]Stats {
+	return map[string]Stats{
+		"users":   s.users.stats(),
+		"groups":  s.groups.stats(),
+		"members": s.members.stats(),
+		"friends": s.friends.stats(),
+	}
+}
+
+// Purge drops every cached entry
+func (s *Storage) Purge() {
+	s.users.purge()
+	s.groups.purge()
+	s.members.purge()
+	s.friends.purge()
+}
+
+// RunInTx bypasses the caches inside the transaction, reads there may see
+// uncommitted rows that must not outlive a rollback.
+func (s *Storage) RunInTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txKey{}).(*pending); ok {
+		return s.Storage.RunInTx(ctx, fn)
+	}
+	p := &pending{}
+	err := s.Storage.RunInTx(context.WithValue(ctx, txKey{}, p), fn)
+	for _, invalidate := range p.invalidations {
+		invalidate()
+	}
+	return err
+}
+
+func inTx(ctx context.Context) bool {
+	_, ok := ctx.Value(txKey{}).(*pending)
+	return ok
+}
+
+// invalidate runs now and again when the transaction of ctx, if any, ends
+func (s *Storage) invalidate(ctx context.Context, invalidate func()) {
+	invalidate()
+	if p, ok := ctx.Value(txKey{}).(*pending); ok {
+		p.invalidations = append(p.invalidations, invalidate)
+	}
+}
+
+func (s *Storage) FetchUserById(ctx context.Context, id string) (*expense.User, error) {
+	if !inTx(ctx) {
+		if user, ok := s.users.get(id); ok {
+			return &user, nil
+		}
+	}
+	user, err := s.Storage.FetchUserById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !inTx(ctx) {
+		s.users.set(id, *user)
+	}
+	return user, nil
+}
+
+func (s *Storage) FetchGroupById(ctx context.Context, id string) (*expense.Group, error) {
+	if !inTx(ctx) {
+		if group, ok := s.groups.get(id); ok {
+			return &group, nil
+		}
+	}
+	group, err := s.Storage.FetchGroupById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !inTx(ctx) {
+		s.groups.set(id, *group)
+	}
+	return group, nil
+}
+
+func (s *Storage) FetchGroupMembers(ctx context.Context, groupId string) ([]expense.User, error) {
+	return cachedUsers(ctx, s.members, groupId, s.Storage.FetchGroupMembers)
+}
+
+func (s *Storage) GetFriends(ctx context.Context, userId string) ([]expense.User, error) {
+	return cachedUsers(ctx, s.friends, userId, s.Storage.GetFriends)
+}
+
+// cachedUsers serves a user list from c, callers get their own copy of the slice
+func cachedUsers(ctx context.Context, c *lru[string, []expense.User], key string, fetch func(context.Context, string) ([]expense.User, error)) ([]expense.User, error) {
+	if !inTx(ctx) {
+		if users, ok := c.get(key); ok {
+			return slices.Clone(users), nil
+		}
+	}
+	users, err := fetch(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	if !inTx(ctx) {
+		c.set(key, slices.Clone(users))
+	}
+	return users, nil
+}
+
+func (s *Storage) UpdateUser(ctx context.Context, user expense.User) (*expense.User, error) {
+	updated, err := s.Storage.UpdateUser(ctx, user)
+	// member and friend lists embed the user, they are cheap to refill
+	s.invalidate(ctx, func() {
+		s.users.delete(user.ID)
+		s.members.purge()
+		s.friends.purge()
+	})
+	return updated, err
+}
+
+func (s *Storage) CreateOrUpdateGroup(ctx context.Context, group expense.Group) (*expense.Group, error) {
+	saved, err := s.Storage.CreateOrUpdateGroup(ctx, group)
+	s.invalidate(ctx, func() { s.groups.delete(group.Id) })
+	return saved, err
+}
+
+func (s *Storage) DeleteGroup(ctx context.Context, groupId string) (bool, error) {
+	deleted, err := s.Storage.DeleteGroup(ctx, groupId)
+	s.invalidate(ctx, func() {
+		s.groups.delete(groupId)
+		s.members.delete(groupId)
+	})
+	return deleted, err
+}
+
+func (s *Storage) AddUserInGroup(ctx context.Context, userId string, groupId string) (bool, error) {
+	ok, err := s.Storage.AddUserInGroup(ctx, userId, groupId)
+	s.invalidate(ctx, func() { s.members.delete(groupId) })
+	return ok, err
+}
+
+func (s *Storage) RemoveUserFromGroup(ctx context.Context, userId string, groupId string) (bool, error) {
+	ok, err := s.Storage.RemoveUserFromGroup(ctx, userId, groupId)
+	s.invalidate(ctx, func() { s.members.delete(groupId) })
+	return ok, err
+}
+
+func (s *Storage) AddFriend(ctx context.Context, userId string, friendId string) (bool, error) {
+	ok, err := s.Storage.AddFriend(ctx, userId, friendId)
+	s.invalidate(ctx, func() { s.friends.delete(userId, friendId) })
+	return ok, err
+}
+
+func (s *Storage) RemoveFriend(ctx context.Context, userId string, friendId string) (bool, error) {
+	removed, err := s.Storage.RemoveFriend(ctx, userId, friendId)
+	s.invalidate(ctx, func() { s.friends.delete(userId, friendId) })
+	return removed, err
+}
@@ -0,0 +1,359 @@
+// Package client is a Go client for the /v1 HTTP API, used by the
+// splitexpense command line tool.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"splitExpense/expense"
+	"splitExpense/service"
+)
+
+const DefaultServer = "http://localhost:8888"
+
+// Error is a response with a 4xx or 5xx status
+type Error struct {
+	Status  int
+	Code    string
+	Message string
+	// Fields says what is wrong with each request field of a validation error
+	Fields map[string]string
+	// RequestId identifies the request in the server logs
+	RequestId string
+	// CurrentVersion is set on 409 version conflicts
+	CurrentVersion int
+}
+
+func (e *Error) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("%d %s", e.Status, http.StatusText(e.Status))
+	}
+	message := fmt.Sprintf("%d %s: %s", e.Status, http.StatusText(e.Status), e.Message)
+	if e.Status == 0 {
+		// errors of bulk items come without a status of their own
+		message = e.Message
+	}
+	fields := []string{}
+	for field, problem := range e.Fields {
+		fields = append(fields, field+" "+problem)
+	}
+	sort.Strings(fields)
+	if len(fields) > 0 {
+		message += " (" + strings.Join(fields, ", ") + ")"
+	}
+	return message
+}
+
+// Client calls the API as the user the token belongs to. The server renews
+// tokens close to expiry, Token always holds the latest one.
+type Client struct {
+	BaseURL string
+	Token   string
+	HTTP    *http.Client
+}
+
+func New(baseURL string, token string) *Client {
+	if baseURL == "" {
+		baseURL = DefaultServer
+	}
+	return &Client{
+		BaseURL: strings.TrimSuffix(baseURL, "/"),
+		Token:   token,
+		HTTP:    &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+func (c *Client) do(ctx context.Context, method string, path string, query url.Values, body any, out any) error {
+	// bodies are JSON except for uploaded files, which are CSV
+	var reader io.Reader
+	contentType := "application/json"
+	switch b := body.(type) {
+	case nil:
+	case io.Reader:
+		reader = b
+		contentType = "text/csv"
+	default:
+		raw, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(raw)
+	}
+
+	target := c.BaseURL + "/v1" + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, method, target, reader)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if c.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	for _, cookie := range resp.Cookies() {
+		if cookie.Name == "token" && cookie.Value != "" {
+			c.Token = cookie.Value
+		}
+	}
+
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode >= 400 {
+		apiErr := &Error{Status: resp.StatusCode, RequestId: resp.Header.Get("X-Request-Id")}
+		var payload struct {
+			Code           string            `json:"code"`
+			Message        string            `json:"message"`
+			Fields         map[string]string `json:"fields"`
+			CurrentVersion int               `json:"currentVersion"`
+		}
+		if json.Unmarshal(raw, &payload) == nil {
+			apiErr.Code = payload.Code
+			apiErr.Message = payload.Message
+			apiErr.Fields = payload.Fields
+			apiErr.CurrentVersion = payload.CurrentVersion
+		}
+		return apiErr
+	}
+	if out == nil || len(raw) == 0 {
+		return nil
+	}
+	return json.Unmarshal(raw, out)
+}
+
+func pageQuery(page expense.PageRequest) url.Values {
+	query := url.Values{}
+	if page.Cursor != "" {
+		query.Set("cursor", page.Cursor)
+	}
+	if page.Limit > 0 {
+		query.Set("limit", strconv.Itoa(page.Limit))
+	}
+	return query
+}
+
+// Login stores the issued token on the client
+func (c *Client) Login(ctx context.Context, email string, password string) (*expense.User, error) {
+	var resp struct {
+		Token string       `json:"token"`
+		User  expense.User `json:"user"`
+	}
+	err := c.do(ctx, http.MethodPost, "/user/login", nil, map[string]string{"email": email, "password": password}, &resp)
+	if err != nil {
+		return nil, err
+	}
+	c.Token = resp.Token
+	return &resp.User, nil
+}
+
+func (c *Client) Signup(ctx context.Context, name string, email string, password string) (*expense.User, error) {
+	var user expense.User
+	err := c.do(ctx, http.MethodPost, "/user/signup", nil, map[string]string{"name": name, "email": email, "password": password}, &user)
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (c *Client) Home(ctx context.Context) (*service.UserHome, error) {
+	var home service.UserHome
+	if err := c.do(ctx, http.MethodGet, "/user/home", nil, nil, &home); err != nil {
+		return nil, err
+	}
+	return &home, nil
+}
+
+func (c *Client) AddFriend(ctx context.Context, email string) error {
+	return c.do(ctx, http.MethodPut, "/friend/add", nil, map[string]string{"email": email}, nil)
+}
+
+func (c *Client) Friends(ctx context.Context) ([]expense.User, error) {
+	friends := []expense.User{}
+	if err := c.do(ctx, http.MethodGet, "/user/friends", nil, nil, &friends); err != nil {
+		return nil, err
+	}
+	return friends, nil
+}
+
+func (c *Client) Groups(ctx context.Context, page expense.PageRequest) (*expense.GroupPage, error) {
+	var groups expense.GroupPage
+	if err := c.do(ctx, http.MethodGet, "/user/groups", pageQuery(page), nil, &groups); err != nil {
+		return nil, err
+	}
+	return &groups, nil
+}
+
+func (c *Client) CreateGroup(ctx context.Context, name string, description string) (*expense.Group, error) {
+	var group expense.Group
+	err := c.do(ctx, http.MethodPost, "/group", nil, map[string]string{"name": name, "description": description}, &group)
+	if err != nil {
+		return nil, err
+	}
+	return &group, nil
+}
+
+func (c *Client) Group(ctx context.Context, groupId string) (*service.GroupDetail, error) {
+	var detail service.GroupDetail
+	if err := c.do(ctx, http.MethodGet, "/group/"+url.PathEscape(groupId), nil, nil, &detail); err != nil {
+		return nil, err
+	}
+	return &detail, nil
+}
+
+func (c *Client) InviteToGroup(ctx context.Context, groupId string, userId string) error {
+	return c.do(ctx, http.MethodPut, "/group/"+url.PathEscape(groupId)+"/invite", nil, map[string]string{"new_member_id": userId}, nil)
+}
+
+func (c *Client) LeaveGroup(ctx context.Context, groupId string) error {
+	return c.do(ctx, http.MethodPut, "/group/"+url.PathEscape(groupId)+"/leave", nil, nil, nil)
+}
+
+// ExpenseRequest creates an expense when ID is empty and updates it otherwise,
+// updates must carry the version they were made against.
+type ExpenseRequest struct {
+	ID          string               `json:"id,omitempty"`
+	Description string               `json:"description"`
+	Category    string               `json:"category"`
+	Amount      float64              `json:"amount"`
+	Split       expense.SplitWrapper `json:"split"`
+	Payee       expense.PayerWrapper `json:"payee"`
+	GroupId     string               `json:"groupId,omitempty"`
+	Version     int                  `json:"version,omitempty"`
+}
+
+func (c *Client) SaveExpense(ctx context.Context, req ExpenseRequest) (*expense.Expense, error) {
+	var saved expense.Expense
+	if err := c.do(ctx, http.MethodPost, "/expense", nil, req, &saved); err != nil {
+		return nil, err
+	}
+	return &saved, nil
+}
+
+// BulkExpenseResult is the outcome of the expense at Index of CreateExpenses,
+// Error says why it was not created and has no Status
+type BulkExpenseResult struct {
+	Index   int              `json:"index"`
+	Expense *expense.Expense `json:"expense"`
+	Error   *Error           `json:"error"`
+}
+
+type BulkExpenseResponse struct {
+	Created int                 `json:"created"`
+	Results []BulkExpenseResult `json:"results"`
+}
+
+// CreateExpenses creates all of reqs or none, or with bestEffort the valid
+// ones. A request that creates nothing fails with the first problem.
+func (c *Client) CreateExpenses(ctx context.Context, reqs []ExpenseRequest, bestEffort bool) (*BulkExpenseResponse, error) {
+	body := struct {
+		Expenses []ExpenseRequest `json:"expenses"`
+		Mode     string           `json:"mode"`
+	}{reqs, "atomic"}
+	if bestEffort {
+		body.Mode = "bestEffort"
+	}
+	var response BulkExpenseResponse
+	if err := c.do(ctx, http.MethodPost, "/expenses/bulk", nil, body, &response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+func (c *Client) SettleExpense(ctx context.Context, expenseId string) error {
+	return c.do(ctx, http.MethodPut, "/expense/"+url.PathEscape(expenseId)+"/settle", nil, nil, nil)
+}
+
+func (c *Client) DeleteExpense(ctx context.Context, expenseId string) error {
+	return c.do(ctx, http.MethodDelete, "/expense/"+url.PathEscape(expenseId), nil, nil, nil)
+}
+
+// Expenses pages through the user's active expenses, newest first
+func (c *Client) Expenses(ctx context.Context, page expense.PageRequest) (*service.UserExpenses, error) {
+	var history service.UserExpenses
+	if err := c.do(ctx, http.MethodGet, "/expenses", pageQuery(page), nil, &history); err != nil {
+		return nil, err
+	}
+	return &history, nil
+}
+
+func (c *Client) GroupExpenses(ctx context.Context, groupId string, includeArchived bool, page expense.PageRequest) (*expense.GroupExpenseHistory, error) {
+	query := pageQuery(page)
+	if includeArchived {
+		query.Set("includeArchived", "true")
+	}
+	var history expense.GroupExpenseHistory
+	if err := c.do(ctx, http.MethodGet, "/group/"+url.PathEscape(groupId)+"/expenses", query, nil, &history); err != nil {
+		return nil, err
+	}
+	return &history, nil
+}
+
+// SearchExpenses takes the filters of GET /v1/expenses/search, such as q, groupId or status
+func (c *Client) SearchExpenses(ctx context.Context, filters url.Values, page expense.PageRequest) (*expense.GroupExpenseHistory, error) {
+	query := pageQuery(page)
+	for key, values := range filters {
+		query[key] = values
+	}
+	var history expense.GroupExpenseHistory
+	if err := c.do(ctx, http.MethodGet, "/expenses/search", query, nil, &history); err != nil {
+		return nil, err
+	}
+	return &history, nil
+}
+
+// ImportRequest describes an import, see POST /v1/import
+type ImportRequest struct {
+	// Format is splitwise or csv
+	Format string
+	// Mapping names the columns of a csv file, as key=column pairs
+	Mapping string
+	GroupId string
+	// Aliases match names of the file to users by id, email or name
+	Aliases map[string]string
+	// Commit creates the expenses, without it the import is only previewed
+	Commit bool
+}
+
+func (c *Client) ImportExpenses(ctx context.Context, req ImportRequest, file io.Reader) (*service.ImportReport, error) {
+	query := url.Values{}
+	query.Set("format", req.Format)
+	if req.Mapping != "" {
+		query.Set("mapping", req.Mapping)
+	}
+	if req.GroupId != "" {
+		query.Set("groupId", req.GroupId)
+	}
+	for name, who := range req.Aliases {
+		query.Add("alias", name+"="+who)
+	}
+	if req.Commit {
+		query.Set("commit", "true")
+	}
+	var report service.ImportReport
+	if err := c.do(ctx, http.MethodPost, "/import", query, file, &report); err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"splitExpense/client"
+	"splitExpense/expense"
+	"splitExpense/terminal"
+)
+
+func runSignup(app *cli, args []string) error {
+	fs := flag.NewFlagSet("signup", flag.ContinueOnError)
+	name := fs.String("name", "", "display name")
+	email := fs.String("email", "", "email to log in with")
+	password := fs.String("password", "", "password, from "+passwordEnv+" or read from stdin when empty")
+	if _, err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if *name == "" || *email == "" {
+		return errors.New("signup needs -name and -email")
+	}
+	if err := promptPassword(password); err != nil {
+		return err
+	}
+
+	user, err := app.api.Signup(app.ctx, *name, *email, *password)
+	if err != nil {
+		return err
+	}
+	return app.out.message(user, "signed up %s <%s>, run splitexpense login to start", user.Name, user.Email)
+}
+
+func runLogin(app *cli, args []string) error {
+	fs := flag.NewFlagSet("login", flag.ContinueOnError)
+	email := fs.String("email", "", "account email, read from stdin when empty")
+	password := fs.String("password", "", "password, from "+passwordEnv+" or read from stdin when empty")
+	if _, err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if err := prompt("email", email); err != nil {
+		return err
+	}
+	if err := promptPassword(password); err != nil {
+		return err
+	}
+
+	user, err := app.api.Login(app.ctx, *email, *password)
+	if err != nil {
+		return err
+	}
+	app.config.Token = app.api.Token
+	app.config.UserId = user.ID
+	app.config.Name = user.Name
+	app.config.Email = user.Email
+	if app.config.Server == "" {
+		app.config.Server = client.DefaultServer
+	}
+	if err := app.config.save(app.configPath); err != nil {
+		return err
+	}
+	user.Password = ""
+	return app.out.message(user, "logged in as %s <%s>", user.Name, user.Email)
+}
+
+func runLogout(app *cli, args []string) error {
+	app.config.Token = ""
+	app.config.UserId = ""
+	app.config.Name = ""
+	app.config.Email = ""
+	app.api.Token = ""
+	if err := app.config.save(app.configPath); err != nil {
+		return err
+	}
+	return app.out.message(map[string]bool{"loggedOut": true}, "logged out")
+}
+
+// passwordEnv gives scripts the password without the -password flag, which
+// shows up in the process list and the shell history
+const passwordEnv = "SPLITEXPENSE_PASSWORD"
+
+// stdin is shared by every prompt, a reader per prompt would keep the lines
+// piped in for the next one in its buffer
+var stdin = bufio.NewReader(os.Stdin)
+
+// prompt reads a missing value from stdin
+func prompt(name string, value *string) error {
+	if *value != "" {
+		return nil
+	}
+	fmt.Fprintf(os.Stderr, "%s: ", name)
+	line, err := stdin.ReadString('\n')
+	*value = strings.TrimSpace(line)
+	if *value == "" {
+		if err != nil {
+			return fmt.Errorf("reading %s: %w", name, err)
+		}
+		return fmt.Errorf("%s is required", name)
+	}
+	return nil
+}
+
+// promptPassword reads a missing password from SPLITEXPENSE_PASSWORD, or
+// from stdin without echoing it at a terminal
+func promptPassword(value *string) error {
+	if *value != "" {
+		return nil
+	}
+	if password, ok := os.LookupEnv(passwordEnv); ok {
+		*value = password
+	} else {
+		password, err := terminal.ReadSecret(os.Stdin, stdin, "password: ")
+		if err != nil {
+			return fmt.Errorf("reading password: %w", err)
+		}
+		*value = password
+	}
+	if *value == "" {
+		return errors.New("password is required")
+	}
+	return nil
+}
+
+func runFriends(app *cli, args []string) error {
+	sub, args, err := subcommand("friends", args, "add", "list")
+	if err != nil {
+		return err
+	}
+	if err := app.requireLogin(); err != nil {
+		return err
+	}
+
+	switch sub {
+	case "add":
+		if len(args) != 1 {
+			return errors.New("usage: splitexpense friends add <email>")
+		}
+		if err := app.api.AddFriend(app.ctx, args[0]); err != nil {
+			return err
+		}
+		return app.out.message(map[string]string{"email": args[0]}, "added %s as a friend", args[0])
+	default:
+		friends, err := app.api.Friends(app.ctx)
+		if err != nil {
+			return err
+		}
+		return app.out.table(friends, []string{"ID", "NAME", "EMAIL"}, userRows(friends))
+	}
+}
+
+func userRows(users []expense.User) [][]string {
+	rows := [][]string{}
+	for _, u := range users {
+		rows = append(rows, []string{u.ID, u.Name, u.Email})
+	}
+	return rows
+}
+
+// resolveUser turns "me", an id, an email or a name into a user id. Everyone
+// else in an expense has to be a friend, so the friend list is enough.
+func (app *cli) resolveUser(who string, friends []expense.User) (string, error) {
+	if who == "me" || who == app.config.UserId || strings.EqualFold(who, app.config.Email) {
+		return app.config.UserId, nil
+	}
+	matches := []expense.User{}
+	for _, f := range friends {
+		if f.ID == who || strings.EqualFold(f.Email, who) {
+			return f.ID, nil
+		}
+		if strings.EqualFold(f.Name, who) {
+			matches = append(matches, f)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return "", fmt.Errorf("%q is not a friend, add them with splitexpense friends add", who)
+	case 1:
+		return matches[0].ID, nil
+	default:
+		return "", fmt.Errorf("%d friends are called %q, use their email", len(matches), who)
+	}
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+)
+
+// userConfig is the per user state kept between runs. The token grants
+// access to the account, so the file is only readable by its owner.
+type userConfig struct {
+	Server string `json:"server"`
+	Token  string `json:"token,omitempty"`
+	UserId string `json:"userId,omitempty"`
+	Name   string `json:"name,omitempty"`
+	Email  string `json:"email,omitempty"`
+}
+
+// configPath is $SPLITEXPENSE_CONFIG, or splitexpense/config.json in the
+// user config directory (~/.config on Linux)
+func configPath() (string, error) {
+	if path := os.Getenv("SPLITEXPENSE_CONFIG"); path != "" {
+		return path, nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "splitexpense", "config.json"), nil
+}
+
+// loadConfig returns an empty config when the file does not exist yet
+func loadConfig(path string) (*userConfig, error) {
+	cfg := &userConfig{}
+	raw, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(raw, cfg); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+func (c *userConfig) save(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	raw, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	// written aside and renamed, so a failed write keeps the previous login
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, append(raw, '\n'), 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
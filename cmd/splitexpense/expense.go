@@ -0,0 +1,406 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+
+	"splitExpense/client"
+	"splitExpense/expense"
+)
+
+func runExpense(app *cli, args []string) error {
+	sub, args, err := subcommand("expense", args, "add", "edit", "settle", "delete", "list")
+	if err != nil {
+		return err
+	}
+	if err := app.requireLogin(); err != nil {
+		return err
+	}
+
+	switch sub {
+	case "add":
+		return app.addExpense(args)
+	case "edit":
+		return app.editExpense(args)
+	case "settle":
+		if len(args) != 1 {
+			return errors.New("usage: splitexpense expense settle <expense>")
+		}
+		if err := app.api.SettleExpense(app.ctx, args[0]); err != nil {
+			return err
+		}
+		return app.out.message(map[string]string{"id": args[0]}, "settled expense %s", args[0])
+	case "delete":
+		if len(args) != 1 {
+			return errors.New("usage: splitexpense expense delete <expense>")
+		}
+		if err := app.api.DeleteExpense(app.ctx, args[0]); err != nil {
+			return err
+		}
+		return app.out.message(map[string]string{"id": args[0]}, "deleted expense %s", args[0])
+	default:
+		return app.listExpenses(args)
+	}
+}
+
+// expenseFlags are the flags of expense add and edit
+type expenseFlags struct {
+	fs          *flag.FlagSet
+	amount      *float64
+	description *string
+	category    *string
+	group       *string
+	paidBy      *string
+	split       *string
+	with        *string
+}
+
+func newExpenseFlags(name string) *expenseFlags {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	return &expenseFlags{
+		fs:          fs,
+		amount:      fs.Float64("amount", 0, "total amount"),
+		description: fs.String("description", "", "what the expense was for"),
+		category:    fs.String("category", "", "expense category"),
+		group:       fs.String("group", "", "group the expense belongs to"),
+		paidBy:      fs.String("paid-by", "me", "who paid, a single person or who=amount,... for several"),
+		split:       fs.String("split", "equal", "equal, unit, percentage or share"),
+		with:        fs.String("with", "", "who shares it, who,... for equal splits and who=value,... otherwise"),
+	}
+}
+
+// set reports whether the flag was given on the command line
+func (f *expenseFlags) set(name string) bool {
+	given := false
+	f.fs.Visit(func(fl *flag.Flag) {
+		if fl.Name == name {
+			given = true
+		}
+	})
+	return given
+}
+
+func (app *cli) addExpense(args []string) error {
+	f := newExpenseFlags("expense add")
+	if _, err := parseFlags(f.fs, args); err != nil {
+		return err
+	}
+	if *f.amount <= 0 || *f.with == "" {
+		return errors.New("expense add needs -amount and -with")
+	}
+
+	friends, err := app.api.Friends(app.ctx)
+	if err != nil {
+		return err
+	}
+	split, err := app.buildSplit(*f.split, *f.with, *f.amount, friends)
+	if err != nil {
+		return err
+	}
+	payer, err := app.buildPayer(*f.paidBy, *f.amount, friends)
+	if err != nil {
+		return err
+	}
+
+	saved, err := app.api.SaveExpense(app.ctx, client.ExpenseRequest{
+		Description: *f.description,
+		Category:    *f.category,
+		Amount:      *f.amount,
+		Split:       split,
+		Payee:       payer,
+		GroupId:     *f.group,
+	})
+	if err != nil {
+		return err
+	}
+	return app.out.message(saved, "added expense %s (%s)", saved.ID, money(saved.Amount))
+}
+
+// editExpense changes the given fields of an expense. Changing the amount
+// rescales equal, percentage and share splits and a single payer, unit
+// splits and several payers have to be given again.
+func (app *cli) editExpense(args []string) error {
+	f := newExpenseFlags("expense edit")
+	version := f.fs.Int("version", 0, "version the edit is made against, defaults to the current one")
+	positional, err := parseFlags(f.fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		return errors.New("usage: splitexpense expense edit <expense> [flags]")
+	}
+
+	current, err := app.findExpense(positional[0], *f.group)
+	if err != nil {
+		return err
+	}
+	friends, err := app.api.Friends(app.ctx)
+	if err != nil {
+		return err
+	}
+
+	req := client.ExpenseRequest{
+		ID:          current.ID,
+		Description: current.Description,
+		Category:    current.Category,
+		Amount:      current.Amount,
+		Split:       current.SplitW,
+		Payee:       current.PayeeW,
+		GroupId:     current.GroupId,
+		Version:     current.Version,
+	}
+	if *version != 0 {
+		req.Version = *version
+	}
+	if f.set("description") {
+		req.Description = *f.description
+	}
+	if f.set("category") {
+		req.Category = *f.category
+	}
+	if f.set("amount") {
+		req.Amount = *f.amount
+	}
+
+	switch {
+	case f.set("with"):
+		kind := current.SplitW.Type
+		if f.set("split") {
+			kind = *f.split
+		}
+		if req.Split, err = app.buildSplit(kind, *f.with, req.Amount, friends); err != nil {
+			return err
+		}
+	case f.set("split"):
+		return errors.New("changing -split needs -with")
+	case f.set("amount"):
+		if req.Split, err = rescaleSplit(current.SplitW, req.Amount); err != nil {
+			return err
+		}
+	}
+
+	switch {
+	case f.set("paid-by"):
+		if req.Payee, err = app.buildPayer(*f.paidBy, req.Amount, friends); err != nil {
+			return err
+		}
+	case f.set("amount"):
+		single, ok := current.PayeeW.Payer.(*expense.SinglePayer)
+		if !ok {
+			return errors.New("the expense has several payers, give -paid-by with the new amounts")
+		}
+		req.Payee = expense.PayerWrapper{Type: "single", Payer: &expense.SinglePayer{Payer: single.Payer, Amount: req.Amount}}
+	}
+
+	saved, err := app.api.SaveExpense(app.ctx, req)
+	var apiErr *client.Error
+	if errors.As(err, &apiErr) && apiErr.Status == 409 {
+		return fmt.Errorf("%w, the expense changed meanwhile, check it and edit again", err)
+	}
+	if err != nil {
+		return err
+	}
+	return app.out.message(saved, "updated expense %s to version %d", saved.ID, saved.Version)
+}
+
+// findExpense looks the expense up among the user's expenses, narrowed to groupId when set
+func (app *cli) findExpense(expenseId string, groupId string) (*expense.Expense, error) {
+	filters := url.Values{}
+	if groupId != "" {
+		filters.Set("groupId", groupId)
+	}
+	page := expense.PageRequest{Limit: expense.MaxPageLimit}
+	for {
+		result, err := app.api.SearchExpenses(app.ctx, filters, page)
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range result.Expenses {
+			if e.Expense.ID == expenseId {
+				return &e.Expense, nil
+			}
+		}
+		if !result.HasMore {
+			return nil, fmt.Errorf("expense %s not found among your expenses", expenseId)
+		}
+		page.Cursor = result.NextCursor
+	}
+}
+
+func (app *cli) listExpenses(args []string) error {
+	fs := flag.NewFlagSet("expense list", flag.ContinueOnError)
+	group := fs.String("group", "", "list the group's expenses instead of your active ones")
+	archived := fs.Bool("archived", false, "include archived group expenses")
+	limit := fs.Int("limit", 0, "page size")
+	cursor := fs.String("cursor", "", "page to start from, printed after a page with more")
+	all := fs.Bool("all", false, "follow every page")
+	if _, err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if *archived && *group == "" {
+		return errors.New("-archived needs -group")
+	}
+
+	page := expense.PageRequest{Cursor: *cursor, Limit: *limit}
+	fetch := func(page expense.PageRequest) ([]expense.DetailedExpense, string, bool, error) {
+		if *group != "" {
+			history, err := app.api.GroupExpenses(app.ctx, *group, *archived, page)
+			if err != nil {
+				return nil, "", false, err
+			}
+			return history.Expenses, history.NextCursor, history.HasMore, nil
+		}
+		history, err := app.api.Expenses(app.ctx, page)
+		if err != nil {
+			return nil, "", false, err
+		}
+		return history.Expenses, history.NextCursor, history.HasMore, nil
+	}
+
+	expenses := []expense.DetailedExpense{}
+	next := ""
+	for {
+		batch, nextCursor, hasMore, err := fetch(page)
+		if err != nil {
+			return err
+		}
+		expenses = append(expenses, batch...)
+		next = ""
+		if hasMore {
+			next = nextCursor
+		}
+		if !*all || next == "" {
+			break
+		}
+		page.Cursor = next
+	}
+
+	rows := [][]string{}
+	for _, e := range expenses {
+		rows = append(rows, []string{
+			e.Expense.ID,
+			date(e.Expense.CreatedAt),
+			e.Expense.Description,
+			e.Expense.Category,
+			money(e.Expense.Amount),
+			string(e.Expense.Status),
+			signed(e.TotalOwed - e.TotalBorrowed),
+		})
+	}
+	value := struct {
+		Expenses   []expense.DetailedExpense `json:"expenses"`
+		NextCursor string                    `json:"nextCursor,omitempty"`
+	}{expenses, next}
+	if err := app.out.table(value, []string{"ID", "DATE", "DESCRIPTION", "CATEGORY", "AMOUNT", "STATUS", "YOU"}, rows); err != nil {
+		return err
+	}
+	if next != "" && app.out.format == formatTable {
+		fmt.Fprintf(os.Stderr, "more expenses, continue with -cursor %s\n", next)
+	}
+	return nil
+}
+
+// buildSplit reads -with for the split kind, a list of people for equal
+// splits and who=value pairs for the others
+func (app *cli) buildSplit(kind string, with string, amount float64, friends []expense.User) (expense.SplitWrapper, error) {
+	if kind == "equal" {
+		payees := []string{}
+		for _, who := range splitList(with) {
+			userId, err := app.resolveUser(who, friends)
+			if err != nil {
+				return expense.SplitWrapper{}, err
+			}
+			payees = append(payees, userId)
+		}
+		return expense.SplitWrapper{Type: kind, Split: &expense.EqualSplit{Payee: payees, TotalAmount: amount}}, nil
+	}
+
+	values, err := app.parseAssignments(with, friends)
+	if err != nil {
+		return expense.SplitWrapper{}, err
+	}
+	switch kind {
+	case "unit":
+		return expense.SplitWrapper{Type: kind, Split: &expense.UnitSplit{PayeeAmountSplit: values}}, nil
+	case "percentage":
+		return expense.SplitWrapper{Type: kind, Split: &expense.PercentageSplit{PercentageSplitMap: values, TotalAmount: amount}}, nil
+	case "share":
+		shares := map[string]int{}
+		for userId, value := range values {
+			if value != float64(int(value)) {
+				return expense.SplitWrapper{}, fmt.Errorf("shares are whole numbers, got %v", value)
+			}
+			shares[userId] = int(value)
+		}
+		return expense.SplitWrapper{Type: kind, Split: &expense.ShareSplit{SplitMap: shares, TotalAmount: amount}}, nil
+	}
+	return expense.SplitWrapper{}, fmt.Errorf("unknown split %q, expected equal, unit, percentage or share", kind)
+}
+
+// rescaleSplit keeps who shares the expense and in which proportion
+func rescaleSplit(split expense.SplitWrapper, amount float64) (expense.SplitWrapper, error) {
+	switch s := split.Split.(type) {
+	case *expense.EqualSplit:
+		return expense.SplitWrapper{Type: split.Type, Split: &expense.EqualSplit{Payee: s.Payee, TotalAmount: amount}}, nil
+	case *expense.PercentageSplit:
+		return expense.SplitWrapper{Type: split.Type, Split: &expense.PercentageSplit{PercentageSplitMap: s.PercentageSplitMap, TotalAmount: amount}}, nil
+	case *expense.ShareSplit:
+		return expense.SplitWrapper{Type: split.Type, Split: &expense.ShareSplit{SplitMap: s.SplitMap, TotalAmount: amount}}, nil
+	}
+	return split, errors.New("the expense has a unit split, give -with with the new amounts")
+}
+
+// buildPayer reads -paid-by, one person paying everything or who=amount pairs
+func (app *cli) buildPayer(paidBy string, amount float64, friends []expense.User) (expense.PayerWrapper, error) {
+	if !strings.Contains(paidBy, "=") {
+		userId, err := app.resolveUser(strings.TrimSpace(paidBy), friends)
+		if err != nil {
+			return expense.PayerWrapper{}, err
+		}
+		return expense.PayerWrapper{Type: "single", Payer: &expense.SinglePayer{Payer: userId, Amount: amount}}, nil
+	}
+	payers, err := app.parseAssignments(paidBy, friends)
+	if err != nil {
+		return expense.PayerWrapper{}, err
+	}
+	return expense.PayerWrapper{Type: "multi", Payer: &expense.MultiPayer{Payers: payers}}, nil
+}
+
+// parseAssignments reads who=value,... into values by user id
+func (app *cli) parseAssignments(list string, friends []expense.User) (map[string]float64, error) {
+	values := map[string]float64{}
+	for _, item := range splitList(list) {
+		who, raw, ok := strings.Cut(item, "=")
+		if !ok {
+			return nil, fmt.Errorf("expected who=value, got %q", item)
+		}
+		value, err := strconv.ParseFloat(strings.TrimSpace(raw), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value in %q", item)
+		}
+		userId, err := app.resolveUser(strings.TrimSpace(who), friends)
+		if err != nil {
+			return nil, err
+		}
+		values[userId] += value
+	}
+	if len(values) == 0 {
+		return nil, errors.New("nobody given")
+	}
+	return values, nil
+}
+
+func splitList(list string) []string {
+	items := []string{}
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
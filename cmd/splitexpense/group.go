@@ -0,0 +1,169 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+
+	"splitExpense/expense"
+)
+
+func runGroup(app *cli, args []string) error {
+	sub, args, err := subcommand("group", args, "list", "create", "show", "invite", "leave")
+	if err != nil {
+		return err
+	}
+	if err := app.requireLogin(); err != nil {
+		return err
+	}
+
+	switch sub {
+	case "list":
+		groups := []expense.Group{}
+		page := expense.PageRequest{Limit: expense.MaxPageLimit}
+		for {
+			result, err := app.api.Groups(app.ctx, page)
+			if err != nil {
+				return err
+			}
+			groups = append(groups, result.Groups...)
+			if !result.HasMore {
+				break
+			}
+			page.Cursor = result.NextCursor
+		}
+		rows := [][]string{}
+		for _, g := range groups {
+			rows = append(rows, []string{g.Id, g.Name, g.Description, date(g.CreatedAt)})
+		}
+		return app.out.table(groups, []string{"ID", "NAME", "DESCRIPTION", "CREATED"}, rows)
+
+	case "create":
+		fs := flag.NewFlagSet("group create", flag.ContinueOnError)
+		name := fs.String("name", "", "group name")
+		description := fs.String("description", "", "group description")
+		if _, err := parseFlags(fs, args); err != nil {
+			return err
+		}
+		if *name == "" {
+			return errors.New("group create needs -name")
+		}
+		group, err := app.api.CreateGroup(app.ctx, *name, *description)
+		if err != nil {
+			return err
+		}
+		return app.out.message(group, "created group %s (%s)", group.Name, group.Id)
+
+	case "show":
+		if len(args) != 1 {
+			return errors.New("usage: splitexpense group show <group>")
+		}
+		detail, err := app.api.Group(app.ctx, args[0])
+		if err != nil {
+			return err
+		}
+		if app.out.format == formatTable {
+			g := detail.Group
+			fmt.Fprintf(app.out.w, "%s (%s)\n", g.Group.Name, g.Group.Id)
+			if g.Group.Description != "" {
+				fmt.Fprintln(app.out.w, g.Group.Description)
+			}
+			fmt.Fprintf(app.out.w, "you are owed %s and owe %s\n\n", money(g.TotalOwed), money(g.TotalBorrowed))
+		}
+		rows := [][]string{}
+		for _, u := range detail.GroupMembers {
+			role := "member"
+			if u.ID == detail.Group.Group.Admin {
+				role = "admin"
+			}
+			rows = append(rows, []string{u.ID, u.Name, u.Email, role})
+		}
+		return app.out.table(detail, []string{"ID", "NAME", "EMAIL", "ROLE"}, rows)
+
+	case "invite":
+		if len(args) != 2 {
+			return errors.New("usage: splitexpense group invite <group> <friend>")
+		}
+		friends, err := app.api.Friends(app.ctx)
+		if err != nil {
+			return err
+		}
+		userId, err := app.resolveUser(args[1], friends)
+		if err != nil {
+			return err
+		}
+		if err := app.api.InviteToGroup(app.ctx, args[0], userId); err != nil {
+			return err
+		}
+		return app.out.message(map[string]string{"groupId": args[0], "userId": userId}, "added %s to group %s", args[1], args[0])
+
+	default:
+		if len(args) != 1 {
+			return errors.New("usage: splitexpense group leave <group>")
+		}
+		if err := app.api.LeaveGroup(app.ctx, args[0]); err != nil {
+			return err
+		}
+		return app.out.message(map[string]string{"groupId": args[0]}, "left group %s", args[0])
+	}
+}
+
+func runBalances(app *cli, args []string) error {
+	if len(args) != 0 {
+		return errors.New("usage: splitexpense balances")
+	}
+	if err := app.requireLogin(); err != nil {
+		return err
+	}
+
+	home, err := app.api.Home(app.ctx)
+	if err != nil {
+		return err
+	}
+	// totals also cover expenses outside groups
+	totals, err := app.api.Expenses(app.ctx, expense.PageRequest{Limit: 1})
+	if err != nil {
+		return err
+	}
+
+	type groupBalance struct {
+		GroupId  string  `json:"groupId"`
+		Name     string  `json:"name"`
+		Owed     float64 `json:"owed"`
+		Borrowed float64 `json:"borrowed"`
+		Net      float64 `json:"net"`
+	}
+	balances := struct {
+		Owed     float64        `json:"owed"`
+		Borrowed float64        `json:"borrowed"`
+		Net      float64        `json:"net"`
+		Groups   []groupBalance `json:"groups"`
+	}{
+		Owed:     totals.TotalOwed,
+		Borrowed: totals.TotalBorrowed,
+		Net:      totals.TotalOwed - totals.TotalBorrowed,
+		Groups:   []groupBalance{},
+	}
+
+	rows := [][]string{}
+	for _, g := range home.AssociatedGroups {
+		b := groupBalance{
+			GroupId:  g.Group.Id,
+			Name:     g.Group.Name,
+			Owed:     g.TotalOwed,
+			Borrowed: g.TotalBorrowed,
+			Net:      g.TotalOwed - g.TotalBorrowed,
+		}
+		balances.Groups = append(balances.Groups, b)
+		rows = append(rows, []string{b.Name, b.GroupId, money(b.Owed), money(b.Borrowed), signed(b.Net)})
+	}
+	rows = append(rows, []string{"total", "", money(balances.Owed), money(balances.Borrowed), signed(balances.Net)})
+	return app.out.table(balances, []string{"GROUP", "ID", "OWED TO YOU", "YOU OWE", "NET"}, rows)
+}
+
+func signed(amount float64) string {
+	if amount > 0 {
+		return "+" + money(amount)
+	}
+	return money(amount)
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"splitExpense/client"
+	"splitExpense/service"
+)
+
+// aliases collects repeated -as name=who flags
+type aliases map[string]string
+
+func (a aliases) String() string {
+	pairs := []string{}
+	for name, who := range a {
+		pairs = append(pairs, name+"="+who)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+func (a aliases) Set(value string) error {
+	name, who, ok := strings.Cut(value, "=")
+	if !ok {
+		return fmt.Errorf("expected name=who, got %q", value)
+	}
+	a[strings.TrimSpace(name)] = strings.TrimSpace(who)
+	return nil
+}
+
+// runImport previews the expenses of a Splitwise export or a CSV file, and
+// creates them with -commit
+func runImport(app *cli, args []string) error {
+	if err := app.requireLogin(); err != nil {
+		return err
+	}
+
+	fs := flag.NewFlagSet("import", flag.ContinueOnError)
+	format := fs.String("format", "splitwise", "splitwise or csv")
+	mapping := fs.String("map", "", "columns of a csv file, as date=<column>,amount=<column>,paidBy=<column>,split=<column>,...")
+	group := fs.String("group", "", "group to import into, names are matched to its members")
+	commit := fs.Bool("commit", false, "create the expenses, otherwise they are only previewed")
+	as := aliases{}
+	fs.Var(as, "as", "name=who, the friend or member a name of the file stands for, repeatable")
+	positional, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		return errors.New("usage: splitexpense import [-format splitwise|csv] [-map spec] [-group <group>] [-as name=who]... [-commit] <file>")
+	}
+
+	f, err := os.Open(positional[0])
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	report, err := app.api.ImportExpenses(app.ctx, client.ImportRequest{
+		Format:  *format,
+		Mapping: *mapping,
+		GroupId: *group,
+		Aliases: as,
+		Commit:  *commit,
+	}, f)
+	var apiErr *client.Error
+	if *commit && errors.As(err, &apiErr) && apiErr.Status == 400 {
+		return fmt.Errorf("%w, run without -commit to see what is wrong", err)
+	}
+	if err != nil {
+		return err
+	}
+	return app.printImport(report)
+}
+
+func (app *cli) printImport(report *service.ImportReport) error {
+	if app.out.format == formatJSON {
+		return app.out.json(report)
+	}
+
+	names := map[string]string{}
+	for name, userId := range report.People {
+		names[userId] = name
+	}
+	who := func(amounts map[string]float64) string {
+		parts := []string{}
+		for userId, amount := range amounts {
+			parts = append(parts, names[userId]+"="+money(amount))
+		}
+		sort.Strings(parts)
+		return strings.Join(parts, ", ")
+	}
+
+	rows := [][]string{}
+	for _, e := range report.Expenses {
+		rows = append(rows, []string{
+			strconv.Itoa(e.Line),
+			e.Date.Format(time.DateOnly),
+			e.Description,
+			e.Category,
+			money(e.Amount),
+			who(e.Payee.Payer.GetPayers()),
+			who(e.Split.Split.GetPayeeSplit()),
+		})
+	}
+	if err := app.out.table(report, []string{"LINE", "DATE", "DESCRIPTION", "CATEGORY", "AMOUNT", "PAID BY", "SPLIT"}, rows); err != nil {
+		return err
+	}
+
+	for _, p := range report.Skipped {
+		fmt.Fprintf(app.out.w, "skipped line %d: %s\n", p.Line, p.Message)
+	}
+	for _, p := range report.Problems {
+		fmt.Fprintf(app.out.w, "problem on line %d: %s\n", p.Line, p.Message)
+	}
+	switch {
+	case report.Committed:
+		fmt.Fprintf(app.out.w, "imported %d expenses\n", len(report.Expenses))
+	case len(report.Problems) > 0:
+		fmt.Fprintf(app.out.w, "%d expenses ready, fix the problems before importing with -commit\n", len(report.Expenses))
+	default:
+		fmt.Fprintf(app.out.w, "%d expenses ready, import them with -commit\n", len(report.Expenses))
+	}
+	return nil
+}
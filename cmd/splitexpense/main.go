@@ -0,0 +1,172 @@
+// Command splitexpense is the command line client for the splitExpense HTTP API.
+//
+//	splitexpense [-server url] [-o table|json] <command> [arguments]
+//
+// The login token is kept in the user config file, see configPath.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"splitExpense/client"
+)
+
+const usage = `usage: splitexpense [-server url] [-o table|json] <command> [arguments]
+
+commands:
+  signup -name <name> -email <email> [-password <password>]
+  login [-email <email>] [-password <password>]
+  logout
+  friends add <email>
+  friends list
+  group list
+  group create -name <name> [-description <text>]
+  group show <group>
+  group invite <group> <friend>
+  group leave <group>
+  expense add -amount <n> [-description <text>] [-category <name>] [-group <group>]
+              [-paid-by <who>|<who=n,...>] [-split equal|unit|percentage|share] -with <who,...|who=n,...>
+  expense edit <expense> [-group <group>] [the expense add flags to change]
+  expense settle <expense>
+  expense delete <expense>
+  expense list [-group <group>] [-archived] [-limit n] [-cursor c] [-all]
+  balances
+  import [-format splitwise|csv] [-map spec] [-group <group>] [-as name=who]... [-commit] <file>
+
+Friends and participants are given by id, email or name, "me" is the logged in user.
+Passwords not given with -password come from SPLITEXPENSE_PASSWORD or stdin.`
+
+type command func(app *cli, args []string) error
+
+var commands = map[string]command{
+	"signup":   runSignup,
+	"login":    runLogin,
+	"logout":   runLogout,
+	"friends":  runFriends,
+	"group":    runGroup,
+	"expense":  runExpense,
+	"balances": runBalances,
+	"import":   runImport,
+}
+
+// cli is the state shared by every command
+type cli struct {
+	ctx        context.Context
+	config     *userConfig
+	configPath string
+	api        *client.Client
+	out        output
+}
+
+func main() {
+	if err := run(os.Args[1:]); err != nil {
+		fmt.Fprintln(os.Stderr, "splitexpense:", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string) error {
+	fs := flag.NewFlagSet("splitexpense", flag.ContinueOnError)
+	fs.Usage = func() { fmt.Fprintln(fs.Output(), usage) }
+	server := fs.String("server", os.Getenv("SPLITEXPENSE_SERVER"), "API server, defaults to the one used at login")
+	format := fs.String("o", formatTable, "output format, table or json")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *format != formatTable && *format != formatJSON {
+		return fmt.Errorf("unknown output format %q", *format)
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return errors.New("missing command")
+	}
+	cmd, ok := commands[fs.Arg(0)]
+	if !ok {
+		names := []string{}
+		for name := range commands {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return fmt.Errorf("unknown command %q, expected one of %s", fs.Arg(0), strings.Join(names, ", "))
+	}
+
+	path, err := configPath()
+	if err != nil {
+		return err
+	}
+	cfg, err := loadConfig(path)
+	if err != nil {
+		return fmt.Errorf("reading %s: %w", path, err)
+	}
+	if *server != "" {
+		cfg.Server = *server
+	}
+
+	app := &cli{
+		ctx:        context.Background(),
+		config:     cfg,
+		configPath: path,
+		api:        client.New(cfg.Server, cfg.Token),
+		out:        output{format: *format, w: os.Stdout},
+	}
+	err = cmd(app, fs.Args()[1:])
+	// keep a token the server renewed during the call
+	if app.config.Token != "" && app.api.Token != app.config.Token {
+		app.config.Token = app.api.Token
+		if saveErr := app.config.save(app.configPath); saveErr != nil && err == nil {
+			err = saveErr
+		}
+	}
+	return authHint(err)
+}
+
+// requireLogin fails commands that need a token before they reach the server
+func (app *cli) requireLogin() error {
+	if app.config.Token == "" {
+		return errors.New("not logged in, run splitexpense login first")
+	}
+	return nil
+}
+
+func authHint(err error) error {
+	var apiErr *client.Error
+	if errors.As(err, &apiErr) && apiErr.Status == 401 {
+		return fmt.Errorf("%w, the login has expired, run splitexpense login again", err)
+	}
+	return err
+}
+
+// subcommand splits `<sub> args...` for commands with subcommands
+func subcommand(name string, args []string, subs ...string) (string, []string, error) {
+	if len(args) == 0 {
+		return "", nil, fmt.Errorf("usage: splitexpense %s %s", name, strings.Join(subs, "|"))
+	}
+	for _, sub := range subs {
+		if args[0] == sub {
+			return sub, args[1:], nil
+		}
+	}
+	return "", nil, fmt.Errorf("unknown %s command %q, expected one of %s", name, args[0], strings.Join(subs, ", "))
+}
+
+// parseFlags parses flags that may follow positional arguments, as in
+// `group show <id>`, and returns the positional arguments
+func parseFlags(fs *flag.FlagSet, args []string) ([]string, error) {
+	positional := []string{}
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		if fs.NArg() == 0 {
+			return positional, nil
+		}
+		positional = append(positional, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+const (
+	formatTable = "table"
+	formatJSON  = "json"
+)
+
+// output prints either aligned tables for people or the API values as JSON
+// for scripts
+type output struct {
+	format string
+	w      io.Writer
+}
+
+// table prints value as JSON, or the headers and rows as a table
+func (o output) table(value any, headers []string, rows [][]string) error {
+	if o.format == formatJSON {
+		return o.json(value)
+	}
+	tw := tabwriter.NewWriter(o.w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.Join(headers, "\t"))
+	for _, row := range rows {
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	return tw.Flush()
+}
+
+// message prints value as JSON, or a one line confirmation
+func (o output) message(value any, format string, args ...any) error {
+	if o.format == formatJSON {
+		return o.json(value)
+	}
+	_, err := fmt.Fprintf(o.w, format+"\n", args...)
+	return err
+}
+
+func (o output) json(value any) error {
+	enc := json.NewEncoder(o.w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(value)
+}
+
+func money(amount float64) string {
+	return strconv.FormatFloat(amount, 'f', 2, 64)
+}
+
+func date(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Local().Format(time.DateOnly)
+}
@@ -1,5 +1,11 @@
 package config
 
+import (
+	"os"
+	"strconv"
+	"time"
+)
+
 type Environment string
 
 const (
@@ -14,5 +20,132 @@ type Config struct {
 	DatabasePassword string
 	DatabaseName     string
 	DatabaseSSLMode  string
-	Environment      Environment
+	// DatabaseReplicaURL is an optional read only DSN, reads that tolerate lag are sent there
+	DatabaseReplicaURL string
+	// connection pool, zero keeps the database/sql default
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+	ConnMaxIdleTime time.Duration
+	// StatementTimeout is enforced by postgres on every statement, zero disables it
+	StatementTimeout time.Duration
+	// ConnectRetries is how many times a failed connection attempt is retried, doubling ConnectRetryBackoff each time
+	ConnectRetries      int
+	ConnectRetryBackoff time.Duration
+	Environment         Environment
+	// Addr is the address the HTTP server listens on
+	Addr string
+	// GRPCAddr is the address the gRPC server listens on, empty disables it
+	GRPCAddr string
+	// AutoMigrate applies pending schema migrations when the server starts
+	AutoMigrate bool
+	// RequestTimeout bounds every HTTP request including its storage calls, zero disables it
+	RequestTimeout time.Duration
+	// ArchiveAfterMonths moves settled expenses unchanged for that many months to the archive, zero disables archival
+	ArchiveAfterMonths int
+	ArchiveInterval    time.Duration
+	ArchiveBatchSize   int
+	// EventSourcing stores every expense and group change as a ledger event, the tables become projections of it
+	EventSourcing bool
+	// OutboxEnabled writes every expense, group and friend change to the outbox and dispatches it to the sinks
+	OutboxEnabled      bool
+	OutboxPollInterval time.Duration
+	OutboxBatchSize    int
+	// OutboxMaxAttempts gives up on an event after that many failed deliveries, zero retries forever
+	OutboxMaxAttempts  int
+	OutboxRetryBackoff time.Duration
+	// OutboxRetention is how long delivered events are kept, zero keeps them
+	OutboxRetention      time.Duration
+	OutboxLogEvents      bool
+	OutboxWebhookURL     string
+	OutboxWebhookSecret  string
+	OutboxWebhookTimeout time.Duration
+	// CacheSize bounds each storage lookup cache, zero disables caching
+	CacheSize int
+	CacheTTL  time.Duration
+	// RowLevelSecurity runs every authenticated request as the splitexpense_rls
+	// role, the database then only exposes the user's groups and expenses
+	RowLevelSecurity bool
+	// GroupDeletePolicy is the default for deleting a group, "refuse" keeps
+	// groups with unsettled expenses, "archive" archives them regardless
+	GroupDeletePolicy string
+	// IdempotencyKeyTTL is how long the response to a request sent with an
+	// Idempotency-Key is replayed, zero ignores the header
+	IdempotencyKeyTTL time.Duration
+}
+
+// Load returns the local development config, overridden by environment variables when set.
+func Load() *Config {
+	return &Config{
+		DatabaseHost:     getEnv("DB_HOST", "localhost"),
+		DatabasePort:     getEnv("DB_PORT", "5432"),
+		DatabaseUser:     getEnv("DB_USER", "postgres"),
+		DatabasePassword: getEnv("DB_PASSWORD", "postgres"),
+		DatabaseName:     getEnv("DB_NAME", "postgres"),
+		DatabaseSSLMode:  getEnv("DB_SSLMODE", "disable"),
+
+		DatabaseReplicaURL:  getEnv("DATABASE_REPLICA_URL", ""),
+		MaxOpenConns:        getEnvInt("DB_MAX_OPEN_CONNS", 25),
+		MaxIdleConns:        getEnvInt("DB_MAX_IDLE_CONNS", 10),
+		ConnMaxLifetime:     getEnvDuration("DB_CONN_MAX_LIFETIME", 30*time.Minute),
+		ConnMaxIdleTime:     getEnvDuration("DB_CONN_MAX_IDLE_TIME", 5*time.Minute),
+		StatementTimeout:    getEnvDuration("DB_STATEMENT_TIMEOUT", 10*time.Second),
+		ConnectRetries:      getEnvInt("DB_CONNECT_RETRIES", 3),
+		ConnectRetryBackoff: getEnvDuration("DB_CONNECT_RETRY_BACKOFF", 200*time.Millisecond),
+
+		Environment:    Environment(getEnv("APP_ENV", string(EnvironmentDevelopment))),
+		Addr:           getEnv("ADDR", ":8888"),
+		GRPCAddr:       getEnv("GRPC_ADDR", ":9090"),
+		AutoMigrate:    getEnv("AUTO_MIGRATE", "false") == "true",
+		RequestTimeout: getEnvDuration("REQUEST_TIMEOUT", 30*time.Second),
+
+		ArchiveAfterMonths: getEnvInt("ARCHIVE_AFTER_MONTHS", 0),
+		ArchiveInterval:    getEnvDuration("ARCHIVE_INTERVAL", 24*time.Hour),
+		ArchiveBatchSize:   getEnvInt("ARCHIVE_BATCH_SIZE", 500),
+
+		EventSourcing: getEnv("EVENT_SOURCING", "false") == "true",
+
+		OutboxEnabled:        getEnv("OUTBOX_ENABLED", "false") == "true",
+		OutboxPollInterval:   getEnvDuration("OUTBOX_POLL_INTERVAL", time.Second),
+		OutboxBatchSize:      getEnvInt("OUTBOX_BATCH_SIZE", 100),
+		OutboxMaxAttempts:    getEnvInt("OUTBOX_MAX_ATTEMPTS", 20),
+		OutboxRetryBackoff:   getEnvDuration("OUTBOX_RETRY_BACKOFF", 5*time.Second),
+		OutboxRetention:      getEnvDuration("OUTBOX_RETENTION", 7*24*time.Hour),
+		OutboxLogEvents:      getEnv("OUTBOX_LOG_EVENTS", "true") == "true",
+		OutboxWebhookURL:     getEnv("OUTBOX_WEBHOOK_URL", ""),
+		OutboxWebhookSecret:  getEnv("OUTBOX_WEBHOOK_SECRET", ""),
+		OutboxWebhookTimeout: getEnvDuration("OUTBOX_WEBHOOK_TIMEOUT", 10*time.Second),
+
+		CacheSize: getEnvInt("CACHE_SIZE", 10000),
+		CacheTTL:  getEnvDuration("CACHE_TTL", 30*time.Second),
+
+		RowLevelSecurity: getEnv("ROW_LEVEL_SECURITY", "false") == "true",
+
+		GroupDeletePolicy: getEnv("GROUP_DELETE_POLICY", "refuse"),
+
+		IdempotencyKeyTTL: getEnvDuration("IDEMPOTENCY_KEY_TTL", 24*time.Hour),
+	}
+}
+
+func getEnv(key string, fallback string) string {
+	if value, ok := os.LookupEnv(key); ok && value != "" {
+		return value
+	}
+	return fallback
+}
+
+func getEnvDuration(key string, fallback time.Duration) time.Duration {
+	d, err := time.ParseDuration(getEnv(key, ""))
+	if err != nil {
+		return fallback
+	}
+	return d
+}
+
+func getEnvInt(key string, fallback int) int {
+	n, err := strconv.Atoi(getEnv(key, ""))
+	if err != nil {
+		return fallback
+	}
+	return n
 }
@@ -7,6 +7,7 @@ package db
 import (
 	"database/sql"
 	"encoding/json"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -21,8 +22,27 @@ type Expense struct {
 	CreatedBy   uuid.UUID
 	Payee       json.RawMessage
 	GroupID     uuid.NullUUID
-	CreatedAt   sql.NullTime
+	CreatedAt   time.Time
 	UpdatedAt   sql.NullTime
+	Version     int32
+	Category    string
+}
+
+type ExpenseArchive struct {
+	ID          uuid.UUID
+	Description sql.NullString
+	Amount      string
+	Split       json.RawMessage
+	Status      string
+	SettledBy   uuid.NullUUID
+	CreatedBy   uuid.UUID
+	Payee       json.RawMessage
+	GroupID     uuid.NullUUID
+	CreatedAt   time.Time
+	UpdatedAt   sql.NullTime
+	Version     int32
+	Category    string
+	ArchivedAt  time.Time
 }
 
 type ExpenseMapping struct {
@@ -30,10 +50,15 @@ type ExpenseMapping struct {
 	UserID    uuid.UUID
 }
 
+type ExpenseMappingArchive struct {
+	ExpenseID uuid.UUID
+	UserID    uuid.UUID
+}
+
 type Friend struct {
 	UserID    uuid.UUID
 	FriendID  uuid.UUID
-	CreatedAt sql.NullTime
+	CreatedAt time.Time
 }
 
 type Group struct {
@@ -41,6 +66,18 @@ type Group struct {
 	Name        string
 	Description string
 	AdminID     uuid.UUID
+	Version     int32
+	CreatedAt   time.Time
+}
+
+type GroupArchive struct {
+	ID          uuid.UUID
+	Name        string
+	Description string
+	AdminID     uuid.UUID
+	Version     int32
+	CreatedAt   time.Time
+	DeletedAt   time.Time
 }
 
 type GroupMember struct {
@@ -48,6 +85,61 @@ type GroupMember struct {
 	GroupID uuid.UUID
 }
 
+type GroupMembersArchive struct {
+	GroupID uuid.UUID
+	UserID  uuid.UUID
+}
+
+type IdempotencyKey struct {
+	UserID         uuid.UUID
+	IdempotencyKey string
+	RequestHash    string
+	Status         sql.NullInt32
+	ContentType    string
+	Response       []byte
+	CreatedAt      time.Time
+	ExpiresAt      time.Time
+}
+
+type LedgerBalanceEntry struct {
+	ExpenseID uuid.UUID
+	UserID    uuid.UUID
+	GroupID   uuid.NullUUID
+	Category  string
+	Status    string
+	Paid      string
+	Share     string
+}
+
+type LedgerEvent struct {
+	Sequence         int64
+	ID               uuid.UUID
+	Type             string
+	AggregateType    string
+	AggregateID      uuid.UUID
+	AggregateVersion int32
+	Payload          json.RawMessage
+	ActorID          uuid.NullUUID
+	OccurredAt       time.Time
+}
+
+type OutboxEvent struct {
+	Sequence         int64
+	ID               uuid.UUID
+	Type             string
+	AggregateType    string
+	AggregateID      uuid.UUID
+	AggregateVersion int32
+	Payload          json.RawMessage
+	ActorID          uuid.NullUUID
+	OccurredAt       time.Time
+	Attempts         int32
+	NextAttemptAt    time.Time
+	LastError        sql.NullString
+	DeliveredAt      sql.NullTime
+	DeadAt           sql.NullTime
+}
+
 type User struct {
 	ID         uuid.UUID
 	Name       string
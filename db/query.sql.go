@@ -9,11 +9,29 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/lib/pq"
 )
 
+const addExpenseMappings = `-- name: AddExpenseMappings :exec
+INSERT INTO expense_mapping (expense_id, user_id)
+SELECT unnest($1::uuid[]), unnest($2::uuid[])
+ON CONFLICT DO NOTHING
+`
+
+type AddExpenseMappingsParams struct {
+	ExpenseIds []uuid.UUID
+	UserIds    []uuid.UUID
+}
+
+// Maps the users to the expenses pairwise, existing mappings are kept.
+func (q *Queries) AddExpenseMappings(ctx context.Context, arg AddExpenseMappingsParams) error {
+	_, err := q.db.ExecContext(ctx, addExpenseMappings, pq.Array(arg.ExpenseIds), pq.Array(arg.UserIds))
+	return err
+}
+
 const addFriend = `-- name: AddFriend :one
 INSERT INTO friends (user_id, friend_id) 
 VALUES ($1, $2)
@@ -71,6 +89,231 @@ func (q *Queries) AddUserInGroup(ctx context.Context, arg AddUserInGroupParams)
 	return column_1, err
 }
 
+const appendLedgerEvent = `-- name: AppendLedgerEvent :one
+INSERT INTO ledger_events (id, type, aggregate_type, aggregate_id, aggregate_version, payload, actor_id)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING sequence, id, type, aggregate_type, aggregate_id, aggregate_version, payload, actor_id, occurred_at
+`
+
+type AppendLedgerEventParams struct {
+	ID               uuid.UUID
+	Type             string
+	AggregateType    string
+	AggregateID      uuid.UUID
+	AggregateVersion int32
+	Payload          json.RawMessage
+	ActorID          uuid.NullUUID
+}
+
+func (q *Queries) AppendLedgerEvent(ctx context.Context, arg AppendLedgerEventParams) (LedgerEvent, error) {
+	row := q.db.QueryRowContext(ctx, appendLedgerEvent,
+		arg.ID,
+		arg.Type,
+		arg.AggregateType,
+		arg.AggregateID,
+		arg.AggregateVersion,
+		arg.Payload,
+		arg.ActorID,
+	)
+	var i LedgerEvent
+	err := row.Scan(
+		&i.Sequence,
+		&i.ID,
+		&i.Type,
+		&i.AggregateType,
+		&i.AggregateID,
+		&i.AggregateVersion,
+		&i.Payload,
+		&i.ActorID,
+		&i.OccurredAt,
+	)
+	return i, err
+}
+
+const archiveGroup = `-- name: ArchiveGroup :one
+INSERT INTO group_archive (id, name, description, admin_id, version, created_at)
+SELECT id, name, description, admin_id, version, created_at
+FROM "group" WHERE id = $1::uuid
+FOR UPDATE
+RETURNING TRUE
+`
+
+// Copies the group to group_archive, locking it against concurrent joins and
+// expenses until the deleting transaction ends.
+func (q *Queries) ArchiveGroup(ctx context.Context, groupID uuid.UUID) (bool, error) {
+	row := q.db.QueryRowContext(ctx, archiveGroup, groupID)
+	var column_1 bool
+	err := row.Scan(&column_1)
+	return column_1, err
+}
+
+const archiveGroupMembers = `-- name: ArchiveGroupMembers :execrows
+INSERT INTO group_members_archive (group_id, user_id)
+SELECT group_id, user_id FROM group_members WHERE group_id = $1::uuid
+`
+
+func (q *Queries) ArchiveGroupMembers(ctx context.Context, groupID uuid.UUID) (int64, error) {
+	result, err := q.db.ExecContext(ctx, archiveGroupMembers, groupID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const bootstrapArchivedExpenseEvents = `-- name: BootstrapArchivedExpenseEvents :execrows
+INSERT INTO ledger_events (id, type, aggregate_type, aggregate_id, aggregate_version, payload)
+SELECT gen_random_uuid(), 'ExpenseArchived', 'expense', e.id, e.version,
+       jsonb_build_object('id', e.id, 'description', COALESCE(e.description, ''), 'category', e.category,
+                          'amount', e.amount::float8, 'createdAt', e.created_at, 'payeeW', e.payee, 'splitW', e.split,
+                          'status', e.status, 'isGroupExpense', e.group_id IS NOT NULL,
+                          'groupId', COALESCE(e.group_id::text, ''), 'settledBy', COALESCE(e.settled_by::text, ''),
+                          'createdBy', e.created_by, 'version', e.version)
+FROM expense_archive e
+WHERE NOT EXISTS (SELECT 1 FROM group_archive ga WHERE ga.id = e.group_id)
+  AND NOT EXISTS (
+    SELECT 1 FROM ledger_events le
+    WHERE le.aggregate_type = 'expense' AND le.aggregate_id = e.id AND le.type = 'ExpenseArchived')
+ORDER BY e.updated_at, e.id
+`
+
+// Runs after the participant events, archived expenses of deleted groups are
+// left to the GroupDeleted events.
+func (q *Queries) BootstrapArchivedExpenseEvents(ctx context.Context) (int64, error) {
+	result, err := q.db.ExecContext(ctx, bootstrapArchivedExpenseEvents)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const bootstrapExpenseEvents = `-- name: BootstrapExpenseEvents :execrows
+INSERT INTO ledger_events (id, type, aggregate_type, aggregate_id, aggregate_version, payload)
+SELECT gen_random_uuid(), 'ExpenseCreated', 'expense', e.id, e.version,
+       jsonb_build_object('id', e.id, 'description', COALESCE(e.description, ''), 'category', e.category,
+                          'amount', e.amount::float8, 'createdAt', e.created_at, 'payeeW', e.payee, 'splitW', e.split,
+                          'status', e.status, 'isGroupExpense', e.group_id IS NOT NULL,
+                          'groupId', COALESCE(e.group_id::text, ''), 'settledBy', COALESCE(e.settled_by::text, ''),
+                          'createdBy', e.created_by, 'version', e.version)
+FROM (
+    SELECT id, description, amount, split, status, settled_by, created_by, payee, group_id, created_at, version, category FROM expense
+    UNION ALL
+    SELECT id, description, amount, split, status, settled_by, created_by, payee, group_id, created_at, version, category FROM expense_archive
+) e
+WHERE NOT EXISTS (
+    SELECT 1 FROM ledger_events le WHERE le.aggregate_type = 'expense' AND le.aggregate_id = e.id)
+ORDER BY e.created_at, e.id
+`
+
+// Records live and archived expenses that predate the ledger as ExpenseCreated
+// events, the payload has the JSON shape of expense.Expense.
+func (q *Queries) BootstrapExpenseEvents(ctx context.Context) (int64, error) {
+	result, err := q.db.ExecContext(ctx, bootstrapExpenseEvents)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const bootstrapGroupDeletedEvents = `-- name: BootstrapGroupDeletedEvents :execrows
+INSERT INTO ledger_events (id, type, aggregate_type, aggregate_id, aggregate_version, payload)
+SELECT gen_random_uuid(), 'GroupDeleted', 'group', g.id, g.version,
+       jsonb_build_object('id', g.id, 'name', g.name, 'description', g.description, 'admin', g.admin_id,
+                          'version', g.version, 'createdAt', g.created_at)
+FROM group_archive g
+WHERE NOT EXISTS (
+    SELECT 1 FROM ledger_events le
+    WHERE le.aggregate_type = 'group' AND le.aggregate_id = g.id AND le.type = 'GroupDeleted')
+ORDER BY g.deleted_at, g.id
+`
+
+// Runs after the participant events, so replay archives a deleted group
+// together with the expenses it had.
+func (q *Queries) BootstrapGroupDeletedEvents(ctx context.Context) (int64, error) {
+	result, err := q.db.ExecContext(ctx, bootstrapGroupDeletedEvents)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const bootstrapGroupEvents = `-- name: BootstrapGroupEvents :execrows
+INSERT INTO ledger_events (id, type, aggregate_type, aggregate_id, aggregate_version, payload)
+SELECT gen_random_uuid(), 'GroupCreated', 'group', g.id, g.version,
+       jsonb_build_object('id', g.id, 'name', g.name, 'description', g.description, 'admin', g.admin_id,
+                          'version', g.version, 'createdAt', g.created_at)
+FROM (
+    SELECT id, name, description, admin_id, version, created_at FROM "group"
+    UNION ALL
+    SELECT id, name, description, admin_id, version, created_at FROM group_archive
+) g
+WHERE NOT EXISTS (
+    SELECT 1 FROM ledger_events le WHERE le.aggregate_type = 'group' AND le.aggregate_id = g.id)
+ORDER BY g.created_at, g.id
+`
+
+// Records live and deleted groups that predate the ledger as GroupCreated events.
+func (q *Queries) BootstrapGroupEvents(ctx context.Context) (int64, error) {
+	result, err := q.db.ExecContext(ctx, bootstrapGroupEvents)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const bootstrapMemberEvents = `-- name: BootstrapMemberEvents :execrows
+INSERT INTO ledger_events (id, type, aggregate_type, aggregate_id, aggregate_version, payload)
+SELECT gen_random_uuid(), 'MemberJoined', 'group', g.id, g.version,
+       jsonb_build_object('groupId', gm.group_id, 'userId', gm.user_id)
+FROM (
+    SELECT group_id, user_id FROM group_members
+    UNION ALL
+    SELECT group_id, user_id FROM group_members_archive
+) gm
+JOIN (
+    SELECT id, version, created_at FROM "group"
+    UNION ALL
+    SELECT id, version, created_at FROM group_archive
+) g ON g.id = gm.group_id
+WHERE NOT EXISTS (
+    SELECT 1 FROM ledger_events le
+    WHERE le.aggregate_type = 'group' AND le.aggregate_id = gm.group_id
+      AND le.type = 'MemberJoined' AND le.payload->>'userId' = gm.user_id::text)
+ORDER BY g.created_at, g.id, gm.user_id
+`
+
+func (q *Queries) BootstrapMemberEvents(ctx context.Context) (int64, error) {
+	result, err := q.db.ExecContext(ctx, bootstrapMemberEvents)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const bootstrapParticipantEvents = `-- name: BootstrapParticipantEvents :execrows
+INSERT INTO ledger_events (id, type, aggregate_type, aggregate_id, aggregate_version, payload)
+SELECT gen_random_uuid(), 'ParticipantsAdded', 'expense', m.expense_id, 0,
+       jsonb_build_object('expenseId', m.expense_id, 'userIds', jsonb_agg(m.user_id ORDER BY m.user_id))
+FROM (
+    SELECT expense_id, user_id FROM expense_mapping
+    UNION ALL
+    SELECT expense_id, user_id FROM expense_mapping_archive
+) m
+WHERE NOT EXISTS (
+    SELECT 1 FROM ledger_events le
+    WHERE le.aggregate_type = 'expense' AND le.aggregate_id = m.expense_id
+      AND le.type = 'ParticipantsAdded' AND le.payload->'userIds' @> to_jsonb(m.user_id::text))
+GROUP BY m.expense_id
+ORDER BY m.expense_id
+`
+
+func (q *Queries) BootstrapParticipantEvents(ctx context.Context) (int64, error) {
+	result, err := q.db.ExecContext(ctx, bootstrapParticipantEvents)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
 const checkUserExistsInGroup = `-- name: CheckUserExistsInGroup :one
 SELECT EXISTS(
     SELECT 1 FROM group_members
@@ -90,11 +333,168 @@ func (q *Queries) CheckUserExistsInGroup(ctx context.Context, arg CheckUserExist
 	return exists, err
 }
 
+const claimOutboxEvents = `-- name: ClaimOutboxEvents :many
+WITH due AS (
+    SELECT o.sequence FROM outbox_events o
+    WHERE o.delivered_at IS NULL AND o.dead_at IS NULL AND o.next_attempt_at <= NOW()
+      AND NOT EXISTS (
+          SELECT 1 FROM outbox_events p
+          WHERE p.aggregate_type = o.aggregate_type AND p.aggregate_id = o.aggregate_id
+            AND p.delivered_at IS NULL AND p.dead_at IS NULL AND p.sequence < o.sequence)
+    ORDER BY o.sequence
+    LIMIT $2
+    FOR UPDATE OF o SKIP LOCKED
+)
+UPDATE outbox_events e
+SET next_attempt_at = $1
+FROM due
+WHERE e.sequence = due.sequence
+RETURNING e.sequence, e.id, e.type, e.aggregate_type, e.aggregate_id, e.aggregate_version, e.payload, e.actor_id, e.occurred_at, e.attempts, e.next_attempt_at, e.last_error, e.delivered_at, e.dead_at
+`
+
+type ClaimOutboxEventsParams struct {
+	LeasedUntil time.Time
+	BatchSize   int32
+}
+
+// Only the oldest pending event of each aggregate is due, so a failing event
+// holds back the ones after it. Claimed events are leased by moving their next
+// attempt to leased_until, other dispatchers skip them until the lease runs out.
+func (q *Queries) ClaimOutboxEvents(ctx context.Context, arg ClaimOutboxEventsParams) ([]OutboxEvent, error) {
+	rows, err := q.db.QueryContext(ctx, claimOutboxEvents, arg.LeasedUntil, arg.BatchSize)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []OutboxEvent
+	for rows.Next() {
+		var i OutboxEvent
+		if err := rows.Scan(
+			&i.Sequence,
+			&i.ID,
+			&i.Type,
+			&i.AggregateType,
+			&i.AggregateID,
+			&i.AggregateVersion,
+			&i.Payload,
+			&i.ActorID,
+			&i.OccurredAt,
+			&i.Attempts,
+			&i.NextAttemptAt,
+			&i.LastError,
+			&i.DeliveredAt,
+			&i.DeadAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const completeIdempotencyKey = `-- name: CompleteIdempotencyKey :exec
+UPDATE idempotency_keys
+SET status = $1, content_type = $2, response = $3
+WHERE user_id = $4 AND idempotency_key = $5
+`
+
+type CompleteIdempotencyKeyParams struct {
+	Status         sql.NullInt32
+	ContentType    string
+	Response       []byte
+	UserID         uuid.UUID
+	IdempotencyKey string
+}
+
+func (q *Queries) CompleteIdempotencyKey(ctx context.Context, arg CompleteIdempotencyKeyParams) error {
+	_, err := q.db.ExecContext(ctx, completeIdempotencyKey,
+		arg.Status,
+		arg.ContentType,
+		arg.Response,
+		arg.UserID,
+		arg.IdempotencyKey,
+	)
+	return err
+}
+
+const copyExpenseMappingsToArchive = `-- name: CopyExpenseMappingsToArchive :exec
+INSERT INTO expense_mapping_archive (expense_id, user_id)
+SELECT expense_id, user_id FROM expense_mapping WHERE expense_id = ANY($1::uuid[])
+`
+
+func (q *Queries) CopyExpenseMappingsToArchive(ctx context.Context, ids []uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, copyExpenseMappingsToArchive, pq.Array(ids))
+	return err
+}
+
+const copyExpensesToArchive = `-- name: CopyExpensesToArchive :exec
+INSERT INTO expense_archive (id, description, amount, split, status, settled_by, created_by, payee, group_id, created_at, updated_at, version, category)
+SELECT id, description, amount, split, status, settled_by, created_by, payee, group_id, created_at, updated_at, version, category
+FROM expense WHERE id = ANY($1::uuid[])
+`
+
+func (q *Queries) CopyExpensesToArchive(ctx context.Context, ids []uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, copyExpensesToArchive, pq.Array(ids))
+	return err
+}
+
+const countLedgerEventsByType = `-- name: CountLedgerEventsByType :many
+SELECT type, COUNT(*) AS count FROM ledger_events GROUP BY type ORDER BY type
+`
+
+type CountLedgerEventsByTypeRow struct {
+	Type  string
+	Count int64
+}
+
+func (q *Queries) CountLedgerEventsByType(ctx context.Context) ([]CountLedgerEventsByTypeRow, error) {
+	rows, err := q.db.QueryContext(ctx, countLedgerEventsByType)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []CountLedgerEventsByTypeRow
+	for rows.Next() {
+		var i CountLedgerEventsByTypeRow
+		if err := rows.Scan(&i.Type, &i.Count); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const countUnsettledGroupExpenses = `-- name: CountUnsettledGroupExpenses :one
+SELECT COUNT(*) AS count FROM expense
+WHERE group_id = $1::uuid AND status = 'DRAFT' AND amount > 0
+`
+
+// Drafts that still carry an amount, settled expenses no longer move money.
+func (q *Queries) CountUnsettledGroupExpenses(ctx context.Context, groupID uuid.UUID) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countUnsettledGroupExpenses, groupID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createOrUpdateExpense = `-- name: CreateOrUpdateExpense :one
-INSERT INTO expense (id, description, amount, split, status, settled_by, created_by, payee, created_at, updated_at, group_id)
-VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+INSERT INTO expense (id, description, amount, split, status, settled_by, created_by, payee, created_at, updated_at, group_id, category, version)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, GREATEST($13::integer, 1))
 ON CONFLICT (id) DO UPDATE SET
     description = EXCLUDED.description,
+    category = EXCLUDED.category,
     amount = EXCLUDED.amount,
     split = EXCLUDED.split,
     status = EXCLUDED.status,
@@ -102,8 +502,10 @@ ON CONFLICT (id) DO UPDATE SET
     created_by = EXCLUDED.created_by,
     payee = EXCLUDED.payee,
     updated_at = NOW() AT TIME ZONE 'Asia/Kolkata',
-    group_id = EXCLUDED.group_id
-RETURNING id, description, amount, split, status, settled_by, created_by, payee, group_id, created_at, updated_at
+    group_id = EXCLUDED.group_id,
+    version = expense.version + 1
+WHERE expense.version = $13::integer
+RETURNING id, description, amount, split, status, settled_by, created_by, payee, group_id, created_at, updated_at, version, category
 `
 
 type CreateOrUpdateExpenseParams struct {
@@ -115,11 +517,14 @@ type CreateOrUpdateExpenseParams struct {
 	SettledBy   uuid.NullUUID
 	CreatedBy   uuid.UUID
 	Payee       json.RawMessage
-	CreatedAt   sql.NullTime
+	CreatedAt   time.Time
 	UpdatedAt   sql.NullTime
 	GroupID     uuid.NullUUID
+	Category    string
+	Version     int32
 }
 
+// A new row starts at the given version, at least 1, see CreateOrUpdateGroup.
 func (q *Queries) CreateOrUpdateExpense(ctx context.Context, arg CreateOrUpdateExpenseParams) (Expense, error) {
 	row := q.db.QueryRowContext(ctx, createOrUpdateExpense,
 		arg.ID,
@@ -133,6 +538,8 @@ func (q *Queries) CreateOrUpdateExpense(ctx context.Context, arg CreateOrUpdateE
 		arg.CreatedAt,
 		arg.UpdatedAt,
 		arg.GroupID,
+		arg.Category,
+		arg.Version,
 	)
 	var i Expense
 	err := row.Scan(
@@ -147,18 +554,22 @@ func (q *Queries) CreateOrUpdateExpense(ctx context.Context, arg CreateOrUpdateE
 		&i.GroupID,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Version,
+		&i.Category,
 	)
 	return i, err
 }
 
 const createOrUpdateGroup = `-- name: CreateOrUpdateGroup :one
-INSERT INTO "group" (id, name, description, admin_id)
-VALUES ($1, $2, $3, $4)
+INSERT INTO "group" (id, name, description, admin_id, version, created_at)
+VALUES ($1, $2, $3, $4, GREATEST($5::integer, 1), COALESCE($6::timestamptz, NOW()))
 ON CONFLICT (id) DO UPDATE SET
     name = EXCLUDED.name,
     description = EXCLUDED.description,
-    admin_id = EXCLUDED.admin_id
-RETURNING id, name, description, admin_id
+    admin_id = EXCLUDED.admin_id,
+    version = "group".version + 1
+WHERE "group".version = $5::integer
+RETURNING id, name, description, admin_id, version, created_at
 `
 
 type CreateOrUpdateGroupParams struct {
@@ -166,14 +577,20 @@ type CreateOrUpdateGroupParams struct {
 	Name        string
 	Description string
 	AdminID     uuid.UUID
+	Version     int32
+	CreatedAt   sql.NullTime
 }
 
+// A new row starts at the given version (at least 1) and created_at, so
+// replaying a recorded group reproduces it exactly.
 func (q *Queries) CreateOrUpdateGroup(ctx context.Context, arg CreateOrUpdateGroupParams) (Group, error) {
 	row := q.db.QueryRowContext(ctx, createOrUpdateGroup,
 		arg.ID,
 		arg.Name,
 		arg.Description,
 		arg.AdminID,
+		arg.Version,
+		arg.CreatedAt,
 	)
 	var i Group
 	err := row.Scan(
@@ -181,10 +598,46 @@ func (q *Queries) CreateOrUpdateGroup(ctx context.Context, arg CreateOrUpdateGro
 		&i.Name,
 		&i.Description,
 		&i.AdminID,
+		&i.Version,
+		&i.CreatedAt,
 	)
 	return i, err
 }
 
+const deleteArchivedExpenses = `-- name: DeleteArchivedExpenses :execrows
+DELETE FROM expense WHERE id = ANY($1::uuid[])
+`
+
+func (q *Queries) DeleteArchivedExpenses(ctx context.Context, ids []uuid.UUID) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteArchivedExpenses, pq.Array(ids))
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteBalanceEntries = `-- name: DeleteBalanceEntries :exec
+DELETE FROM ledger_balance_entries WHERE expense_id = $1
+`
+
+func (q *Queries) DeleteBalanceEntries(ctx context.Context, expenseID uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, deleteBalanceEntries, expenseID)
+	return err
+}
+
+const deleteDeliveredOutboxEvents = `-- name: DeleteDeliveredOutboxEvents :execrows
+DELETE FROM outbox_events
+WHERE delivered_at < $1
+`
+
+func (q *Queries) DeleteDeliveredOutboxEvents(ctx context.Context, deliveredBefore sql.NullTime) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteDeliveredOutboxEvents, deliveredBefore)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
 const deleteExpense = `-- name: DeleteExpense :one
 DELETE FROM expense WHERE id = $1 RETURNING TRUE
 `
@@ -196,6 +649,18 @@ func (q *Queries) DeleteExpense(ctx context.Context, id uuid.UUID) (bool, error)
 	return column_1, err
 }
 
+const deleteExpiredIdempotencyKeys = `-- name: DeleteExpiredIdempotencyKeys :execrows
+DELETE FROM idempotency_keys WHERE expires_at <= NOW()
+`
+
+func (q *Queries) DeleteExpiredIdempotencyKeys(ctx context.Context) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteExpiredIdempotencyKeys)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
 const deleteGroup = `-- name: DeleteGroup :one
 DELETE FROM "group" WHERE id = $1 RETURNING TRUE
 `
@@ -207,8 +672,45 @@ func (q *Queries) DeleteGroup(ctx context.Context, id uuid.UUID) (bool, error) {
 	return column_1, err
 }
 
+const deleteGroupBalanceEntries = `-- name: DeleteGroupBalanceEntries :exec
+DELETE FROM ledger_balance_entries WHERE group_id = $1
+`
+
+func (q *Queries) DeleteGroupBalanceEntries(ctx context.Context, groupID uuid.NullUUID) error {
+	_, err := q.db.ExecContext(ctx, deleteGroupBalanceEntries, groupID)
+	return err
+}
+
+const enqueueOutboxEvent = `-- name: EnqueueOutboxEvent :exec
+INSERT INTO outbox_events (id, type, aggregate_type, aggregate_id, aggregate_version, payload, actor_id)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+`
+
+type EnqueueOutboxEventParams struct {
+	ID               uuid.UUID
+	Type             string
+	AggregateType    string
+	AggregateID      uuid.UUID
+	AggregateVersion int32
+	Payload          json.RawMessage
+	ActorID          uuid.NullUUID
+}
+
+func (q *Queries) EnqueueOutboxEvent(ctx context.Context, arg EnqueueOutboxEventParams) error {
+	_, err := q.db.ExecContext(ctx, enqueueOutboxEvent,
+		arg.ID,
+		arg.Type,
+		arg.AggregateType,
+		arg.AggregateID,
+		arg.AggregateVersion,
+		arg.Payload,
+		arg.ActorID,
+	)
+	return err
+}
+
 const fetchExpense = `-- name: FetchExpense :one
-SELECT id, description, amount, split, status, settled_by, created_by, payee, group_id, created_at, updated_at FROM expense WHERE id = $1 LIMIT 1
+SELECT id, description, amount, split, status, settled_by, created_by, payee, group_id, created_at, updated_at, version, category FROM expense WHERE id = $1 LIMIT 1
 `
 
 func (q *Queries) FetchExpense(ctx context.Context, id uuid.UUID) (Expense, error) {
@@ -226,12 +728,14 @@ func (q *Queries) FetchExpense(ctx context.Context, id uuid.UUID) (Expense, erro
 		&i.GroupID,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Version,
+		&i.Category,
 	)
 	return i, err
 }
 
 const fetchExpenseByUserAndStatus = `-- name: FetchExpenseByUserAndStatus :many
-SELECT e.id, e.description, e.amount, e.split, e.status, e.settled_by, e.created_by, e.payee, e.group_id, e.created_at, e.updated_at from expense_mapping em
+SELECT e.id, e.description, e.amount, e.split, e.status, e.settled_by, e.created_by, e.payee, e.group_id, e.created_at, e.updated_at, e.version, e.category from expense_mapping em
 JOIN expense e ON em.expense_id = e.id
 where em.user_id = $1 AND e.status = $2
 ORDER BY e.created_at DESC
@@ -271,6 +775,8 @@ func (q *Queries) FetchExpenseByUserAndStatus(ctx context.Context, arg FetchExpe
 			&i.GroupID,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.Version,
+			&i.Category,
 		); err != nil {
 			return nil, err
 		}
@@ -330,61 +836,24 @@ func (q *Queries) FetchExpenseCountByUserAndStatus(ctx context.Context, arg Fetc
 	return count, err
 }
 
-const fetchGroupById = `-- name: FetchGroupById :one
-SELECT id, name, description, admin_id FROM "group" WHERE id = $1 LIMIT 1
-`
-
-func (q *Queries) FetchGroupById(ctx context.Context, id uuid.UUID) (Group, error) {
-	row := q.db.QueryRowContext(ctx, fetchGroupById, id)
-	var i Group
-	err := row.Scan(
-		&i.ID,
-		&i.Name,
-		&i.Description,
-		&i.AdminID,
-	)
-	return i, err
-}
-
-const fetchGroupExpenses = `-- name: FetchGroupExpenses :many
-SELECT e.id, e.description, e.amount, e.split, e.status, e.settled_by, e.created_by, e.payee, e.group_id, e.created_at, e.updated_at
-FROM expense e
-WHERE e.group_id = $1
-ORDER BY e.created_at DESC
-LIMIT $3 OFFSET (($2 - 1) * $3)
+const fetchExpenseParticipants = `-- name: FetchExpenseParticipants :many
+SELECT user_id FROM expense_mapping
+WHERE expense_id = $1
 `
 
-type FetchGroupExpensesParams struct {
-	GroupID uuid.NullUUID
-	Column2 interface{}
-	Limit   int32
-}
-
-func (q *Queries) FetchGroupExpenses(ctx context.Context, arg FetchGroupExpensesParams) ([]Expense, error) {
-	rows, err := q.db.QueryContext(ctx, fetchGroupExpenses, arg.GroupID, arg.Column2, arg.Limit)
+func (q *Queries) FetchExpenseParticipants(ctx context.Context, expenseID uuid.UUID) ([]uuid.UUID, error) {
+	rows, err := q.db.QueryContext(ctx, fetchExpenseParticipants, expenseID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []Expense
+	var items []uuid.UUID
 	for rows.Next() {
-		var i Expense
-		if err := rows.Scan(
-			&i.ID,
-			&i.Description,
-			&i.Amount,
-			&i.Split,
-			&i.Status,
-			&i.SettledBy,
-			&i.CreatedBy,
-			&i.Payee,
-			&i.GroupID,
-			&i.CreatedAt,
-			&i.UpdatedAt,
-		); err != nil {
+		var user_id uuid.UUID
+		if err := rows.Scan(&user_id); err != nil {
 			return nil, err
 		}
-		items = append(items, i)
+		items = append(items, user_id)
 	}
 	if err := rows.Close(); err != nil {
 		return nil, err
@@ -395,28 +864,21 @@ func (q *Queries) FetchGroupExpenses(ctx context.Context, arg FetchGroupExpenses
 	return items, nil
 }
 
-const fetchGroupExpensesByStatus = `-- name: FetchGroupExpensesByStatus :many
-SELECT e.id, e.description, e.amount, e.split, e.status, e.settled_by, e.created_by, e.payee, e.group_id, e.created_at, e.updated_at 
-FROM expense e
-WHERE e.group_id = $1 AND e.status = $2
-ORDER BY e.created_at DESC
-LIMIT $4 OFFSET (($3 - 1) * $4)
+const fetchExpensesAfterId = `-- name: FetchExpensesAfterId :many
+SELECT id, description, amount, split, status, settled_by, created_by, payee, group_id, created_at, updated_at, version, category FROM expense
+WHERE id > $1::uuid
+ORDER BY id
+LIMIT $2
 `
 
-type FetchGroupExpensesByStatusParams struct {
-	GroupID uuid.NullUUID
-	Status  string
-	Column3 interface{}
-	Limit   int32
+type FetchExpensesAfterIdParams struct {
+	AfterID   uuid.UUID
+	PageLimit int32
 }
 
-func (q *Queries) FetchGroupExpensesByStatus(ctx context.Context, arg FetchGroupExpensesByStatusParams) ([]Expense, error) {
-	rows, err := q.db.QueryContext(ctx, fetchGroupExpensesByStatus,
-		arg.GroupID,
-		arg.Status,
-		arg.Column3,
-		arg.Limit,
-	)
+// Walks every live expense in id order, for maintenance jobs.
+func (q *Queries) FetchExpensesAfterId(ctx context.Context, arg FetchExpensesAfterIdParams) ([]Expense, error) {
+	rows, err := q.db.QueryContext(ctx, fetchExpensesAfterId, arg.AfterID, arg.PageLimit)
 	if err != nil {
 		return nil, err
 	}
@@ -436,6 +898,8 @@ func (q *Queries) FetchGroupExpensesByStatus(ctx context.Context, arg FetchGroup
 			&i.GroupID,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.Version,
+			&i.Category,
 		); err != nil {
 			return nil, err
 		}
@@ -450,29 +914,382 @@ func (q *Queries) FetchGroupExpensesByStatus(ctx context.Context, arg FetchGroup
 	return items, nil
 }
 
-const fetchGroupMembers = `-- name: FetchGroupMembers :many
-SELECT u.id, u.name, u.email, u.is_verified, u.password, u.created_at, u.updated_at FROM "users" u
-JOIN group_members gm ON u.id = gm.user_id
-WHERE gm.group_id = $1
+const fetchGroupById = `-- name: FetchGroupById :one
+SELECT id, name, description, admin_id, version, created_at FROM "group" WHERE id = $1 LIMIT 1
 `
 
-func (q *Queries) FetchGroupMembers(ctx context.Context, groupID uuid.UUID) ([]User, error) {
-	rows, err := q.db.QueryContext(ctx, fetchGroupMembers, groupID)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-	var items []User
+func (q *Queries) FetchGroupById(ctx context.Context, id uuid.UUID) (Group, error) {
+	row := q.db.QueryRowContext(ctx, fetchGroupById, id)
+	var i Group
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Description,
+		&i.AdminID,
+		&i.Version,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const fetchGroupExpenseIdsForUpdate = `-- name: FetchGroupExpenseIdsForUpdate :many
+SELECT id FROM expense WHERE group_id = $1::uuid ORDER BY id FOR UPDATE
+`
+
+func (q *Queries) FetchGroupExpenseIdsForUpdate(ctx context.Context, groupID uuid.UUID) ([]uuid.UUID, error) {
+	rows, err := q.db.QueryContext(ctx, fetchGroupExpenseIdsForUpdate, groupID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const fetchGroupExpenseNonMembers = `-- name: FetchGroupExpenseNonMembers :many
+SELECT em.expense_id, e.group_id, em.user_id FROM expense_mapping em
+JOIN expense e ON e.id = em.expense_id
+WHERE e.group_id IS NOT NULL
+  AND NOT EXISTS (SELECT 1 FROM group_members gm WHERE gm.group_id = e.group_id AND gm.user_id = em.user_id)
+ORDER BY em.expense_id, em.user_id
+`
+
+type FetchGroupExpenseNonMembersRow struct {
+	ExpenseID uuid.UUID
+	GroupID   uuid.NullUUID
+	UserID    uuid.UUID
+}
+
+// Participants of group expenses who are not, or no longer, members of the group.
+func (q *Queries) FetchGroupExpenseNonMembers(ctx context.Context) ([]FetchGroupExpenseNonMembersRow, error) {
+	rows, err := q.db.QueryContext(ctx, fetchGroupExpenseNonMembers)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []FetchGroupExpenseNonMembersRow
+	for rows.Next() {
+		var i FetchGroupExpenseNonMembersRow
+		if err := rows.Scan(&i.ExpenseID, &i.GroupID, &i.UserID); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const fetchGroupExpenses = `-- name: FetchGroupExpenses :many
+SELECT e.id, e.description, e.amount, e.split, e.status, e.settled_by, e.created_by, e.payee, e.group_id, e.created_at, e.updated_at, e.version, e.category
+FROM expense e
+WHERE e.group_id = $1
+ORDER BY e.created_at DESC
+LIMIT $3 OFFSET (($2 - 1) * $3)
+`
+
+type FetchGroupExpensesParams struct {
+	GroupID uuid.NullUUID
+	Column2 interface{}
+	Limit   int32
+}
+
+func (q *Queries) FetchGroupExpenses(ctx context.Context, arg FetchGroupExpensesParams) ([]Expense, error) {
+	rows, err := q.db.QueryContext(ctx, fetchGroupExpenses, arg.GroupID, arg.Column2, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Expense
+	for rows.Next() {
+		var i Expense
+		if err := rows.Scan(
+			&i.ID,
+			&i.Description,
+			&i.Amount,
+			&i.Split,
+			&i.Status,
+			&i.SettledBy,
+			&i.CreatedBy,
+			&i.Payee,
+			&i.GroupID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Version,
+			&i.Category,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const fetchGroupExpensesByStatus = `-- name: FetchGroupExpensesByStatus :many
+SELECT e.id, e.description, e.amount, e.split, e.status, e.settled_by, e.created_by, e.payee, e.group_id, e.created_at, e.updated_at, e.version, e.category 
+FROM expense e
+WHERE e.group_id = $1 AND e.status = $2
+ORDER BY e.created_at DESC
+LIMIT $4 OFFSET (($3 - 1) * $4)
+`
+
+type FetchGroupExpensesByStatusParams struct {
+	GroupID uuid.NullUUID
+	Status  string
+	Column3 interface{}
+	Limit   int32
+}
+
+func (q *Queries) FetchGroupExpensesByStatus(ctx context.Context, arg FetchGroupExpensesByStatusParams) ([]Expense, error) {
+	rows, err := q.db.QueryContext(ctx, fetchGroupExpensesByStatus,
+		arg.GroupID,
+		arg.Status,
+		arg.Column3,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Expense
+	for rows.Next() {
+		var i Expense
+		if err := rows.Scan(
+			&i.ID,
+			&i.Description,
+			&i.Amount,
+			&i.Split,
+			&i.Status,
+			&i.SettledBy,
+			&i.CreatedBy,
+			&i.Payee,
+			&i.GroupID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Version,
+			&i.Category,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const fetchGroupExpensesPage = `-- name: FetchGroupExpensesPage :many
+WITH e AS (
+    SELECT id, description, amount, split, status, settled_by, created_by, payee, group_id, created_at, updated_at, version, category FROM expense
+    UNION ALL
+    SELECT id, description, amount, split, status, settled_by, created_by, payee, group_id, created_at, updated_at, version, category FROM expense_archive WHERE $6::boolean
+)
+SELECT id, description, amount, split, status, settled_by, created_by, payee, group_id, created_at, updated_at, version, category FROM e
+WHERE e.group_id = $1::uuid
+  AND ($2::text IS NULL OR e.status = $2::text)
+  AND ($3::timestamptz IS NULL
+       OR (e.created_at, e.id) < ($3::timestamptz, $4::uuid))
+ORDER BY e.created_at DESC, e.id DESC
+LIMIT $5
+`
+
+type FetchGroupExpensesPageParams struct {
+	GroupID         uuid.UUID
+	Status          sql.NullString
+	CursorCreatedAt sql.NullTime
+	CursorID        uuid.NullUUID
+	PageLimit       int32
+	IncludeArchived bool
+}
+
+type FetchGroupExpensesPageRow struct {
+	ID          uuid.UUID
+	Description sql.NullString
+	Amount      string
+	Split       json.RawMessage
+	Status      string
+	SettledBy   uuid.NullUUID
+	CreatedBy   uuid.UUID
+	Payee       json.RawMessage
+	GroupID     uuid.NullUUID
+	CreatedAt   time.Time
+	UpdatedAt   sql.NullTime
+	Version     int32
+	Category    string
+}
+
+// Archived expenses are only read when include_archived is set.
+func (q *Queries) FetchGroupExpensesPage(ctx context.Context, arg FetchGroupExpensesPageParams) ([]FetchGroupExpensesPageRow, error) {
+	rows, err := q.db.QueryContext(ctx, fetchGroupExpensesPage,
+		arg.GroupID,
+		arg.Status,
+		arg.CursorCreatedAt,
+		arg.CursorID,
+		arg.PageLimit,
+		arg.IncludeArchived,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []FetchGroupExpensesPageRow
+	for rows.Next() {
+		var i FetchGroupExpensesPageRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Description,
+			&i.Amount,
+			&i.Split,
+			&i.Status,
+			&i.SettledBy,
+			&i.CreatedBy,
+			&i.Payee,
+			&i.GroupID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Version,
+			&i.Category,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const fetchGroupMembers = `-- name: FetchGroupMembers :many
+SELECT u.id, u.name, u.email, u.is_verified, u.password, u.created_at, u.updated_at FROM "users" u
+JOIN group_members gm ON u.id = gm.user_id
+WHERE gm.group_id = $1
+`
+
+func (q *Queries) FetchGroupMembers(ctx context.Context, groupID uuid.UUID) ([]User, error) {
+	rows, err := q.db.QueryContext(ctx, fetchGroupMembers, groupID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []User
+	for rows.Next() {
+		var i User
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Email,
+			&i.IsVerified,
+			&i.Password,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const fetchGroupMembersByGroupIds = `-- name: FetchGroupMembersByGroupIds :many
+SELECT gm.group_id, u.id, u.name, u.email, u.is_verified FROM "users" u
+JOIN group_members gm ON u.id = gm.user_id
+WHERE gm.group_id = ANY($1::uuid[])
+`
+
+type FetchGroupMembersByGroupIdsRow struct {
+	GroupID    uuid.UUID
+	ID         uuid.UUID
+	Name       string
+	Email      string
+	IsVerified bool
+}
+
+func (q *Queries) FetchGroupMembersByGroupIds(ctx context.Context, groupIds []uuid.UUID) ([]FetchGroupMembersByGroupIdsRow, error) {
+	rows, err := q.db.QueryContext(ctx, fetchGroupMembersByGroupIds, pq.Array(groupIds))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []FetchGroupMembersByGroupIdsRow
+	for rows.Next() {
+		var i FetchGroupMembersByGroupIdsRow
+		if err := rows.Scan(
+			&i.GroupID,
+			&i.ID,
+			&i.Name,
+			&i.Email,
+			&i.IsVerified,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const fetchGroupsByIds = `-- name: FetchGroupsByIds :many
+SELECT id, name, description, admin_id, version, created_at FROM "group" WHERE id = ANY($1::uuid[])
+`
+
+func (q *Queries) FetchGroupsByIds(ctx context.Context, ids []uuid.UUID) ([]Group, error) {
+	rows, err := q.db.QueryContext(ctx, fetchGroupsByIds, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Group
 	for rows.Next() {
-		var i User
+		var i Group
 		if err := rows.Scan(
 			&i.ID,
 			&i.Name,
-			&i.Email,
-			&i.IsVerified,
-			&i.Password,
+			&i.Description,
+			&i.AdminID,
+			&i.Version,
 			&i.CreatedAt,
-			&i.UpdatedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -488,7 +1305,7 @@ func (q *Queries) FetchGroupMembers(ctx context.Context, groupID uuid.UUID) ([]U
 }
 
 const fetchGroupsByUser = `-- name: FetchGroupsByUser :many
-SELECT g.id, g.name, g.description, g.admin_id FROM "group" g
+SELECT g.id, g.name, g.description, g.admin_id, g.version, g.created_at FROM "group" g
 JOIN group_members gm ON g.id = gm.group_id
 WHERE gm.user_id = $1
 `
@@ -507,6 +1324,148 @@ func (q *Queries) FetchGroupsByUser(ctx context.Context, userID uuid.UUID) ([]Gr
 			&i.Name,
 			&i.Description,
 			&i.AdminID,
+			&i.Version,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const fetchGroupsByUserPage = `-- name: FetchGroupsByUserPage :many
+SELECT g.id, g.name, g.description, g.admin_id, g.version, g.created_at FROM "group" g
+JOIN group_members gm ON g.id = gm.group_id
+WHERE gm.user_id = $1
+  AND ($2::timestamptz IS NULL
+       OR (g.created_at, g.id) < ($2::timestamptz, $3::uuid))
+ORDER BY g.created_at DESC, g.id DESC
+LIMIT $4
+`
+
+type FetchGroupsByUserPageParams struct {
+	UserID          uuid.UUID
+	CursorCreatedAt sql.NullTime
+	CursorID        uuid.NullUUID
+	PageLimit       int32
+}
+
+func (q *Queries) FetchGroupsByUserPage(ctx context.Context, arg FetchGroupsByUserPageParams) ([]Group, error) {
+	rows, err := q.db.QueryContext(ctx, fetchGroupsByUserPage,
+		arg.UserID,
+		arg.CursorCreatedAt,
+		arg.CursorID,
+		arg.PageLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Group
+	for rows.Next() {
+		var i Group
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Description,
+			&i.AdminID,
+			&i.Version,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const fetchGroupsExpensesPage = `-- name: FetchGroupsExpensesPage :many
+WITH e AS (
+    SELECT id, description, amount, split, status, settled_by, created_by, payee, group_id, created_at, updated_at, version, category FROM expense
+    WHERE group_id = ANY($2::uuid[])
+    UNION ALL
+    SELECT id, description, amount, split, status, settled_by, created_by, payee, group_id, created_at, updated_at, version, category FROM expense_archive
+    WHERE $3::boolean AND group_id = ANY($2::uuid[])
+), ranked AS (
+    SELECT e.id, e.description, e.amount, e.split, e.status, e.settled_by, e.created_by, e.payee, e.group_id, e.created_at, e.updated_at, e.version, e.category, ROW_NUMBER() OVER (PARTITION BY e.group_id ORDER BY e.created_at DESC, e.id DESC) AS position
+    FROM e
+    WHERE $4::timestamptz IS NULL
+       OR (e.created_at, e.id) < ($4::timestamptz, $5::uuid)
+)
+SELECT id, description, amount, split, status, settled_by, created_by, payee, group_id, created_at, updated_at, version, category
+FROM ranked
+WHERE position <= $1::bigint
+ORDER BY group_id, created_at DESC, id DESC
+`
+
+type FetchGroupsExpensesPageParams struct {
+	PageLimit       int64
+	GroupIds        []uuid.UUID
+	IncludeArchived bool
+	CursorCreatedAt sql.NullTime
+	CursorID        uuid.NullUUID
+}
+
+type FetchGroupsExpensesPageRow struct {
+	ID          uuid.UUID
+	Description sql.NullString
+	Amount      string
+	Split       json.RawMessage
+	Status      string
+	SettledBy   uuid.NullUUID
+	CreatedBy   uuid.UUID
+	Payee       json.RawMessage
+	GroupID     uuid.NullUUID
+	CreatedAt   time.Time
+	UpdatedAt   sql.NullTime
+	Version     int32
+	Category    string
+}
+
+// The page of FetchGroupExpensesPage for several groups at once, every group
+// gets up to page_limit expenses after the same cursor.
+func (q *Queries) FetchGroupsExpensesPage(ctx context.Context, arg FetchGroupsExpensesPageParams) ([]FetchGroupsExpensesPageRow, error) {
+	rows, err := q.db.QueryContext(ctx, fetchGroupsExpensesPage,
+		arg.PageLimit,
+		pq.Array(arg.GroupIds),
+		arg.IncludeArchived,
+		arg.CursorCreatedAt,
+		arg.CursorID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []FetchGroupsExpensesPageRow
+	for rows.Next() {
+		var i FetchGroupsExpensesPageRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Description,
+			&i.Amount,
+			&i.Split,
+			&i.Status,
+			&i.SettledBy,
+			&i.CreatedBy,
+			&i.Payee,
+			&i.GroupID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Version,
+			&i.Category,
 		); err != nil {
 			return nil, err
 		}
@@ -521,6 +1480,175 @@ func (q *Queries) FetchGroupsByUser(ctx context.Context, userID uuid.UUID) ([]Gr
 	return items, nil
 }
 
+const fetchGroupsWithoutAdminMember = `-- name: FetchGroupsWithoutAdminMember :many
+SELECT g.id, g.admin_id FROM "group" g
+WHERE NOT EXISTS (SELECT 1 FROM group_members gm WHERE gm.group_id = g.id AND gm.user_id = g.admin_id)
+ORDER BY g.id
+`
+
+type FetchGroupsWithoutAdminMemberRow struct {
+	ID      uuid.UUID
+	AdminID uuid.UUID
+}
+
+func (q *Queries) FetchGroupsWithoutAdminMember(ctx context.Context) ([]FetchGroupsWithoutAdminMemberRow, error) {
+	rows, err := q.db.QueryContext(ctx, fetchGroupsWithoutAdminMember)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []FetchGroupsWithoutAdminMemberRow
+	for rows.Next() {
+		var i FetchGroupsWithoutAdminMemberRow
+		if err := rows.Scan(&i.ID, &i.AdminID); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const fetchIdempotencyKey = `-- name: FetchIdempotencyKey :one
+SELECT user_id, idempotency_key, request_hash, status, content_type, response, created_at, expires_at FROM idempotency_keys
+WHERE user_id = $1 AND idempotency_key = $2
+`
+
+type FetchIdempotencyKeyParams struct {
+	UserID         uuid.UUID
+	IdempotencyKey string
+}
+
+func (q *Queries) FetchIdempotencyKey(ctx context.Context, arg FetchIdempotencyKeyParams) (IdempotencyKey, error) {
+	row := q.db.QueryRowContext(ctx, fetchIdempotencyKey, arg.UserID, arg.IdempotencyKey)
+	var i IdempotencyKey
+	err := row.Scan(
+		&i.UserID,
+		&i.IdempotencyKey,
+		&i.RequestHash,
+		&i.Status,
+		&i.ContentType,
+		&i.Response,
+		&i.CreatedAt,
+		&i.ExpiresAt,
+	)
+	return i, err
+}
+
+const fetchLedgerBalances = `-- name: FetchLedgerBalances :many
+SELECT group_id, SUM(paid)::numeric AS paid, SUM(share)::numeric AS share
+FROM ledger_balance_entries
+WHERE user_id = $1 AND status = 'DRAFT'
+GROUP BY group_id
+`
+
+type FetchLedgerBalancesRow struct {
+	GroupID uuid.NullUUID
+	Paid    string
+	Share   string
+}
+
+func (q *Queries) FetchLedgerBalances(ctx context.Context, userID uuid.UUID) ([]FetchLedgerBalancesRow, error) {
+	rows, err := q.db.QueryContext(ctx, fetchLedgerBalances, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []FetchLedgerBalancesRow
+	for rows.Next() {
+		var i FetchLedgerBalancesRow
+		if err := rows.Scan(&i.GroupID, &i.Paid, &i.Share); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const fetchLedgerCategoryTotals = `-- name: FetchLedgerCategoryTotals :many
+SELECT category, SUM(share)::numeric AS total
+FROM ledger_balance_entries
+WHERE user_id = $1
+GROUP BY category
+ORDER BY category
+`
+
+type FetchLedgerCategoryTotalsRow struct {
+	Category string
+	Total    string
+}
+
+func (q *Queries) FetchLedgerCategoryTotals(ctx context.Context, userID uuid.UUID) ([]FetchLedgerCategoryTotalsRow, error) {
+	rows, err := q.db.QueryContext(ctx, fetchLedgerCategoryTotals, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []FetchLedgerCategoryTotalsRow
+	for rows.Next() {
+		var i FetchLedgerCategoryTotalsRow
+		if err := rows.Scan(&i.Category, &i.Total); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const fetchSettledExpensesToArchive = `-- name: FetchSettledExpensesToArchive :many
+SELECT id FROM expense
+WHERE status = 'SETTLED' AND updated_at < $1
+ORDER BY updated_at
+LIMIT $2
+FOR UPDATE SKIP LOCKED
+`
+
+type FetchSettledExpensesToArchiveParams struct {
+	SettledBefore sql.NullTime
+	BatchSize     int32
+}
+
+// Locks the batch so concurrent archival runs skip each other's rows.
+func (q *Queries) FetchSettledExpensesToArchive(ctx context.Context, arg FetchSettledExpensesToArchiveParams) ([]uuid.UUID, error) {
+	rows, err := q.db.QueryContext(ctx, fetchSettledExpensesToArchive, arg.SettledBefore, arg.BatchSize)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const fetchUserByEmail = `-- name: FetchUserByEmail :one
 SELECT id, name, email, is_verified, password, created_at, updated_at FROM "users" WHERE email = $1
 `
@@ -559,6 +1687,104 @@ func (q *Queries) FetchUserById(ctx context.Context, id uuid.UUID) (User, error)
 	return i, err
 }
 
+const fetchUserExpensesPage = `-- name: FetchUserExpensesPage :many
+SELECT e.id, e.description, e.amount, e.split, e.status, e.settled_by, e.created_by, e.payee, e.group_id, e.created_at, e.updated_at, e.version, e.category FROM expense_mapping em
+JOIN expense e ON em.expense_id = e.id
+WHERE em.user_id = $1
+  AND ($2::text IS NULL OR e.status = $2::text)
+  AND ($3::timestamptz IS NULL
+       OR (e.created_at, e.id) < ($3::timestamptz, $4::uuid))
+ORDER BY e.created_at DESC, e.id DESC
+LIMIT $5
+`
+
+type FetchUserExpensesPageParams struct {
+	UserID          uuid.UUID
+	Status          sql.NullString
+	CursorCreatedAt sql.NullTime
+	CursorID        uuid.NullUUID
+	PageLimit       int32
+}
+
+func (q *Queries) FetchUserExpensesPage(ctx context.Context, arg FetchUserExpensesPageParams) ([]Expense, error) {
+	rows, err := q.db.QueryContext(ctx, fetchUserExpensesPage,
+		arg.UserID,
+		arg.Status,
+		arg.CursorCreatedAt,
+		arg.CursorID,
+		arg.PageLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Expense
+	for rows.Next() {
+		var i Expense
+		if err := rows.Scan(
+			&i.ID,
+			&i.Description,
+			&i.Amount,
+			&i.Split,
+			&i.Status,
+			&i.SettledBy,
+			&i.CreatedBy,
+			&i.Payee,
+			&i.GroupID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Version,
+			&i.Category,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const fetchUsersByIds = `-- name: FetchUsersByIds :many
+SELECT id, name, email, is_verified, password, created_at, updated_at FROM "users" WHERE id = ANY($1::uuid[])
+`
+
+// Batched lookups for the GraphQL loaders, ids that match nothing are left out.
+func (q *Queries) FetchUsersByIds(ctx context.Context, ids []uuid.UUID) ([]User, error) {
+	rows, err := q.db.QueryContext(ctx, fetchUsersByIds, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []User
+	for rows.Next() {
+		var i User
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Email,
+			&i.IsVerified,
+			&i.Password,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getFriend = `-- name: GetFriend :one
 SELECT u.id, u.name, u.email
 FROM users u
@@ -632,6 +1858,125 @@ func (q *Queries) GetFriends(ctx context.Context, userID uuid.UUID) ([]GetFriend
 	return items, nil
 }
 
+const getFriendsPage = `-- name: GetFriendsPage :many
+SELECT u.id, u.name, u.email, u.is_verified, f.created_at AS friends_since
+FROM users u
+JOIN friends f ON u.id = f.friend_id
+WHERE f.user_id = $1
+  AND ($2::timestamp IS NULL
+       OR (f.created_at, f.friend_id) < ($2::timestamp, $3::uuid))
+ORDER BY f.created_at DESC, f.friend_id DESC
+LIMIT $4
+`
+
+type GetFriendsPageParams struct {
+	UserID          uuid.UUID
+	CursorCreatedAt sql.NullTime
+	CursorID        uuid.NullUUID
+	PageLimit       int32
+}
+
+type GetFriendsPageRow struct {
+	ID           uuid.UUID
+	Name         string
+	Email        string
+	IsVerified   bool
+	FriendsSince time.Time
+}
+
+func (q *Queries) GetFriendsPage(ctx context.Context, arg GetFriendsPageParams) ([]GetFriendsPageRow, error) {
+	rows, err := q.db.QueryContext(ctx, getFriendsPage,
+		arg.UserID,
+		arg.CursorCreatedAt,
+		arg.CursorID,
+		arg.PageLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetFriendsPageRow
+	for rows.Next() {
+		var i GetFriendsPageRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Email,
+			&i.IsVerified,
+			&i.FriendsSince,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const insertBalanceEntries = `-- name: InsertBalanceEntries :exec
+INSERT INTO ledger_balance_entries (expense_id, user_id, group_id, category, status, paid, share)
+SELECT unnest($1::uuid[]), unnest($2::uuid[]),
+    NULLIF(unnest($3::text[]), '')::uuid, unnest($4::text[]),
+    unnest($5::text[]), unnest($6::numeric[]), unnest($7::numeric[])
+`
+
+type InsertBalanceEntriesParams struct {
+	ExpenseIds []uuid.UUID
+	UserIds    []uuid.UUID
+	GroupIds   []string
+	Categories []string
+	Statuses   []string
+	Paid       []string
+	Shares     []string
+}
+
+// The arrays hold one entry each position, an empty group id is a personal expense.
+func (q *Queries) InsertBalanceEntries(ctx context.Context, arg InsertBalanceEntriesParams) error {
+	_, err := q.db.ExecContext(ctx, insertBalanceEntries,
+		pq.Array(arg.ExpenseIds),
+		pq.Array(arg.UserIds),
+		pq.Array(arg.GroupIds),
+		pq.Array(arg.Categories),
+		pq.Array(arg.Statuses),
+		pq.Array(arg.Paid),
+		pq.Array(arg.Shares),
+	)
+	return err
+}
+
+const insertBalanceEntry = `-- name: InsertBalanceEntry :exec
+INSERT INTO ledger_balance_entries (expense_id, user_id, group_id, category, status, paid, share)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+`
+
+type InsertBalanceEntryParams struct {
+	ExpenseID uuid.UUID
+	UserID    uuid.UUID
+	GroupID   uuid.NullUUID
+	Category  string
+	Status    string
+	Paid      string
+	Share     string
+}
+
+func (q *Queries) InsertBalanceEntry(ctx context.Context, arg InsertBalanceEntryParams) error {
+	_, err := q.db.ExecContext(ctx, insertBalanceEntry,
+		arg.ExpenseID,
+		arg.UserID,
+		arg.GroupID,
+		arg.Category,
+		arg.Status,
+		arg.Paid,
+		arg.Share,
+	)
+	return err
+}
+
 const insertUser = `-- name: InsertUser :one
 INSERT INTO "users" (id, name, email, is_verified, password, created_at, updated_at)
 VALUES ($1, $2, $3, $4, $5, $6, $7)
@@ -671,6 +2016,169 @@ func (q *Queries) InsertUser(ctx context.Context, arg InsertUserParams) (User, e
 	return i, err
 }
 
+const loadAggregateEvents = `-- name: LoadAggregateEvents :many
+SELECT sequence, id, type, aggregate_type, aggregate_id, aggregate_version, payload, actor_id, occurred_at FROM ledger_events
+WHERE aggregate_type = $1 AND aggregate_id = $2
+ORDER BY sequence
+`
+
+type LoadAggregateEventsParams struct {
+	AggregateType string
+	AggregateID   uuid.UUID
+}
+
+func (q *Queries) LoadAggregateEvents(ctx context.Context, arg LoadAggregateEventsParams) ([]LedgerEvent, error) {
+	rows, err := q.db.QueryContext(ctx, loadAggregateEvents, arg.AggregateType, arg.AggregateID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []LedgerEvent
+	for rows.Next() {
+		var i LedgerEvent
+		if err := rows.Scan(
+			&i.Sequence,
+			&i.ID,
+			&i.Type,
+			&i.AggregateType,
+			&i.AggregateID,
+			&i.AggregateVersion,
+			&i.Payload,
+			&i.ActorID,
+			&i.OccurredAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const loadLedgerEvents = `-- name: LoadLedgerEvents :many
+SELECT sequence, id, type, aggregate_type, aggregate_id, aggregate_version, payload, actor_id, occurred_at FROM ledger_events
+WHERE sequence > $1
+ORDER BY sequence
+LIMIT $2
+`
+
+type LoadLedgerEventsParams struct {
+	AfterSequence int64
+	PageLimit     int32
+}
+
+func (q *Queries) LoadLedgerEvents(ctx context.Context, arg LoadLedgerEventsParams) ([]LedgerEvent, error) {
+	rows, err := q.db.QueryContext(ctx, loadLedgerEvents, arg.AfterSequence, arg.PageLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []LedgerEvent
+	for rows.Next() {
+		var i LedgerEvent
+		if err := rows.Scan(
+			&i.Sequence,
+			&i.ID,
+			&i.Type,
+			&i.AggregateType,
+			&i.AggregateID,
+			&i.AggregateVersion,
+			&i.Payload,
+			&i.ActorID,
+			&i.OccurredAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const lockGroup = `-- name: LockGroup :one
+SELECT TRUE FROM "group" WHERE id = $1::uuid FOR UPDATE
+`
+
+// Holds the group against concurrent joins and expenses until the transaction ends.
+func (q *Queries) LockGroup(ctx context.Context, groupID uuid.UUID) (bool, error) {
+	row := q.db.QueryRowContext(ctx, lockGroup, groupID)
+	var column_1 bool
+	err := row.Scan(&column_1)
+	return column_1, err
+}
+
+const lockLedger = `-- name: LockLedger :exec
+LOCK TABLE ledger_events IN SHARE ROW EXCLUSIVE MODE
+`
+
+// Blocks other writers and replays until the transaction ends.
+func (q *Queries) LockLedger(ctx context.Context) error {
+	_, err := q.db.ExecContext(ctx, lockLedger)
+	return err
+}
+
+const markOutboxEventDelivered = `-- name: MarkOutboxEventDelivered :exec
+UPDATE outbox_events
+SET delivered_at = NOW(), attempts = attempts + 1, last_error = NULL
+WHERE sequence = $1
+`
+
+func (q *Queries) MarkOutboxEventDelivered(ctx context.Context, sequence int64) error {
+	_, err := q.db.ExecContext(ctx, markOutboxEventDelivered, sequence)
+	return err
+}
+
+const markOutboxEventFailed = `-- name: MarkOutboxEventFailed :exec
+UPDATE outbox_events
+SET attempts = attempts + 1,
+    last_error = $1,
+    next_attempt_at = $2,
+    dead_at = CASE WHEN $3::boolean THEN NOW() END
+WHERE sequence = $4
+`
+
+type MarkOutboxEventFailedParams struct {
+	LastError     sql.NullString
+	NextAttemptAt time.Time
+	Dead          bool
+	Sequence      int64
+}
+
+func (q *Queries) MarkOutboxEventFailed(ctx context.Context, arg MarkOutboxEventFailedParams) error {
+	_, err := q.db.ExecContext(ctx, markOutboxEventFailed,
+		arg.LastError,
+		arg.NextAttemptAt,
+		arg.Dead,
+		arg.Sequence,
+	)
+	return err
+}
+
+const releaseIdempotencyKey = `-- name: ReleaseIdempotencyKey :exec
+DELETE FROM idempotency_keys
+WHERE user_id = $1 AND idempotency_key = $2
+`
+
+type ReleaseIdempotencyKeyParams struct {
+	UserID         uuid.UUID
+	IdempotencyKey string
+}
+
+func (q *Queries) ReleaseIdempotencyKey(ctx context.Context, arg ReleaseIdempotencyKeyParams) error {
+	_, err := q.db.ExecContext(ctx, releaseIdempotencyKey, arg.UserID, arg.IdempotencyKey)
+	return err
+}
+
 const removeFriend = `-- name: RemoveFriend :one
 DELETE FROM friends 
 WHERE (user_id = $1 AND friend_id = $2) 
@@ -726,6 +2234,257 @@ func (q *Queries) RemoveUsersFromExpenseMapping(ctx context.Context, arg RemoveU
 	return column_1, err
 }
 
+const reserveIdempotencyKey = `-- name: ReserveIdempotencyKey :one
+INSERT INTO idempotency_keys (user_id, idempotency_key, request_hash, expires_at)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (user_id, idempotency_key) DO UPDATE
+SET request_hash = EXCLUDED.request_hash, status = NULL, content_type = '', response = NULL,
+    created_at = NOW(), expires_at = EXCLUDED.expires_at
+WHERE idempotency_keys.expires_at <= NOW()
+RETURNING created_at
+`
+
+type ReserveIdempotencyKeyParams struct {
+	UserID         uuid.UUID
+	IdempotencyKey string
+	RequestHash    string
+	ExpiresAt      time.Time
+}
+
+// Claims the key for a request, taking over an expired claim. No row means
+// the key is in use.
+func (q *Queries) ReserveIdempotencyKey(ctx context.Context, arg ReserveIdempotencyKeyParams) (time.Time, error) {
+	row := q.db.QueryRowContext(ctx, reserveIdempotencyKey,
+		arg.UserID,
+		arg.IdempotencyKey,
+		arg.RequestHash,
+		arg.ExpiresAt,
+	)
+	var created_at time.Time
+	err := row.Scan(&created_at)
+	return created_at, err
+}
+
+const resetBalanceEntries = `-- name: ResetBalanceEntries :exec
+DELETE FROM ledger_balance_entries
+`
+
+func (q *Queries) ResetBalanceEntries(ctx context.Context) error {
+	_, err := q.db.ExecContext(ctx, resetBalanceEntries)
+	return err
+}
+
+const resetExpenseArchive = `-- name: ResetExpenseArchive :exec
+DELETE FROM expense_archive
+`
+
+func (q *Queries) ResetExpenseArchive(ctx context.Context) error {
+	_, err := q.db.ExecContext(ctx, resetExpenseArchive)
+	return err
+}
+
+const resetExpenseMappingArchive = `-- name: ResetExpenseMappingArchive :exec
+DELETE FROM expense_mapping_archive
+`
+
+func (q *Queries) ResetExpenseMappingArchive(ctx context.Context) error {
+	_, err := q.db.ExecContext(ctx, resetExpenseMappingArchive)
+	return err
+}
+
+const resetExpenseMappings = `-- name: ResetExpenseMappings :exec
+DELETE FROM expense_mapping
+`
+
+func (q *Queries) ResetExpenseMappings(ctx context.Context) error {
+	_, err := q.db.ExecContext(ctx, resetExpenseMappings)
+	return err
+}
+
+const resetExpenses = `-- name: ResetExpenses :exec
+DELETE FROM expense
+`
+
+func (q *Queries) ResetExpenses(ctx context.Context) error {
+	_, err := q.db.ExecContext(ctx, resetExpenses)
+	return err
+}
+
+const resetGroupArchive = `-- name: ResetGroupArchive :exec
+DELETE FROM group_archive
+`
+
+func (q *Queries) ResetGroupArchive(ctx context.Context) error {
+	_, err := q.db.ExecContext(ctx, resetGroupArchive)
+	return err
+}
+
+const resetGroupMembers = `-- name: ResetGroupMembers :exec
+DELETE FROM group_members
+`
+
+func (q *Queries) ResetGroupMembers(ctx context.Context) error {
+	_, err := q.db.ExecContext(ctx, resetGroupMembers)
+	return err
+}
+
+const resetGroupMembersArchive = `-- name: ResetGroupMembersArchive :exec
+DELETE FROM group_members_archive
+`
+
+func (q *Queries) ResetGroupMembersArchive(ctx context.Context) error {
+	_, err := q.db.ExecContext(ctx, resetGroupMembersArchive)
+	return err
+}
+
+const resetGroups = `-- name: ResetGroups :exec
+DELETE FROM "group"
+`
+
+func (q *Queries) ResetGroups(ctx context.Context) error {
+	_, err := q.db.ExecContext(ctx, resetGroups)
+	return err
+}
+
+const searchExpenses = `-- name: SearchExpenses :many
+WITH e AS (
+    SELECT id, description, amount, split, status, settled_by, created_by, payee, group_id, created_at, updated_at, version, category FROM expense
+    UNION ALL
+    SELECT id, description, amount, split, status, settled_by, created_by, payee, group_id, created_at, updated_at, version, category FROM expense_archive WHERE $15::boolean
+)
+SELECT id, description, amount, split, status, settled_by, created_by, payee, group_id, created_at, updated_at, version, category FROM e
+WHERE e.id IN (
+       SELECT expense_id FROM expense_mapping WHERE user_id = $1::uuid
+       UNION ALL
+       SELECT expense_id FROM expense_mapping_archive WHERE user_id = $1::uuid)
+  AND ($2::text IS NULL
+       OR to_tsvector('english', coalesce(e.description, '')) @@ websearch_to_tsquery('english', $2::text))
+  AND ($3::uuid IS NULL OR e.group_id = $3::uuid)
+  AND ($4::uuid IS NULL OR e.id IN (
+       SELECT expense_id FROM expense_mapping WHERE user_id = $4::uuid
+       UNION ALL
+       SELECT expense_id FROM expense_mapping_archive WHERE user_id = $4::uuid))
+  AND ($5::text IS NULL OR e.payee->'payerSplit'->>$5::text IS NOT NULL)
+  AND ($6::text IS NULL OR e.status = $6::text)
+  AND ($7::text IS NULL OR lower(e.category) = lower($7::text))
+  AND ($8::numeric IS NULL OR e.amount >= $8::numeric)
+  AND ($9::numeric IS NULL OR e.amount <= $9::numeric)
+  AND ($10::timestamptz IS NULL OR e.created_at >= $10::timestamptz)
+  AND ($11::timestamptz IS NULL OR e.created_at < $11::timestamptz)
+  AND ($12::timestamptz IS NULL
+       OR (e.created_at, e.id) < ($12::timestamptz, $13::uuid))
+ORDER BY e.created_at DESC, e.id DESC
+LIMIT $14
+`
+
+type SearchExpensesParams struct {
+	UserID          uuid.UUID
+	Query           sql.NullString
+	GroupID         uuid.NullUUID
+	ParticipantID   uuid.NullUUID
+	PayerID         sql.NullString
+	Status          sql.NullString
+	Category        sql.NullString
+	MinAmount       sql.NullString
+	MaxAmount       sql.NullString
+	CreatedFrom     sql.NullTime
+	CreatedBefore   sql.NullTime
+	CursorCreatedAt sql.NullTime
+	CursorID        uuid.NullUUID
+	PageLimit       int32
+	IncludeArchived bool
+}
+
+type SearchExpensesRow struct {
+	ID          uuid.UUID
+	Description sql.NullString
+	Amount      string
+	Split       json.RawMessage
+	Status      string
+	SettledBy   uuid.NullUUID
+	CreatedBy   uuid.UUID
+	Payee       json.RawMessage
+	GroupID     uuid.NullUUID
+	CreatedAt   time.Time
+	UpdatedAt   sql.NullTime
+	Version     int32
+	Category    string
+}
+
+// Every filter is optional, a null argument matches all rows. Archived
+// expenses are only searched when include_archived is set.
+func (q *Queries) SearchExpenses(ctx context.Context, arg SearchExpensesParams) ([]SearchExpensesRow, error) {
+	rows, err := q.db.QueryContext(ctx, searchExpenses,
+		arg.UserID,
+		arg.Query,
+		arg.GroupID,
+		arg.ParticipantID,
+		arg.PayerID,
+		arg.Status,
+		arg.Category,
+		arg.MinAmount,
+		arg.MaxAmount,
+		arg.CreatedFrom,
+		arg.CreatedBefore,
+		arg.CursorCreatedAt,
+		arg.CursorID,
+		arg.PageLimit,
+		arg.IncludeArchived,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SearchExpensesRow
+	for rows.Next() {
+		var i SearchExpensesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Description,
+			&i.Amount,
+			&i.Split,
+			&i.Status,
+			&i.SettledBy,
+			&i.CreatedBy,
+			&i.Payee,
+			&i.GroupID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Version,
+			&i.Category,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const setRowSecurityRole = `-- name: SetRowSecurityRole :exec
+SET LOCAL ROLE splitexpense_rls
+`
+
+// Only valid inside a transaction, the role is reset when it ends.
+func (q *Queries) SetRowSecurityRole(ctx context.Context) error {
+	_, err := q.db.ExecContext(ctx, setRowSecurityRole)
+	return err
+}
+
+const setRowSecurityUser = `-- name: SetRowSecurityUser :exec
+SELECT set_config('app.user_id', $1::text, true)
+`
+
+func (q *Queries) SetRowSecurityUser(ctx context.Context, userID string) error {
+	_, err := q.db.ExecContext(ctx, setRowSecurityUser, userID)
+	return err
+}
+
 const updateUser = `-- name: UpdateUser :one
 UPDATE "users"
 SET name = $2, email = $3, is_verified = $4, password = $5, updated_at = NOW() AT TIME ZONE 'Asia/Kolkata'
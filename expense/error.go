@@ -5,12 +5,20 @@ import "fmt"
 type AppError struct {
 	Type    string
 	Message string
+	// Fields says what is wrong with each request field of a ValidationError
+	Fields map[string]string
+	// cause is the storage or service error behind a ServiceError
+	cause error
 }
 
 func (e *AppError) Error() string {
 	return fmt.Sprintf("%s: %s", e.Type, e.Message)
 }
 
+func (e *AppError) Unwrap() error {
+	return e.cause
+}
+
 func ErrValidation(message string) *AppError {
 	return &AppError{Type: "ValidationError", Message: message}
 }
@@ -22,3 +30,52 @@ func ErrInvalidQuery(message string) *AppError {
 func ErrService(message string) *AppError {
 	return &AppError{Type: "ServiceError", Message: message}
 }
+
+// ErrServiceCause keeps err behind the ServiceError, so a missing row still
+// reads as sql.ErrNoRows
+func ErrServiceCause(err error) *AppError {
+	return &AppError{Type: "ServiceError", Message: err.Error(), cause: err}
+}
+
+// ErrFieldValidation is a ValidationError listing the offending request fields
+func ErrFieldValidation(message string, fields map[string]string) *AppError {
+	return &AppError{Type: "ValidationError", Message: message, Fields: fields}
+}
+
+func ErrNotFound(message string) *AppError {
+	return &AppError{Type: "NotFoundError", Message: message}
+}
+
+// ErrForbidden is returned when the user may not touch a group or expense, such
+// as a non member reading a group or a member editing it without being admin
+func ErrForbidden(message string) *AppError {
+	return &AppError{Type: "ForbiddenError", Message: message}
+}
+
+// ErrConflict is returned when the current state of an entity does not allow
+// the change, stale versions have VersionConflictError instead
+func ErrConflict(message string) *AppError {
+	return &AppError{Type: "ConflictError", Message: message}
+}
+
+// VersionConflictError is returned when a write carries a version that is no
+// longer current. CurrentVersion lets clients refetch and merge.
+type VersionConflictError struct {
+	Entity         string
+	ID             string
+	CurrentVersion int
+}
+
+func (e *VersionConflictError) Error() string {
+	return fmt.Sprintf("ConflictError: %s %s has been modified, current version is %d", e.Entity, e.ID, e.CurrentVersion)
+}
+
+// UnsettledGroupError is returned when the refuse policy keeps a group that
+// still has unsettled expenses. Report says what deleting would have affected.
+type UnsettledGroupError struct {
+	Report GroupDeletion
+}
+
+func (e *UnsettledGroupError) Error() string {
+	return fmt.Sprintf("ConflictError: group %s has %d unsettled expenses, settle them or delete with the archive policy", e.Report.GroupId, e.Report.UnsettledExpenses)
+}
@@ -0,0 +1,105 @@
+package expense
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"time"
+)
+
+type EventType string
+
+const (
+	EventExpenseCreated      EventType = "ExpenseCreated"
+	EventExpenseUpdated      EventType = "ExpenseUpdated"
+	EventSplitChanged        EventType = "SplitChanged"
+	EventExpenseSettled      EventType = "ExpenseSettled"
+	EventExpenseReopened     EventType = "ExpenseReopened"
+	EventExpenseDeleted      EventType = "ExpenseDeleted"
+	EventExpenseArchived     EventType = "ExpenseArchived"
+	EventParticipantsAdded   EventType = "ParticipantsAdded"
+	EventParticipantsRemoved EventType = "ParticipantsRemoved"
+
+	EventGroupCreated EventType = "GroupCreated"
+	EventGroupUpdated EventType = "GroupUpdated"
+	EventGroupDeleted EventType = "GroupDeleted"
+	EventMemberJoined EventType = "MemberJoined"
+	EventMemberLeft   EventType = "MemberLeft"
+
+	EventFriendAdded   EventType = "FriendAdded"
+	EventFriendRemoved EventType = "FriendRemoved"
+)
+
+const (
+	AggregateExpense = "expense"
+	AggregateGroup   = "group"
+	AggregateUser    = "user"
+)
+
+// Event is an immutable domain event. Expense and group events carry the full
+// Expense or Group after the change, participant, member and friend events
+// carry ParticipantsPayload, MembershipPayload and FriendshipPayload.
+type Event struct {
+	// Sequence is the position in the store, assigned when the event is appended
+	Sequence      int64     `json:"sequence"`
+	ID            string    `json:"id"`
+	Type          EventType `json:"type"`
+	AggregateType string    `json:"aggregateType"`
+	AggregateID   string    `json:"aggregateId"`
+	// AggregateVersion is the expense or group version after the event
+	AggregateVersion int             `json:"aggregateVersion"`
+	Payload          json.RawMessage `json:"payload"`
+	// ActorID is the user who made the change, empty for system changes
+	ActorID    string    `json:"actorId"`
+	OccurredAt time.Time `json:"occurredAt"`
+}
+
+type ParticipantsPayload struct {
+	ExpenseId string   `json:"expenseId"`
+	UserIds   []string `json:"userIds"`
+}
+
+type MembershipPayload struct {
+	GroupId string `json:"groupId"`
+	UserId  string `json:"userId"`
+}
+
+type FriendshipPayload struct {
+	UserId   string `json:"userId"`
+	FriendId string `json:"friendId"`
+}
+
+// ExpenseChange names an update by its most significant change
+func ExpenseChange(current Expense, updated Expense) EventType {
+	switch {
+	case current.Status != updated.Status && updated.Status == ExpenseSettled:
+		return EventExpenseSettled
+	case current.Status != updated.Status:
+		return EventExpenseReopened
+	case current.Amount != updated.Amount ||
+		!reflect.DeepEqual(current.PayeeW.Payer.GetPayers(), updated.PayeeW.Payer.GetPayers()) ||
+		!reflect.DeepEqual(current.SplitW.Split.GetPayeeSplit(), updated.SplitW.Split.GetPayeeSplit()):
+		return EventSplitChanged
+	default:
+		return EventExpenseUpdated
+	}
+}
+
+type EventStore interface {
+	AppendEvent(ctx context.Context, event Event) (*Event, error)
+	// LoadEvents returns up to limit events after afterSequence, oldest first
+	LoadEvents(ctx context.Context, afterSequence int64, limit int) ([]Event, error)
+	LoadAggregateEvents(ctx context.Context, aggregateType string, aggregateId string) ([]Event, error)
+}
+
+type actorKey struct{}
+
+// WithActor records the authenticated user on ctx so changes can be attributed to them
+func WithActor(ctx context.Context, userId string) context.Context {
+	return context.WithValue(ctx, actorKey{}, userId)
+}
+
+func ActorFrom(ctx context.Context) string {
+	userId, _ := ctx.Value(actorKey{}).(string)
+	return userId
+}
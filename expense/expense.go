@@ -132,16 +132,20 @@ type ExpenseHistory struct {
 
 type ExpenseCreate struct {
 	Description    string
+	Category       string
 	Amount         float64
 	SplitW         SplitWrapper
 	PayeeW         PayerWrapper
 	IsGroupExpense bool
 	GroupId        string
+	// CreatedAt dates the expense, zero is now. Imports keep the original date.
+	CreatedAt time.Time
 }
 
 type Expense struct {
 	ID             string        `json:"id"`
 	Description    string        `json:"description"`
+	Category       string        `json:"category"`
 	Amount         float64       `json:"amount"`
 	CreatedAt      time.Time     `json:"createdAt"`
 	PayeeW         PayerWrapper  `json:"payeeW"`
@@ -151,6 +155,7 @@ type Expense struct {
 	GroupId        string        `json:"groupId"`
 	SettledBy      string        `json:"settledBy"`
 	CreatedBy      string        `json:"createdBy"`
+	Version        int           `json:"version"`
 }
 
 // type Expense struct {
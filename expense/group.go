@@ -1,5 +1,7 @@
 package expense
 
+import "time"
+
 type ExpenseSummary struct {
 	// list of users, for each user -> list of all users he has borrowed the amount from
 	BorrowedFrom map[int](map[int]float64)
@@ -13,26 +15,58 @@ type DetailedExpense struct {
 	TotalBorrowed float64 `json:"totalBorrowed"`
 }
 
+// StoredGroupExpenseHistory is one page of expenses. Cursor pages fill
+// NextCursor and HasMore, page-number pages fill PageNumber and TotalPages.
 type StoredGroupExpenseHistory struct {
 	Expenses   []Expense `json:"expenses"`
 	PageNumber int       `json:"pageNumber"`
 	TotalPages int       `json:"totalPages"`
+	NextCursor string    `json:"nextCursor"`
+	HasMore    bool      `json:"hasMore"`
 }
 
 type GroupExpenseHistory struct {
 	Expenses   []DetailedExpense `json:"expenses"`
 	PageNumber int               `json:"pageNumber"`
 	TotalPages int               `json:"totalPages"`
+	NextCursor string            `json:"nextCursor"`
+	HasMore    bool              `json:"hasMore"`
 }
 
 type Group struct {
-	Id          string `json:"id"`
-	Name        string `json:"name"`
-	Description string `json:"description"`
-	Admin       string `json:"admin"`
+	Id          string    `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Admin       string    `json:"admin"`
+	Version     int       `json:"version"`
+	CreatedAt   time.Time `json:"createdAt"`
 }
 
 func (g *Group) getExpenseSummary() ExpenseSummary {
 	// TODO:
 	return ExpenseSummary{}
 }
+
+// GroupDeletePolicy decides what deleting a group does with its expenses
+type GroupDeletePolicy string
+
+const (
+	// GroupDeleteRefuse keeps the group while it has unsettled expenses
+	GroupDeleteRefuse GroupDeletePolicy = "refuse"
+	// GroupDeleteArchive moves the group, its members and every expense to the archive tables
+	GroupDeleteArchive GroupDeletePolicy = "archive"
+)
+
+func (p GroupDeletePolicy) Valid() bool {
+	return p == GroupDeleteRefuse || p == GroupDeleteArchive
+}
+
+// GroupDeletion reports what deleting a group affected
+type GroupDeletion struct {
+	GroupId           string            `json:"groupId"`
+	Policy            GroupDeletePolicy `json:"policy"`
+	Deleted           bool              `json:"deleted"`
+	Members           int               `json:"members"`
+	ArchivedExpenses  int               `json:"archivedExpenses"`
+	UnsettledExpenses int               `json:"unsettledExpenses"`
+}
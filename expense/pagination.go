@@ -0,0 +1,66 @@
+package expense
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"time"
+)
+
+const DefaultPageLimit = 20
+const MaxPageLimit = 100
+
+// PageRequest asks for the page after Cursor, an empty cursor is the first page.
+type PageRequest struct {
+	Cursor string
+	Limit  int
+}
+
+func (p PageRequest) Size() int {
+	if p.Limit <= 0 {
+		return DefaultPageLimit
+	}
+	if p.Limit > MaxPageLimit {
+		return MaxPageLimit
+	}
+	return p.Limit
+}
+
+// Cursor is the (created_at, id) keyset of the last row of a page. Clients
+// only ever see it as the opaque token produced by Encode.
+type Cursor struct {
+	CreatedAt time.Time `json:"t"`
+	ID        string    `json:"id"`
+}
+
+func (c Cursor) Encode() string {
+	raw, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// DecodeCursor parses a token from Cursor.Encode, it returns nil for an empty token.
+func DecodeCursor(token string) (*Cursor, error) {
+	if token == "" {
+		return nil, nil
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, ErrInvalidQuery("invalid cursor")
+	}
+	var c Cursor
+	if err := json.Unmarshal(raw, &c); err != nil || c.ID == "" || c.CreatedAt.IsZero() {
+		return nil, ErrInvalidQuery("invalid cursor")
+	}
+	return &c, nil
+}
+
+type UserPage struct {
+	Users      []User `json:"users"`
+	NextCursor string `json:"nextCursor"`
+	HasMore    bool   `json:"hasMore"`
+}
+
+type GroupPage struct {
+	Groups     []Group `json:"groups"`
+	NextCursor string  `json:"nextCursor"`
+	HasMore    bool    `json:"hasMore"`
+}
@@ -0,0 +1,38 @@
+package expense
+
+import "time"
+
+// ExpenseSearch filters expenses visible to a user. Zero values leave a
+// filter out, so an empty search lists every expense of the user.
+type ExpenseSearch struct {
+	// Query is free text matched against descriptions. Postgres uses full
+	// text search, simpler backends may match it as a case-insensitive substring.
+	Query         string
+	GroupId       string
+	ParticipantId string
+	PayerId       string
+	Status        ExpenseStatus
+	Category      string
+	MinAmount     *float64
+	MaxAmount     *float64
+	// CreatedFrom is inclusive, CreatedBefore is exclusive
+	CreatedFrom   *time.Time
+	CreatedBefore *time.Time
+	// IncludeArchived also searches expenses moved out by the archival job
+	IncludeArchived bool
+}
+
+func (s ExpenseSearch) Validate() error {
+	if s.MinAmount != nil && s.MaxAmount != nil && *s.MinAmount > *s.MaxAmount {
+		return ErrInvalidQuery("minAmount should not be more than maxAmount")
+	}
+	if s.CreatedFrom != nil && s.CreatedBefore != nil && !s.CreatedFrom.Before(*s.CreatedBefore) {
+		return ErrInvalidQuery("from should be before to")
+	}
+	switch s.Status {
+	case "", ExpenseDraft, ExpenseSettled, ExpenseReopened:
+	default:
+		return ErrInvalidQuery("unknown status " + string(s.Status))
+	}
+	return nil
+}
@@ -1,5 +1,10 @@
 package expense
 
+import (
+	"context"
+	"time"
+)
+
 type UserHome struct {
 	ExpenseWithUsers []Expense
 	AssociatedGroups []Group
@@ -13,34 +18,62 @@ type UserCreate struct {
 }
 
 type Storage interface {
-	FetchUserByEmail(email string) (*User, error)
-	CreateUser(User) (*User, error)
-	UpdateUser(User) (*User, error)
-	FetchGroupsByUser(userId string) ([]Group, error)
-	FetchUserById(id string) (*User, error)
-
-	AddFriend(userId string, friendId string) (bool, error)
-	GetFriend(userId string, friendId string) (*User, error)
-	GetFriends(userId string) ([]User, error)
-	RemoveFriend(userId string, friendId string) (bool, error)
-
-	DeleteGroup(groupId string) (bool, error)
-
-	FetchGroupMembers(groupId string) ([]User, error)
-	FetchGroupById(id string) (*Group, error)
-	FetchGroupExpenses(groupId string, pageNumber int) (*StoredGroupExpenseHistory, error)
-	CreateOrUpdateGroup(group Group) (*Group, error)
-	AddUserInGroup(userId string, groupId string) (bool, error)
-	RemoveUserFromGroup(userId string, groupId string) (bool, error)
-
-	AddExpenseMapping(expenseId string, userId string) (bool, error)
-	FetchExpenseCountByGroup(groupId string) (int, error)
-	CreateOrUpdateExpense(expense Expense) (*Expense, error)
-	FetchExpense(id string) (*Expense, error)
-	CheckUserExistsInGroup(userId string, groupId string) (bool, error)
-	RemoveUsersFromExpense(expenseId string, usersToRemove []string) (bool, error)
-	DeleteExpense(id string) (bool, error)
-
-	FetchExpenseByUserAndStatus(userId string, status ExpenseStatus, pageNumber int, limit int32) (*StoredGroupExpenseHistory, error)
-	FetchGroupExpensesByStatus(groupId string, status ExpenseStatus, pageNumber int) (*StoredGroupExpenseHistory, error)
+	// RunInTx runs fn in one transaction. Storage calls made with the ctx passed
+	// to fn join it, an error from fn rolls everything back.
+	RunInTx(ctx context.Context, fn func(ctx context.Context) error) error
+
+	FetchUserByEmail(ctx context.Context, email string) (*User, error)
+	CreateUser(ctx context.Context, user User) (*User, error)
+	UpdateUser(ctx context.Context, user User) (*User, error)
+	FetchGroupsByUser(ctx context.Context, userId string) ([]Group, error)
+	FetchUserById(ctx context.Context, id string) (*User, error)
+
+	AddFriend(ctx context.Context, userId string, friendId string) (bool, error)
+	GetFriend(ctx context.Context, userId string, friendId string) (*User, error)
+	GetFriends(ctx context.Context, userId string) ([]User, error)
+	RemoveFriend(ctx context.Context, userId string, friendId string) (bool, error)
+
+	// DeleteGroup archives the group with its members and expenses, an
+	// unknown group returns false.
+	DeleteGroup(ctx context.Context, groupId string) (bool, error)
+
+	FetchGroupMembers(ctx context.Context, groupId string) ([]User, error)
+	FetchGroupById(ctx context.Context, id string) (*Group, error)
+	FetchGroupExpenses(ctx context.Context, groupId string, pageNumber int) (*StoredGroupExpenseHistory, error)
+	CreateOrUpdateGroup(ctx context.Context, group Group) (*Group, error)
+	AddUserInGroup(ctx context.Context, userId string, groupId string) (bool, error)
+	RemoveUserFromGroup(ctx context.Context, userId string, groupId string) (bool, error)
+
+	AddExpenseMapping(ctx context.Context, expenseId string, userId string) (bool, error)
+	// AddExpenseMappings maps the users to each expense by id in one write, existing mappings are kept
+	AddExpenseMappings(ctx context.Context, mappings map[string][]string) error
+	FetchExpenseCountByGroup(ctx context.Context, groupId string) (int, error)
+	// LockGroup holds the group row until the surrounding transaction ends, so
+	// nobody joins or adds an expense meanwhile. An unknown group returns false.
+	LockGroup(ctx context.Context, groupId string) (bool, error)
+	// CountUnsettledGroupExpenses counts the group's drafts that still carry an amount
+	CountUnsettledGroupExpenses(ctx context.Context, groupId string) (int, error)
+	CreateOrUpdateExpense(ctx context.Context, expense Expense) (*Expense, error)
+	// CreateExpenses writes new expenses in one transaction and returns them in the same order
+	CreateExpenses(ctx context.Context, expenses []Expense) ([]Expense, error)
+	FetchExpense(ctx context.Context, id string) (*Expense, error)
+	CheckUserExistsInGroup(ctx context.Context, userId string, groupId string) (bool, error)
+	RemoveUsersFromExpense(ctx context.Context, expenseId string, usersToRemove []string) (bool, error)
+	DeleteExpense(ctx context.Context, id string) (bool, error)
+
+	FetchExpenseByUserAndStatus(ctx context.Context, userId string, status ExpenseStatus, pageNumber int, limit int32) (*StoredGroupExpenseHistory, error)
+	FetchGroupExpensesByStatus(ctx context.Context, groupId string, status ExpenseStatus, pageNumber int) (*StoredGroupExpenseHistory, error)
+
+	// keyset pagination, newest first. An empty status matches every status.
+	FetchGroupExpensesPage(ctx context.Context, groupId string, status ExpenseStatus, includeArchived bool, page PageRequest) (*StoredGroupExpenseHistory, error)
+	FetchUserExpensesPage(ctx context.Context, userId string, status ExpenseStatus, page PageRequest) (*StoredGroupExpenseHistory, error)
+	GetFriendsPage(ctx context.Context, userId string, page PageRequest) (*UserPage, error)
+	FetchGroupsByUserPage(ctx context.Context, userId string, page PageRequest) (*GroupPage, error)
+
+	// SearchExpenses pages through the expenses userId takes part in that match search, newest first.
+	SearchExpenses(ctx context.Context, userId string, search ExpenseSearch, page PageRequest) (*StoredGroupExpenseHistory, error)
+
+	// ArchiveSettledExpenses moves up to limit expenses settled before settledBefore, with their
+	// mappings, out of the live tables and returns how many were moved.
+	ArchiveSettledExpenses(ctx context.Context, settledBefore time.Time, limit int) (int, error)
 }
@@ -0,0 +1,30 @@
+package expense
+
+import (
+	"regexp"
+)
+
+// the rules are shared by request validation and the orchestrator, patterns
+// are compiled once
+var (
+	emailPattern   = regexp.MustCompile(`^[a-zA-Z0-9._%+-]+@[a-zA-Z0-9.-]+\.[a-zA-Z]{2,}$`)
+	lowerPattern   = regexp.MustCompile(`[a-z]`)
+	upperPattern   = regexp.MustCompile(`[A-Z]`)
+	numberPattern  = regexp.MustCompile(`[0-9]`)
+	specialPattern = regexp.MustCompile(`[!@#\$%\^&\*\(\)_\+\-=\[\]{};':"\\|,.<>\/?]`)
+)
+
+// PasswordPolicy describes what ValidPassword accepts
+const PasswordPolicy = "password should be longer than 8 characters and contain atleast one uppercase, one lowercase, one special character and one digit"
+
+func ValidEmail(email string) bool {
+	return emailPattern.MatchString(email)
+}
+
+func ValidPassword(password string) bool {
+	return len(password) > 8 &&
+		lowerPattern.MatchString(password) &&
+		upperPattern.MatchString(password) &&
+		numberPattern.MatchString(password) &&
+		specialPattern.MatchString(password)
+}
@@ -0,0 +1,117 @@
+// Package export writes expenses in formats spreadsheets and accounting
+// tools read. Writers take expenses one at a time so exports stream.
+package export
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"splitExpense/expense"
+)
+
+// Participant names the columns of one person taking part in the exported expenses
+type Participant struct {
+	ID   string
+	Name string
+}
+
+// CSV writes one row per expense: date, description, category, amount,
+// payers and status, then a share and a net column for every participant.
+// Net is what the participant paid minus their share, positive when they are owed.
+type CSV struct {
+	w            *csv.Writer
+	participants []Participant
+	names        map[string]string
+}
+
+var csvColumns = []string{"date", "description", "category", "amount", "payers", "status"}
+
+// NewCSV writes the header row, participants keep the order given
+func NewCSV(w io.Writer, participants []Participant) (*CSV, error) {
+	c := &CSV{w: csv.NewWriter(w), participants: participants, names: map[string]string{}}
+	header := append([]string{}, csvColumns...)
+	for _, p := range participants {
+		c.names[p.ID] = p.Name
+		header = append(header, text(p.Name+" share"), text(p.Name+" net"))
+	}
+	if err := c.w.Write(header); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Write adds the row of exp, whose participants must all have been given to NewCSV
+func (c *CSV) Write(exp expense.Expense) error {
+	paid := exp.PayeeW.Payer.GetPayers()
+	shares := exp.SplitW.Split.GetPayeeSplit()
+	for _, amounts := range []map[string]float64{paid, shares} {
+		for id := range amounts {
+			if _, ok := c.names[id]; !ok {
+				return fmt.Errorf("expense %s: participant %s has no column", exp.ID, id)
+			}
+		}
+	}
+
+	row := []string{
+		exp.CreatedAt.UTC().Format(time.DateOnly),
+		text(exp.Description),
+		text(exp.Category),
+		amount(exp.Amount),
+		text(c.payers(paid)),
+		string(exp.Status),
+	}
+	for _, p := range c.participants {
+		row = append(row, amount(shares[p.ID]), amount(paid[p.ID]-shares[p.ID]))
+	}
+	return c.w.Write(row)
+}
+
+// Flush writes out buffered rows, it must be called once the last expense is written
+func (c *CSV) Flush() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+// payers lists who paid as "name: amount" pairs separated by semicolons
+func (c *CSV) payers(paid map[string]float64) string {
+	ids := make([]string, 0, len(paid))
+	for id := range paid {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	parts := make([]string, 0, len(ids))
+	for _, id := range ids {
+		name, ok := c.names[id]
+		if !ok {
+			name = id
+		}
+		parts = append(parts, fmt.Sprintf("%s: %s", name, amount(paid[id])))
+	}
+	return strings.Join(parts, "; ")
+}
+
+// text keeps a spreadsheet from reading a user written cell as a formula,
+// cells starting with = + - @ tab or carriage return get a leading quote.
+// Amounts are formatted here and stay numbers.
+func text(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+// amount rounds to cents, a net of -0.001 prints as 0.00 rather than -0.00
+func amount(value float64) string {
+	value = math.Round(value*100) / 100
+	if value == 0 {
+		value = 0
+	}
+	return strconv.FormatFloat(value, 'f', 2, 64)
+}
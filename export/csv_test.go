@@ -0,0 +1,76 @@
+package export
+
+import (
+	"encoding/csv"
+	"strings"
+	"testing"
+	"time"
+
+	"splitExpense/expense"
+)
+
+func testExpense(id string, description string, category string, payer string, amount float64, participants ...string) expense.Expense {
+	return expense.Expense{
+		ID:          id,
+		Description: description,
+		Category:    category,
+		Amount:      amount,
+		CreatedAt:   time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC),
+		PayeeW:      expense.PayerWrapper{Type: "single", Payer: &expense.SinglePayer{Payer: payer, Amount: amount}},
+		SplitW:      expense.SplitWrapper{Type: "equal", Split: &expense.EqualSplit{Payee: participants, TotalAmount: amount}},
+		Status:      expense.ExpenseDraft,
+		CreatedBy:   payer,
+	}
+}
+
+func TestCSVEscapesFormulas(t *testing.T) {
+	var out strings.Builder
+	participants := []Participant{{ID: "u1", Name: "=HYPERLINK(\"x\")"}, {ID: "u2", Name: "Bob"}}
+	w, err := NewCSV(&out, participants)
+	if err != nil {
+		t.Fatalf("NewCSV: %v", err)
+	}
+	if err := w.Write(testExpense("e1", "=1+1", "@SUM(A1)", "u1", 10, "u1", "u2")); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if err := w.Write(testExpense("e2", "-2", "\tfood", "u2", 10, "u1", "u2")); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+
+	rows, err := csv.NewReader(strings.NewReader(out.String())).ReadAll()
+	if err != nil {
+		t.Fatalf("read back: %v", err)
+	}
+	tests := []struct {
+		row, column int
+		want        string
+	}{
+		{0, 6, `'=HYPERLINK("x") share`},
+		{1, 1, "'=1+1"},
+		{1, 2, "'@SUM(A1)"},
+		{1, 4, `'=HYPERLINK("x"): 10.00`},
+		{2, 1, "'-2"},
+		{2, 2, "'\tfood"},
+		{2, 4, "Bob: 10.00"},
+		// amounts are ours and stay numbers
+		{2, 7, "-5.00"},
+	}
+	for _, tt := range tests {
+		if got := rows[tt.row][tt.column]; got != tt.want {
+			t.Errorf("row %d column %d: got %q, want %q", tt.row, tt.column, got, tt.want)
+		}
+	}
+}
+
+func TestCSVRejectsParticipantsWithoutColumn(t *testing.T) {
+	w, err := NewCSV(&strings.Builder{}, []Participant{{ID: "u1", Name: "Alice"}})
+	if err != nil {
+		t.Fatalf("NewCSV: %v", err)
+	}
+	if err := w.Write(testExpense("e1", "lunch", "food", "u1", 10, "u1", "u2")); err == nil {
+		t.Fatalf("Write: got no error for a participant missing from the header")
+	}
+}
@@ -0,0 +1,305 @@
+package export
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"splitExpense/expense"
+)
+
+// Syntax is the plain text accounting format of a Journal
+type Syntax string
+
+const (
+	SyntaxLedger    Syntax = "ledger"
+	SyntaxBeancount Syntax = "beancount"
+)
+
+type JournalOptions struct {
+	Syntax Syntax
+	// Currency every amount is in, USD when empty
+	Currency string
+	// FundingAccount is where the user's payments come from, Assets:Cash when
+	// empty. Its components are cleaned up like names and it goes under
+	// Assets unless it starts with a root account.
+	FundingAccount string
+}
+
+// rootAccounts are the top level accounts beancount allows
+var rootAccounts = []string{"Assets", "Liabilities", "Equity", "Income", "Expenses"}
+
+var currencyPattern = regexp.MustCompile(`^[A-Z][A-Z0-9'._-]{0,22}[A-Z0-9]$`)
+
+// ValidCurrency tells whether both syntaxes accept currency as a commodity, such as USD or EUR
+func ValidCurrency(currency string) bool {
+	return currencyPattern.MatchString(strings.ToUpper(currency))
+}
+
+// Journal writes one user's expenses as balanced double-entry transactions.
+// The user's share goes to Expenses:<category>, what they lent to a friend to
+// Assets:Receivables:<friend> and what they borrowed to Liabilities:Payables:<friend>,
+// the part they paid comes out of the funding account. A settled expense
+// adds a settle up transaction clearing the receivables and payables, dated
+// like the expense since settling is not timestamped. Transactions carry the
+// expense id, and the id with a -settle suffix, so re-exports can be de-duplicated.
+// Transactions are written in the order given, both tools sort them by date.
+type Journal struct {
+	w      *bufio.Writer
+	userId string
+	opts   JournalOptions
+	// accounts names the receivable and payable accounts of each participant
+	accounts map[string]string
+	// categories are those whose expense account is opened
+	categories map[string]bool
+}
+
+// NewJournal declares the accounts used by the export, opened on opened,
+// categories and participants are those of the expenses to come
+func NewJournal(w io.Writer, userId string, participants []Participant, categories []string, opened time.Time, opts JournalOptions) (*Journal, error) {
+	if opts.Syntax != SyntaxLedger && opts.Syntax != SyntaxBeancount {
+		return nil, fmt.Errorf("unknown syntax %q, expected %s or %s", opts.Syntax, SyntaxLedger, SyntaxBeancount)
+	}
+	if opts.Currency == "" {
+		opts.Currency = "USD"
+	}
+	if !ValidCurrency(opts.Currency) {
+		return nil, fmt.Errorf("invalid currency %q", opts.Currency)
+	}
+	opts.Currency = strings.ToUpper(opts.Currency)
+	opts.FundingAccount = accountPath(opts.FundingAccount)
+	j := &Journal{w: bufio.NewWriter(w), userId: userId, opts: opts, accounts: map[string]string{}, categories: map[string]bool{}}
+
+	// people sharing a name are told apart by the start of their id
+	byName := map[string]int{}
+	for _, p := range participants {
+		byName[accountName(p.Name)]++
+	}
+	for _, p := range participants {
+		name := accountName(p.Name)
+		if byName[name] > 1 {
+			name += "-" + accountName(p.ID[:min(8, len(p.ID))])
+		}
+		j.accounts[p.ID] = name
+	}
+
+	accounts := []string{opts.FundingAccount}
+	for _, category := range categories {
+		j.categories[category] = true
+		accounts = append(accounts, expenseAccount(category))
+	}
+	for _, p := range participants {
+		if p.ID != userId {
+			accounts = append(accounts, "Assets:Receivables:"+j.accounts[p.ID], "Liabilities:Payables:"+j.accounts[p.ID])
+		}
+	}
+	sort.Strings(accounts)
+
+	if opts.Syntax == SyntaxBeancount {
+		fmt.Fprintf(j.w, "option \"operating_currency\" \"%s\"\n\n", opts.Currency)
+	}
+	seen := map[string]bool{}
+	for _, account := range accounts {
+		if seen[account] {
+			continue
+		}
+		seen[account] = true
+		if opts.Syntax == SyntaxBeancount {
+			fmt.Fprintf(j.w, "%s open %s %s\n", opened.UTC().Format(time.DateOnly), account, opts.Currency)
+		} else {
+			fmt.Fprintf(j.w, "account %s\n", account)
+		}
+	}
+	return j, nil
+}
+
+type posting struct {
+	account string
+	cents   int64
+}
+
+// Write adds the transactions of exp, whose participants and category must
+// have been given to NewJournal so its postings go to opened accounts
+func (j *Journal) Write(exp expense.Expense) error {
+	paid := toCents(exp.PayeeW.Payer.GetPayers())
+	shares := toCents(exp.SplitW.Split.GetPayeeSplit())
+	var total int64
+	for _, amount := range paid {
+		total += amount
+	}
+	myPaid, myShare := paid[j.userId], shares[j.userId]
+	if total == 0 || (myPaid == 0 && myShare == 0) {
+		return nil
+	}
+	for _, amounts := range []map[string]int64{paid, shares} {
+		for id := range amounts {
+			if _, ok := j.accounts[id]; !ok && id != j.userId {
+				return fmt.Errorf("expense %s: participant %s has no account", exp.ID, id)
+			}
+		}
+	}
+
+	// what each other participant owes the user on this expense, negative
+	// when the user owes them: their share of what the user paid, less the
+	// user's share of what they paid
+	others := []string{}
+	for _, amounts := range []map[string]int64{paid, shares} {
+		for id := range amounts {
+			if id != j.userId && !slices.Contains(others, id) {
+				others = append(others, id)
+			}
+		}
+	}
+	sort.Strings(others)
+	lent := map[string]int64{}
+	var sum int64
+	for _, id := range others {
+		lent[id] = int64(math.Round(float64(shares[id]*myPaid-myShare*paid[id]) / float64(total)))
+		sum += lent[id]
+	}
+	// the rounding difference goes to the biggest debt so the transaction balances
+	if len(others) > 0 {
+		sort.SliceStable(others, func(a, b int) bool { return abs(lent[others[a]]) > abs(lent[others[b]]) })
+		lent[others[0]] += myPaid - myShare - sum
+	}
+
+	postings := []posting{}
+	if myShare != 0 && !j.categories[exp.Category] {
+		return fmt.Errorf("expense %s: category %q has no account", exp.ID, exp.Category)
+	}
+	if myShare != 0 {
+		postings = append(postings, posting{expenseAccount(exp.Category), myShare})
+	}
+	settle := []posting{}
+	var settled int64
+	for _, id := range others {
+		if lent[id] == 0 {
+			continue
+		}
+		account := j.counterparty(id, lent[id])
+		postings = append(postings, posting{account, lent[id]})
+		settle = append(settle, posting{account, -lent[id]})
+		settled += lent[id]
+	}
+	if myPaid != 0 {
+		postings = append(postings, posting{j.opts.FundingAccount, -myPaid})
+	}
+	j.transaction(exp.CreatedAt, exp.ID, exp.Description, postings)
+
+	if exp.Status == expense.ExpenseSettled && len(settle) > 0 {
+		settle = append(settle, posting{j.opts.FundingAccount, settled})
+		j.transaction(exp.CreatedAt, exp.ID+"-settle", "Settle up: "+exp.Description, settle)
+	}
+	return nil
+}
+
+// Flush writes out buffered transactions, it must be called once the last expense is written
+func (j *Journal) Flush() error {
+	return j.w.Flush()
+}
+
+func (j *Journal) counterparty(userId string, lent int64) string {
+	name := j.accounts[userId]
+	if lent > 0 {
+		return "Assets:Receivables:" + name
+	}
+	return "Liabilities:Payables:" + name
+}
+
+func (j *Journal) transaction(date time.Time, id string, narration string, postings []posting) {
+	day := date.UTC().Format(time.DateOnly)
+	if narration == "" {
+		narration = "Expense"
+	}
+	fmt.Fprintln(j.w)
+	if j.opts.Syntax == SyntaxBeancount {
+		fmt.Fprintf(j.w, "%s * %s\n", day, quote(narration))
+		fmt.Fprintf(j.w, "  id: %s\n", quote(id))
+	} else {
+		fmt.Fprintf(j.w, "%s * (%s) %s\n", day, id, strings.ReplaceAll(narration, "\n", " "))
+	}
+	for _, p := range postings {
+		fmt.Fprintf(j.w, "  %-48s  %s %s\n", p.account, centsAmount(p.cents), j.opts.Currency)
+	}
+}
+
+func quote(value string) string {
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	value = strings.ReplaceAll(value, `"`, `\"`)
+	return `"` + strings.ReplaceAll(value, "\n", " ") + `"`
+}
+
+// accountName turns a name into an account component both syntaxes accept:
+// words capitalized and joined by dashes, starting with a letter
+func accountName(name string) string {
+	words := strings.FieldsFunc(name, func(r rune) bool {
+		return r > unicode.MaxASCII || !(unicode.IsLetter(r) || unicode.IsDigit(r))
+	})
+	for i, word := range words {
+		words[i] = strings.ToUpper(word[:1]) + word[1:]
+	}
+	component := strings.Join(words, "-")
+	if component == "" || !unicode.IsLetter(rune(component[0])) {
+		component = "X" + component
+	}
+	return component
+}
+
+// accountPath cleans up every component of an account given by the user,
+// Assets:Cash when it has none
+func accountPath(account string) string {
+	components := []string{}
+	for _, component := range strings.Split(account, ":") {
+		if strings.TrimSpace(component) != "" {
+			components = append(components, accountName(component))
+		}
+	}
+	if len(components) == 0 {
+		return "Assets:Cash"
+	}
+	if !slices.Contains(rootAccounts, components[0]) {
+		components = append([]string{"Assets"}, components...)
+	}
+	// beancount wants at least one component under the root
+	if len(components) == 1 {
+		components = append(components, "Cash")
+	}
+	return strings.Join(components, ":")
+}
+
+func expenseAccount(category string) string {
+	if strings.TrimSpace(category) == "" {
+		return "Expenses:Uncategorized"
+	}
+	return "Expenses:" + accountName(category)
+}
+
+func toCents(amounts map[string]float64) map[string]int64 {
+	values := map[string]int64{}
+	for id, amount := range amounts {
+		values[id] = int64(math.Round(amount * 100))
+	}
+	return values
+}
+
+func centsAmount(value int64) string {
+	sign := ""
+	if value < 0 {
+		sign, value = "-", -value
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, value/100, value%100)
+}
+
+func abs(value int64) int64 {
+	if value < 0 {
+		return -value
+	}
+	return value
+}
@@ -0,0 +1,113 @@
+package export
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"splitExpense/expense"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files of the journal tests")
+
+func TestJournalGolden(t *testing.T) {
+	participants := []Participant{
+		{ID: "u-me", Name: "Me"},
+		{ID: "u-mary", Name: "Mary Ann"},
+		{ID: "u-who", Name: "Dr: Who"},
+		{ID: "u-bob1", Name: "bob"},
+		{ID: "u-bob2", Name: "Bob"},
+	}
+	dinner := testExpense("e-dinner", "Dinner \"at\" Luigi's", "Food: Dining out", "u-me", 40, "u-me", "u-mary", "u-who", "u-bob1")
+	taxi := testExpense("e-taxi", "Taxi\nhome", "", "u-mary", 10, "u-me", "u-mary", "u-bob2")
+	taxi.CreatedAt = taxi.CreatedAt.Add(24 * time.Hour)
+	rent := testExpense("e-rent", "Rent", "12 rent", "u-who", 100, "u-me", "u-who")
+	rent.Status = expense.ExpenseSettled
+	rent.CreatedAt = rent.CreatedAt.Add(48 * time.Hour)
+	exps := []expense.Expense{dinner, taxi, rent}
+	categories := []string{"Food: Dining out", "", "12 rent"}
+	opened := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	for _, syntax := range []Syntax{SyntaxLedger, SyntaxBeancount} {
+		t.Run(string(syntax), func(t *testing.T) {
+			var out strings.Builder
+			opts := JournalOptions{Syntax: syntax, Currency: "eur", FundingAccount: "bank account: Main checking"}
+			j, err := NewJournal(&out, "u-me", participants, categories, opened, opts)
+			if err != nil {
+				t.Fatalf("NewJournal: %v", err)
+			}
+			for _, exp := range exps {
+				if err := j.Write(exp); err != nil {
+					t.Fatalf("Write: %v", err)
+				}
+			}
+			if err := j.Flush(); err != nil {
+				t.Fatalf("Flush: %v", err)
+			}
+
+			golden := filepath.Join("testdata", "journal."+string(syntax))
+			if *update {
+				if err := os.WriteFile(golden, []byte(out.String()), 0o644); err != nil {
+					t.Fatalf("update %s: %v", golden, err)
+				}
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("read %s: %v", golden, err)
+			}
+			if out.String() != string(want) {
+				t.Fatalf("journal differs from %s, run go test -update after checking it:\n%s", golden, out.String())
+			}
+		})
+	}
+}
+
+func TestJournalOptions(t *testing.T) {
+	tests := []struct {
+		account string
+		want    string
+	}{
+		{"", "Assets:Cash"},
+		{"Liabilities:Credit Card", "Liabilities:Credit-Card"},
+		{"my wallet", "Assets:My-Wallet"},
+		{"assets::savings: joint", "Assets:Savings:Joint"},
+		{"::", "Assets:Cash"},
+		{"Liabilities", "Liabilities:Cash"},
+	}
+	for _, tt := range tests {
+		var out strings.Builder
+		j, err := NewJournal(&out, "u-me", nil, nil, time.Now(), JournalOptions{Syntax: SyntaxLedger, FundingAccount: tt.account})
+		if err != nil {
+			t.Fatalf("NewJournal(%q): %v", tt.account, err)
+		}
+		if j.opts.FundingAccount != tt.want {
+			t.Errorf("funding account %q: got %q, want %q", tt.account, j.opts.FundingAccount, tt.want)
+		}
+	}
+
+	for _, currency := range []string{"US D", "usd\n", "$", "A", "USD:X"} {
+		if _, err := NewJournal(&strings.Builder{}, "u-me", nil, nil, time.Now(), JournalOptions{Syntax: SyntaxBeancount, Currency: currency}); err == nil {
+			t.Errorf("NewJournal with currency %q: got no error", currency)
+		}
+	}
+}
+
+func TestJournalRejectsUnopenedAccounts(t *testing.T) {
+	participants := []Participant{{ID: "u-me", Name: "Me"}, {ID: "u-mary", Name: "Mary"}}
+	tests := map[string]expense.Expense{
+		"participant": testExpense("e1", "lunch", "food", "u-me", 30, "u-me", "u-mary", "u-bob"),
+		"category":    testExpense("e2", "taxi", "travel", "u-me", 20, "u-me", "u-mary"),
+	}
+	for name, exp := range tests {
+		j, err := NewJournal(&strings.Builder{}, "u-me", participants, []string{"food"}, time.Now(), JournalOptions{Syntax: SyntaxBeancount})
+		if err != nil {
+			t.Fatalf("NewJournal: %v", err)
+		}
+		if err := j.Write(exp); err == nil {
+			t.Errorf("Write with an unopened %s: got no error", name)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+// Package graph is the GraphQL schema of the v1 API. Resolvers call the
+// orchestrator like the HTTP handlers do, users, groups, group members, group
+// balances and group expenses referenced from lists are batched per request by
+// loaders.
+package graph
+
+import (
+	"context"
+	"splitExpense/expense"
+	"splitExpense/graphql"
+	"splitExpense/orchestrator"
+	"splitExpense/service"
+)
+
+type stateKey struct{}
+
+// state is what the resolvers of one request share
+type state struct {
+	o      orchestrator.ExpenseAppImpl
+	userId string
+
+	users    *graphql.Loader[string, expense.User]
+	groups   *graphql.Loader[string, expense.Group]
+	members  *graphql.Loader[string, []expense.User]
+	balances *graphql.Loader[string, service.RunningBalance]
+	expenses *graphql.Loader[groupExpensesKey, *expense.GroupExpenseHistory]
+}
+
+// groupExpensesKey is a page of a group's expenses, groups asking for the same
+// page are fetched together
+type groupExpensesKey struct {
+	groupId         string
+	includeArchived bool
+	page            expense.PageRequest
+}
+
+// pageKey is what the groups fetched in one call share
+type pageKey struct {
+	includeArchived bool
+	page            expense.PageRequest
+}
+
+func loadGroupExpenses(o orchestrator.ExpenseAppImpl, userId string) func(ctx context.Context, keys []groupExpensesKey) (map[groupExpensesKey]*expense.GroupExpenseHistory, error) {
+	return func(ctx context.Context, keys []groupExpensesKey) (map[groupExpensesKey]*expense.GroupExpenseHistory, error) {
+		groupIds := map[pageKey][]string{}
+		for _, key := range keys {
+			pk := pageKey{includeArchived: key.includeArchived, page: key.page}
+			groupIds[pk] = append(groupIds[pk], key.groupId)
+		}
+		pages := make(map[groupExpensesKey]*expense.GroupExpenseHistory, len(keys))
+		for pk, ids := range groupIds {
+			byGroup, err := o.LoadGroupExpenses(ctx, userId, ids, pk.includeArchived, pk.page)
+			if err != nil {
+				return nil, err
+			}
+			for groupId, history := range byGroup {
+				pages[groupExpensesKey{groupId: groupId, includeArchived: pk.includeArchived, page: pk.page}] = history
+			}
+		}
+		return pages, nil
+	}
+}
+
+func from(ctx context.Context) *state {
+	return ctx.Value(stateKey{}).(*state)
+}
+
+// Execute runs a request as userId, the authenticated caller
+func Execute(ctx context.Context, o orchestrator.ExpenseAppImpl, userId string, req graphql.Request) *graphql.Result {
+	s := &state{
+		o:       o,
+		userId:  userId,
+		users:   graphql.NewLoader(o.LoadUsers),
+		groups:  graphql.NewLoader(o.LoadGroups),
+		members: graphql.NewLoader(o.LoadGroupMembers),
+		balances: graphql.NewLoader(func(ctx context.Context, groupIds []string) (map[string]service.RunningBalance, error) {
+			return o.GetGroupBalances(ctx, userId, groupIds)
+		}),
+		expenses: graphql.NewLoader(loadGroupExpenses(o, userId)),
+	}
+	return graphql.Execute(context.WithValue(ctx, stateKey{}, s), Schema, req)
+}
@@ -0,0 +1,448 @@
+package graph
+
+import (
+	"sort"
+	"splitExpense/expense"
+	"splitExpense/graphql"
+	"splitExpense/service"
+	"time"
+
+	lodash "github.com/samber/lo"
+)
+
+// Schema is served at /v1/graphql, GET on the endpoint prints it as SDL.
+// Object sources are expense.User for User and Friend, expense.Group,
+// expense.DetailedExpense for Expense, the payer and split wrappers,
+// service.RunningBalance for Balance and the page types for the connections.
+var Schema = newSchema()
+
+// maxDepth and maxComplexity bound an operation, the deepest useful query,
+// groups down to the name of a user sharing an expense, is eight fields deep
+const (
+	maxDepth      = 12
+	maxComplexity = 500
+)
+
+// share is one person's part of a payer or a split
+type share struct {
+	userId string
+	amount float64
+}
+
+func field(t graphql.Type, resolve graphql.ResolveFunc, args ...*graphql.Arg) *graphql.FieldDef {
+	return &graphql.FieldDef{Type: t, Resolve: resolve, Args: args}
+}
+
+func arg(name string, t graphql.Type) *graphql.Arg {
+	return &graphql.Arg{Name: name, Type: t}
+}
+
+var pageArgs = []*graphql.Arg{arg("first", graphql.Int), arg("after", graphql.String)}
+
+func withPageArgs(args ...*graphql.Arg) []*graphql.Arg {
+	return append(args, pageArgs...)
+}
+
+func stringArg(p graphql.ResolveParams, name string) string {
+	value, _ := p.Args[name].(string)
+	return value
+}
+
+func boolArg(p graphql.ResolveParams, name string) bool {
+	value, _ := p.Args[name].(bool)
+	return value
+}
+
+func floatArg(p graphql.ResolveParams, name string) *float64 {
+	value, ok := p.Args[name].(float64)
+	if !ok {
+		return nil
+	}
+	return &value
+}
+
+// page reads the first and after arguments, the cursor pagination of the HTTP API
+func page(p graphql.ResolveParams) expense.PageRequest {
+	first, _ := p.Args["first"].(int)
+	return expense.PageRequest{Cursor: stringArg(p, "after"), Limit: first}
+}
+
+func timestamp(t time.Time) string {
+	return t.Format(time.RFC3339)
+}
+
+// ifMember resolves to load once the caller is known to be a member of the
+// group, every field reading a group by id goes through it
+func ifMember(p graphql.ResolveParams, groupId string, load graphql.Thunk) graphql.Thunk {
+	s := from(p.Context)
+	members := s.members.Load(p.Context, groupId)
+	return func() (any, error) {
+		value, err := members()
+		if err != nil {
+			return nil, err
+		}
+		users, _ := value.([]expense.User)
+		if !lodash.ContainsBy(users, func(u expense.User) bool { return u.ID == s.userId }) {
+			return nil, expense.ErrForbidden("user is not a member of the group")
+		}
+		return load()
+	}
+}
+
+// loadUser resolves a user reference, an empty id is null
+func loadUser(p graphql.ResolveParams, id string) (any, error) {
+	if id == "" {
+		return nil, nil
+	}
+	return from(p.Context).users.Load(p.Context, id), nil
+}
+
+func payerType(pw expense.PayerWrapper) string {
+	switch pw.Payer.(type) {
+	case *expense.SinglePayer:
+		return "single"
+	case *expense.MultiPayer:
+		return "multi"
+	}
+	return pw.Type
+}
+
+func splitType(sw expense.SplitWrapper) string {
+	switch sw.Split.(type) {
+	case *expense.EqualSplit:
+		return "equal"
+	case *expense.UnitSplit:
+		return "unit"
+	case *expense.PercentageSplit:
+		return "percentage"
+	case *expense.ShareSplit:
+		return "share"
+	}
+	return sw.Type
+}
+
+func shares(amounts map[string]float64) []share {
+	out := make([]share, 0, len(amounts))
+	for userId, amount := range amounts {
+		out = append(out, share{userId: userId, amount: amount})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].userId < out[j].userId })
+	return out
+}
+
+func parseSearchTime(value string) (time.Time, bool, error) {
+	if t, err := time.Parse(time.DateOnly, value); err == nil {
+		return t, true, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	return t, false, err
+}
+
+// expenseSearch follows the query parameters of GET /v1/expenses/search
+func expenseSearch(p graphql.ResolveParams) (expense.ExpenseSearch, error) {
+	search := expense.ExpenseSearch{
+		Query:           stringArg(p, "query"),
+		GroupId:         stringArg(p, "groupId"),
+		ParticipantId:   stringArg(p, "participantId"),
+		PayerId:         stringArg(p, "payerId"),
+		Status:          expense.ExpenseStatus(stringArg(p, "status")),
+		Category:        stringArg(p, "category"),
+		MinAmount:       floatArg(p, "minAmount"),
+		MaxAmount:       floatArg(p, "maxAmount"),
+		IncludeArchived: boolArg(p, "includeArchived"),
+	}
+	if value := stringArg(p, "from"); value != "" {
+		from, _, err := parseSearchTime(value)
+		if err != nil {
+			return search, expense.ErrInvalidQuery("invalid from")
+		}
+		search.CreatedFrom = &from
+	}
+	if value := stringArg(p, "to"); value != "" {
+		to, isDate, err := parseSearchTime(value)
+		if err != nil {
+			return search, expense.ErrInvalidQuery("invalid to")
+		}
+		if isDate {
+			to = to.AddDate(0, 0, 1)
+		}
+		search.CreatedBefore = &to
+	}
+	return search, nil
+}
+
+func personFields() map[string]*graphql.FieldDef {
+	return map[string]*graphql.FieldDef{
+		"id": field(graphql.NonNullOf(graphql.ID), func(p graphql.ResolveParams) (any, error) {
+			return p.Source.(expense.User).ID, nil
+		}),
+		"name": field(graphql.NonNullOf(graphql.String), func(p graphql.ResolveParams) (any, error) {
+			return p.Source.(expense.User).Name, nil
+		}),
+		"email": field(graphql.NonNullOf(graphql.String), func(p graphql.ResolveParams) (any, error) {
+			return p.Source.(expense.User).Email, nil
+		}),
+		"isVerified": field(graphql.NonNullOf(graphql.Boolean), func(p graphql.ResolveParams) (any, error) {
+			return p.Source.(expense.User).IsVerified, nil
+		}),
+	}
+}
+
+func newSchema() *graphql.Schema {
+	user := &graphql.Object{Name: "User", Fields: personFields()}
+	friend := &graphql.Object{Name: "Friend", Description: "A user the caller added as a friend", Fields: personFields()}
+	group := &graphql.Object{Name: "Group"}
+	exp := &graphql.Object{Name: "Expense"}
+
+	balance := &graphql.Object{Name: "Balance", Description: "What the caller is owed and owes on unsettled expenses", Fields: map[string]*graphql.FieldDef{
+		"owed": field(graphql.NonNullOf(graphql.Float), func(p graphql.ResolveParams) (any, error) {
+			return p.Source.(service.RunningBalance).TotalOwed, nil
+		}),
+		"borrowed": field(graphql.NonNullOf(graphql.Float), func(p graphql.ResolveParams) (any, error) {
+			return p.Source.(service.RunningBalance).TotalBorrowed, nil
+		}),
+		"net": field(graphql.NonNullOf(graphql.Float), func(p graphql.ResolveParams) (any, error) {
+			b := p.Source.(service.RunningBalance)
+			return b.TotalOwed - b.TotalBorrowed, nil
+		}),
+	}}
+
+	shareFields := func() map[string]*graphql.FieldDef {
+		return map[string]*graphql.FieldDef{
+			"user": field(user, func(p graphql.ResolveParams) (any, error) {
+				return loadUser(p, p.Source.(share).userId)
+			}),
+			"amount": field(graphql.NonNullOf(graphql.Float), func(p graphql.ResolveParams) (any, error) {
+				return p.Source.(share).amount, nil
+			}),
+		}
+	}
+	payerShare := &graphql.Object{Name: "PayerShare", Fields: shareFields()}
+	splitShare := &graphql.Object{Name: "SplitShare", Fields: shareFields()}
+
+	payer := &graphql.Object{Name: "Payer", Fields: map[string]*graphql.FieldDef{
+		"type": field(graphql.NonNullOf(graphql.String), func(p graphql.ResolveParams) (any, error) {
+			return payerType(p.Source.(expense.PayerWrapper)), nil
+		}),
+		"payers": field(graphql.NonNullListOf(payerShare), func(p graphql.ResolveParams) (any, error) {
+			pw := p.Source.(expense.PayerWrapper)
+			if pw.Payer == nil {
+				return []share{}, nil
+			}
+			return shares(pw.Payer.GetPayers()), nil
+		}),
+	}}
+
+	split := &graphql.Object{Name: "Split", Fields: map[string]*graphql.FieldDef{
+		"type": field(graphql.NonNullOf(graphql.String), func(p graphql.ResolveParams) (any, error) {
+			return splitType(p.Source.(expense.SplitWrapper)), nil
+		}),
+		"totalAmount": field(graphql.NonNullOf(graphql.Float), func(p graphql.ResolveParams) (any, error) {
+			sw := p.Source.(expense.SplitWrapper)
+			if sw.Split == nil {
+				return 0.0, nil
+			}
+			return sw.Split.ComputeTotal(), nil
+		}),
+		"shares": field(graphql.NonNullListOf(splitShare), func(p graphql.ResolveParams) (any, error) {
+			sw := p.Source.(expense.SplitWrapper)
+			if sw.Split == nil {
+				return []share{}, nil
+			}
+			return shares(sw.Split.GetPayeeSplit()), nil
+		}),
+	}}
+
+	expenseConnection := &graphql.Object{Name: "ExpenseConnection", Fields: map[string]*graphql.FieldDef{
+		"nodes": field(graphql.NonNullListOf(exp), func(p graphql.ResolveParams) (any, error) {
+			return p.Source.(*expense.GroupExpenseHistory).Expenses, nil
+		}),
+		"nextCursor": field(graphql.String, func(p graphql.ResolveParams) (any, error) {
+			if cursor := p.Source.(*expense.GroupExpenseHistory).NextCursor; cursor != "" {
+				return cursor, nil
+			}
+			return nil, nil
+		}),
+		"hasMore": field(graphql.NonNullOf(graphql.Boolean), func(p graphql.ResolveParams) (any, error) {
+			return p.Source.(*expense.GroupExpenseHistory).HasMore, nil
+		}),
+	}}
+
+	groupConnection := &graphql.Object{Name: "GroupConnection", Fields: map[string]*graphql.FieldDef{
+		"nodes": field(graphql.NonNullListOf(group), func(p graphql.ResolveParams) (any, error) {
+			return p.Source.(*expense.GroupPage).Groups, nil
+		}),
+		"nextCursor": field(graphql.String, func(p graphql.ResolveParams) (any, error) {
+			if cursor := p.Source.(*expense.GroupPage).NextCursor; cursor != "" {
+				return cursor, nil
+			}
+			return nil, nil
+		}),
+		"hasMore": field(graphql.NonNullOf(graphql.Boolean), func(p graphql.ResolveParams) (any, error) {
+			return p.Source.(*expense.GroupPage).HasMore, nil
+		}),
+	}}
+
+	friendConnection := &graphql.Object{Name: "FriendConnection", Fields: map[string]*graphql.FieldDef{
+		"nodes": field(graphql.NonNullListOf(friend), func(p graphql.ResolveParams) (any, error) {
+			return p.Source.(*expense.UserPage).Users, nil
+		}),
+		"nextCursor": field(graphql.String, func(p graphql.ResolveParams) (any, error) {
+			if cursor := p.Source.(*expense.UserPage).NextCursor; cursor != "" {
+				return cursor, nil
+			}
+			return nil, nil
+		}),
+		"hasMore": field(graphql.NonNullOf(graphql.Boolean), func(p graphql.ResolveParams) (any, error) {
+			return p.Source.(*expense.UserPage).HasMore, nil
+		}),
+	}}
+
+	group.Fields = map[string]*graphql.FieldDef{
+		"id": field(graphql.NonNullOf(graphql.ID), func(p graphql.ResolveParams) (any, error) {
+			return p.Source.(expense.Group).Id, nil
+		}),
+		"name": field(graphql.NonNullOf(graphql.String), func(p graphql.ResolveParams) (any, error) {
+			return p.Source.(expense.Group).Name, nil
+		}),
+		"description": field(graphql.NonNullOf(graphql.String), func(p graphql.ResolveParams) (any, error) {
+			return p.Source.(expense.Group).Description, nil
+		}),
+		"version": field(graphql.NonNullOf(graphql.Int), func(p graphql.ResolveParams) (any, error) {
+			return p.Source.(expense.Group).Version, nil
+		}),
+		"createdAt": field(graphql.NonNullOf(graphql.String), func(p graphql.ResolveParams) (any, error) {
+			return timestamp(p.Source.(expense.Group).CreatedAt), nil
+		}),
+		"admin": field(user, func(p graphql.ResolveParams) (any, error) {
+			return loadUser(p, p.Source.(expense.Group).Admin)
+		}),
+		// members, balance and expenses are only shown to members, like the group detail of the HTTP API
+		"members": field(graphql.NonNullListOf(user), func(p graphql.ResolveParams) (any, error) {
+			groupId := p.Source.(expense.Group).Id
+			return ifMember(p, groupId, from(p.Context).members.Load(p.Context, groupId)), nil
+		}),
+		"balance": field(graphql.NonNullOf(balance), func(p graphql.ResolveParams) (any, error) {
+			groupId := p.Source.(expense.Group).Id
+			return ifMember(p, groupId, from(p.Context).balances.Load(p.Context, groupId)), nil
+		}),
+		"expenses": field(graphql.NonNullOf(expenseConnection), func(p graphql.ResolveParams) (any, error) {
+			key := groupExpensesKey{groupId: p.Source.(expense.Group).Id, includeArchived: boolArg(p, "includeArchived"), page: page(p)}
+			return ifMember(p, key.groupId, from(p.Context).expenses.Load(p.Context, key)), nil
+		}, withPageArgs(&graphql.Arg{Name: "includeArchived", Type: graphql.Boolean, Default: false})...),
+	}
+
+	exp.Fields = map[string]*graphql.FieldDef{
+		"id": field(graphql.NonNullOf(graphql.ID), func(p graphql.ResolveParams) (any, error) {
+			return p.Source.(expense.DetailedExpense).Expense.ID, nil
+		}),
+		"description": field(graphql.NonNullOf(graphql.String), func(p graphql.ResolveParams) (any, error) {
+			return p.Source.(expense.DetailedExpense).Expense.Description, nil
+		}),
+		"category": field(graphql.NonNullOf(graphql.String), func(p graphql.ResolveParams) (any, error) {
+			return p.Source.(expense.DetailedExpense).Expense.Category, nil
+		}),
+		"amount": field(graphql.NonNullOf(graphql.Float), func(p graphql.ResolveParams) (any, error) {
+			return p.Source.(expense.DetailedExpense).Expense.Amount, nil
+		}),
+		"createdAt": field(graphql.NonNullOf(graphql.String), func(p graphql.ResolveParams) (any, error) {
+			return timestamp(p.Source.(expense.DetailedExpense).Expense.CreatedAt), nil
+		}),
+		"status": field(graphql.NonNullOf(graphql.String), func(p graphql.ResolveParams) (any, error) {
+			return string(p.Source.(expense.DetailedExpense).Expense.Status), nil
+		}),
+		"version": field(graphql.NonNullOf(graphql.Int), func(p graphql.ResolveParams) (any, error) {
+			return p.Source.(expense.DetailedExpense).Expense.Version, nil
+		}),
+		"owed": field(graphql.NonNullOf(graphql.Float), func(p graphql.ResolveParams) (any, error) {
+			return p.Source.(expense.DetailedExpense).TotalOwed, nil
+		}),
+		"borrowed": field(graphql.NonNullOf(graphql.Float), func(p graphql.ResolveParams) (any, error) {
+			return p.Source.(expense.DetailedExpense).TotalBorrowed, nil
+		}),
+		"group": field(group, func(p graphql.ResolveParams) (any, error) {
+			groupId := p.Source.(expense.DetailedExpense).Expense.GroupId
+			if groupId == "" {
+				return nil, nil
+			}
+			return ifMember(p, groupId, from(p.Context).groups.Load(p.Context, groupId)), nil
+		}),
+		"createdBy": field(user, func(p graphql.ResolveParams) (any, error) {
+			return loadUser(p, p.Source.(expense.DetailedExpense).Expense.CreatedBy)
+		}),
+		"settledBy": field(user, func(p graphql.ResolveParams) (any, error) {
+			return loadUser(p, p.Source.(expense.DetailedExpense).Expense.SettledBy)
+		}),
+		"payer": field(graphql.NonNullOf(payer), func(p graphql.ResolveParams) (any, error) {
+			return p.Source.(expense.DetailedExpense).Expense.PayeeW, nil
+		}),
+		"split": field(graphql.NonNullOf(split), func(p graphql.ResolveParams) (any, error) {
+			return p.Source.(expense.DetailedExpense).Expense.SplitW, nil
+		}),
+	}
+
+	query := &graphql.Object{Name: "Query", Fields: map[string]*graphql.FieldDef{
+		"me": field(graphql.NonNullOf(user), func(p graphql.ResolveParams) (any, error) {
+			s := from(p.Context)
+			me, err := s.o.GetUserService().GetUser(p.Context, s.userId)
+			if err != nil {
+				return nil, err
+			}
+			return *me, nil
+		}),
+		"friends": field(graphql.NonNullOf(friendConnection), func(p graphql.ResolveParams) (any, error) {
+			s := from(p.Context)
+			return s.o.GetFriendsPage(p.Context, s.userId, page(p))
+		}, pageArgs...),
+		"groups": field(graphql.NonNullOf(groupConnection), func(p graphql.ResolveParams) (any, error) {
+			s := from(p.Context)
+			return s.o.GetGroups(p.Context, s.userId, page(p))
+		}, pageArgs...),
+		"group": field(group, func(p graphql.ResolveParams) (any, error) {
+			s := from(p.Context)
+			g, err := s.o.GetGroup(p.Context, s.userId, stringArg(p, "id"))
+			if err != nil {
+				return nil, err
+			}
+			return *g, nil
+		}, arg("id", graphql.NonNullOf(graphql.ID))),
+		"expenses": field(graphql.NonNullOf(expenseConnection), func(p graphql.ResolveParams) (any, error) {
+			s := from(p.Context)
+			history, err := s.o.GetUserExpenseHistoryPage(p.Context, s.userId, page(p))
+			if err != nil {
+				return nil, err
+			}
+			return &expense.GroupExpenseHistory{Expenses: history.Expenses, NextCursor: history.NextCursor, HasMore: history.HasMore}, nil
+		}, pageArgs...),
+		"searchExpenses": field(graphql.NonNullOf(expenseConnection), func(p graphql.ResolveParams) (any, error) {
+			s := from(p.Context)
+			search, err := expenseSearch(p)
+			if err != nil {
+				return nil, err
+			}
+			return s.o.SearchExpenses(p.Context, s.userId, search, page(p))
+		}, withPageArgs(
+			arg("query", graphql.String),
+			arg("groupId", graphql.ID),
+			arg("participantId", graphql.ID),
+			arg("payerId", graphql.ID),
+			arg("status", graphql.String),
+			arg("category", graphql.String),
+			arg("minAmount", graphql.Float),
+			arg("maxAmount", graphql.Float),
+			arg("from", graphql.String),
+			arg("to", graphql.String),
+			&graphql.Arg{Name: "includeArchived", Type: graphql.Boolean, Default: false},
+		)...),
+		"balance": field(graphql.NonNullOf(balance), func(p graphql.ResolveParams) (any, error) {
+			s := from(p.Context)
+			owed, borrowed, err := s.o.GetExpenseService().CalculateAllUserRunningExpenses(p.Context, s.userId)
+			if err != nil {
+				return nil, err
+			}
+			return service.RunningBalance{TotalOwed: owed, TotalBorrowed: borrowed}, nil
+		}),
+	}}
+
+	return &graphql.Schema{Query: query, MaxDepth: maxDepth, MaxComplexity: maxComplexity}
+}
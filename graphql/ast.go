@@ -0,0 +1,136 @@
+package graphql
+
+// The AST covers executable documents only: operations, fragments and the
+// values inside them. Schemas are built in Go, see schema.go.
+
+type Location struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+type Document struct {
+	Operations []*Operation
+	Fragments  map[string]*Fragment
+}
+
+type Operation struct {
+	// Type is query, mutation or subscription
+	Type       string
+	Name       string
+	Variables  []*VariableDefinition
+	Directives []*Directive
+	Selections []Selection
+	Loc        Location
+}
+
+type VariableDefinition struct {
+	Name    string
+	Type    *TypeRef
+	Default *Value
+	Loc     Location
+}
+
+// TypeRef is a type as written in a variable definition, either a named type or a list
+type TypeRef struct {
+	Name    string
+	Elem    *TypeRef
+	NonNull bool
+}
+
+func (t *TypeRef) String() string {
+	s := t.Name
+	if t.Elem != nil {
+		s = "[" + t.Elem.String() + "]"
+	}
+	if t.NonNull {
+		s += "!"
+	}
+	return s
+}
+
+type Selection interface {
+	selection()
+}
+
+type Field struct {
+	Alias      string
+	Name       string
+	Arguments  []*Argument
+	Directives []*Directive
+	Selections []Selection
+	Loc        Location
+}
+
+// ResponseKey is the name of the field in the result
+func (f *Field) ResponseKey() string {
+	if f.Alias != "" {
+		return f.Alias
+	}
+	return f.Name
+}
+
+type FragmentSpread struct {
+	Name       string
+	Directives []*Directive
+	Loc        Location
+}
+
+type InlineFragment struct {
+	TypeCondition string
+	Directives    []*Directive
+	Selections    []Selection
+	Loc           Location
+}
+
+func (*Field) selection()          {}
+func (*FragmentSpread) selection() {}
+func (*InlineFragment) selection() {}
+
+type Fragment struct {
+	Name          string
+	TypeCondition string
+	Directives    []*Directive
+	Selections    []Selection
+	Loc           Location
+}
+
+type Argument struct {
+	Name  string
+	Value *Value
+	Loc   Location
+}
+
+type Directive struct {
+	Name      string
+	Arguments []*Argument
+	Loc       Location
+}
+
+type ValueKind int
+
+const (
+	VariableValue ValueKind = iota
+	IntValue
+	FloatValue
+	StringValue
+	BooleanValue
+	NullValue
+	EnumValue
+	ListValue
+	ObjectValue
+)
+
+// Value is a literal or a variable reference. Raw holds the variable name,
+// the number or enum as written, or the unescaped string.
+type Value struct {
+	Kind   ValueKind
+	Raw    string
+	List   []*Value
+	Fields []*ObjectField
+	Loc    Location
+}
+
+type ObjectField struct {
+	Name  string
+	Value *Value
+}
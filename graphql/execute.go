@@ -0,0 +1,655 @@
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+// Request is the body of a GraphQL POST
+type Request struct {
+	Query         string         `json:"query"`
+	OperationName string         `json:"operationName"`
+	Variables     map[string]any `json:"variables"`
+}
+
+type Error struct {
+	Message   string     `json:"message"`
+	Locations []Location `json:"locations,omitempty"`
+	Path      []any      `json:"path,omitempty"`
+}
+
+// Result is the response body. Data is left out when the request failed
+// before execution, and is null when a non-null root field failed.
+type Result struct {
+	Data     any
+	Errors   []*Error
+	executed bool
+}
+
+func (r *Result) MarshalJSON() ([]byte, error) {
+	body := struct {
+		Data   *any     `json:"data,omitempty"`
+		Errors []*Error `json:"errors,omitempty"`
+	}{Errors: r.Errors}
+	if r.executed {
+		body.Data = &r.Data
+	}
+	return json.Marshal(body)
+}
+
+// RequestFailed reports whether the request failed before execution, partial
+// results with field errors still count as executed
+func (r *Result) RequestFailed() bool {
+	return !r.executed
+}
+
+func requestError(message string, loc *Location) *Result {
+	err := &Error{Message: message}
+	if loc != nil {
+		err.Locations = []Location{*loc}
+	}
+	return &Result{Errors: []*Error{err}}
+}
+
+// Execute runs one operation of the request. Fields are resolved one depth at
+// a time across the whole result, so every Thunk of a depth is created before
+// the first one is forced and a Loader sees all keys of the depth at once.
+func Execute(ctx context.Context, schema *Schema, req Request) *Result {
+	doc, err := Parse(req.Query)
+	if err != nil {
+		syntaxErr := err.(*SyntaxError)
+		return requestError(syntaxErr.Error(), &syntaxErr.Loc)
+	}
+
+	op, errResult := selectOperation(doc, req.OperationName)
+	if errResult != nil {
+		return errResult
+	}
+	var root *Object
+	switch op.Type {
+	case "query":
+		root = schema.Query
+	case "mutation":
+		root = schema.Mutation
+	}
+	if root == nil {
+		return requestError(op.Type+" operations are not supported", &op.Loc)
+	}
+
+	if errs := validate(doc, op, schema, root); len(errs) > 0 {
+		return &Result{Errors: errs}
+	}
+	variables, errs := coerceVariables(op, req.Variables)
+	if len(errs) > 0 {
+		return &Result{Errors: errs}
+	}
+
+	ex := &executor{ctx: ctx, doc: doc, variables: variables}
+	rootNode := &objectNode{obj: root, selections: op.Selections}
+	level := []*objectNode{rootNode}
+	for len(level) > 0 {
+		level = ex.resolveLevel(level)
+	}
+
+	data, _ := ex.completeObject(rootNode)
+	result := &Result{executed: true, Errors: ex.errors}
+	if data != nil {
+		result.Data = data
+	}
+	return result
+}
+
+func selectOperation(doc *Document, name string) (*Operation, *Result) {
+	if name == "" {
+		if len(doc.Operations) > 1 {
+			return nil, requestError("operationName is required when the document has several operations", nil)
+		}
+		return doc.Operations[0], nil
+	}
+	for _, op := range doc.Operations {
+		if op.Name == name {
+			return op, nil
+		}
+	}
+	return nil, requestError(fmt.Sprintf("unknown operation %q", name), nil)
+}
+
+type objectNode struct {
+	obj        *Object
+	source     any
+	path       []any
+	selections []Selection
+	fields     []*fieldNode
+}
+
+type fieldNode struct {
+	key  string
+	asts []*Field
+	// def is nil for __typename
+	def  *FieldDef
+	path []any
+	raw  any
+	err  error
+	// value is the built result: an *objectNode, a []any of built values or a serialized scalar
+	value any
+}
+
+func (f *fieldNode) fieldType() Type {
+	if f.def == nil {
+		return NonNullOf(String)
+	}
+	return f.def.Type
+}
+
+type executor struct {
+	ctx       context.Context
+	doc       *Document
+	variables map[string]any
+	errors    []*Error
+}
+
+func appendPath(path []any, elem any) []any {
+	return append(append(make([]any, 0, len(path)+1), path...), elem)
+}
+
+// resolveLevel calls the resolvers of every field of the level, then forces
+// their thunks and returns the objects of the next level
+func (ex *executor) resolveLevel(level []*objectNode) []*objectNode {
+	for _, n := range level {
+		for _, group := range ex.collectFields(n.obj, n.selections, map[string]bool{}) {
+			f := &fieldNode{key: group.key, asts: group.fields, path: appendPath(n.path, group.key)}
+			n.fields = append(n.fields, f)
+			if group.fields[0].Name == "__typename" {
+				f.raw = n.obj.Name
+				continue
+			}
+			f.def = n.obj.Fields[group.fields[0].Name]
+			f.raw, f.err = ex.resolve(f.def, n.source, group.fields[0])
+		}
+	}
+
+	for _, n := range level {
+		for _, f := range n.fields {
+			for f.err == nil {
+				thunk, ok := f.raw.(Thunk)
+				if !ok {
+					break
+				}
+				f.raw, f.err = ex.force(thunk)
+			}
+		}
+	}
+
+	var next []*objectNode
+	for _, n := range level {
+		for _, f := range n.fields {
+			if f.err != nil {
+				continue
+			}
+			f.value, f.err = ex.build(f.fieldType(), f.raw, subSelections(f.asts), f.path, &next)
+		}
+	}
+	return next
+}
+
+func subSelections(fields []*Field) []Selection {
+	var selections []Selection
+	for _, f := range fields {
+		selections = append(selections, f.Selections...)
+	}
+	return selections
+}
+
+func (ex *executor) resolve(def *FieldDef, source any, ast *Field) (value any, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			value, err = nil, fmt.Errorf("internal error resolving %s: %v", ast.Name, r)
+		}
+	}()
+	args, err := ex.argumentValues(def, ast)
+	if err != nil {
+		return nil, err
+	}
+	return def.Resolve(ResolveParams{Context: ex.ctx, Source: source, Args: args})
+}
+
+func (ex *executor) force(thunk Thunk) (value any, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			value, err = nil, fmt.Errorf("internal error: %v", r)
+		}
+	}()
+	return thunk()
+}
+
+func isNil(value any) bool {
+	if value == nil {
+		return true
+	}
+	switch v := reflect.ValueOf(value); v.Kind() {
+	case reflect.Pointer, reflect.Map, reflect.Slice, reflect.Interface, reflect.Func:
+		return v.IsNil()
+	}
+	return false
+}
+
+// build shapes a resolved value after its type, queueing objects for the next level
+func (ex *executor) build(t Type, raw any, selections []Selection, path []any, next *[]*objectNode) (any, error) {
+	if isNil(raw) {
+		return nil, nil
+	}
+	switch tt := t.(type) {
+	case *NonNull:
+		return ex.build(tt.Of, raw, selections, path, next)
+	case *List:
+		v := reflect.ValueOf(raw)
+		if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+			return nil, fmt.Errorf("expected a list for %s, got %T", t, raw)
+		}
+		items := make([]any, v.Len())
+		for i := range items {
+			item, err := ex.build(tt.Of, v.Index(i).Interface(), selections, appendPath(path, i), next)
+			if err != nil {
+				return nil, err
+			}
+			items[i] = item
+		}
+		return items, nil
+	case *Object:
+		n := &objectNode{obj: tt, source: raw, path: path, selections: selections}
+		*next = append(*next, n)
+		return n, nil
+	case *Scalar:
+		return tt.Serialize(raw)
+	}
+	return nil, fmt.Errorf("unknown type %s", t)
+}
+
+func (ex *executor) addError(message string, f *fieldNode, path []any) {
+	ex.errors = append(ex.errors, &Error{Message: message, Locations: []Location{f.asts[0].Loc}, Path: path})
+}
+
+// completeObject turns a resolved object into its result. A null in a
+// non-null field makes the whole object null, errored tells the parent an
+// error was already reported for it.
+func (ex *executor) completeObject(n *objectNode) (*orderedMap, bool) {
+	out := &orderedMap{values: map[string]any{}}
+	for _, f := range n.fields {
+		var value any
+		errored := false
+		if f.err != nil {
+			ex.addError(f.err.Error(), f, f.path)
+			errored = true
+		} else {
+			value, errored = ex.completeValue(f.fieldType(), f.value, f, f.path)
+		}
+		if value == nil && isNonNull(f.fieldType()) {
+			if !errored {
+				ex.addError(fmt.Sprintf("cannot return null for non-nullable field %s.%s", n.obj.Name, f.asts[0].Name), f, f.path)
+			}
+			return nil, true
+		}
+		out.set(f.key, value)
+	}
+	return out, false
+}
+
+func (ex *executor) completeValue(t Type, value any, f *fieldNode, path []any) (any, bool) {
+	if nn, ok := t.(*NonNull); ok {
+		completed, errored := ex.completeValue(nn.Of, value, f, path)
+		if completed == nil && !errored {
+			ex.addError(fmt.Sprintf("cannot return null for non-nullable %s", t), f, path)
+			errored = true
+		}
+		return completed, errored
+	}
+	if value == nil {
+		return nil, false
+	}
+
+	switch tt := t.(type) {
+	case *List:
+		items := value.([]any)
+		out := make([]any, len(items))
+		for i, item := range items {
+			completed, errored := ex.completeValue(tt.Of, item, f, appendPath(path, i))
+			if completed == nil && isNonNull(tt.Of) {
+				return nil, errored
+			}
+			out[i] = completed
+		}
+		return out, false
+	case *Object:
+		completed, errored := ex.completeObject(value.(*objectNode))
+		if completed == nil {
+			return nil, errored
+		}
+		return completed, false
+	}
+	return value, false
+}
+
+// orderedMap keeps the fields of an object in query order
+type orderedMap struct {
+	keys   []string
+	values map[string]any
+}
+
+func (m *orderedMap) set(key string, value any) {
+	if _, ok := m.values[key]; !ok {
+		m.keys = append(m.keys, key)
+	}
+	m.values[key] = value
+}
+
+func (m *orderedMap) MarshalJSON() ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteByte('{')
+	for i, key := range m.keys {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		k, _ := json.Marshal(key)
+		b.Write(k)
+		b.WriteByte(':')
+		v, err := json.Marshal(m.values[key])
+		if err != nil {
+			return nil, err
+		}
+		b.Write(v)
+	}
+	b.WriteByte('}')
+	return b.Bytes(), nil
+}
+
+type fieldGroup struct {
+	key    string
+	fields []*Field
+}
+
+// collectFields flattens fragments and merges fields that share a response
+// key, honouring @skip and @include
+func (ex *executor) collectFields(obj *Object, selections []Selection, visited map[string]bool) []*fieldGroup {
+	var groups []*fieldGroup
+	index := map[string]*fieldGroup{}
+	var collect func(selections []Selection)
+	collect = func(selections []Selection) {
+		for _, sel := range selections {
+			switch s := sel.(type) {
+			case *Field:
+				if !ex.included(s.Directives) {
+					continue
+				}
+				key := s.ResponseKey()
+				if g, ok := index[key]; ok {
+					g.fields = append(g.fields, s)
+					continue
+				}
+				g := &fieldGroup{key: key, fields: []*Field{s}}
+				index[key] = g
+				groups = append(groups, g)
+			case *InlineFragment:
+				if ex.included(s.Directives) {
+					collect(s.Selections)
+				}
+			case *FragmentSpread:
+				if visited[s.Name] || !ex.included(s.Directives) {
+					continue
+				}
+				visited[s.Name] = true
+				collect(ex.doc.Fragments[s.Name].Selections)
+			}
+		}
+	}
+	collect(selections)
+	return groups
+}
+
+func (ex *executor) included(directives []*Directive) bool {
+	for _, d := range directives {
+		if d.Name != "skip" && d.Name != "include" {
+			continue
+		}
+		var condition any
+		for _, arg := range d.Arguments {
+			if arg.Name == "if" {
+				condition, _ = ex.literal(NonNullOf(Boolean), arg.Value)
+			}
+		}
+		if value, _ := condition.(bool); value == (d.Name == "skip") {
+			return false
+		}
+	}
+	return true
+}
+
+// argumentValues coerces the arguments of a field, filling in defaults
+func (ex *executor) argumentValues(def *FieldDef, ast *Field) (map[string]any, error) {
+	args := map[string]any{}
+	for _, a := range def.Args {
+		var literal *Value
+		for _, arg := range ast.Arguments {
+			if arg.Name == a.Name {
+				literal = arg.Value
+			}
+		}
+		if literal != nil && literal.Kind == VariableValue {
+			if _, ok := ex.variables[literal.Raw]; !ok {
+				literal = nil
+			}
+		}
+		if literal == nil {
+			if a.Default != nil {
+				args[a.Name] = a.Default
+			} else if isNonNull(a.Type) {
+				return nil, fmt.Errorf("argument %s of type %s is required", a.Name, a.Type)
+			}
+			continue
+		}
+		value, err := ex.literal(a.Type, literal)
+		if err != nil {
+			return nil, fmt.Errorf("argument %s: %w", a.Name, err)
+		}
+		args[a.Name] = value
+	}
+	return args, nil
+}
+
+// literal coerces a value from the document to the input type t
+func (ex *executor) literal(t Type, v *Value) (any, error) {
+	if v.Kind == VariableValue {
+		value, ok := ex.variables[v.Raw]
+		if !ok {
+			return nil, nil
+		}
+		return inputValue(t, value)
+	}
+	if nn, ok := t.(*NonNull); ok {
+		if v.Kind == NullValue {
+			return nil, fmt.Errorf("expected a non-null %s", nn.Of)
+		}
+		return ex.literal(nn.Of, v)
+	}
+	if v.Kind == NullValue {
+		return nil, nil
+	}
+
+	switch tt := t.(type) {
+	case *List:
+		if v.Kind != ListValue {
+			item, err := ex.literal(tt.Of, v)
+			if err != nil {
+				return nil, err
+			}
+			return []any{item}, nil
+		}
+		items := make([]any, len(v.List))
+		for i, item := range v.List {
+			value, err := ex.literal(tt.Of, item)
+			if err != nil {
+				return nil, err
+			}
+			items[i] = value
+		}
+		return items, nil
+	case *Scalar:
+		var value any
+		switch v.Kind {
+		case IntValue:
+			n, err := strconv.ParseInt(v.Raw, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("%s can not represent %s", tt.Name, v.Raw)
+			}
+			value = n
+		case FloatValue:
+			f, err := strconv.ParseFloat(v.Raw, 64)
+			if err != nil {
+				return nil, fmt.Errorf("%s can not represent %s", tt.Name, v.Raw)
+			}
+			value = f
+		case StringValue:
+			value = v.Raw
+		case BooleanValue:
+			value = v.Raw == "true"
+		default:
+			return nil, fmt.Errorf("%s can not represent %s", tt.Name, describeValue(v))
+		}
+		if kinds, ok := literalKinds[tt.Name]; ok && !kinds[v.Kind] {
+			return nil, fmt.Errorf("%s can not represent %s", tt.Name, describeValue(v))
+		}
+		return tt.Parse(value)
+	}
+	return nil, fmt.Errorf("%s is not an input type", t)
+}
+
+// literalKinds are the literals the built in scalars accept, custom scalars
+// get every literal and decide in Parse
+var literalKinds = map[string]map[ValueKind]bool{
+	"Int":     {IntValue: true},
+	"Float":   {IntValue: true, FloatValue: true},
+	"String":  {StringValue: true},
+	"Boolean": {BooleanValue: true},
+	"ID":      {IntValue: true, StringValue: true},
+}
+
+func describeValue(v *Value) string {
+	switch v.Kind {
+	case StringValue:
+		return strconv.Quote(v.Raw)
+	case ListValue:
+		return "a list"
+	case ObjectValue:
+		return "an object"
+	}
+	return v.Raw
+}
+
+// inputValue coerces a JSON variable to the input type t
+func inputValue(t Type, value any) (any, error) {
+	if nn, ok := t.(*NonNull); ok {
+		if value == nil {
+			return nil, fmt.Errorf("expected a non-null %s", nn.Of)
+		}
+		return inputValue(nn.Of, value)
+	}
+	if value == nil {
+		return nil, nil
+	}
+	switch tt := t.(type) {
+	case *List:
+		list, ok := value.([]any)
+		if !ok {
+			item, err := inputValue(tt.Of, value)
+			if err != nil {
+				return nil, err
+			}
+			return []any{item}, nil
+		}
+		items := make([]any, len(list))
+		for i, item := range list {
+			coerced, err := inputValue(tt.Of, item)
+			if err != nil {
+				return nil, err
+			}
+			items[i] = coerced
+		}
+		return items, nil
+	case *Scalar:
+		if n, ok := value.(json.Number); ok {
+			f, err := n.Float64()
+			if err != nil {
+				return nil, err
+			}
+			value = f
+		}
+		return tt.Parse(value)
+	}
+	return nil, fmt.Errorf("%s is not an input type", t)
+}
+
+var inputScalars = map[string]*Scalar{"String": String, "Int": Int, "Float": Float, "Boolean": Boolean, "ID": ID}
+
+func typeFromRef(ref *TypeRef) (Type, error) {
+	var t Type
+	if ref.Elem != nil {
+		elem, err := typeFromRef(ref.Elem)
+		if err != nil {
+			return nil, err
+		}
+		t = ListOf(elem)
+	} else {
+		scalar, ok := inputScalars[ref.Name]
+		if !ok {
+			return nil, fmt.Errorf("unknown input type %s", ref.Name)
+		}
+		t = scalar
+	}
+	if ref.NonNull {
+		t = NonNullOf(t)
+	}
+	return t, nil
+}
+
+// coerceVariables checks the request variables against the operation's
+// definitions, variables left out without a default stay unset
+func coerceVariables(op *Operation, provided map[string]any) (map[string]any, []*Error) {
+	variables := map[string]any{}
+	var errs []*Error
+	fail := func(def *VariableDefinition, format string, args ...any) {
+		errs = append(errs, &Error{Message: fmt.Sprintf(format, args...), Locations: []Location{def.Loc}})
+	}
+
+	ex := &executor{variables: map[string]any{}}
+	for _, def := range op.Variables {
+		t, err := typeFromRef(def.Type)
+		if err != nil {
+			fail(def, "variable $%s: %s", def.Name, err)
+			continue
+		}
+		value, ok := provided[def.Name]
+		if !ok {
+			if def.Default != nil {
+				value, err := ex.literal(t, def.Default)
+				if err != nil {
+					fail(def, "variable $%s: %s", def.Name, err)
+					continue
+				}
+				variables[def.Name] = value
+			} else if isNonNull(t) {
+				fail(def, "variable $%s of required type %s was not provided", def.Name, def.Type)
+			}
+			continue
+		}
+		coerced, err := inputValue(t, value)
+		if err != nil {
+			fail(def, "variable $%s: %s", def.Name, err)
+			continue
+		}
+		variables[def.Name] = coerced
+	}
+	return variables, errs
+}
@@ -0,0 +1,154 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+// node is the source of the Node type of the test schema, a tree of
+// children numbered after their parent
+type node struct {
+	id string
+}
+
+// testSchema answers node(id), nodes and greeting(name). Node.parent goes
+// through a Loader that counts its fetches in fetches, so a schema serves one
+// request like the loaders of graph.Execute. Node.broken always fails.
+func testSchema(fetches *int) *Schema {
+	nodeType := &Object{Name: "Node"}
+	parents := NewLoader(func(ctx context.Context, ids []string) (map[string]node, error) {
+		*fetches++
+		out := map[string]node{}
+		for _, id := range ids {
+			out[id] = node{id: "parent of " + id}
+		}
+		return out, nil
+	})
+	nodeType.Fields = map[string]*FieldDef{
+		"id": {Type: NonNullOf(ID), Resolve: func(p ResolveParams) (any, error) {
+			return p.Source.(node).id, nil
+		}},
+		"children": {Type: NonNullListOf(nodeType), Resolve: func(p ResolveParams) (any, error) {
+			id := p.Source.(node).id
+			return []node{{id: id + ".1"}, {id: id + ".2"}}, nil
+		}},
+		"parent": {Type: nodeType, Resolve: func(p ResolveParams) (any, error) {
+			return parents.Load(p.Context, p.Source.(node).id), nil
+		}},
+		"broken": {Type: NonNullOf(String), Resolve: func(p ResolveParams) (any, error) {
+			return nil, errors.New("broken on " + p.Source.(node).id)
+		}},
+	}
+	query := &Object{Name: "Query", Fields: map[string]*FieldDef{
+		"node": {Type: nodeType, Args: []*Arg{{Name: "id", Type: NonNullOf(ID)}}, Resolve: func(p ResolveParams) (any, error) {
+			return node{id: p.Args["id"].(string)}, nil
+		}},
+		"nodes": {Type: NonNullListOf(nodeType), Resolve: func(p ResolveParams) (any, error) {
+			return []node{{id: "1"}, {id: "2"}, {id: "3"}}, nil
+		}},
+		"greeting": {Type: NonNullOf(String), Args: []*Arg{{Name: "name", Type: String, Default: "world"}}, Resolve: func(p ResolveParams) (any, error) {
+			return fmt.Sprintf("hello %v", p.Args["name"]), nil
+		}},
+	}}
+	return &Schema{Query: query}
+}
+
+func run(t *testing.T, schema *Schema, query string, variables map[string]any) (string, *Result) {
+	t.Helper()
+	result := Execute(context.Background(), schema, Request{Query: query, Variables: variables})
+	body, err := json.Marshal(result)
+	if err != nil {
+		t.Fatalf("marshalling the result: %v", err)
+	}
+	return string(body), result
+}
+
+func TestExecute(t *testing.T) {
+	fetches := 0
+	schema := testSchema(&fetches)
+	tests := []struct {
+		name      string
+		query     string
+		variables map[string]any
+		want      string
+	}{
+		{
+			name:  "fields in query order with aliases and typename",
+			query: `{ b: node(id: 7) { __typename id } greeting }`,
+			want:  `{"data":{"b":{"__typename":"Node","id":"7"},"greeting":"hello world"}}`,
+		},
+		{
+			name:      "variables and defaults",
+			query:     `query ($name: String, $id: ID!) { greeting(name: $name) node(id: $id) { id } }`,
+			variables: map[string]any{"name": "ann", "id": "x"},
+			want:      `{"data":{"greeting":"hello ann","node":{"id":"x"}}}`,
+		},
+		{
+			name:  "fragments and directives",
+			query: `{ node(id: "n") { ...ids children @skip(if: true) { id } ... on Node @include(if: false) { broken } } } fragment ids on Node { id }`,
+			want:  `{"data":{"node":{"id":"n"}}}`,
+		},
+		{
+			name:  "nested lists",
+			query: `{ node(id: "n") { children { id children { id } } } }`,
+			want:  `{"data":{"node":{"children":[{"id":"n.1","children":[{"id":"n.1.1"},{"id":"n.1.2"}]},{"id":"n.2","children":[{"id":"n.2.1"},{"id":"n.2.2"}]}]}}}`,
+		},
+		{
+			name:  "an error nulls the nearest nullable parent",
+			query: `{ greeting node(id: "n") { id broken } }`,
+			want:  `{"data":{"greeting":"hello world","node":null},"errors":[{"message":"broken on n","locations":[{"line":1,"column":31}],"path":["node","broken"]}]}`,
+		},
+		{
+			name:  "an error in a non-null list item nulls the whole list",
+			query: `{ nodes { broken } }`,
+			want:  `{"data":null,"errors":[{"message":"broken on 1","locations":[{"line":1,"column":11}],"path":["nodes",0,"broken"]}]}`,
+		},
+		{
+			name:      "a missing required variable",
+			query:     `query ($id: ID!) { node(id: $id) { id } }`,
+			variables: map[string]any{},
+			want:      `{"errors":[{"message":"variable $id of required type ID! was not provided","locations":[{"line":1,"column":8}]}]}`,
+		},
+		{
+			name:  "mutations are not served",
+			query: `mutation { greeting }`,
+			want:  `{"errors":[{"message":"mutation operations are not supported","locations":[{"line":1,"column":1}]}]}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, _ := run(t, schema, tt.query, tt.variables); got != tt.want {
+				t.Fatalf("got  %s\nwant %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExecuteBatchesLoadsOfALevel(t *testing.T) {
+	fetches := 0
+	schema := testSchema(&fetches)
+	got, result := run(t, schema, `{ nodes { parent { id } } }`, nil)
+	if result.RequestFailed() || len(result.Errors) > 0 {
+		t.Fatalf("Execute: %s", got)
+	}
+	want := `{"data":{"nodes":[{"parent":{"id":"parent of 1"}},{"parent":{"id":"parent of 2"}},{"parent":{"id":"parent of 3"}}]}}`
+	if got != want {
+		t.Fatalf("got  %s\nwant %s", got, want)
+	}
+	if fetches != 1 {
+		t.Fatalf("the parents of one level took %d fetches, want 1", fetches)
+	}
+}
+
+func TestExecuteRequestErrors(t *testing.T) {
+	schema := testSchema(new(int))
+	for _, query := range []string{`{ node(id: 1) { id }`, `{ unknown }`, `query A { greeting } query B { greeting }`} {
+		got, result := run(t, schema, query, nil)
+		if !result.RequestFailed() || len(result.Errors) == 0 {
+			t.Fatalf("Execute(%q): got %s, want a failed request", query, got)
+		}
+	}
+}
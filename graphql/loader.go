@@ -0,0 +1,54 @@
+package graphql
+
+import "context"
+
+// Loader batches and caches lookups by key for one request. Load only queues
+// the key, the returned Thunk fetches every queued key in one call of fetch
+// when it is first forced, which Execute delays until the whole depth of the
+// query has been resolved.
+type Loader[K comparable, V any] struct {
+	fetch   func(ctx context.Context, keys []K) (map[K]V, error)
+	pending []K
+	queued  map[K]bool
+	values  map[K]V
+	errs    map[K]error
+}
+
+// NewLoader wraps a batch fetch, keys missing from its result load as nil
+func NewLoader[K comparable, V any](fetch func(ctx context.Context, keys []K) (map[K]V, error)) *Loader[K, V] {
+	return &Loader[K, V]{fetch: fetch, queued: map[K]bool{}, values: map[K]V{}, errs: map[K]error{}}
+}
+
+func (l *Loader[K, V]) Load(ctx context.Context, key K) Thunk {
+	if !l.queued[key] {
+		l.queued[key] = true
+		l.pending = append(l.pending, key)
+	}
+	return func() (any, error) {
+		if len(l.pending) > 0 {
+			l.dispatch(ctx)
+		}
+		if err, ok := l.errs[key]; ok {
+			return nil, err
+		}
+		if value, ok := l.values[key]; ok {
+			return value, nil
+		}
+		return nil, nil
+	}
+}
+
+func (l *Loader[K, V]) dispatch(ctx context.Context) {
+	keys := l.pending
+	l.pending = nil
+	values, err := l.fetch(ctx, keys)
+	for _, key := range keys {
+		if err != nil {
+			l.errs[key] = err
+			continue
+		}
+		if value, ok := values[key]; ok {
+			l.values[key] = value
+		}
+	}
+}
@@ -0,0 +1,499 @@
+package graphql
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokPunct
+	tokName
+	tokInt
+	tokFloat
+	tokString
+)
+
+type token struct {
+	kind  tokenKind
+	value string
+	loc   Location
+}
+
+// SyntaxError is a malformed document, Loc points at the offending token
+type SyntaxError struct {
+	Message string
+	Loc     Location
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("syntax error at %d:%d: %s", e.Loc.Line, e.Loc.Column, e.Message)
+}
+
+type lexer struct {
+	src       string
+	pos       int
+	line      int
+	lineStart int
+}
+
+func (l *lexer) loc() Location {
+	return Location{Line: l.line, Column: l.pos - l.lineStart + 1}
+}
+
+func (l *lexer) fail(format string, args ...any) {
+	panic(&SyntaxError{Message: fmt.Sprintf(format, args...), Loc: l.loc()})
+}
+
+// skip moves past whitespace, commas and comments, which are all insignificant
+func (l *lexer) skip() {
+	for l.pos < len(l.src) {
+		switch c := l.src[l.pos]; c {
+		case '\n':
+			l.pos++
+			l.line++
+			l.lineStart = l.pos
+		case ' ', '\t', '\r', ',':
+			l.pos++
+		case '#':
+			for l.pos < len(l.src) && l.src[l.pos] != '\n' {
+				l.pos++
+			}
+		default:
+			return
+		}
+	}
+}
+
+func isNameStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func (l *lexer) next() token {
+	l.skip()
+	loc := l.loc()
+	if l.pos >= len(l.src) {
+		return token{kind: tokEOF, loc: loc}
+	}
+	c := l.src[l.pos]
+	switch {
+	case strings.HasPrefix(l.src[l.pos:], "..."):
+		l.pos += 3
+		return token{kind: tokPunct, value: "...", loc: loc}
+	case strings.IndexByte("!$()[]{}:=@|&", c) >= 0:
+		l.pos++
+		return token{kind: tokPunct, value: string(c), loc: loc}
+	case isNameStart(c):
+		start := l.pos
+		for l.pos < len(l.src) && (isNameStart(l.src[l.pos]) || isDigit(l.src[l.pos])) {
+			l.pos++
+		}
+		return token{kind: tokName, value: l.src[start:l.pos], loc: loc}
+	case c == '-' || isDigit(c):
+		return l.number(loc)
+	case c == '"':
+		if strings.HasPrefix(l.src[l.pos:], `"""`) {
+			return token{kind: tokString, value: l.blockString(), loc: loc}
+		}
+		return token{kind: tokString, value: l.string(), loc: loc}
+	}
+	r, _ := utf8.DecodeRuneInString(l.src[l.pos:])
+	l.fail("unexpected character %q", r)
+	return token{}
+}
+
+func (l *lexer) number(loc Location) token {
+	start := l.pos
+	kind := tokInt
+	if l.src[l.pos] == '-' {
+		l.pos++
+	}
+	digits := func() {
+		if l.pos >= len(l.src) || !isDigit(l.src[l.pos]) {
+			l.fail("invalid number")
+		}
+		for l.pos < len(l.src) && isDigit(l.src[l.pos]) {
+			l.pos++
+		}
+	}
+	digits()
+	if l.pos < len(l.src) && l.src[l.pos] == '.' {
+		kind = tokFloat
+		l.pos++
+		digits()
+	}
+	if l.pos < len(l.src) && (l.src[l.pos] == 'e' || l.src[l.pos] == 'E') {
+		kind = tokFloat
+		l.pos++
+		if l.pos < len(l.src) && (l.src[l.pos] == '+' || l.src[l.pos] == '-') {
+			l.pos++
+		}
+		digits()
+	}
+	return token{kind: kind, value: l.src[start:l.pos], loc: loc}
+}
+
+func (l *lexer) string() string {
+	l.pos++
+	var b strings.Builder
+	for {
+		if l.pos >= len(l.src) || l.src[l.pos] == '\n' {
+			l.fail("unterminated string")
+		}
+		c := l.src[l.pos]
+		switch c {
+		case '"':
+			l.pos++
+			return b.String()
+		case '\\':
+			if l.pos+1 >= len(l.src) {
+				l.fail("unterminated string")
+			}
+			l.pos++
+			switch e := l.src[l.pos]; e {
+			case '"', '\\', '/':
+				b.WriteByte(e)
+			case 'b':
+				b.WriteByte('\b')
+			case 'f':
+				b.WriteByte('\f')
+			case 'n':
+				b.WriteByte('\n')
+			case 'r':
+				b.WriteByte('\r')
+			case 't':
+				b.WriteByte('\t')
+			case 'u':
+				if l.pos+4 >= len(l.src) {
+					l.fail("invalid unicode escape")
+				}
+				code, err := strconv.ParseUint(l.src[l.pos+1:l.pos+5], 16, 32)
+				if err != nil {
+					l.fail("invalid unicode escape")
+				}
+				b.WriteRune(rune(code))
+				l.pos += 4
+			default:
+				l.fail("invalid escape \\%c", e)
+			}
+			l.pos++
+		default:
+			b.WriteByte(c)
+			l.pos++
+		}
+	}
+}
+
+// blockString reads a """ string, dropping the common indentation and the
+// blank first and last lines like the spec asks
+func (l *lexer) blockString() string {
+	l.pos += 3
+	end := strings.Index(l.src[l.pos:], `"""`)
+	if end < 0 {
+		l.fail("unterminated block string")
+	}
+	begin := l.pos
+	raw := l.src[begin : begin+end]
+	l.line += strings.Count(raw, "\n")
+	if i := strings.LastIndex(raw, "\n"); i >= 0 {
+		l.lineStart = begin + i + 1
+	}
+	l.pos += end + 3
+
+	lines := strings.Split(strings.ReplaceAll(raw, `\"""`, `"""`), "\n")
+	indent := -1
+	for _, line := range lines[1:] {
+		trimmed := strings.TrimLeft(line, " \t")
+		if trimmed == "" {
+			continue
+		}
+		if n := len(line) - len(trimmed); indent < 0 || n < indent {
+			indent = n
+		}
+	}
+	for i := 1; i < len(lines) && indent > 0; i++ {
+		if len(lines[i]) >= indent {
+			lines[i] = lines[i][indent:]
+		}
+	}
+	for len(lines) > 0 && strings.TrimSpace(lines[0]) == "" {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return strings.Join(lines, "\n")
+}
+
+type parser struct {
+	lex *lexer
+	tok token
+}
+
+// Parse reads an executable document: operations and fragment definitions
+func Parse(query string) (doc *Document, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			syntaxErr, ok := r.(*SyntaxError)
+			if !ok {
+				panic(r)
+			}
+			doc, err = nil, syntaxErr
+		}
+	}()
+
+	p := &parser{lex: &lexer{src: query, line: 1}}
+	p.advance()
+	doc = &Document{Fragments: map[string]*Fragment{}}
+	for p.tok.kind != tokEOF {
+		switch {
+		case p.peek("{"):
+			doc.Operations = append(doc.Operations, &Operation{Type: "query", Loc: p.tok.loc, Selections: p.selectionSet()})
+		case p.peekName("query"), p.peekName("mutation"), p.peekName("subscription"):
+			doc.Operations = append(doc.Operations, p.operation())
+		case p.peekName("fragment"):
+			fragment := p.fragment()
+			if _, ok := doc.Fragments[fragment.Name]; ok {
+				return nil, &SyntaxError{Message: "duplicate fragment " + fragment.Name, Loc: fragment.Loc}
+			}
+			doc.Fragments[fragment.Name] = fragment
+		default:
+			p.unexpected()
+		}
+	}
+	if len(doc.Operations) == 0 {
+		return nil, &SyntaxError{Message: "document has no operation", Loc: p.tok.loc}
+	}
+	return doc, nil
+}
+
+func (p *parser) advance() {
+	p.tok = p.lex.next()
+}
+
+func (p *parser) unexpected() {
+	if p.tok.kind == tokEOF {
+		panic(&SyntaxError{Message: "unexpected end of document", Loc: p.tok.loc})
+	}
+	panic(&SyntaxError{Message: fmt.Sprintf("unexpected %q", p.tok.value), Loc: p.tok.loc})
+}
+
+func (p *parser) peek(punct string) bool {
+	return p.tok.kind == tokPunct && p.tok.value == punct
+}
+
+func (p *parser) peekName(name string) bool {
+	return p.tok.kind == tokName && p.tok.value == name
+}
+
+func (p *parser) expect(punct string) {
+	if !p.peek(punct) {
+		p.unexpected()
+	}
+	p.advance()
+}
+
+func (p *parser) name() string {
+	if p.tok.kind != tokName {
+		p.unexpected()
+	}
+	name := p.tok.value
+	p.advance()
+	return name
+}
+
+func (p *parser) operation() *Operation {
+	op := &Operation{Type: p.tok.value, Loc: p.tok.loc}
+	p.advance()
+	if p.tok.kind == tokName {
+		op.Name = p.name()
+	}
+	if p.peek("(") {
+		p.advance()
+		for !p.peek(")") {
+			op.Variables = append(op.Variables, p.variableDefinition())
+		}
+		p.advance()
+	}
+	op.Directives = p.directives()
+	op.Selections = p.selectionSet()
+	return op
+}
+
+func (p *parser) variableDefinition() *VariableDefinition {
+	def := &VariableDefinition{Loc: p.tok.loc}
+	p.expect("$")
+	def.Name = p.name()
+	p.expect(":")
+	def.Type = p.typeRef()
+	if p.peek("=") {
+		p.advance()
+		def.Default = p.value(true)
+	}
+	p.directives()
+	return def
+}
+
+func (p *parser) typeRef() *TypeRef {
+	var t *TypeRef
+	if p.peek("[") {
+		p.advance()
+		t = &TypeRef{Elem: p.typeRef()}
+		p.expect("]")
+	} else {
+		t = &TypeRef{Name: p.name()}
+	}
+	if p.peek("!") {
+		p.advance()
+		t.NonNull = true
+	}
+	return t
+}
+
+func (p *parser) fragment() *Fragment {
+	fragment := &Fragment{Loc: p.tok.loc}
+	p.advance()
+	fragment.Name = p.name()
+	if fragment.Name == "on" {
+		p.unexpected()
+	}
+	if !p.peekName("on") {
+		p.unexpected()
+	}
+	p.advance()
+	fragment.TypeCondition = p.name()
+	fragment.Directives = p.directives()
+	fragment.Selections = p.selectionSet()
+	return fragment
+}
+
+func (p *parser) selectionSet() []Selection {
+	p.expect("{")
+	selections := []Selection{}
+	for !p.peek("}") {
+		selections = append(selections, p.selection())
+	}
+	p.advance()
+	return selections
+}
+
+func (p *parser) selection() Selection {
+	loc := p.tok.loc
+	if p.peek("...") {
+		p.advance()
+		if p.tok.kind == tokName && p.tok.value != "on" {
+			return &FragmentSpread{Name: p.name(), Directives: p.directives(), Loc: loc}
+		}
+		inline := &InlineFragment{Loc: loc}
+		if p.peekName("on") {
+			p.advance()
+			inline.TypeCondition = p.name()
+		}
+		inline.Directives = p.directives()
+		inline.Selections = p.selectionSet()
+		return inline
+	}
+
+	field := &Field{Name: p.name(), Loc: loc}
+	if p.peek(":") {
+		p.advance()
+		field.Alias, field.Name = field.Name, p.name()
+	}
+	field.Arguments = p.arguments(false)
+	field.Directives = p.directives()
+	if p.peek("{") {
+		field.Selections = p.selectionSet()
+	}
+	return field
+}
+
+func (p *parser) arguments(constant bool) []*Argument {
+	if !p.peek("(") {
+		return nil
+	}
+	p.advance()
+	args := []*Argument{}
+	for !p.peek(")") {
+		arg := &Argument{Loc: p.tok.loc, Name: p.name()}
+		p.expect(":")
+		arg.Value = p.value(constant)
+		args = append(args, arg)
+	}
+	p.advance()
+	return args
+}
+
+func (p *parser) directives() []*Directive {
+	var directives []*Directive
+	for p.peek("@") {
+		loc := p.tok.loc
+		p.advance()
+		directives = append(directives, &Directive{Name: p.name(), Arguments: p.arguments(false), Loc: loc})
+	}
+	return directives
+}
+
+// value reads a literal, constant values such as variable defaults can not refer to variables
+func (p *parser) value(constant bool) *Value {
+	v := &Value{Loc: p.tok.loc}
+	switch p.tok.kind {
+	case tokInt:
+		v.Kind, v.Raw = IntValue, p.tok.value
+	case tokFloat:
+		v.Kind, v.Raw = FloatValue, p.tok.value
+	case tokString:
+		v.Kind, v.Raw = StringValue, p.tok.value
+	case tokName:
+		switch p.tok.value {
+		case "true", "false":
+			v.Kind = BooleanValue
+		case "null":
+			v.Kind = NullValue
+		default:
+			v.Kind = EnumValue
+		}
+		v.Raw = p.tok.value
+	case tokPunct:
+		switch p.tok.value {
+		case "$":
+			if constant {
+				p.unexpected()
+			}
+			p.advance()
+			v.Kind, v.Raw = VariableValue, p.name()
+			return v
+		case "[":
+			p.advance()
+			v.Kind, v.List = ListValue, []*Value{}
+			for !p.peek("]") {
+				v.List = append(v.List, p.value(constant))
+			}
+			p.advance()
+			return v
+		case "{":
+			p.advance()
+			v.Kind = ObjectValue
+			for !p.peek("}") {
+				name := p.name()
+				p.expect(":")
+				v.Fields = append(v.Fields, &ObjectField{Name: name, Value: p.value(constant)})
+			}
+			p.advance()
+			return v
+		default:
+			p.unexpected()
+		}
+	default:
+		p.unexpected()
+	}
+	p.advance()
+	return v
+}
@@ -0,0 +1,99 @@
+package graphql
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	doc, err := Parse(`
+		# a comment
+		query Groups($first: Int = 10, $ids: [ID!]!) @include(if: true) {
+			groups(first: $first) { nodes { ...group name: title } }
+		}
+		fragment group on Group { id, amount(unit: "cents", scale: 1.5, tags: [A, B], filter: {open: null}) }
+		{ me { id } }
+	`)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if len(doc.Operations) != 2 || len(doc.Fragments) != 1 {
+		t.Fatalf("Parse: got %d operations and %d fragments, want 2 and 1", len(doc.Operations), len(doc.Fragments))
+	}
+
+	op := doc.Operations[0]
+	if op.Type != "query" || op.Name != "Groups" || len(op.Directives) != 1 {
+		t.Fatalf("operation: got %+v", op)
+	}
+	if len(op.Variables) != 2 || op.Variables[0].Default.Raw != "10" || op.Variables[1].Type.String() != "[ID!]!" {
+		t.Fatalf("variables: got %+v %+v", op.Variables[0], op.Variables[1])
+	}
+	groups := op.Selections[0].(*Field)
+	if groups.Arguments[0].Value.Kind != VariableValue || groups.Arguments[0].Value.Raw != "first" {
+		t.Fatalf("argument: got %+v", groups.Arguments[0].Value)
+	}
+	nodes := groups.Selections[0].(*Field)
+	if spread, ok := nodes.Selections[0].(*FragmentSpread); !ok || spread.Name != "group" {
+		t.Fatalf("fragment spread: got %+v", nodes.Selections[0])
+	}
+	if aliased := nodes.Selections[1].(*Field); aliased.ResponseKey() != "name" || aliased.Name != "title" {
+		t.Fatalf("alias: got %+v", aliased)
+	}
+
+	args := doc.Fragments["group"].Selections[1].(*Field).Arguments
+	kinds := []ValueKind{StringValue, FloatValue, ListValue, ObjectValue}
+	for i, kind := range kinds {
+		if args[i].Value.Kind != kind {
+			t.Fatalf("argument %s: got kind %d, want %d", args[i].Name, args[i].Value.Kind, kind)
+		}
+	}
+	if doc.Operations[1].Type != "query" || doc.Operations[1].Name != "" {
+		t.Fatalf("shorthand query: got %+v", doc.Operations[1])
+	}
+}
+
+func TestParseStrings(t *testing.T) {
+	doc, err := Parse(`{ a(s: "tab\there é \"q\"") b(s: """
+		block "quoted"
+		  indented
+	""") }`)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	fields := doc.Operations[0].Selections
+	if got := fields[0].(*Field).Arguments[0].Value.Raw; got != "tab\there é \"q\"" {
+		t.Fatalf("string: got %q", got)
+	}
+	if got := fields[1].(*Field).Arguments[0].Value.Raw; got != "block \"quoted\"\n  indented" {
+		t.Fatalf("block string: got %q", got)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		line  int
+		col   int
+	}{
+		{"empty", "", 1, 1},
+		{"unclosed selection", "{ me { id }", 1, 12},
+		{"missing argument name", "{ me(: 1) }", 1, 6},
+		{"bad token", "{\n  me ^ }", 2, 6},
+		{"unterminated string", `{ a(s: "open) }`, 1, 16},
+		{"duplicate fragment", "{ me }\nfragment f on A { id }\nfragment f on A { id }", 3, 1},
+		{"only fragments", "fragment f on A { id }", 1, 23},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tt.query)
+			var syntaxErr *SyntaxError
+			if !errors.As(err, &syntaxErr) {
+				t.Fatalf("Parse(%q): got %v, want a SyntaxError", tt.query, err)
+			}
+			if syntaxErr.Loc.Line != tt.line || syntaxErr.Loc.Column != tt.col {
+				t.Fatalf("Parse(%q): got %v at %d:%d, want %d:%d", tt.query, syntaxErr, syntaxErr.Loc.Line, syntaxErr.Loc.Column, tt.line, tt.col)
+			}
+		})
+	}
+}
@@ -0,0 +1,273 @@
+package graphql
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Type is a scalar, an object or a List or NonNull wrapping one. Interfaces,
+// unions, enums and input objects are not supported, the schema does not need them.
+type Type interface {
+	String() string
+}
+
+type Scalar struct {
+	Name string
+	// Serialize turns a resolved Go value into its JSON form
+	Serialize func(value any) (any, error)
+	// Parse coerces an argument, from a literal or a JSON variable
+	Parse func(value any) (any, error)
+}
+
+func (s *Scalar) String() string { return s.Name }
+
+type List struct {
+	Of Type
+}
+
+func (l *List) String() string { return "[" + l.Of.String() + "]" }
+
+type NonNull struct {
+	Of Type
+}
+
+func (n *NonNull) String() string { return n.Of.String() + "!" }
+
+func ListOf(t Type) *List       { return &List{Of: t} }
+func NonNullOf(t Type) *NonNull { return &NonNull{Of: t} }
+func NonNullListOf(t Type) Type { return NonNullOf(ListOf(NonNullOf(t))) }
+
+func isNonNull(t Type) bool {
+	_, ok := t.(*NonNull)
+	return ok
+}
+
+// namedType strips the List and NonNull wrappers
+func namedType(t Type) Type {
+	for {
+		switch w := t.(type) {
+		case *NonNull:
+			t = w.Of
+		case *List:
+			t = w.Of
+		default:
+			return t
+		}
+	}
+}
+
+type Object struct {
+	Name        string
+	Description string
+	// Fields is filled after the object is declared so types can refer to each other
+	Fields map[string]*FieldDef
+}
+
+func (o *Object) String() string { return o.Name }
+
+// ResolveParams is what a resolver gets: the value of the parent object and
+// the coerced arguments
+type ResolveParams struct {
+	Context context.Context
+	Source  any
+	Args    map[string]any
+}
+
+// ResolveFunc returns the field value, or a Thunk to resolve it after every
+// field of the same depth has been visited. Loaders rely on that to batch.
+type ResolveFunc func(p ResolveParams) (any, error)
+
+// Thunk is a deferred field value
+type Thunk func() (any, error)
+
+type FieldDef struct {
+	Type        Type
+	Description string
+	Args        []*Arg
+	Resolve     ResolveFunc
+}
+
+type Arg struct {
+	Name        string
+	Type        Type
+	Default     any
+	Description string
+}
+
+func (f *FieldDef) arg(name string) *Arg {
+	for _, a := range f.Args {
+		if a.Name == name {
+			return a
+		}
+	}
+	return nil
+}
+
+type Schema struct {
+	Query *Object
+	// Mutation is optional, without it mutation operations are rejected
+	Mutation *Object
+	// MaxDepth bounds how deeply an operation nests fields and MaxComplexity
+	// how many fields it selects with its fragments expanded, zero leaves
+	// them unbounded
+	MaxDepth      int
+	MaxComplexity int
+}
+
+// SDL prints the schema in the schema definition language, it documents the
+// endpoint since introspection is not supported
+func (s *Schema) SDL() string {
+	objects := map[string]*Object{}
+	scalars := map[string]bool{}
+	var visit func(o *Object)
+	visit = func(o *Object) {
+		if o == nil || objects[o.Name] != nil {
+			return
+		}
+		objects[o.Name] = o
+		for _, f := range o.Fields {
+			for _, a := range f.Args {
+				if sc, ok := namedType(a.Type).(*Scalar); ok {
+					scalars[sc.Name] = true
+				}
+			}
+			switch t := namedType(f.Type).(type) {
+			case *Object:
+				visit(t)
+			case *Scalar:
+				scalars[t.Name] = true
+			}
+		}
+	}
+	visit(s.Query)
+	visit(s.Mutation)
+
+	b := &strings.Builder{}
+	b.WriteString("schema {\n  query: " + s.Query.Name + "\n")
+	if s.Mutation != nil {
+		b.WriteString("  mutation: " + s.Mutation.Name + "\n")
+	}
+	b.WriteString("}\n")
+
+	for _, name := range sortedKeys(scalars) {
+		if !builtinScalars[name] {
+			fmt.Fprintf(b, "\nscalar %s\n", name)
+		}
+	}
+	for _, name := range sortedKeys(objects) {
+		o := objects[name]
+		b.WriteString("\n")
+		writeDescription(b, o.Description, "")
+		fmt.Fprintf(b, "type %s {\n", o.Name)
+		for _, fieldName := range sortedKeys(o.Fields) {
+			f := o.Fields[fieldName]
+			writeDescription(b, f.Description, "  ")
+			b.WriteString("  " + fieldName)
+			if len(f.Args) > 0 {
+				args := []string{}
+				for _, a := range f.Args {
+					arg := a.Name + ": " + a.Type.String()
+					if a.Default != nil {
+						arg += fmt.Sprintf(" = %v", a.Default)
+					}
+					args = append(args, arg)
+				}
+				b.WriteString("(" + strings.Join(args, ", ") + ")")
+			}
+			b.WriteString(": " + f.Type.String() + "\n")
+		}
+		b.WriteString("}\n")
+	}
+	return b.String()
+}
+
+func writeDescription(b *strings.Builder, description, indent string) {
+	if description != "" {
+		fmt.Fprintf(b, "%s\"\"\"%s\"\"\"\n", indent, description)
+	}
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+var builtinScalars = map[string]bool{"String": true, "Int": true, "Float": true, "Boolean": true, "ID": true}
+
+func coerceInt(value any) (any, error) {
+	switch v := value.(type) {
+	case int:
+		return v, nil
+	case int32:
+		return int(v), nil
+	case int64:
+		if v > math.MaxInt32 || v < math.MinInt32 {
+			return nil, fmt.Errorf("Int can not represent %d", v)
+		}
+		return int(v), nil
+	case float64:
+		if v != math.Trunc(v) || v > math.MaxInt32 || v < math.MinInt32 {
+			return nil, fmt.Errorf("Int can not represent %v", v)
+		}
+		return int(v), nil
+	}
+	return nil, fmt.Errorf("Int can not represent %v", value)
+}
+
+func coerceFloat(value any) (any, error) {
+	switch v := value.(type) {
+	case float64:
+		return v, nil
+	case float32:
+		return float64(v), nil
+	case int:
+		return float64(v), nil
+	case int32:
+		return float64(v), nil
+	case int64:
+		return float64(v), nil
+	}
+	return nil, fmt.Errorf("Float can not represent %v", value)
+}
+
+func coerceString(value any) (any, error) {
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case fmt.Stringer:
+		return v.String(), nil
+	}
+	if v := reflect.ValueOf(value); v.Kind() == reflect.String {
+		return v.String(), nil
+	}
+	return nil, fmt.Errorf("String can not represent %v", value)
+}
+
+func coerceBoolean(value any) (any, error) {
+	if v, ok := value.(bool); ok {
+		return v, nil
+	}
+	return nil, fmt.Errorf("Boolean can not represent %v", value)
+}
+
+var (
+	Int     = &Scalar{Name: "Int", Serialize: coerceInt, Parse: coerceInt}
+	Float   = &Scalar{Name: "Float", Serialize: coerceFloat, Parse: coerceFloat}
+	String  = &Scalar{Name: "String", Serialize: coerceString, Parse: coerceString}
+	Boolean = &Scalar{Name: "Boolean", Serialize: coerceBoolean, Parse: coerceBoolean}
+	// ID is serialized as a string, it also accepts integers as input
+	ID = &Scalar{Name: "ID", Serialize: coerceString, Parse: func(value any) (any, error) {
+		if n, err := coerceInt(value); err == nil {
+			return fmt.Sprint(n), nil
+		}
+		return coerceString(value)
+	}}
+)
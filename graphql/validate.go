@@ -0,0 +1,185 @@
+package graphql
+
+import "fmt"
+
+// validate checks the operation against the schema before anything runs:
+// fields and arguments exist, required arguments are given, objects have a
+// selection and scalars have none, fragments exist, apply to the type and do
+// not form cycles, every variable used is defined and the operation stays
+// within the depth and complexity limits of the schema.
+func validate(doc *Document, op *Operation, schema *Schema, root *Object) []*Error {
+	v := &validator{doc: doc, defined: map[string]bool{}, spreading: map[string]bool{}, expanded: map[string]cost{}}
+	for _, def := range op.Variables {
+		if v.defined[def.Name] {
+			v.fail(def.Loc, "variable $%s is defined more than once", def.Name)
+		}
+		v.defined[def.Name] = true
+	}
+	v.directives(op.Directives)
+	c := v.selections(root, op.Selections)
+	if schema.MaxDepth > 0 && c.depth > schema.MaxDepth {
+		v.fail(op.Loc, "the operation is %d fields deep, at most %d are allowed", c.depth, schema.MaxDepth)
+	}
+	if schema.MaxComplexity > 0 && c.complexity > schema.MaxComplexity {
+		v.fail(op.Loc, "the operation selects %d fields, at most %d are allowed", c.complexity, schema.MaxComplexity)
+	}
+	return v.errors
+}
+
+// maxCost caps the counted complexity, fragments spread several times in a
+// row grow it exponentially
+const maxCost = 1 << 30
+
+// cost is how deep a selection goes and how many fields it selects once its
+// fragments are expanded
+type cost struct {
+	depth      int
+	complexity int
+}
+
+func (c cost) add(other cost) cost {
+	c.depth = max(c.depth, other.depth)
+	c.complexity = min(c.complexity+other.complexity, maxCost)
+	return c
+}
+
+type validator struct {
+	doc     *Document
+	defined map[string]bool
+	errors  []*Error
+	// spreading are the fragments being expanded, expanded the cost of those
+	// already checked, so each fragment is walked once however often it is spread
+	spreading map[string]bool
+	expanded  map[string]cost
+}
+
+func (v *validator) fail(loc Location, format string, args ...any) {
+	v.errors = append(v.errors, &Error{Message: fmt.Sprintf(format, args...), Locations: []Location{loc}})
+}
+
+func (v *validator) selections(obj *Object, selections []Selection) cost {
+	var total cost
+	seen := map[string]string{}
+	for _, sel := range selections {
+		switch s := sel.(type) {
+		case *Field:
+			total = total.add(v.field(obj, s))
+			if name, ok := seen[s.ResponseKey()]; ok && name != s.Name {
+				v.fail(s.Loc, "fields %s and %s both answer %q, use different aliases", name, s.Name, s.ResponseKey())
+			}
+			seen[s.ResponseKey()] = s.Name
+		case *InlineFragment:
+			v.directives(s.Directives)
+			if s.TypeCondition != "" && s.TypeCondition != obj.Name {
+				v.fail(s.Loc, "fragment on %s can not be spread on %s", s.TypeCondition, obj.Name)
+				continue
+			}
+			total = total.add(v.selections(obj, s.Selections))
+		case *FragmentSpread:
+			v.directives(s.Directives)
+			fragment, ok := v.doc.Fragments[s.Name]
+			if !ok {
+				v.fail(s.Loc, "unknown fragment %s", s.Name)
+				continue
+			}
+			if v.spreading[s.Name] {
+				v.fail(s.Loc, "fragment %s spreads itself", s.Name)
+				continue
+			}
+			if fragment.TypeCondition != obj.Name {
+				v.fail(s.Loc, "fragment %s on %s can not be spread on %s", s.Name, fragment.TypeCondition, obj.Name)
+				continue
+			}
+			c, ok := v.expanded[s.Name]
+			if !ok {
+				v.spreading[s.Name] = true
+				c = v.selections(obj, fragment.Selections)
+				delete(v.spreading, s.Name)
+				v.expanded[s.Name] = c
+			}
+			total = total.add(c)
+		}
+	}
+	return total
+}
+
+// field checks a selected field, which counts one level and one field
+func (v *validator) field(obj *Object, f *Field) cost {
+	c := cost{depth: 1, complexity: 1}
+	v.directives(f.Directives)
+	if f.Name == "__typename" {
+		if len(f.Selections) > 0 {
+			v.fail(f.Loc, "__typename has no fields to select")
+		}
+		return c
+	}
+	def, ok := obj.Fields[f.Name]
+	if !ok {
+		v.fail(f.Loc, "cannot query field %q on type %s", f.Name, obj.Name)
+		return c
+	}
+
+	given := map[string]bool{}
+	for _, arg := range f.Arguments {
+		if def.arg(arg.Name) == nil {
+			v.fail(arg.Loc, "unknown argument %q on field %s.%s", arg.Name, obj.Name, f.Name)
+		}
+		if given[arg.Name] {
+			v.fail(arg.Loc, "argument %q is given more than once", arg.Name)
+		}
+		given[arg.Name] = true
+		v.value(arg.Value)
+	}
+	for _, a := range def.Args {
+		if isNonNull(a.Type) && a.Default == nil && !given[a.Name] {
+			v.fail(f.Loc, "field %s.%s requires argument %s of type %s", obj.Name, f.Name, a.Name, a.Type)
+		}
+	}
+
+	switch t := namedType(def.Type).(type) {
+	case *Object:
+		if len(f.Selections) == 0 {
+			v.fail(f.Loc, "field %s of type %s must have a selection of subfields", f.Name, def.Type)
+			return c
+		}
+		sub := v.selections(t, f.Selections)
+		c.depth += sub.depth
+		c.complexity = min(c.complexity+sub.complexity, maxCost)
+	default:
+		if len(f.Selections) > 0 {
+			v.fail(f.Loc, "field %s of type %s has no subfields to select", f.Name, def.Type)
+		}
+	}
+	return c
+}
+
+func (v *validator) directives(directives []*Directive) {
+	for _, d := range directives {
+		if d.Name != "skip" && d.Name != "include" {
+			v.fail(d.Loc, "unknown directive @%s", d.Name)
+			continue
+		}
+		if len(d.Arguments) != 1 || d.Arguments[0].Name != "if" {
+			v.fail(d.Loc, "@%s takes exactly one argument, if", d.Name)
+			continue
+		}
+		v.value(d.Arguments[0].Value)
+	}
+}
+
+func (v *validator) value(value *Value) {
+	switch value.Kind {
+	case VariableValue:
+		if !v.defined[value.Raw] {
+			v.fail(value.Loc, "variable $%s is not defined", value.Raw)
+		}
+	case ListValue:
+		for _, item := range value.List {
+			v.value(item)
+		}
+	case ObjectValue:
+		for _, field := range value.Fields {
+			v.value(field.Value)
+		}
+	}
+}
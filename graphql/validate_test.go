@@ -0,0 +1,101 @@
+package graphql
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func validateQuery(t *testing.T, schema *Schema, query string) []*Error {
+	t.Helper()
+	doc, err := Parse(query)
+	if err != nil {
+		t.Fatalf("Parse(%q): %v", query, err)
+	}
+	return validate(doc, doc.Operations[0], schema, schema.Query)
+}
+
+func TestValidate(t *testing.T) {
+	schema := testSchema(new(int))
+	tests := []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{"valid", `query ($id: ID!) { node(id: $id) { ...f ... on Node { id } } } fragment f on Node { children { id } }`, ""},
+		{"unknown field", `{ missing }`, `cannot query field "missing" on type Query`},
+		{"unknown argument", `{ greeting(nickname: "a") }`, `unknown argument "nickname" on field Query.greeting`},
+		{"repeated argument", `{ greeting(name: "a", name: "b") }`, `argument "name" is given more than once`},
+		{"required argument", `{ node { id } }`, `field Query.node requires argument id of type ID!`},
+		{"object without selection", `{ node(id: 1) }`, `field node of type Node must have a selection of subfields`},
+		{"scalar with selection", `{ greeting { id } }`, `field greeting of type String! has no subfields to select`},
+		{"conflicting aliases", `{ node(id: 1) { x: id x: broken } }`, `fields id and broken both answer "x", use different aliases`},
+		{"unknown fragment", `{ node(id: 1) { ...f } }`, `unknown fragment f`},
+		{"fragment on another type", `{ ...f } fragment f on Node { id }`, `fragment f on Node can not be spread on Query`},
+		{"fragment cycle", `{ node(id: 1) { ...a } } fragment a on Node { ...b } fragment b on Node { ...a }`, `fragment a spreads itself`},
+		{"undefined variable", `{ node(id: $id) { id } }`, `variable $id is not defined`},
+		{"unknown directive", `{ greeting @cached }`, `unknown directive @cached`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := validateQuery(t, schema, tt.query)
+			if tt.want == "" {
+				if len(errs) > 0 {
+					t.Fatalf("validate: got %s, want no errors", errs[0].Message)
+				}
+				return
+			}
+			if len(errs) == 0 || errs[0].Message != tt.want {
+				t.Fatalf("validate: got %+v, want %q", errs, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateLimits(t *testing.T) {
+	schema := testSchema(new(int))
+	schema.MaxDepth = 3
+	schema.MaxComplexity = 6
+
+	if errs := validateQuery(t, schema, `{ node(id: 1) { children { id } } }`); len(errs) > 0 {
+		t.Fatalf("query at the depth limit: %s", errs[0].Message)
+	}
+	errs := validateQuery(t, schema, `{ node(id: 1) { children { children { id } } } }`)
+	if len(errs) != 1 || errs[0].Message != "the operation is 4 fields deep, at most 3 are allowed" {
+		t.Fatalf("query over the depth limit: got %+v", errs)
+	}
+
+	// the fragment counts every time it is spread
+	errs = validateQuery(t, schema, `{ a: node(id: 1) { ...f } b: node(id: 2) { ...f } } fragment f on Node { id children { id } }`)
+	if len(errs) != 1 || errs[0].Message != "the operation selects 8 fields, at most 6 are allowed" {
+		t.Fatalf("query over the complexity limit: got %+v", errs)
+	}
+}
+
+// TestValidateFragmentFanOut spreads each fragment twice in the next, which
+// expands to 2^30 fields. Validation walks each fragment once and stops it.
+func TestValidateFragmentFanOut(t *testing.T) {
+	schema := testSchema(new(int))
+	schema.MaxComplexity = 1000
+
+	var query strings.Builder
+	query.WriteString("{ node(id: 1) { ...f0 } }\nfragment f0 on Node { id }\n")
+	for i := 1; i <= 30; i++ {
+		fmt.Fprintf(&query, "fragment f%d on Node { ...f%d children { ...f%d } }\n", i, i-1, i-1)
+	}
+	query.WriteString("query Deep { node(id: 1) { ...f30 } }")
+
+	doc, err := Parse(query.String())
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	start := time.Now()
+	errs := validate(doc, doc.Operations[1], schema, schema.Query)
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("validate took %v", elapsed)
+	}
+	if len(errs) != 1 || !strings.HasPrefix(errs[0].Message, "the operation selects") {
+		t.Fatalf("validate: got %+v, want the complexity error", errs)
+	}
+}
@@ -0,0 +1,188 @@
+package importer
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Mapping names the columns of a generic CSV, header names match case-insensitively.
+//
+// The paid by column holds one name, who paid the whole amount, or
+// name:amount pairs separated by semicolons. The split column holds names
+// separated by semicolons to share the amount evenly, or name:amount pairs.
+type Mapping struct {
+	Date        string `json:"date"`
+	Description string `json:"description"`
+	Category    string `json:"category"`
+	Amount      string `json:"amount"`
+	PaidBy      string `json:"paidBy"`
+	Split       string `json:"split"`
+	// DateFormat is a Go time layout, dates are 2006-01-02 or RFC 3339 by default
+	DateFormat string `json:"dateFormat"`
+}
+
+// DefaultMapping reads a CSV with the columns date, description, category, amount, paid by and split
+var DefaultMapping = Mapping{
+	Date:        "date",
+	Description: "description",
+	Category:    "category",
+	Amount:      "amount",
+	PaidBy:      "paid by",
+	Split:       "split",
+}
+
+// ParseMapping reads a spec such as "date=When,amount=Total,paidBy=Payer",
+// keys left out keep the column of DefaultMapping
+func ParseMapping(spec string) (Mapping, error) {
+	mapping := DefaultMapping
+	targets := map[string]*string{
+		"date":        &mapping.Date,
+		"description": &mapping.Description,
+		"category":    &mapping.Category,
+		"amount":      &mapping.Amount,
+		"paidby":      &mapping.PaidBy,
+		"split":       &mapping.Split,
+		"dateformat":  &mapping.DateFormat,
+	}
+	for _, item := range strings.Split(spec, ",") {
+		if strings.TrimSpace(item) == "" {
+			continue
+		}
+		key, column, ok := strings.Cut(item, "=")
+		if !ok {
+			return mapping, fmt.Errorf("expected key=column in the mapping, got %q", item)
+		}
+		target, ok := targets[strings.ToLower(strings.TrimSpace(key))]
+		if !ok {
+			return mapping, fmt.Errorf("unknown mapping key %q, expected date, description, category, amount, paidBy, split or dateFormat", key)
+		}
+		*target = strings.TrimSpace(column)
+	}
+	return mapping, nil
+}
+
+// ReadCSV reads a CSV laid out as mapping describes
+func ReadCSV(r io.Reader, mapping Mapping) (*File, error) {
+	reader := newReader(r)
+	header, err := readHeader(reader)
+	if err != nil {
+		return nil, err
+	}
+	index := func(column string) int {
+		for i, name := range header {
+			if column != "" && strings.EqualFold(name, column) {
+				return i
+			}
+		}
+		return -1
+	}
+	columns := map[string]int{}
+	for key, column := range map[string]string{"date": mapping.Date, "amount": mapping.Amount, "paid by": mapping.PaidBy, "split": mapping.Split} {
+		if columns[key] = index(column); columns[key] < 0 {
+			return nil, fmt.Errorf("the file has no %s column %q", key, column)
+		}
+	}
+	description, category := index(mapping.Description), index(mapping.Category)
+
+	layouts := []string{time.DateOnly, time.RFC3339}
+	if mapping.DateFormat != "" {
+		layouts = []string{mapping.DateFormat}
+	}
+
+	file := &File{}
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := reader.FieldPos(0)
+		if blank(record) {
+			continue
+		}
+
+		date, err := parseDate(field(record, columns["date"]), layouts...)
+		if err != nil {
+			file.problem(line, "%s", err)
+			continue
+		}
+		amount, err := cents(field(record, columns["amount"]))
+		if err != nil {
+			file.problem(line, "%s", err)
+			continue
+		}
+		if amount <= 0 {
+			file.problem(line, "amount should be more than 0")
+			continue
+		}
+		paid, err := parseAmounts(field(record, columns["paid by"]), amount)
+		if err != nil {
+			file.problem(line, "paid by: %s", err)
+			continue
+		}
+		shares, err := parseAmounts(field(record, columns["split"]), amount)
+		if err != nil {
+			file.problem(line, "split: %s", err)
+			continue
+		}
+
+		file.add(Entry{
+			Line:        line,
+			Date:        date,
+			Description: field(record, description),
+			Category:    field(record, category),
+			Amount:      float64(amount) / 100,
+			Paid:        toAmounts(paid),
+			Shares:      toAmounts(shares),
+		})
+	}
+	file.collectPeople()
+	return file, nil
+}
+
+// parseAmounts reads "name;name" as an even division of total and
+// "name:amount;name:amount" as given, the amounts have to add up to total
+func parseAmounts(value string, total int64) (map[string]int64, error) {
+	names := []string{}
+	amounts := map[string]int64{}
+	for _, item := range strings.Split(value, ";") {
+		if strings.TrimSpace(item) == "" {
+			continue
+		}
+		name, raw, hasAmount := strings.Cut(item, ":")
+		name = strings.TrimSpace(name)
+		if (hasAmount && len(names) > 0) || (!hasAmount && len(amounts) > 0) {
+			return nil, fmt.Errorf("give an amount for everyone or for nobody")
+		}
+		if !hasAmount {
+			if !slices.Contains(names, name) {
+				names = append(names, name)
+			}
+			continue
+		}
+		amount, err := cents(raw)
+		if err != nil {
+			return nil, err
+		}
+		amounts[name] += amount
+	}
+	if len(names) > 0 {
+		return spread(total, names), nil
+	}
+	if len(amounts) == 0 {
+		return nil, fmt.Errorf("nobody given")
+	}
+	var sum int64
+	for _, amount := range amounts {
+		sum += amount
+	}
+	if sum != total {
+		return nil, fmt.Errorf("amounts add up to %s instead of %s", money(sum), money(total))
+	}
+	return amounts, nil
+}
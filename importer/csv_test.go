@@ -0,0 +1,121 @@
+package importer
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const csvHeader = "date,description,category,amount,paid by,split\n"
+
+func TestReadCSV(t *testing.T) {
+	tests := []struct {
+		name    string
+		row     string
+		problem string
+		paid    map[string]float64
+		shares  map[string]float64
+	}{
+		{
+			name:   "even split",
+			row:    "2024-01-02,Dinner,Food,10.00,Alice,Alice;Bob;Carol",
+			paid:   map[string]float64{"Alice": 10},
+			shares: map[string]float64{"Alice": 3.34, "Bob": 3.33, "Carol": 3.33},
+		},
+		{
+			name:   "amounts given",
+			row:    `2024-01-02,Taxi,,"1,234.50",Alice:1000;Bob:234.50,Alice:600;Bob:634.50`,
+			paid:   map[string]float64{"Alice": 1000, "Bob": 234.5},
+			shares: map[string]float64{"Alice": 600, "Bob": 634.5},
+		},
+		{name: "invalid date", row: "02/01/2024,Dinner,Food,10,Alice,Alice;Bob", problem: `invalid date "02/01/2024"`},
+		{name: "invalid amount", row: "2024-01-02,Dinner,Food,ten,Alice,Alice;Bob", problem: `invalid amount "ten"`},
+		{name: "zero amount", row: "2024-01-02,Dinner,Food,0,Alice,Alice;Bob", problem: "amount should be more than 0"},
+		{name: "missing fields", row: "2024-01-02,Dinner,Food,10", problem: "paid by: nobody given"},
+		{name: "mixed split", row: "2024-01-02,Dinner,Food,10,Alice,Alice:5;Bob", problem: "split: give an amount for everyone or for nobody"},
+		{name: "invalid share", row: "2024-01-02,Dinner,Food,10,Alice,Alice:5;Bob:five", problem: `split: invalid amount "five"`},
+		{name: "shares short of the amount", row: "2024-01-02,Dinner,Food,10,Alice,Alice:3;Bob:3", problem: "split: amounts add up to 6.00 instead of 10.00"},
+		{name: "payments over the amount", row: "2024-01-02,Dinner,Food,10,Alice:8;Bob:8,Alice;Bob", problem: "paid by: amounts add up to 16.00 instead of 10.00"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file, err := ReadCSV(strings.NewReader(csvHeader+tt.row+"\n"), DefaultMapping)
+			if err != nil {
+				t.Fatalf("ReadCSV: %v", err)
+			}
+			if tt.problem != "" {
+				want := []Problem{{Line: 2, Message: tt.problem}}
+				if !reflect.DeepEqual(file.Problems, want) || len(file.Entries) != 0 {
+					t.Fatalf("ReadCSV: got problems %v and %d entries, want %v", file.Problems, len(file.Entries), want)
+				}
+				return
+			}
+			if len(file.Problems) != 0 || len(file.Entries) != 1 {
+				t.Fatalf("ReadCSV: got problems %v and %d entries, want one entry", file.Problems, len(file.Entries))
+			}
+			entry := file.Entries[0]
+			if !reflect.DeepEqual(entry.Paid, tt.paid) || !reflect.DeepEqual(entry.Shares, tt.shares) {
+				t.Fatalf("ReadCSV: got paid %v shares %v, want %v %v", entry.Paid, entry.Shares, tt.paid, tt.shares)
+			}
+		})
+	}
+}
+
+func TestReadCSVFile(t *testing.T) {
+	t.Run("problems keep their line", func(t *testing.T) {
+		input := csvHeader +
+			"2024-01-02,Dinner,Food,10,Alice,Alice;Bob\n" +
+			"\n" +
+			"2024-01-03,Lunch,Food,abc,Bob,Alice;Bob\n" +
+			"2024-01-04,Taxi,,6,Carol,Bob;Carol\n"
+		file, err := ReadCSV(strings.NewReader(input), DefaultMapping)
+		if err != nil {
+			t.Fatalf("ReadCSV: %v", err)
+		}
+		if want := []Problem{{Line: 4, Message: `invalid amount "abc"`}}; !reflect.DeepEqual(file.Problems, want) {
+			t.Fatalf("ReadCSV problems: got %v, want %v", file.Problems, want)
+		}
+		if len(file.Entries) != 2 || file.Entries[0].Line != 2 || file.Entries[1].Line != 5 {
+			t.Fatalf("ReadCSV entries: got %+v", file.Entries)
+		}
+		if want := []string{"Alice", "Bob", "Carol"}; !reflect.DeepEqual(file.People, want) {
+			t.Fatalf("ReadCSV people: got %v, want %v", file.People, want)
+		}
+	})
+
+	tests := []struct {
+		name  string
+		input string
+		err   string
+	}{
+		{"empty file", "", "the file is empty"},
+		{"missing column", "date,description,amount,split\n", `the file has no paid by column "paid by"`},
+		{"broken quoting", csvHeader + "2024-01-02,\"Dinner,Food,10,Alice,Alice\n", "extraneous or missing \" in quoted-field"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ReadCSV(strings.NewReader(tt.input), DefaultMapping)
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Fatalf("ReadCSV: got %v, want %q", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestParseMapping(t *testing.T) {
+	mapping, err := ParseMapping("date=When, amount=Total,paidBy=Payer")
+	if err != nil {
+		t.Fatalf("ParseMapping: %v", err)
+	}
+	want := DefaultMapping
+	want.Date, want.Amount, want.PaidBy = "When", "Total", "Payer"
+	if mapping != want {
+		t.Fatalf("ParseMapping: got %+v, want %+v", mapping, want)
+	}
+
+	for _, spec := range []string{"date", "payer=Who"} {
+		if _, err := ParseMapping(spec); err == nil {
+			t.Fatalf("ParseMapping(%q): got no error", spec)
+		}
+	}
+}
@@ -0,0 +1,179 @@
+// Package importer reads expenses exported by other apps: the CSV export of
+// Splitwise and any CSV whose columns are described by a Mapping. People are
+// kept as they are named in the file, matching them to users is left to the
+// caller. Amounts are worked out in cents so shares always add up.
+package importer
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Format string
+
+const (
+	FormatSplitwise Format = "splitwise"
+	FormatCSV       Format = "csv"
+)
+
+// Entry is one expense of the file
+type Entry struct {
+	Line        int       `json:"line"`
+	Date        time.Time `json:"date"`
+	Description string    `json:"description"`
+	Category    string    `json:"category"`
+	Amount      float64   `json:"amount"`
+	// Paid and Shares are by name as written in the file
+	Paid   map[string]float64 `json:"paid"`
+	Shares map[string]float64 `json:"shares"`
+}
+
+// Problem is a line that could not be read, or was left out on purpose
+type Problem struct {
+	Line    int    `json:"line"`
+	Message string `json:"message"`
+}
+
+type File struct {
+	Entries  []Entry   `json:"entries"`
+	Problems []Problem `json:"problems"`
+	// Skipped lines are not expenses, such as Splitwise payments and totals
+	Skipped []Problem `json:"skipped"`
+	// People is every name the entries use, sorted
+	People []string `json:"people"`
+}
+
+func (f *File) problem(line int, format string, args ...any) {
+	f.Problems = append(f.Problems, Problem{Line: line, Message: fmt.Sprintf(format, args...)})
+}
+
+func (f *File) skip(line int, format string, args ...any) {
+	f.Skipped = append(f.Skipped, Problem{Line: line, Message: fmt.Sprintf(format, args...)})
+}
+
+func (f *File) add(entry Entry) {
+	f.Entries = append(f.Entries, entry)
+}
+
+func (f *File) collectPeople() {
+	seen := map[string]bool{}
+	for _, entry := range f.Entries {
+		for _, amounts := range []map[string]float64{entry.Paid, entry.Shares} {
+			for name := range amounts {
+				if !seen[name] {
+					seen[name] = true
+					f.People = append(f.People, name)
+				}
+			}
+		}
+	}
+	sort.Strings(f.People)
+}
+
+// Read parses r in format, mapping is only used by FormatCSV
+func Read(r io.Reader, format Format, mapping Mapping) (*File, error) {
+	switch format {
+	case FormatSplitwise:
+		return ReadSplitwise(r)
+	case FormatCSV:
+		return ReadCSV(r, mapping)
+	}
+	return nil, fmt.Errorf("unknown import format %q, expected %s or %s", format, FormatSplitwise, FormatCSV)
+}
+
+func newReader(r io.Reader) *csv.Reader {
+	reader := csv.NewReader(r)
+	// rows of totals and blank lines have fewer fields
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	return reader
+}
+
+// readHeader returns the first row, dropping a UTF-8 byte order mark spreadsheets like to add
+func readHeader(reader *csv.Reader) ([]string, error) {
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, errors.New("the file is empty")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(header) > 0 {
+		header[0] = strings.TrimPrefix(header[0], "\ufeff")
+	}
+	for i := range header {
+		header[i] = strings.TrimSpace(header[i])
+	}
+	return header, nil
+}
+
+func blank(record []string) bool {
+	for _, field := range record {
+		if strings.TrimSpace(field) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+func field(record []string, i int) string {
+	if i < 0 || i >= len(record) {
+		return ""
+	}
+	return strings.TrimSpace(record[i])
+}
+
+// cents parses an amount such as 12.50 or 1,234.50
+func cents(value string) (int64, error) {
+	value = strings.ReplaceAll(strings.TrimSpace(value), ",", "")
+	if value == "" {
+		return 0, nil
+	}
+	amount, err := strconv.ParseFloat(value, 64)
+	if err != nil || math.IsInf(amount, 0) || math.IsNaN(amount) {
+		return 0, fmt.Errorf("invalid amount %q", value)
+	}
+	return int64(math.Round(amount * 100)), nil
+}
+
+func toAmounts(values map[string]int64) map[string]float64 {
+	amounts := map[string]float64{}
+	for name, value := range values {
+		if value != 0 {
+			amounts[name] = float64(value) / 100
+		}
+	}
+	return amounts
+}
+
+// spread divides total between names as evenly as cents allow, the first names take the odd cents
+func spread(total int64, names []string) map[string]int64 {
+	parts := map[string]int64{}
+	if len(names) == 0 {
+		return parts
+	}
+	each, rest := total/int64(len(names)), total%int64(len(names))
+	for i, name := range names {
+		parts[name] = each
+		if int64(i) < rest {
+			parts[name]++
+		}
+	}
+	return parts
+}
+
+func parseDate(value string, layouts ...string) (time.Time, error) {
+	for _, layout := range layouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid date %q", value)
+}
@@ -0,0 +1,174 @@
+package importer
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// splitwiseColumns come first in a Splitwise export, every further column is
+// a person holding their balance on the expense: what they paid minus their share.
+var splitwiseColumns = []string{"Date", "Description", "Category", "Cost", "Currency"}
+
+// ReadSplitwise reads the CSV export of a Splitwise group. The export only
+// keeps balances, so who paid what is rebuilt: people owed money paid their
+// balance plus an even part of what remains of the cost, which is also
+// their share, and people owing money paid nothing. Payments between members
+// and the closing total are skipped.
+func ReadSplitwise(r io.Reader) (*File, error) {
+	reader := newReader(r)
+	header, err := readHeader(reader)
+	if err != nil {
+		return nil, err
+	}
+	if len(header) <= len(splitwiseColumns) {
+		return nil, fmt.Errorf("not a Splitwise export, expected the columns %s and one per person", strings.Join(splitwiseColumns, ", "))
+	}
+	for i, name := range splitwiseColumns {
+		if !strings.EqualFold(header[i], name) {
+			return nil, fmt.Errorf("not a Splitwise export, column %d is %q instead of %s", i+1, header[i], name)
+		}
+	}
+	people := header[len(splitwiseColumns):]
+
+	file := &File{}
+	currency := ""
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := reader.FieldPos(0)
+		if blank(record) {
+			continue
+		}
+
+		description := field(record, 1)
+		category := field(record, 2)
+		if strings.EqualFold(description, "Total balance") {
+			file.skip(line, "total balance")
+			continue
+		}
+		if strings.EqualFold(category, "Payment") {
+			file.skip(line, "payment %q, record it as settling up", description)
+			continue
+		}
+
+		if c := field(record, 4); c != "" {
+			if currency == "" {
+				currency = c
+			} else if c != currency {
+				file.problem(line, "currency %s differs from %s, amounts are not converted", c, currency)
+				continue
+			}
+		}
+
+		date, err := parseDate(field(record, 0), "2006-01-02", "2006-01-02 15:04:05")
+		if err != nil {
+			file.problem(line, "%s", err)
+			continue
+		}
+		cost, err := cents(field(record, 3))
+		if err != nil {
+			file.problem(line, "%s", err)
+			continue
+		}
+		if cost <= 0 {
+			file.problem(line, "cost should be more than 0")
+			continue
+		}
+
+		balances := map[string]int64{}
+		bad := false
+		for i, name := range people {
+			balance, err := cents(field(record, len(splitwiseColumns)+i))
+			if err != nil {
+				file.problem(line, "%s for %s", err, name)
+				bad = true
+				break
+			}
+			if balance != 0 {
+				balances[name] = balance
+			}
+		}
+		if bad {
+			continue
+		}
+		if len(balances) == 0 {
+			file.skip(line, "nobody owes anything on %q", description)
+			continue
+		}
+
+		paid, shares, err := fromBalances(cost, balances)
+		if err != nil {
+			file.problem(line, "%s", err)
+			continue
+		}
+		file.add(Entry{
+			Line:        line,
+			Date:        date,
+			Description: description,
+			Category:    category,
+			Amount:      float64(cost) / 100,
+			Paid:        toAmounts(paid),
+			Shares:      toAmounts(shares),
+		})
+	}
+	file.collectPeople()
+	return file, nil
+}
+
+// fromBalances works out payments and shares adding up to cost from balances
+func fromBalances(cost int64, balances map[string]int64) (map[string]int64, map[string]int64, error) {
+	owed, owing := []string{}, []string{}
+	var totalOwed, totalOwing int64
+	for name, balance := range balances {
+		if balance > 0 {
+			owed = append(owed, name)
+			totalOwed += balance
+		} else {
+			owing = append(owing, name)
+			totalOwing -= balance
+		}
+	}
+	sort.Strings(owed)
+	sort.Strings(owing)
+
+	if len(owed) == 0 {
+		return nil, nil, fmt.Errorf("nobody paid for the expense")
+	}
+	// Splitwise rounds every balance, allow a cent per person
+	if diff := totalOwed - totalOwing; diff > int64(len(balances)) || -diff > int64(len(balances)) {
+		return nil, nil, fmt.Errorf("balances do not add up, %s owed against %s owing", money(totalOwed), money(totalOwing))
+	}
+	if totalOwed > cost {
+		return nil, nil, fmt.Errorf("balances of %s are more than the cost", money(totalOwed))
+	}
+
+	paid := map[string]int64{}
+	shares := map[string]int64{}
+	for _, name := range owing {
+		shares[name] = -balances[name]
+	}
+	// put the rounding difference on the biggest share owed
+	if len(owing) > 0 {
+		sort.SliceStable(owing, func(i, j int) bool { return shares[owing[i]] > shares[owing[j]] })
+		shares[owing[0]] += totalOwed - totalOwing
+	}
+	for name, part := range spread(cost-totalOwed, owed) {
+		shares[name] = part
+		paid[name] = balances[name] + part
+	}
+	if len(owing) == 0 {
+		shares[owed[0]] += totalOwed
+	}
+	return paid, shares, nil
+}
+
+func money(value int64) string {
+	return fmt.Sprintf("%.2f", float64(value)/100)
+}
@@ -0,0 +1,114 @@
+package importer
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const splitwiseHeader = "Date,Description,Category,Cost,Currency,Alice,Bob,Carol\n"
+
+func TestReadSplitwise(t *testing.T) {
+	tests := []struct {
+		name    string
+		row     string
+		problem string
+		skipped string
+		paid    map[string]float64
+		shares  map[string]float64
+	}{
+		{
+			name:   "one payer",
+			row:    "2024-01-02,Dinner,Food,30.00,USD,20.00,-10.00,-10.00",
+			paid:   map[string]float64{"Alice": 30},
+			shares: map[string]float64{"Alice": 10, "Bob": 10, "Carol": 10},
+		},
+		{
+			name:   "two payers",
+			row:    "2024-01-02 18:30:00,Groceries,General,90.00,USD,15.00,15.00,-30.00",
+			paid:   map[string]float64{"Alice": 45, "Bob": 45},
+			shares: map[string]float64{"Alice": 30, "Bob": 30, "Carol": 30},
+		},
+		{
+			name:   "rounded balances",
+			row:    "2024-01-02,Taxi,Transport,10.00,USD,6.67,-3.33,-3.33",
+			paid:   map[string]float64{"Alice": 10},
+			shares: map[string]float64{"Alice": 3.33, "Bob": 3.34, "Carol": 3.33},
+		},
+		{name: "payment", row: "2024-01-03,Bob paid Alice,Payment,10.00,USD,-10.00,10.00,0", skipped: `payment "Bob paid Alice", record it as settling up`},
+		{name: "total", row: "2024-01-04,Total balance, , ,USD,10.00,-5.00,-5.00", skipped: "total balance"},
+		{name: "nobody owes", row: "2024-01-02,Gift,General,10.00,USD,0,0,0", skipped: `nobody owes anything on "Gift"`},
+		{name: "invalid date", row: "Jan 2,Dinner,Food,30.00,USD,20.00,-10.00,-10.00", problem: `invalid date "Jan 2"`},
+		{name: "invalid cost", row: "2024-01-02,Dinner,Food,thirty,USD,20.00,-10.00,-10.00", problem: `invalid amount "thirty"`},
+		{name: "zero cost", row: "2024-01-02,Dinner,Food,0,USD,20.00,-10.00,-10.00", problem: "cost should be more than 0"},
+		{name: "invalid balance", row: "2024-01-02,Dinner,Food,30.00,USD,20.00,ten,-10.00", problem: `invalid amount "ten" for Bob`},
+		{name: "balances not adding up", row: "2024-01-02,Dinner,Food,30.00,USD,20.00,-5.00,-5.00", problem: "balances do not add up, 20.00 owed against 10.00 owing"},
+		{name: "balances over the cost", row: "2024-01-02,Dinner,Food,10.00,USD,20.00,-10.00,-10.00", problem: "balances of 20.00 are more than the cost"},
+		{name: "nobody paid", row: "2024-01-02,Dinner,Food,10.00,USD,-0.01,0,0", problem: "nobody paid for the expense"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file, err := ReadSplitwise(strings.NewReader(splitwiseHeader + tt.row + "\n"))
+			if err != nil {
+				t.Fatalf("ReadSplitwise: %v", err)
+			}
+			var problems, skipped []Problem
+			if tt.problem != "" {
+				problems = []Problem{{Line: 2, Message: tt.problem}}
+			}
+			if tt.skipped != "" {
+				skipped = []Problem{{Line: 2, Message: tt.skipped}}
+			}
+			if !reflect.DeepEqual(file.Problems, problems) || !reflect.DeepEqual(file.Skipped, skipped) {
+				t.Fatalf("ReadSplitwise: got problems %v skipped %v, want %v %v", file.Problems, file.Skipped, problems, skipped)
+			}
+			if tt.paid == nil {
+				if len(file.Entries) != 0 {
+					t.Fatalf("ReadSplitwise: got entries %+v, want none", file.Entries)
+				}
+				return
+			}
+			if len(file.Entries) != 1 {
+				t.Fatalf("ReadSplitwise: got %d entries, want one", len(file.Entries))
+			}
+			entry := file.Entries[0]
+			if !reflect.DeepEqual(entry.Paid, tt.paid) || !reflect.DeepEqual(entry.Shares, tt.shares) {
+				t.Fatalf("ReadSplitwise: got paid %v shares %v, want %v %v", entry.Paid, entry.Shares, tt.paid, tt.shares)
+			}
+		})
+	}
+}
+
+func TestReadSplitwiseFile(t *testing.T) {
+	t.Run("currency changes", func(t *testing.T) {
+		input := splitwiseHeader +
+			"2024-01-02,Dinner,Food,30.00,USD,20.00,-10.00,-10.00\n" +
+			"2024-01-03,Hotel,Lodging,30.00,EUR,20.00,-10.00,-10.00\n"
+		file, err := ReadSplitwise(strings.NewReader(input))
+		if err != nil {
+			t.Fatalf("ReadSplitwise: %v", err)
+		}
+		want := []Problem{{Line: 3, Message: "currency EUR differs from USD, amounts are not converted"}}
+		if !reflect.DeepEqual(file.Problems, want) || len(file.Entries) != 1 {
+			t.Fatalf("ReadSplitwise: got problems %v and %d entries, want %v", file.Problems, len(file.Entries), want)
+		}
+	})
+
+	tests := []struct {
+		name  string
+		input string
+		err   string
+	}{
+		{"empty file", "", "the file is empty"},
+		{"nobody", "Date,Description,Category,Cost,Currency\n", "not a Splitwise export, expected the columns"},
+		{"other columns", "Date,Description,Amount,Cost,Currency,Alice\n", `not a Splitwise export, column 3 is "Amount" instead of Category`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ReadSplitwise(strings.NewReader(tt.input))
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Fatalf("ReadSplitwise: got %v, want %q", err, tt.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"sort"
+	"splitExpense/config"
+	"splitExpense/expense"
+	"splitExpense/orchestrator"
+)
+
+// runLedger handles `splitExpense ledger replay [-projection name]` and `splitExpense ledger status`
+func runLedger(cfg *config.Config, args []string) error {
+	if len(args) == 0 {
+		return errors.New("usage: splitExpense ledger replay [-projection rows|balances] | status")
+	}
+	// replay rebuilds the tables from the events, which stop while the server
+	// runs without the ledger, so it refuses to reset tables that moved past them
+	if args[0] == "replay" && !cfg.EventSourcing {
+		return errors.New("ledger replay rebuilds the tables from the events, set EVENT_SOURCING=true to run it")
+	}
+	// status only reads the event table, even when the server runs without it
+	cfg.EventSourcing = true
+	app := orchestrator.NewExpenseApp(cfg)
+	ctx := context.Background()
+
+	switch args[0] {
+	case "replay":
+		fs := flag.NewFlagSet("ledger replay", flag.ContinueOnError)
+		projection := fs.String("projection", "", "rebuild only this projection")
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+		projections := []string{}
+		if *projection != "" {
+			projections = append(projections, *projection)
+		}
+		replayed, err := app.ReplayLedger(ctx, projections...)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("replayed %d events\n", replayed)
+		return nil
+	case "status":
+		counts, err := app.LedgerStatus(ctx)
+		if err != nil {
+			return err
+		}
+		types := []string{}
+		for t := range counts {
+			types = append(types, string(t))
+		}
+		sort.Strings(types)
+		for _, t := range types {
+			fmt.Printf("%-22s %d\n", t, counts[expense.EventType(t)])
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown ledger command %q", args[0])
+	}
+}
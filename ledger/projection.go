@@ -0,0 +1,191 @@
+package ledger
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"splitExpense/expense"
+
+	lodash "github.com/samber/lo"
+)
+
+// Projection derives a read model from events. Apply runs in the same
+// transaction as the append, Reset empties the model before a replay.
+type Projection interface {
+	Name() string
+	Reset(ctx context.Context) error
+	Apply(ctx context.Context, event expense.Event) error
+}
+
+// BatchProjection is a Projection that applies events recorded together, such
+// as the expenses of a bulk create, in fewer writes
+type BatchProjection interface {
+	Projection
+	ApplyAll(ctx context.Context, events []expense.Event) error
+}
+
+// DefaultProjections are the projections kept current on every write
+func DefaultProjections(store Store) []Projection {
+	return []Projection{&RowsProjection{store: store}, &BalanceProjection{store: store}}
+}
+
+// RowsProjection maintains the expense, mapping, group and member tables
+// the rest of the application reads.
+type RowsProjection struct {
+	store Store
+}
+
+func (p *RowsProjection) Name() string {
+	return "rows"
+}
+
+func (p *RowsProjection) Reset(ctx context.Context) error {
+	return p.store.ResetExpenseTables(ctx)
+}
+
+func (p *RowsProjection) Apply(ctx context.Context, event expense.Event) error {
+	switch event.Type {
+	case expense.EventExpenseCreated, expense.EventExpenseUpdated, expense.EventSplitChanged,
+		expense.EventExpenseSettled, expense.EventExpenseReopened:
+		var exp expense.Expense
+		if err := json.Unmarshal(event.Payload, &exp); err != nil {
+			return err
+		}
+		// the row holds the version before the event, a created row starts at the recorded one
+		if event.Type != expense.EventExpenseCreated {
+			exp.Version--
+		}
+		_, err := p.store.CreateOrUpdateExpense(ctx, exp)
+		return err
+
+	case expense.EventExpenseDeleted:
+		_, err := p.store.DeleteExpense(ctx, event.AggregateID)
+		return err
+
+	case expense.EventExpenseArchived:
+		_, err := p.store.ArchiveExpenses(ctx, []string{event.AggregateID})
+		return err
+
+	case expense.EventParticipantsAdded:
+		var payload expense.ParticipantsPayload
+		if err := json.Unmarshal(event.Payload, &payload); err != nil {
+			return err
+		}
+		return p.store.AddExpenseMappings(ctx, map[string][]string{payload.ExpenseId: payload.UserIds})
+
+	case expense.EventParticipantsRemoved:
+		var payload expense.ParticipantsPayload
+		if err := json.Unmarshal(event.Payload, &payload); err != nil {
+			return err
+		}
+		_, err := p.store.RemoveUsersFromExpense(ctx, payload.ExpenseId, payload.UserIds)
+		return err
+
+	case expense.EventGroupCreated, expense.EventGroupUpdated:
+		var group expense.Group
+		if err := json.Unmarshal(event.Payload, &group); err != nil {
+			return err
+		}
+		if event.Type == expense.EventGroupUpdated {
+			group.Version--
+		}
+		_, err := p.store.CreateOrUpdateGroup(ctx, group)
+		return err
+
+	case expense.EventGroupDeleted:
+		_, err := p.store.DeleteGroup(ctx, event.AggregateID)
+		return err
+
+	case expense.EventMemberJoined, expense.EventMemberLeft:
+		var payload expense.MembershipPayload
+		if err := json.Unmarshal(event.Payload, &payload); err != nil {
+			return err
+		}
+		var err error
+		if event.Type == expense.EventMemberJoined {
+			_, err = p.store.AddUserInGroup(ctx, payload.UserId, payload.GroupId)
+		} else {
+			_, err = p.store.RemoveUserFromGroup(ctx, payload.UserId, payload.GroupId)
+		}
+		return err
+	}
+	return fmt.Errorf("rows projection: unknown event type %s", event.Type)
+}
+
+// BalanceProjection keeps what every participant paid and owes per expense,
+// the source of ledger balances and per-category totals.
+type BalanceProjection struct {
+	store Store
+}
+
+func (p *BalanceProjection) Name() string {
+	return "balances"
+}
+
+func (p *BalanceProjection) Reset(ctx context.Context) error {
+	return p.store.ResetBalanceEntries(ctx)
+}
+
+func (p *BalanceProjection) Apply(ctx context.Context, event expense.Event) error {
+	switch event.Type {
+	case expense.EventExpenseCreated, expense.EventExpenseUpdated, expense.EventSplitChanged,
+		expense.EventExpenseSettled, expense.EventExpenseReopened:
+		var exp expense.Expense
+		if err := json.Unmarshal(event.Payload, &exp); err != nil {
+			return err
+		}
+		return p.store.ReplaceBalanceEntries(ctx, exp.ID, balanceEntries(exp))
+
+	case expense.EventExpenseDeleted:
+		return p.store.ReplaceBalanceEntries(ctx, event.AggregateID, nil)
+
+	case expense.EventGroupDeleted:
+		return p.store.DeleteGroupBalanceEntries(ctx, event.AggregateID)
+	}
+	// archival, participant and membership changes do not move money
+	return nil
+}
+
+// ApplyAll inserts the entries of every created expense at once, other events
+// replace the entries of their expense one by one
+func (p *BalanceProjection) ApplyAll(ctx context.Context, events []expense.Event) error {
+	created := []BalanceEntry{}
+	for _, event := range events {
+		if event.Type != expense.EventExpenseCreated {
+			if err := p.Apply(ctx, event); err != nil {
+				return err
+			}
+			continue
+		}
+		var exp expense.Expense
+		if err := json.Unmarshal(event.Payload, &exp); err != nil {
+			return err
+		}
+		created = append(created, balanceEntries(exp)...)
+	}
+	return p.store.InsertBalanceEntries(ctx, created)
+}
+
+func balanceEntries(exp expense.Expense) []BalanceEntry {
+	paid := exp.PayeeW.Payer.GetPayers()
+	shares := exp.SplitW.Split.GetPayeeSplit()
+	groupId := ""
+	if exp.IsGroupExpense {
+		groupId = exp.GroupId
+	}
+
+	entries := []BalanceEntry{}
+	for _, userId := range lodash.Union(lodash.Keys(paid), lodash.Keys(shares)) {
+		entries = append(entries, BalanceEntry{
+			ExpenseId: exp.ID,
+			UserId:    userId,
+			GroupId:   groupId,
+			Category:  exp.Category,
+			Status:    exp.Status,
+			Paid:      paid[userId],
+			Share:     shares[userId],
+		})
+	}
+	return entries
+}
@@ -0,0 +1,77 @@
+package ledger
+
+import (
+	"context"
+	"fmt"
+
+	"splitExpense/expense"
+
+	lodash "github.com/samber/lo"
+)
+
+const replayBatchSize = 500
+
+// Bootstrap records rows written before the ledger was enabled as events, so
+// a later replay does not lose them. It is a no-op once every row has events.
+func Bootstrap(ctx context.Context, store Store) (int, error) {
+	recorded := 0
+	err := store.RunInTx(ctx, func(ctx context.Context) error {
+		var err error
+		recorded, err = store.BootstrapEvents(ctx)
+		return err
+	})
+	return recorded, err
+}
+
+// Replay empties the named projections, every projection when names is
+// empty, and rebuilds them from the full event history. It runs in one
+// transaction that holds writers back, so readers never see a half built model.
+func Replay(ctx context.Context, store Store, names ...string) (int, error) {
+	projections := DefaultProjections(store)
+	if len(names) > 0 {
+		for _, name := range names {
+			if !lodash.ContainsBy(projections, func(p Projection) bool { return p.Name() == name }) {
+				return 0, fmt.Errorf("unknown projection %q", name)
+			}
+		}
+		projections = lodash.Filter(projections, func(p Projection, _ int) bool { return lodash.Contains(names, p.Name()) })
+	}
+
+	replayed := 0
+	err := store.RunInTx(ctx, func(ctx context.Context) error {
+		if _, err := store.BootstrapEvents(ctx); err != nil {
+			return err
+		}
+		for _, p := range projections {
+			if err := p.Reset(ctx); err != nil {
+				return fmt.Errorf("resetting projection %s: %w", p.Name(), err)
+			}
+		}
+
+		var after int64
+		for {
+			events, err := store.LoadEvents(ctx, after, replayBatchSize)
+			if err != nil {
+				return err
+			}
+			for _, event := range events {
+				for _, p := range projections {
+					if err := p.Apply(ctx, event); err != nil {
+						return fmt.Errorf("projection %s failed on event %d (%s): %w", p.Name(), event.Sequence, event.Type, err)
+					}
+				}
+				after = event.Sequence
+				replayed++
+			}
+			if len(events) < replayBatchSize {
+				return nil
+			}
+		}
+	})
+	return replayed, err
+}
+
+// History returns the events of one expense or group, oldest first
+func History(ctx context.Context, store Store, aggregateType string, aggregateId string) ([]expense.Event, error) {
+	return store.LoadAggregateEvents(ctx, aggregateType, aggregateId)
+}
@@ -0,0 +1,366 @@
+package ledger
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"slices"
+	"time"
+
+	"splitExpense/expense"
+
+	"github.com/google/uuid"
+	lodash "github.com/samber/lo"
+)
+
+// Storage decorates a Store so every expense and group write is appended as
+// an event and applied through the projections in one transaction. Reads and
+// user writes go straight to the Store.
+type Storage struct {
+	Store
+	projections []Projection
+}
+
+func NewStorage(store Store) *Storage {
+	return &Storage{Store: store, projections: DefaultProjections(store)}
+}
+
+// record appends event and applies it to every projection
+func (s *Storage) record(ctx context.Context, event expense.Event) error {
+	event.ID = uuid.New().String()
+	event.ActorID = expense.ActorFrom(ctx)
+	appended, err := s.Store.AppendEvent(ctx, event)
+	if err != nil {
+		return err
+	}
+	for _, p := range s.projections {
+		if err := p.Apply(ctx, *appended); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// recordAll appends events and applies them, projections that can apply them
+// together do so in one go
+func (s *Storage) recordAll(ctx context.Context, events []expense.Event) error {
+	appended := make([]expense.Event, 0, len(events))
+	for _, event := range events {
+		event.ID = uuid.New().String()
+		event.ActorID = expense.ActorFrom(ctx)
+		saved, err := s.Store.AppendEvent(ctx, event)
+		if err != nil {
+			return err
+		}
+		appended = append(appended, *saved)
+	}
+	for _, p := range s.projections {
+		if batch, ok := p.(BatchProjection); ok {
+			if err := batch.ApplyAll(ctx, appended); err != nil {
+				return err
+			}
+			continue
+		}
+		for _, event := range appended {
+			if err := p.Apply(ctx, event); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func newEvent(eventType expense.EventType, aggregateType string, aggregateId string, version int, payload any) (expense.Event, error) {
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		return expense.Event{}, err
+	}
+	return expense.Event{
+		Type:             eventType,
+		AggregateType:    aggregateType,
+		AggregateID:      aggregateId,
+		AggregateVersion: version,
+		Payload:          raw,
+	}, nil
+}
+
+func (s *Storage) CreateOrUpdateExpense(ctx context.Context, exp expense.Expense) (*expense.Expense, error) {
+	var saved *expense.Expense
+	err := s.Store.RunInTx(ctx, func(ctx context.Context) error {
+		current, err := s.Store.FetchExpense(ctx, exp.ID)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+
+		eventType := expense.EventExpenseCreated
+		if current == nil {
+			exp.Version = 1
+		} else {
+			// the caller's version must be the stored one before it moves on
+			if current.Version != exp.Version {
+				return &expense.VersionConflictError{Entity: "expense", ID: exp.ID, CurrentVersion: current.Version}
+			}
+			eventType = expense.ExpenseChange(*current, exp)
+			exp.Version = current.Version + 1
+		}
+
+		event, err := newEvent(eventType, expense.AggregateExpense, exp.ID, exp.Version, exp)
+		if err != nil {
+			return err
+		}
+		if err := s.record(ctx, event); err != nil {
+			return err
+		}
+		saved, err = s.Store.FetchExpense(ctx, exp.ID)
+		return err
+	})
+	return saved, err
+}
+
+func (s *Storage) CreateExpenses(ctx context.Context, exps []expense.Expense) ([]expense.Expense, error) {
+	created := make([]expense.Expense, 0, len(exps))
+	err := s.Store.RunInTx(ctx, func(ctx context.Context) error {
+		events := make([]expense.Event, 0, len(exps))
+		for _, exp := range exps {
+			exp.Version = 1
+			event, err := newEvent(expense.EventExpenseCreated, expense.AggregateExpense, exp.ID, exp.Version, exp)
+			if err != nil {
+				return err
+			}
+			events = append(events, event)
+		}
+		if err := s.recordAll(ctx, events); err != nil {
+			return err
+		}
+		for _, exp := range exps {
+			saved, err := s.Store.FetchExpense(ctx, exp.ID)
+			if err != nil {
+				return err
+			}
+			created = append(created, *saved)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+func (s *Storage) DeleteExpense(ctx context.Context, id string) (bool, error) {
+	deleted := false
+	err := s.Store.RunInTx(ctx, func(ctx context.Context) error {
+		current, err := s.Store.FetchExpense(ctx, id)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		event, err := newEvent(expense.EventExpenseDeleted, expense.AggregateExpense, id, current.Version, current)
+		if err != nil {
+			return err
+		}
+		deleted = true
+		return s.record(ctx, event)
+	})
+	return deleted, err
+}
+
+// ArchiveSettledExpenses records an ExpenseArchived event for each expense it
+// moves, so a replay archives them again
+func (s *Storage) ArchiveSettledExpenses(ctx context.Context, settledBefore time.Time, limit int) (int, error) {
+	archived := 0
+	err := s.Store.RunInTx(ctx, func(ctx context.Context) error {
+		ids, err := s.Store.FetchSettledExpensesToArchive(ctx, settledBefore, limit)
+		if err != nil {
+			return err
+		}
+		events := []expense.Event{}
+		for _, id := range ids {
+			current, err := s.Store.FetchExpense(ctx, id)
+			if err != nil {
+				return err
+			}
+			event, err := newEvent(expense.EventExpenseArchived, expense.AggregateExpense, id, current.Version, current)
+			if err != nil {
+				return err
+			}
+			events = append(events, event)
+		}
+		archived = len(events)
+		return s.recordAll(ctx, events)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return archived, nil
+}
+
+func (s *Storage) AddExpenseMapping(ctx context.Context, expenseId string, userId string) (bool, error) {
+	return s.recordParticipants(ctx, expense.EventParticipantsAdded, expenseId, []string{userId})
+}
+
+// AddExpenseMappings records one participants event for each expense that gains users
+func (s *Storage) AddExpenseMappings(ctx context.Context, mappings map[string][]string) error {
+	return s.Store.RunInTx(ctx, func(ctx context.Context) error {
+		expenseIds := lodash.Keys(mappings)
+		slices.Sort(expenseIds)
+
+		events := []expense.Event{}
+		for _, expenseId := range expenseIds {
+			userIds := mappings[expenseId]
+			current, err := s.Store.FetchExpense(ctx, expenseId)
+			if err != nil {
+				return err
+			}
+			mapped, err := s.Store.FetchExpenseParticipants(ctx, expenseId)
+			if err != nil {
+				return err
+			}
+			_, added := lodash.Difference(mapped, lodash.Uniq(userIds))
+			if len(added) == 0 {
+				continue
+			}
+			event, err := newEvent(expense.EventParticipantsAdded, expense.AggregateExpense, expenseId, current.Version,
+				expense.ParticipantsPayload{ExpenseId: expenseId, UserIds: added})
+			if err != nil {
+				return err
+			}
+			events = append(events, event)
+		}
+		return s.recordAll(ctx, events)
+	})
+}
+
+func (s *Storage) RemoveUsersFromExpense(ctx context.Context, expenseId string, usersToRemove []string) (bool, error) {
+	if len(usersToRemove) == 0 {
+		return false, nil
+	}
+	return s.recordParticipants(ctx, expense.EventParticipantsRemoved, expenseId, usersToRemove)
+}
+
+// recordParticipants records only the users whose mapping changes. Adding
+// mapped users succeeds without an event, removing unmapped ones reports false.
+func (s *Storage) recordParticipants(ctx context.Context, eventType expense.EventType, expenseId string, userIds []string) (bool, error) {
+	changed := false
+	err := s.Store.RunInTx(ctx, func(ctx context.Context) error {
+		current, err := s.Store.FetchExpense(ctx, expenseId)
+		if err != nil {
+			return err
+		}
+		mapped, err := s.Store.FetchExpenseParticipants(ctx, expenseId)
+		if err != nil {
+			return err
+		}
+		if eventType == expense.EventParticipantsAdded {
+			_, userIds = lodash.Difference(mapped, lodash.Uniq(userIds))
+		} else {
+			userIds = lodash.Intersect(mapped, userIds)
+		}
+		if len(userIds) == 0 {
+			return nil
+		}
+
+		event, err := newEvent(eventType, expense.AggregateExpense, expenseId, current.Version,
+			expense.ParticipantsPayload{ExpenseId: expenseId, UserIds: userIds})
+		if err != nil {
+			return err
+		}
+		changed = true
+		return s.record(ctx, event)
+	})
+	if err != nil {
+		return false, err
+	}
+	return changed || eventType == expense.EventParticipantsAdded, nil
+}
+
+func (s *Storage) CreateOrUpdateGroup(ctx context.Context, group expense.Group) (*expense.Group, error) {
+	var saved *expense.Group
+	err := s.Store.RunInTx(ctx, func(ctx context.Context) error {
+		current, err := s.Store.FetchGroupById(ctx, group.Id)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+
+		eventType := expense.EventGroupCreated
+		if current == nil {
+			group.Version = 1
+		} else {
+			if current.Version != group.Version {
+				return &expense.VersionConflictError{Entity: "group", ID: group.Id, CurrentVersion: current.Version}
+			}
+			eventType = expense.EventGroupUpdated
+			group.Version = current.Version + 1
+			group.CreatedAt = current.CreatedAt
+		}
+
+		event, err := newEvent(eventType, expense.AggregateGroup, group.Id, group.Version, group)
+		if err != nil {
+			return err
+		}
+		if err := s.record(ctx, event); err != nil {
+			return err
+		}
+		saved, err = s.Store.FetchGroupById(ctx, group.Id)
+		return err
+	})
+	return saved, err
+}
+
+func (s *Storage) DeleteGroup(ctx context.Context, groupId string) (bool, error) {
+	deleted := false
+	err := s.Store.RunInTx(ctx, func(ctx context.Context) error {
+		current, err := s.Store.FetchGroupById(ctx, groupId)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		event, err := newEvent(expense.EventGroupDeleted, expense.AggregateGroup, groupId, current.Version, current)
+		if err != nil {
+			return err
+		}
+		deleted = true
+		return s.record(ctx, event)
+	})
+	return deleted, err
+}
+
+func (s *Storage) AddUserInGroup(ctx context.Context, userId string, groupId string) (bool, error) {
+	return s.recordMembership(ctx, expense.EventMemberJoined, userId, groupId)
+}
+
+func (s *Storage) RemoveUserFromGroup(ctx context.Context, userId string, groupId string) (bool, error) {
+	return s.recordMembership(ctx, expense.EventMemberLeft, userId, groupId)
+}
+
+// recordMembership records a join or leave only when it changes the membership
+func (s *Storage) recordMembership(ctx context.Context, eventType expense.EventType, userId string, groupId string) (bool, error) {
+	err := s.Store.RunInTx(ctx, func(ctx context.Context) error {
+		group, err := s.Store.FetchGroupById(ctx, groupId)
+		if err != nil {
+			return err
+		}
+		isMember, err := s.Store.CheckUserExistsInGroup(ctx, userId, groupId)
+		if err != nil {
+			return err
+		}
+		if isMember == (eventType == expense.EventMemberJoined) {
+			return nil
+		}
+
+		event, err := newEvent(eventType, expense.AggregateGroup, groupId, group.Version,
+			expense.MembershipPayload{GroupId: groupId, UserId: userId})
+		if err != nil {
+			return err
+		}
+		return s.record(ctx, event)
+	})
+	return err == nil, err
+}
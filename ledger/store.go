@@ -0,0 +1,67 @@
+// Package ledger is the optional event sourced mode. Storage records every
+// expense and group change as an immutable event and derives the expense
+// tables and balance read models from it through projections, which Replay
+// can rebuild from the full history at any time.
+package ledger
+
+import (
+	"context"
+	"time"
+
+	"splitExpense/expense"
+)
+
+// BalanceEntry is what one participant paid for an expense and their share of it
+type BalanceEntry struct {
+	ExpenseId string
+	UserId    string
+	GroupId   string
+	Category  string
+	Status    expense.ExpenseStatus
+	Paid      float64
+	Share     float64
+}
+
+// Balance is a user's running balance in a group, GroupId is empty for personal expenses
+type Balance struct {
+	GroupId string  `json:"groupId"`
+	Paid    float64 `json:"paid"`
+	Share   float64 `json:"share"`
+}
+
+type CategoryTotal struct {
+	Category string  `json:"category"`
+	Total    float64 `json:"total"`
+}
+
+// Store is the persistence the ledger needs on top of expense.Storage.
+type Store interface {
+	expense.Storage
+	expense.EventStore
+
+	// BootstrapEvents records groups, members, expenses and participants
+	// written before the ledger was enabled as creation events. It locks the
+	// event table until the surrounding transaction ends.
+	BootstrapEvents(ctx context.Context) (int, error)
+	CountEventsByType(ctx context.Context) (map[expense.EventType]int, error)
+
+	// FetchExpenseParticipants lists the users mapped to a live expense
+	FetchExpenseParticipants(ctx context.Context, expenseId string) ([]string, error)
+
+	// FetchSettledExpensesToArchive locks the expenses the next archival moves
+	FetchSettledExpensesToArchive(ctx context.Context, settledBefore time.Time, limit int) ([]string, error)
+	// ArchiveExpenses moves live expenses with their mappings to the archive
+	ArchiveExpenses(ctx context.Context, ids []string) (int, error)
+
+	// ResetExpenseTables empties groups, members, expenses, mappings and the archive
+	ResetExpenseTables(ctx context.Context) error
+
+	// ReplaceBalanceEntries swaps the entries of one expense, no entries removes them
+	ReplaceBalanceEntries(ctx context.Context, expenseId string, entries []BalanceEntry) error
+	// InsertBalanceEntries adds the entries of expenses that have none yet in one write
+	InsertBalanceEntries(ctx context.Context, entries []BalanceEntry) error
+	DeleteGroupBalanceEntries(ctx context.Context, groupId string) error
+	ResetBalanceEntries(ctx context.Context) error
+	FetchBalances(ctx context.Context, userId string) ([]Balance, error)
+	FetchCategoryTotals(ctx context.Context, userId string) ([]CategoryTotal, error)
+}
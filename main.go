@@ -1,9 +1,50 @@
 package main
 
 import (
-	apiServer "splitExpense/api"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"splitExpense/config"
 )
 
+// commands are the subcommands of the server binary, without one it serves
+// HTTP like `serve`
+var commands = map[string]func(cfg *config.Config, args []string) error{
+	"serve":   runServe,
+	"migrate": runMigrate,
+	"archive": func(cfg *config.Config, _ []string) error { return runArchive(cfg) },
+	"ledger":  runLedger,
+	"backup":  runBackup,
+	"user":    runUser,
+	"group":   runGroup,
+	"recompute-balances": func(cfg *config.Config, _ []string) error {
+		return runRecomputeBalances(cfg)
+	},
+	"check-integrity": runCheckIntegrity,
+	"seed":            runSeed,
+}
+
 func main() {
-	apiServer.Start()
+	cfg := config.Load()
+
+	name, args := "serve", []string{}
+	if len(os.Args) > 1 {
+		name, args = os.Args[1], os.Args[2:]
+	}
+	run, ok := commands[name]
+	if !ok {
+		names := []string{}
+		for n := range commands {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		fmt.Fprintf(os.Stderr, "unknown command %q, expected one of %s\n", name, strings.Join(names, ", "))
+		os.Exit(2)
+	}
+	if err := run(cfg, args); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
 }
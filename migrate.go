@@ -0,0 +1,66 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"splitExpense/config"
+	"splitExpense/migrations"
+	"splitExpense/storage"
+)
+
+const migrateUsage = "usage: splitExpense migrate up|down [-steps n]|status"
+
+// runMigrate handles `splitExpense migrate <up|down|status>`
+func runMigrate(cfg *config.Config, args []string) error {
+	if len(args) == 0 || (args[0] != "up" && args[0] != "down" && args[0] != "status") {
+		return errors.New(migrateUsage)
+	}
+
+	fs := flag.NewFlagSet("migrate "+args[0], flag.ContinueOnError)
+	steps := fs.Int("steps", 1, "number of migrations to revert")
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	pg, err := storage.NewPostgresDB(cfg)
+	if err != nil {
+		return err
+	}
+	defer pg.Close()
+	ctx := context.Background()
+
+	switch args[0] {
+	case "up":
+		applied, err := migrations.Up(ctx, pg)
+		for _, m := range applied {
+			fmt.Printf("applied   %04d_%s\n", m.Version, m.Name)
+		}
+		if err == nil && len(applied) == 0 {
+			fmt.Println("schema is up to date")
+		}
+		return err
+	case "down":
+		reverted, err := migrations.Down(ctx, pg, *steps)
+		for _, m := range reverted {
+			fmt.Printf("reverted  %04d_%s\n", m.Version, m.Name)
+		}
+		return err
+	case "status":
+		statuses, err := migrations.Status(ctx, pg)
+		if err != nil {
+			return err
+		}
+		for _, s := range statuses {
+			if s.Applied {
+				fmt.Printf("applied   %04d_%s  (%s)\n", s.Version, s.Name, s.AppliedAt.Format("2006-01-02 15:04:05"))
+			} else {
+				fmt.Printf("pending   %04d_%s\n", s.Version, s.Name)
+			}
+		}
+		return nil
+	default:
+		return errors.New(migrateUsage)
+	}
+}
@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS friends;
+DROP TABLE IF EXISTS group_members;
+DROP TABLE IF EXISTS expense_mapping;
+DROP TABLE IF EXISTS expense;
+DROP TABLE IF EXISTS "group";
+DROP TABLE IF EXISTS "users";
@@ -1,7 +1,10 @@
+-- Baseline schema. Statements are idempotent so databases created from the
+-- old schema.sql can adopt migrations without being recreated.
+
 -- Enable pgcrypto for UUID generation
 CREATE EXTENSION IF NOT EXISTS "pgcrypto";
 
-CREATE TABLE "users" (
+CREATE TABLE IF NOT EXISTS "users" (
     id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
     name TEXT NOT NULL,
     email TEXT NOT NULL,
@@ -11,14 +14,16 @@ CREATE TABLE "users" (
     updated_at TIMESTAMP WITH TIME ZONE DEFAULT (NOW() AT TIME ZONE 'Asia/Kolkata')
 );
 
-CREATE TABLE "group" (
+CREATE TABLE IF NOT EXISTS "group" (
     id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
     name TEXT NOT NULL,
     description TEXT NOT NULL,
     admin_id UUID NOT NULL
 );
 
-CREATE TABLE expense (
+ALTER TABLE "group" ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
+
+CREATE TABLE IF NOT EXISTS expense (
     id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
     description TEXT,
     amount DECIMAL(19, 4) NOT NULL,
@@ -32,16 +37,18 @@ CREATE TABLE expense (
     updated_at TIMESTAMP WITH TIME ZONE DEFAULT (NOW() AT TIME ZONE 'Asia/Kolkata')
 );
 
+ALTER TABLE expense ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
+
 -- Index for faster status-based queries
-CREATE INDEX idx_expense_status ON expense(status);
+CREATE INDEX IF NOT EXISTS idx_expense_status ON expense(status);
 
 -- Index for creator lookup
-CREATE INDEX idx_expense_created_by ON expense(created_by);
+CREATE INDEX IF NOT EXISTS idx_expense_created_by ON expense(created_by);
 
 -- Index for group-based expense queries
-CREATE INDEX idx_expense_group ON expense(group_id);
+CREATE INDEX IF NOT EXISTS idx_expense_group ON expense(group_id);
 
-CREATE TABLE expense_mapping (
+CREATE TABLE IF NOT EXISTS expense_mapping (
     expense_id UUID NOT NULL,
     user_id UUID NOT NULL,
     PRIMARY KEY (expense_id, user_id)
@@ -49,17 +56,17 @@ CREATE TABLE expense_mapping (
 
 
 -- Index for fetching all expenses of a users
-CREATE INDEX idx_expense_mapping_user ON expense_mapping(user_id);
+CREATE INDEX IF NOT EXISTS idx_expense_mapping_user ON expense_mapping(user_id);
 
 
-CREATE TABLE group_members (
+CREATE TABLE IF NOT EXISTS group_members (
     user_id UUID NOT NULL,
     group_id UUID NOT NULL,
     PRIMARY KEY (user_id, group_id)
 );
 
 
-CREATE TABLE friends (
+CREATE TABLE IF NOT EXISTS friends (
     user_id UUID NOT NULL,
     friend_id UUID NOT NULL,
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
@@ -67,5 +74,5 @@ CREATE TABLE friends (
 );
 
 -- Index for efficient searching
-CREATE INDEX idx_user_friends ON friends(user_id);
-CREATE INDEX idx_friend_users ON friends(friend_id);
\ No newline at end of file
+CREATE INDEX IF NOT EXISTS idx_user_friends ON friends(user_id);
+CREATE INDEX IF NOT EXISTS idx_friend_users ON friends(friend_id);
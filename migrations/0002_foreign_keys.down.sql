@@ -0,0 +1,21 @@
+DROP INDEX IF EXISTS idx_group_members_group;
+
+ALTER TABLE friends
+    DROP CONSTRAINT IF EXISTS fk_friends_friend,
+    DROP CONSTRAINT IF EXISTS fk_friends_user;
+
+ALTER TABLE group_members
+    DROP CONSTRAINT IF EXISTS fk_group_members_group,
+    DROP CONSTRAINT IF EXISTS fk_group_members_user;
+
+ALTER TABLE expense_mapping
+    DROP CONSTRAINT IF EXISTS fk_expense_mapping_user,
+    DROP CONSTRAINT IF EXISTS fk_expense_mapping_expense;
+
+ALTER TABLE expense
+    DROP CONSTRAINT IF EXISTS fk_expense_group,
+    DROP CONSTRAINT IF EXISTS fk_expense_settled_by,
+    DROP CONSTRAINT IF EXISTS fk_expense_created_by;
+
+ALTER TABLE "group"
+    DROP CONSTRAINT IF EXISTS fk_group_admin;
@@ -0,0 +1,47 @@
+-- Foreign keys between expenses, groups, memberships, friends and users.
+-- Orphaned rows left behind by earlier deletes are removed first, otherwise
+-- the constraints cannot be validated.
+
+DELETE FROM group_members gm
+WHERE NOT EXISTS (SELECT 1 FROM "group" g WHERE g.id = gm.group_id)
+   OR NOT EXISTS (SELECT 1 FROM "users" u WHERE u.id = gm.user_id);
+
+DELETE FROM expense e
+WHERE e.group_id IS NOT NULL
+  AND NOT EXISTS (SELECT 1 FROM "group" g WHERE g.id = e.group_id);
+
+DELETE FROM expense_mapping em
+WHERE NOT EXISTS (SELECT 1 FROM expense e WHERE e.id = em.expense_id)
+   OR NOT EXISTS (SELECT 1 FROM "users" u WHERE u.id = em.user_id);
+
+DELETE FROM friends f
+WHERE NOT EXISTS (SELECT 1 FROM "users" u WHERE u.id = f.user_id)
+   OR NOT EXISTS (SELECT 1 FROM "users" u WHERE u.id = f.friend_id);
+
+UPDATE expense e SET settled_by = NULL
+WHERE e.settled_by IS NOT NULL
+  AND NOT EXISTS (SELECT 1 FROM "users" u WHERE u.id = e.settled_by);
+
+-- a group cannot outlive its admin, reassign admin before deleting a user
+ALTER TABLE "group"
+    ADD CONSTRAINT fk_group_admin FOREIGN KEY (admin_id) REFERENCES "users"(id) ON DELETE RESTRICT;
+
+ALTER TABLE expense
+    ADD CONSTRAINT fk_expense_created_by FOREIGN KEY (created_by) REFERENCES "users"(id) ON DELETE RESTRICT,
+    ADD CONSTRAINT fk_expense_settled_by FOREIGN KEY (settled_by) REFERENCES "users"(id) ON DELETE SET NULL,
+    ADD CONSTRAINT fk_expense_group FOREIGN KEY (group_id) REFERENCES "group"(id) ON DELETE CASCADE;
+
+ALTER TABLE expense_mapping
+    ADD CONSTRAINT fk_expense_mapping_expense FOREIGN KEY (expense_id) REFERENCES expense(id) ON DELETE CASCADE,
+    ADD CONSTRAINT fk_expense_mapping_user FOREIGN KEY (user_id) REFERENCES "users"(id) ON DELETE CASCADE;
+
+ALTER TABLE group_members
+    ADD CONSTRAINT fk_group_members_user FOREIGN KEY (user_id) REFERENCES "users"(id) ON DELETE CASCADE,
+    ADD CONSTRAINT fk_group_members_group FOREIGN KEY (group_id) REFERENCES "group"(id) ON DELETE CASCADE;
+
+ALTER TABLE friends
+    ADD CONSTRAINT fk_friends_user FOREIGN KEY (user_id) REFERENCES "users"(id) ON DELETE CASCADE,
+    ADD CONSTRAINT fk_friends_friend FOREIGN KEY (friend_id) REFERENCES "users"(id) ON DELETE CASCADE;
+
+-- Index for cascading deletes from group_members by group
+CREATE INDEX idx_group_members_group ON group_members(group_id);
@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS idx_friends_user_created;
+DROP INDEX IF EXISTS idx_expense_created;
+DROP INDEX IF EXISTS idx_expense_group_created;
+
+ALTER TABLE friends ALTER COLUMN created_at DROP NOT NULL;
+ALTER TABLE expense ALTER COLUMN created_at DROP NOT NULL;
+
+ALTER TABLE "group" DROP COLUMN IF EXISTS created_at;
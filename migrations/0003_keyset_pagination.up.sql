@@ -0,0 +1,14 @@
+-- Keyset pagination orders every list by (created_at, id), so the sort
+-- columns must be non null and indexed.
+
+ALTER TABLE "group" ADD COLUMN created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW();
+
+UPDATE expense SET created_at = NOW() WHERE created_at IS NULL;
+ALTER TABLE expense ALTER COLUMN created_at SET NOT NULL;
+
+UPDATE friends SET created_at = CURRENT_TIMESTAMP WHERE created_at IS NULL;
+ALTER TABLE friends ALTER COLUMN created_at SET NOT NULL;
+
+CREATE INDEX idx_expense_group_created ON expense(group_id, created_at DESC, id DESC);
+CREATE INDEX idx_expense_created ON expense(created_at DESC, id DESC);
+CREATE INDEX idx_friends_user_created ON friends(user_id, created_at DESC, friend_id DESC);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_expense_category;
+DROP INDEX IF EXISTS idx_expense_description_fts;
+
+ALTER TABLE expense DROP COLUMN IF EXISTS category;
@@ -0,0 +1,8 @@
+-- Expense search matches descriptions with full-text search and filters by
+-- category. The expression below must stay identical to the one in the
+-- SearchExpenses query for the index to be used.
+
+ALTER TABLE expense ADD COLUMN category TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX idx_expense_description_fts ON expense USING GIN (to_tsvector('english', coalesce(description, '')));
+CREATE INDEX idx_expense_category ON expense(lower(category));
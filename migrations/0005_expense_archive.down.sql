@@ -0,0 +1,15 @@
+-- archived expenses are moved back so reverting loses no data
+
+DROP INDEX IF EXISTS idx_expense_settled_updated;
+
+INSERT INTO expense (id, description, amount, split, status, settled_by, created_by, payee, group_id, created_at, updated_at, version, category)
+SELECT id, description, amount, split, status, settled_by, created_by, payee, group_id, created_at, updated_at, version, category
+FROM expense_archive
+ON CONFLICT (id) DO NOTHING;
+
+INSERT INTO expense_mapping (expense_id, user_id)
+SELECT expense_id, user_id FROM expense_mapping_archive
+ON CONFLICT DO NOTHING;
+
+DROP TABLE IF EXISTS expense_mapping_archive;
+DROP TABLE IF EXISTS expense_archive;
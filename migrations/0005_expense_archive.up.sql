@@ -0,0 +1,35 @@
+-- Settled expenses untouched for a while are moved here by the archival job
+-- so the live expense table stays small. Columns mirror expense in the same
+-- order, queries read both tables with UNION ALL.
+
+CREATE TABLE expense_archive (
+    id UUID PRIMARY KEY,
+    description TEXT,
+    amount DECIMAL(19, 4) NOT NULL,
+    split JSONB NOT NULL,
+    status TEXT NOT NULL,
+    settled_by UUID,
+    created_by UUID NOT NULL,
+    payee JSONB NOT NULL,
+    group_id UUID,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE,
+    version INTEGER NOT NULL,
+    category TEXT NOT NULL DEFAULT '',
+    archived_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    CONSTRAINT fk_expense_archive_created_by FOREIGN KEY (created_by) REFERENCES "users"(id) ON DELETE RESTRICT,
+    CONSTRAINT fk_expense_archive_settled_by FOREIGN KEY (settled_by) REFERENCES "users"(id) ON DELETE SET NULL,
+    CONSTRAINT fk_expense_archive_group FOREIGN KEY (group_id) REFERENCES "group"(id) ON DELETE CASCADE
+);
+
+CREATE TABLE expense_mapping_archive (
+    expense_id UUID NOT NULL REFERENCES expense_archive(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES "users"(id) ON DELETE CASCADE,
+    PRIMARY KEY (expense_id, user_id)
+);
+
+CREATE INDEX idx_expense_archive_group_created ON expense_archive(group_id, created_at DESC, id DESC);
+CREATE INDEX idx_expense_mapping_archive_user ON expense_mapping_archive(user_id);
+
+-- the archival job scans for old settled expenses
+CREATE INDEX idx_expense_settled_updated ON expense(updated_at) WHERE status = 'SETTLED';
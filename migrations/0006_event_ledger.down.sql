@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS ledger_balance_entries;
+DROP TRIGGER IF EXISTS trg_ledger_events_append_only ON ledger_events;
+DROP FUNCTION IF EXISTS ledger_events_append_only();
+DROP TABLE IF EXISTS ledger_events;
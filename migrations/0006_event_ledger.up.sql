@@ -0,0 +1,43 @@
+-- Event sourced ledger. Every expense and group change is appended to
+-- ledger_events, the expense tables and ledger_balance_entries are
+-- projections of it that `splitExpense ledger replay` can rebuild.
+
+CREATE TABLE ledger_events (
+    sequence BIGSERIAL PRIMARY KEY,
+    id UUID NOT NULL UNIQUE,
+    type TEXT NOT NULL,
+    aggregate_type TEXT NOT NULL,
+    aggregate_id UUID NOT NULL,
+    aggregate_version INTEGER NOT NULL,
+    payload JSONB NOT NULL,
+    actor_id UUID,
+    occurred_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_ledger_events_aggregate ON ledger_events(aggregate_type, aggregate_id, sequence);
+
+CREATE FUNCTION ledger_events_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'ledger_events is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_ledger_events_append_only
+    BEFORE UPDATE OR DELETE ON ledger_events
+    FOR EACH ROW EXECUTE FUNCTION ledger_events_append_only();
+
+-- One row per expense participant with what they paid and their share.
+-- Balances sum the DRAFT rows, category totals sum every row.
+CREATE TABLE ledger_balance_entries (
+    expense_id UUID NOT NULL,
+    user_id UUID NOT NULL,
+    group_id UUID,
+    category TEXT NOT NULL DEFAULT '',
+    status TEXT NOT NULL,
+    paid DECIMAL(19, 4) NOT NULL,
+    share DECIMAL(19, 4) NOT NULL,
+    PRIMARY KEY (expense_id, user_id)
+);
+
+CREATE INDEX idx_ledger_balance_entries_user ON ledger_balance_entries(user_id);
+CREATE INDEX idx_ledger_balance_entries_group ON ledger_balance_entries(group_id);
@@ -0,0 +1,26 @@
+-- Transactional outbox. Events are inserted in the same transaction as the
+-- change they describe, the dispatcher delivers them to the configured sinks
+-- at least once and in order per aggregate.
+
+CREATE TABLE outbox_events (
+    sequence BIGSERIAL PRIMARY KEY,
+    id UUID NOT NULL UNIQUE,
+    type TEXT NOT NULL,
+    aggregate_type TEXT NOT NULL,
+    aggregate_id UUID NOT NULL,
+    aggregate_version INTEGER NOT NULL,
+    payload JSONB NOT NULL,
+    actor_id UUID,
+    occurred_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    last_error TEXT,
+    delivered_at TIMESTAMP WITH TIME ZONE,
+    -- dead_at is set once an event runs out of attempts, it no longer holds back its aggregate
+    dead_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX idx_outbox_events_pending ON outbox_events(next_attempt_at, sequence)
+    WHERE delivered_at IS NULL AND dead_at IS NULL;
+CREATE INDEX idx_outbox_events_aggregate ON outbox_events(aggregate_type, aggregate_id, sequence)
+    WHERE delivered_at IS NULL AND dead_at IS NULL;
@@ -0,0 +1,40 @@
+DROP POLICY IF EXISTS expense_mapping_archive_select ON expense_mapping_archive;
+ALTER TABLE expense_mapping_archive DISABLE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS expense_archive_select ON expense_archive;
+ALTER TABLE expense_archive DISABLE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS expense_mapping_select ON expense_mapping;
+DROP POLICY IF EXISTS expense_mapping_insert ON expense_mapping;
+DROP POLICY IF EXISTS expense_mapping_delete ON expense_mapping;
+ALTER TABLE expense_mapping DISABLE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS expense_select ON expense;
+DROP POLICY IF EXISTS expense_insert ON expense;
+DROP POLICY IF EXISTS expense_update ON expense;
+DROP POLICY IF EXISTS expense_delete ON expense;
+ALTER TABLE expense DISABLE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS group_members_select ON group_members;
+DROP POLICY IF EXISTS group_members_insert ON group_members;
+DROP POLICY IF EXISTS group_members_delete ON group_members;
+ALTER TABLE group_members DISABLE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS group_select ON "group";
+DROP POLICY IF EXISTS group_insert ON "group";
+DROP POLICY IF EXISTS group_update ON "group";
+DROP POLICY IF EXISTS group_delete ON "group";
+ALTER TABLE "group" DISABLE ROW LEVEL SECURITY;
+
+DROP FUNCTION IF EXISTS rls_can_see_expense(UUID);
+DROP FUNCTION IF EXISTS rls_is_expense_participant(UUID);
+DROP FUNCTION IF EXISTS rls_is_group_member(UUID);
+DROP FUNCTION IF EXISTS rls_user_id();
+
+-- the role is cluster wide and may serve other databases, only its grants here are dropped
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'splitexpense_rls') THEN
+        DROP OWNED BY splitexpense_rls;
+    END IF;
+END
+$$;
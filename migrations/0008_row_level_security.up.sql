@@ -0,0 +1,110 @@
+-- Row level security for groups and expenses, enforced when the app runs with
+-- ROW_LEVEL_SECURITY=true. Authenticated requests then run in a transaction
+-- that switches to the splitexpense_rls role and sets app.user_id, so the
+-- policies below limit them to the groups and expenses of that user. The
+-- table owner is not subject to the policies, which keeps migrations and
+-- background jobs such as archival unrestricted.
+
+-- Creating the role needs CREATEROLE, without it the policies are still
+-- installed and ROW_LEVEL_SECURITY fails until an administrator creates the
+-- role and reruns the grants below.
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'splitexpense_rls') THEN
+        IF NOT EXISTS (SELECT 1 FROM pg_roles WHERE rolname = current_user AND (rolsuper OR rolcreaterole)) THEN
+            RAISE NOTICE 'role splitexpense_rls is missing and % cannot create it', current_user;
+            RETURN;
+        END IF;
+        CREATE ROLE splitexpense_rls NOLOGIN;
+    END IF;
+
+    EXECUTE format('GRANT splitexpense_rls TO %I', current_user);
+    GRANT USAGE ON SCHEMA public TO splitexpense_rls;
+    GRANT SELECT, INSERT, UPDATE, DELETE ON ALL TABLES IN SCHEMA public TO splitexpense_rls;
+    GRANT USAGE, SELECT ON ALL SEQUENCES IN SCHEMA public TO splitexpense_rls;
+    ALTER DEFAULT PRIVILEGES IN SCHEMA public GRANT SELECT, INSERT, UPDATE, DELETE ON TABLES TO splitexpense_rls;
+    ALTER DEFAULT PRIVILEGES IN SCHEMA public GRANT USAGE, SELECT ON SEQUENCES TO splitexpense_rls;
+END
+$$;
+
+CREATE FUNCTION rls_user_id() RETURNS UUID
+LANGUAGE sql STABLE AS $$
+    SELECT NULLIF(current_setting('app.user_id', true), '')::uuid
+$$;
+
+-- The helpers below are SECURITY DEFINER so they read the tables as their
+-- owner, policies that look at other protected tables would recurse otherwise.
+
+CREATE FUNCTION rls_is_group_member(gid UUID) RETURNS BOOLEAN
+LANGUAGE sql STABLE SECURITY DEFINER SET search_path = public AS $$
+    SELECT EXISTS (SELECT 1 FROM group_members WHERE group_id = gid AND user_id = rls_user_id())
+        OR EXISTS (SELECT 1 FROM "group" WHERE id = gid AND admin_id = rls_user_id())
+$$;
+
+CREATE FUNCTION rls_is_expense_participant(eid UUID) RETURNS BOOLEAN
+LANGUAGE sql STABLE SECURITY DEFINER SET search_path = public AS $$
+    SELECT EXISTS (SELECT 1 FROM expense_mapping WHERE expense_id = eid AND user_id = rls_user_id())
+        OR EXISTS (SELECT 1 FROM expense_mapping_archive WHERE expense_id = eid AND user_id = rls_user_id())
+$$;
+
+-- rls_can_see_expense is true when the stored expense eid is visible to the user
+CREATE FUNCTION rls_can_see_expense(eid UUID) RETURNS BOOLEAN
+LANGUAGE sql STABLE SECURITY DEFINER SET search_path = public AS $$
+    SELECT rls_is_expense_participant(eid)
+        OR EXISTS (
+            SELECT 1 FROM expense e WHERE e.id = eid
+              AND (e.created_by = rls_user_id() OR (e.group_id IS NOT NULL AND rls_is_group_member(e.group_id))))
+        OR EXISTS (
+            SELECT 1 FROM expense_archive e WHERE e.id = eid
+              AND (e.created_by = rls_user_id() OR (e.group_id IS NOT NULL AND rls_is_group_member(e.group_id))))
+$$;
+
+-- groups: members see them, only the admin creates, changes or deletes them
+ALTER TABLE "group" ENABLE ROW LEVEL SECURITY;
+CREATE POLICY group_select ON "group" FOR SELECT
+    USING (admin_id = rls_user_id() OR rls_is_group_member(id));
+CREATE POLICY group_insert ON "group" FOR INSERT
+    WITH CHECK (admin_id = rls_user_id());
+CREATE POLICY group_update ON "group" FOR UPDATE
+    USING (admin_id = rls_user_id()) WITH CHECK (admin_id = rls_user_id());
+CREATE POLICY group_delete ON "group" FOR DELETE
+    USING (admin_id = rls_user_id());
+
+-- memberships: members see each other, only members add people, anyone may leave
+ALTER TABLE group_members ENABLE ROW LEVEL SECURITY;
+CREATE POLICY group_members_select ON group_members FOR SELECT
+    USING (user_id = rls_user_id() OR rls_is_group_member(group_id));
+CREATE POLICY group_members_insert ON group_members FOR INSERT
+    WITH CHECK (rls_is_group_member(group_id));
+CREATE POLICY group_members_delete ON group_members FOR DELETE
+    USING (user_id = rls_user_id() OR EXISTS (SELECT 1 FROM "group" g WHERE g.id = group_id AND g.admin_id = rls_user_id()));
+
+-- expenses: visible to their creator, participants and group members. An
+-- update is an upsert, so the insert check also admits visible expenses.
+ALTER TABLE expense ENABLE ROW LEVEL SECURITY;
+CREATE POLICY expense_select ON expense FOR SELECT
+    USING (created_by = rls_user_id() OR (group_id IS NOT NULL AND rls_is_group_member(group_id)) OR rls_is_expense_participant(id));
+CREATE POLICY expense_insert ON expense FOR INSERT
+    WITH CHECK ((group_id IS NULL OR rls_is_group_member(group_id)) AND (created_by = rls_user_id() OR rls_can_see_expense(id)));
+CREATE POLICY expense_update ON expense FOR UPDATE
+    USING (created_by = rls_user_id() OR (group_id IS NOT NULL AND rls_is_group_member(group_id)) OR rls_is_expense_participant(id))
+    WITH CHECK (group_id IS NULL OR rls_is_group_member(group_id));
+CREATE POLICY expense_delete ON expense FOR DELETE
+    USING (created_by = rls_user_id() OR (group_id IS NOT NULL AND rls_is_group_member(group_id)) OR rls_is_expense_participant(id));
+
+ALTER TABLE expense_mapping ENABLE ROW LEVEL SECURITY;
+CREATE POLICY expense_mapping_select ON expense_mapping FOR SELECT
+    USING (user_id = rls_user_id() OR rls_can_see_expense(expense_id));
+CREATE POLICY expense_mapping_insert ON expense_mapping FOR INSERT
+    WITH CHECK (rls_can_see_expense(expense_id));
+CREATE POLICY expense_mapping_delete ON expense_mapping FOR DELETE
+    USING (rls_can_see_expense(expense_id));
+
+-- the archive is read only for requests, archival runs as the owner
+ALTER TABLE expense_archive ENABLE ROW LEVEL SECURITY;
+CREATE POLICY expense_archive_select ON expense_archive FOR SELECT
+    USING (created_by = rls_user_id() OR (group_id IS NOT NULL AND rls_is_group_member(group_id)) OR rls_is_expense_participant(id));
+
+ALTER TABLE expense_mapping_archive ENABLE ROW LEVEL SECURITY;
+CREATE POLICY expense_mapping_archive_select ON expense_mapping_archive FOR SELECT
+    USING (user_id = rls_user_id() OR rls_can_see_expense(expense_id));
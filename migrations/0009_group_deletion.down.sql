@@ -0,0 +1,19 @@
+DROP POLICY IF EXISTS expense_mapping_archive_insert ON expense_mapping_archive;
+DROP POLICY IF EXISTS expense_archive_insert ON expense_archive;
+
+-- deleted groups come back with their members, their expenses stay archived
+INSERT INTO "group" (id, name, description, admin_id, version, created_at)
+SELECT id, name, description, admin_id, version, created_at FROM group_archive;
+INSERT INTO group_members (group_id, user_id)
+SELECT group_id, user_id FROM group_members_archive;
+
+DROP TABLE IF EXISTS group_members_archive;
+DROP TABLE IF EXISTS group_archive;
+DROP FUNCTION IF EXISTS rls_was_group_member(UUID);
+
+ALTER TABLE expense
+    DROP CONSTRAINT fk_expense_group,
+    ADD CONSTRAINT fk_expense_group FOREIGN KEY (group_id) REFERENCES "group"(id) ON DELETE CASCADE;
+
+ALTER TABLE expense_archive
+    ADD CONSTRAINT fk_expense_archive_group FOREIGN KEY (group_id) REFERENCES "group"(id) ON DELETE CASCADE;
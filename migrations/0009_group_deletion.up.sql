@@ -0,0 +1,56 @@
+-- Deleting a group moves it, its memberships and every group expense to the
+-- archive tables in one transaction, so balances stop counting them while the
+-- history is kept. The deletion policy decides whether unsettled expenses may
+-- be archived or make the deletion fail.
+
+CREATE TABLE group_archive (
+    id UUID PRIMARY KEY,
+    name TEXT NOT NULL,
+    description TEXT NOT NULL,
+    admin_id UUID NOT NULL REFERENCES "users"(id) ON DELETE RESTRICT,
+    version INTEGER NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    deleted_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE group_members_archive (
+    group_id UUID NOT NULL REFERENCES group_archive(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES "users"(id) ON DELETE CASCADE,
+    PRIMARY KEY (group_id, user_id)
+);
+
+CREATE INDEX idx_group_members_archive_user ON group_members_archive(user_id);
+
+-- archived expenses outlive their group, which then lives in group_archive
+ALTER TABLE expense_archive DROP CONSTRAINT fk_expense_archive_group;
+
+-- a group with live expenses is only removed through the deletion policy,
+-- never by a cascade that silently drops them
+ALTER TABLE expense
+    DROP CONSTRAINT fk_expense_group,
+    ADD CONSTRAINT fk_expense_group FOREIGN KEY (group_id) REFERENCES "group"(id) ON DELETE RESTRICT;
+
+-- row level security, see 0008. Deleting runs with the request's role, so
+-- the archive tables admit the copies a group member makes.
+CREATE FUNCTION rls_was_group_member(gid UUID) RETURNS BOOLEAN
+LANGUAGE sql STABLE SECURITY DEFINER SET search_path = public AS $$
+    SELECT EXISTS (SELECT 1 FROM group_members_archive WHERE group_id = gid AND user_id = rls_user_id())
+        OR EXISTS (SELECT 1 FROM group_archive WHERE id = gid AND admin_id = rls_user_id())
+$$;
+
+ALTER TABLE group_archive ENABLE ROW LEVEL SECURITY;
+CREATE POLICY group_archive_select ON group_archive FOR SELECT
+    USING (admin_id = rls_user_id() OR rls_was_group_member(id));
+CREATE POLICY group_archive_insert ON group_archive FOR INSERT
+    WITH CHECK (admin_id = rls_user_id());
+
+ALTER TABLE group_members_archive ENABLE ROW LEVEL SECURITY;
+CREATE POLICY group_members_archive_select ON group_members_archive FOR SELECT
+    USING (user_id = rls_user_id() OR rls_was_group_member(group_id));
+CREATE POLICY group_members_archive_insert ON group_members_archive FOR INSERT
+    WITH CHECK (rls_is_group_member(group_id));
+
+CREATE POLICY expense_archive_insert ON expense_archive FOR INSERT
+    WITH CHECK (group_id IS NOT NULL AND rls_is_group_member(group_id));
+CREATE POLICY expense_mapping_archive_insert ON expense_mapping_archive FOR INSERT
+    WITH CHECK (rls_can_see_expense(expense_id));
@@ -0,0 +1,18 @@
+-- Responses to requests sent with an Idempotency-Key header, so a retried
+-- request gets the first response instead of running again. A row without
+-- status is a request still running.
+
+CREATE TABLE idempotency_keys (
+    user_id UUID NOT NULL REFERENCES "users"(id) ON DELETE CASCADE,
+    idempotency_key TEXT NOT NULL,
+    -- request_hash covers the method, path and body the key was first used with
+    request_hash TEXT NOT NULL,
+    status INTEGER,
+    content_type TEXT NOT NULL DEFAULT '',
+    response BYTEA,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    PRIMARY KEY (user_id, idempotency_key)
+);
+
+CREATE INDEX idx_idempotency_keys_expires_at ON idempotency_keys(expires_at);
@@ -0,0 +1,269 @@
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"splitExpense/expense"
+
+	lodash "github.com/samber/lo"
+)
+
+// The admin methods back the maintenance commands of the server binary. They
+// act as an operator, not as a user, so they skip the membership checks of
+// the API methods.
+
+// AdminCreateUser creates a user like signup does, optionally already verified
+func (e *ExpenseAppImpl) AdminCreateUser(ctx context.Context, name, email, password string, verified bool) (*expense.User, error) {
+	user, appErr := e.UserSignup(ctx, name, email, password)
+	if appErr != nil {
+		return nil, appErr
+	}
+	if !verified {
+		return user, nil
+	}
+	return e.userService.VerifyUser(ctx, user.ID)
+}
+
+// VerifyUser marks the user, given by id or email, as verified
+func (e *ExpenseAppImpl) VerifyUser(ctx context.Context, idOrEmail string) (*expense.User, error) {
+	userId, err := e.resolveUser(ctx, idOrEmail)
+	if err != nil {
+		return nil, err
+	}
+	user, err := e.userService.VerifyUser(ctx, userId)
+	if err != nil {
+		return nil, expense.ErrServiceCause(err)
+	}
+	return user, nil
+}
+
+// ResetPassword replaces the password of the user given by id or email
+func (e *ExpenseAppImpl) ResetPassword(ctx context.Context, idOrEmail, password string) error {
+	validator := NewValidator().Password(password)
+	if !validator.Ok() {
+		return validator.Err()
+	}
+	userId, err := e.resolveUser(ctx, idOrEmail)
+	if err != nil {
+		return err
+	}
+	if err := e.userService.ResetPassword(ctx, userId, password); err != nil {
+		return expense.ErrServiceCause(err)
+	}
+	return nil
+}
+
+// MemberBalance is what a group member is owed and owes on the group's draft expenses
+type MemberBalance struct {
+	User     expense.User `json:"user"`
+	Owed     float64      `json:"owed"`
+	Borrowed float64      `json:"borrowed"`
+	Net      float64      `json:"net"`
+}
+
+type GroupInspection struct {
+	Group             expense.Group   `json:"group"`
+	Members           []MemberBalance `json:"members"`
+	Expenses          int             `json:"expenses"`
+	UnsettledExpenses int             `json:"unsettledExpenses"`
+}
+
+func (e *ExpenseAppImpl) InspectGroup(ctx context.Context, groupId string) (*GroupInspection, error) {
+	group, err := e.userService.GetGroupById(ctx, groupId)
+	if err != nil {
+		return nil, expense.ErrNotFound("group not found")
+	}
+	members, err := e.storage.FetchGroupMembers(ctx, groupId)
+	if err != nil {
+		return nil, err
+	}
+	inspection := &GroupInspection{Group: *group, Members: []MemberBalance{}}
+	for _, member := range members {
+		owed, borrowed, err := e.expenseService.CalculateUserRunningExpensesInGroup(ctx, member.ID, group)
+		if err != nil {
+			return nil, err
+		}
+		member.Password = ""
+		inspection.Members = append(inspection.Members, MemberBalance{User: member, Owed: owed, Borrowed: borrowed, Net: owed - borrowed})
+	}
+	if inspection.Expenses, err = e.storage.FetchExpenseCountByGroup(ctx, groupId); err != nil {
+		return nil, err
+	}
+	if inspection.UnsettledExpenses, err = e.storage.CountUnsettledGroupExpenses(ctx, groupId); err != nil {
+		return nil, err
+	}
+	return inspection, nil
+}
+
+// RecomputeBalances rebuilds the balance projection of the event ledger, it
+// needs event sourcing enabled
+func (e *ExpenseAppImpl) RecomputeBalances(ctx context.Context) (int, error) {
+	return e.ReplayLedger(ctx, "balances")
+}
+
+// IntegrityIssue is one inconsistency found by CheckIntegrity
+type IntegrityIssue struct {
+	Check   string `json:"check"`
+	Subject string `json:"subject"`
+	Detail  string `json:"detail"`
+}
+
+const integrityBatchSize = 500
+
+// CheckIntegrity looks for data the API would not produce: expenses whose
+// split or payers do not add up, people in an expense without a mapping,
+// group expenses with participants outside the group and groups whose admin
+// left. It reads only and reports what it finds.
+func (e *ExpenseAppImpl) CheckIntegrity(ctx context.Context) ([]IntegrityIssue, error) {
+	issues := []IntegrityIssue{}
+	report := func(check, subject, format string, args ...any) {
+		issues = append(issues, IntegrityIssue{Check: check, Subject: subject, Detail: fmt.Sprintf(format, args...)})
+	}
+
+	err := e.dbStorage.ScanExpenses(ctx, integrityBatchSize, func(exp expense.Expense) error {
+		if exp.SplitW.Split == nil || exp.PayeeW.Payer == nil {
+			report("unreadable", exp.ID, "split or payer can not be decoded")
+			return nil
+		}
+		if total := exp.SplitW.Split.ComputeTotal(); !e.verifyAmount(total, exp.Amount) {
+			report("split-total", exp.ID, "split adds up to %.2f, amount is %.2f", total, exp.Amount)
+		}
+		if total := exp.PayeeW.Payer.GetTotal(); !e.verifyAmount(total, exp.Amount) {
+			report("payer-total", exp.ID, "payers add up to %.2f, amount is %.2f", total, exp.Amount)
+		}
+		if exp.Status == expense.ExpenseSettled && exp.SettledBy == "" {
+			report("settled-by", exp.ID, "settled without settled_by")
+		}
+
+		mapped, err := e.dbStorage.FetchExpenseParticipants(ctx, exp.ID)
+		if err != nil {
+			return err
+		}
+		involved := lodash.Union(lodash.Keys(exp.PayeeW.Payer.GetPayers()), lodash.Keys(exp.SplitW.Split.GetPayeeSplit()))
+		if missing := lodash.Without(involved, mapped...); len(missing) > 0 {
+			report("participants", exp.ID, "no expense mapping for %s", strings.Join(missing, ", "))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	nonMembers, err := e.dbStorage.FetchGroupExpenseNonMembers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range nonMembers {
+		report("group-membership", p.ExpenseId, "participant %s is not a member of group %s", p.UserId, p.GroupId)
+	}
+
+	groups, err := e.dbStorage.FetchGroupsWithoutAdminMember(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, g := range groups {
+		report("group-admin", g.Id, "admin %s is not a member", g.Admin)
+	}
+	return issues, nil
+}
+
+// SeedOptions sizes the demo data created by Seed
+type SeedOptions struct {
+	Users       int
+	Groups      int
+	Expenses    int
+	Password    string
+	EmailDomain string
+}
+
+type SeedReport struct {
+	Users    []expense.User  `json:"users"`
+	Groups   []expense.Group `json:"groups"`
+	Expenses int             `json:"expenses"`
+	Password string          `json:"password"`
+}
+
+var seedDescriptions = []string{"Groceries", "Dinner", "Cab", "Rent", "Movie tickets", "Coffee", "Electricity bill", "Snacks"}
+
+// Seed creates demo users who are all friends, groups they all belong to and
+// expenses split equally among each group. Users and groups that already
+// exist are reused, so seeding twice only adds expenses.
+func (e *ExpenseAppImpl) Seed(ctx context.Context, opts SeedOptions) (*SeedReport, error) {
+	if opts.Users < 2 {
+		return nil, expense.ErrValidation("seeding needs at least two users")
+	}
+	if opts.Expenses > 0 && opts.Groups < 1 {
+		return nil, expense.ErrValidation("seeding expenses needs at least one group")
+	}
+	report := &SeedReport{Users: []expense.User{}, Groups: []expense.Group{}, Password: opts.Password}
+
+	for i := 1; i <= opts.Users; i++ {
+		email := fmt.Sprintf("seed%d@%s", i, opts.EmailDomain)
+		user, err := e.userService.FetchUserCredentials(ctx, email)
+		if err != nil {
+			user, err = e.AdminCreateUser(ctx, fmt.Sprintf("Seed User %d", i), email, opts.Password, true)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("seeding %s: %w", email, err)
+		}
+		user.Password = ""
+		report.Users = append(report.Users, *user)
+	}
+
+	for _, user := range report.Users {
+		for _, friend := range report.Users {
+			if user.ID == friend.ID {
+				continue
+			}
+			if _, err := e.userService.AddFriend(ctx, user.ID, friend.ID); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	admin := report.Users[0]
+	existing, err := e.userService.GetAssociatedGroups(ctx, admin.ID)
+	if err != nil {
+		return nil, err
+	}
+	for i := 1; i <= opts.Groups; i++ {
+		name := fmt.Sprintf("Seed Group %d", i)
+		group, found := lodash.Find(existing, func(g expense.Group) bool { return g.Name == name })
+		if !found {
+			created, err := e.CreateGroup(ctx, admin.ID, name, "demo data")
+			if err != nil {
+				return nil, err
+			}
+			group = *created
+		}
+		for _, member := range report.Users[1:] {
+			if _, err := e.userService.JoinGroup(ctx, member.ID, group.Id); err != nil {
+				return nil, err
+			}
+		}
+		report.Groups = append(report.Groups, group)
+	}
+
+	memberIds := lodash.Map(report.Users, func(u expense.User, _ int) string { return u.ID })
+	for i := 0; i < opts.Expenses; i++ {
+		group := report.Groups[i%len(report.Groups)]
+		payer := report.Users[i%len(report.Users)]
+		amount := float64(10 + (i*37)%90)
+		_, err := e.CreateExpense(ctx, payer.ID, expense.ExpenseCreate{
+			Description:    seedDescriptions[i%len(seedDescriptions)],
+			Category:       "demo",
+			Amount:         amount,
+			SplitW:         expense.SplitWrapper{Type: "equal", Split: &expense.EqualSplit{Payee: memberIds, TotalAmount: amount}},
+			PayeeW:         expense.PayerWrapper{Type: "single", Payer: &expense.SinglePayer{Payer: payer.ID, Amount: amount}},
+			IsGroupExpense: true,
+			GroupId:        group.Id,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("seeding expense %d: %w", i+1, err)
+		}
+		report.Expenses++
+	}
+	return report, nil
+}
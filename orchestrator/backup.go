@@ -0,0 +1,95 @@
+package orchestrator
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"splitExpense/backup"
+	"splitExpense/expense"
+)
+
+// ExportAccountBackup writes an archive of userId's account, friends and groups to w
+func (e *ExpenseAppImpl) ExportAccountBackup(ctx context.Context, userId string, w io.Writer) error {
+	return e.ExportBackup(ctx, backup.KindAccount, userId, w)
+}
+
+// ExportGroupBackup writes an archive of a group userId belongs to
+func (e *ExpenseAppImpl) ExportGroupBackup(ctx context.Context, userId string, groupId string, w io.Writer) error {
+	validator := NewValidator().NonEmptyID(userId).NonEmptyID(groupId)
+	if !validator.Ok() {
+		return validator.Err()
+	}
+	isMember, err := e.storage.CheckUserExistsInGroup(ctx, userId, groupId)
+	if err != nil {
+		return expense.ErrServiceCause(err)
+	}
+	if !isMember {
+		return expense.ErrForbidden("user is not a member of the group, cannot export it")
+	}
+	return e.ExportBackup(ctx, backup.KindGroup, groupId, w)
+}
+
+// ExportBackup writes an archive without checking who asks for it, for
+// operators. An account subject may be given by id or email.
+func (e *ExpenseAppImpl) ExportBackup(ctx context.Context, kind backup.Kind, subject string, w io.Writer) error {
+	var snapshot *backup.Snapshot
+	var err error
+	switch kind {
+	case backup.KindAccount:
+		subject, err = e.resolveUser(ctx, subject)
+		if err != nil {
+			return err
+		}
+		snapshot, err = backup.ExportAccount(ctx, e.storage, e.eventStore(), subject)
+	case backup.KindGroup:
+		snapshot, err = backup.ExportGroup(ctx, e.storage, e.eventStore(), subject)
+	default:
+		return expense.ErrValidation(fmt.Sprintf("unknown backup kind %q", kind))
+	}
+	if err != nil {
+		return err
+	}
+	return backup.Write(w, kind, subject, snapshot)
+}
+
+// RestoreBackupAs imports an archive for operators, there is no endpoint as
+// an archive can name any user. as is an optional id or email of the user
+// replacing the archived account owner.
+func (e *ExpenseAppImpl) RestoreBackupAs(ctx context.Context, data []byte, as string) (*backup.Report, error) {
+	opts := backup.RestoreOptions{}
+	if as != "" {
+		userId, err := e.resolveUser(ctx, as)
+		if err != nil {
+			return nil, err
+		}
+		opts.AsUserId = userId
+	}
+	manifest, snapshot, err := backup.Read(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, expense.ErrValidation(err.Error())
+	}
+	return backup.Restore(ctx, e.storage, manifest, snapshot, opts)
+}
+
+// resolveUser accepts a user id or email and returns the id
+func (e *ExpenseAppImpl) resolveUser(ctx context.Context, idOrEmail string) (string, error) {
+	if !strings.Contains(idOrEmail, "@") {
+		return idOrEmail, nil
+	}
+	user, err := e.storage.FetchUserByEmail(ctx, idOrEmail)
+	if err != nil {
+		return "", expense.ErrNotFound(fmt.Sprintf("no user with email %s", idOrEmail))
+	}
+	return user.ID, nil
+}
+
+// eventStore is the ledger when event sourcing is enabled, nil otherwise
+func (e *ExpenseAppImpl) eventStore() expense.EventStore {
+	if e.ledger == nil {
+		return nil
+	}
+	return e.ledger
+}
@@ -0,0 +1,61 @@
+package orchestrator
+
+import (
+	"context"
+
+	"splitExpense/expense"
+	"splitExpense/service"
+
+	lodash "github.com/samber/lo"
+)
+
+// The batch reads serve the GraphQL loaders. They skip the per-call checks of
+// the API methods, the resolvers only ask for users and groups referenced by
+// data the caller was already allowed to read.
+
+func (e *ExpenseAppImpl) LoadUsers(ctx context.Context, ids []string) (map[string]expense.User, error) {
+	return e.dbStorage.FetchUsersByIds(ctx, ids)
+}
+
+func (e *ExpenseAppImpl) LoadGroups(ctx context.Context, ids []string) (map[string]expense.Group, error) {
+	return e.dbStorage.FetchGroupsByIds(ctx, ids)
+}
+
+func (e *ExpenseAppImpl) LoadGroupMembers(ctx context.Context, groupIds []string) (map[string][]expense.User, error) {
+	return e.dbStorage.FetchGroupMembersByGroupIds(ctx, groupIds)
+}
+
+// LoadGroupExpenses returns a page of each group's expenses from userId's
+// side, the batched GetGroupExpenses. Membership is checked by the caller.
+func (e *ExpenseAppImpl) LoadGroupExpenses(ctx context.Context, userId string, groupIds []string, includeArchived bool, page expense.PageRequest) (map[string]*expense.GroupExpenseHistory, error) {
+	stored, err := e.dbStorage.FetchGroupsExpensesPage(ctx, groupIds, includeArchived, page)
+	if err != nil {
+		return nil, err
+	}
+	pages := make(map[string]*expense.GroupExpenseHistory, len(stored))
+	for groupId, history := range stored {
+		pages[groupId] = service.DetailUserExpenses(userId, history)
+	}
+	return pages, nil
+}
+
+// GetGroup returns the group if the user is one of its members
+func (e *ExpenseAppImpl) GetGroup(ctx context.Context, userId, groupId string) (*expense.Group, error) {
+	validator := NewValidator().NonEmptyID(userId).NonEmptyID(groupId)
+	if !validator.Ok() {
+		return nil, validator.Err()
+	}
+
+	group, err := e.userService.GetGroupById(ctx, groupId)
+	if err != nil {
+		return nil, expense.ErrNotFound("group not found")
+	}
+	members, err := e.userService.GetAssociatedUsers(ctx, groupId)
+	if err != nil {
+		return nil, err
+	}
+	if !lodash.ContainsBy(members.Users, func(u expense.User) bool { return u.ID == userId }) {
+		return nil, expense.ErrForbidden("user is not a member of the group")
+	}
+	return group, nil
+}
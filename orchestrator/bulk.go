@@ -0,0 +1,89 @@
+package orchestrator
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"splitExpense/expense"
+)
+
+// MaxBulkExpenses bounds the expenses of one bulk create
+const MaxBulkExpenses = 100
+
+// BulkExpenseResult is the outcome of one expense of a bulk create, the
+// created expense or why it was not created
+type BulkExpenseResult struct {
+	Expense *expense.Expense
+	Err     error
+}
+
+// CreateExpenses checks every expense as CreateExpense does and creates the
+// valid ones in one transaction. Atomic creates nothing when any expense is
+// invalid, otherwise the valid ones are created and the others reported. The
+// results follow the order of exps.
+func (e *ExpenseAppImpl) CreateExpenses(ctx context.Context, userId string, exps []expense.ExpenseCreate, atomic bool) ([]BulkExpenseResult, error) {
+	validator := NewValidator().NonEmptyID(userId)
+	if !validator.Ok() {
+		return nil, validator.Err()
+	}
+	if len(exps) == 0 || len(exps) > MaxBulkExpenses {
+		return nil, expense.ErrValidation(fmt.Sprintf("give between 1 and %d expenses", MaxBulkExpenses))
+	}
+
+	friends, err := e.GetUserService().GetFriends(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+	groupFound := map[string]bool{}
+	results := make([]BulkExpenseResult, len(exps))
+	valid := []int{}
+	for i, exp := range exps {
+		if exp.IsGroupExpense {
+			found, checked := groupFound[exp.GroupId]
+			if !checked {
+				_, err := e.userService.GetGroupById(ctx, exp.GroupId)
+				if err != nil && !errors.Is(err, sql.ErrNoRows) {
+					return nil, expense.ErrServiceCause(err)
+				}
+				found = err == nil
+				groupFound[exp.GroupId] = found
+			}
+			if !found {
+				results[i].Err = expense.ErrNotFound("group not found")
+				continue
+			}
+		}
+		if err := e.checkExpenseCreate(userId, friends, exp); err != nil {
+			results[i].Err = err
+			continue
+		}
+		valid = append(valid, i)
+	}
+
+	invalid := len(exps) - len(valid)
+	if atomic && invalid > 0 {
+		return results, expense.ErrValidation(fmt.Sprintf("%d of %d expenses are invalid, nothing was created", invalid, len(exps)))
+	}
+	if len(valid) == 0 {
+		return results, nil
+	}
+
+	creates := make([]expense.ExpenseCreate, len(valid))
+	for j, i := range valid {
+		creates[j] = exps[i]
+	}
+	created, err := e.expenseService.CreateExpenses(ctx, userId, creates)
+	if err != nil {
+		err = expense.ErrServiceCause(err)
+		for _, i := range valid {
+			results[i].Err = err
+		}
+		return results, err
+	}
+	for j, i := range valid {
+		results[i].Expense = &created[j]
+	}
+	return results, nil
+}
@@ -2,11 +2,16 @@ package orchestrator
 
 import (
 	"crypto"
+	"database/sql"
 	"encoding/base64"
+	"errors"
 	"fmt"
 	"math"
+	"splitExpense/cache"
 	"splitExpense/config"
 	"splitExpense/expense"
+	"splitExpense/ledger"
+	"splitExpense/outbox"
 	"splitExpense/service"
 	"splitExpense/storage"
 
@@ -19,6 +24,17 @@ type ExpenseAppImpl struct {
 	config         config.Config
 	userService    service.UserService
 	expenseService service.ExpenseService
+	archiver       *service.Archiver
+	// ledger is nil unless event sourcing is enabled
+	ledger ledger.Store
+	// dispatcher is nil unless the outbox is enabled
+	dispatcher  *outbox.Dispatcher
+	subscribers *outbox.Subscribers
+	// cache is nil unless storage caching is enabled
+	cache *cache.Storage
+	// storage is the fully decorated storage the services use, dbStorage the postgres backend below it
+	storage   expense.Storage
+	dbStorage *storage.DBStorage
 }
 
 // Implement service.Service interface
@@ -30,13 +46,13 @@ func (e *ExpenseAppImpl) GetExpenseService() service.ExpenseService {
 	return e.expenseService
 }
 
-func (e *ExpenseAppImpl) UserSignup(name, email, password string) (*expense.User, *expense.AppError) {
+func (e *ExpenseAppImpl) UserSignup(ctx context.Context, name, email, password string) (*expense.User, *expense.AppError) {
 	validator := NewValidator().Email(email).Name(name).Password(password)
 	if validator.Ok() {
 
-		user, err := e.userService.CreateUser(name, email, password)
+		user, err := e.userService.CreateUser(ctx, name, email, password)
 		if err != nil {
-			return nil, expense.ErrService(err.Error())
+			return nil, expense.ErrServiceCause(err)
 		}
 		return user, nil
 	} else {
@@ -44,82 +60,129 @@ func (e *ExpenseAppImpl) UserSignup(name, email, password string) (*expense.User
 	}
 }
 
-func (e *ExpenseAppImpl) AddFriend(userId, friendEmail string) (bool, error) {
+func (e *ExpenseAppImpl) AddFriend(ctx context.Context, userId, friendEmail string) (bool, error) {
 	validator := NewValidator().NonEmptyID(userId).Email(friendEmail)
 	if !validator.Ok() {
 		return false, validator.Err()
 	}
 
-	friend, err := e.userService.FetchUserCredentials(friendEmail)
+	friend, err := e.userService.FetchUserCredentials(ctx, friendEmail)
 	if err != nil {
-		return false, expense.ErrService(err.Error())
+		return false, expense.ErrServiceCause(err)
 	}
 
-	ok, err := e.userService.AddFriend(userId, friend.ID)
+	ok, err := e.userService.AddFriend(ctx, userId, friend.ID)
 	if err != nil {
-		return false, expense.ErrService(err.Error())
+		return false, expense.ErrServiceCause(err)
 	}
 	return ok, nil
 }
 
-func (e *ExpenseAppImpl) JoinGroup(userId, newMemberId, groupId string) (bool, error) {
+func (e *ExpenseAppImpl) JoinGroup(ctx context.Context, userId, newMemberId, groupId string) (bool, error) {
 	validator := NewValidator().NonEmptyID(userId).NonEmptyID(newMemberId).NonEmptyID(groupId)
 	if !validator.Ok() {
 		return false, validator.Err()
 	}
 
-	_, err := e.userService.GetFriend(userId, newMemberId)
+	_, err := e.userService.GetFriend(ctx, userId, newMemberId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, expense.ErrValidation("only friends can be added to a group")
+	}
 	if err != nil {
-		return false, expense.ErrService(err.Error())
+		return false, expense.ErrServiceCause(err)
 	}
-	ok, err := e.userService.JoinGroup(newMemberId, groupId)
+	ok, err := e.userService.JoinGroup(ctx, newMemberId, groupId)
 	if err != nil {
-		return false, expense.ErrService(err.Error())
+		return false, expense.ErrServiceCause(err)
 	}
 	return ok, nil
 }
 
-func (e *ExpenseAppImpl) LeaveGroup(userId, groupId string) (bool, *expense.AppError) {
+func (e *ExpenseAppImpl) LeaveGroup(ctx context.Context, userId, groupId string) (bool, *expense.AppError) {
 	validator := NewValidator().NonEmptyID(userId).NonEmptyID(groupId)
 	if !validator.Ok() {
 		return false, validator.Err()
 	}
 
-	ok, err := e.userService.LeaveGroup(userId, groupId)
+	ok, err := e.userService.LeaveGroup(ctx, userId, groupId)
 	if err != nil {
-		return false, expense.ErrService(err.Error())
+		return false, expense.ErrServiceCause(err)
 	}
 
 	return ok, nil
 }
 
-func (e *ExpenseAppImpl) CreateGroup(userId, name, description string) (*expense.Group, error) {
+func (e *ExpenseAppImpl) CreateGroup(ctx context.Context, userId, name, description string) (*expense.Group, error) {
 	validator := NewValidator().NonEmptyID(userId).Name(name)
 	if !validator.Ok() {
 		return nil, validator.Err()
 	}
-	return e.userService.CreateGroup(userId, name, description)
+	return e.userService.CreateGroup(ctx, userId, name, description)
+}
+
+func (e *ExpenseAppImpl) UpdateGroup(ctx context.Context, userId string, group expense.Group) (*expense.Group, error) {
+	validator := NewValidator().NonEmptyID(userId).NonEmptyID(group.Id).Name(group.Name)
+	if !validator.Ok() {
+		return nil, validator.Err()
+	}
+
+	existing, err := e.userService.GetGroupById(ctx, group.Id)
+	if err != nil {
+		return nil, expense.ErrNotFound("group not found")
+	}
+	if existing.Admin != userId {
+		return nil, expense.ErrForbidden("user is not admin of the group, cannot update group")
+	}
+	if existing.Version != group.Version {
+		return nil, &expense.VersionConflictError{Entity: "group", ID: group.Id, CurrentVersion: existing.Version}
+	}
+
+	groupUpdate := *existing
+	groupUpdate.Name = group.Name
+	groupUpdate.Description = group.Description
+	groupUpdate.Version = group.Version
+
+	return e.userService.UpdateGroup(ctx, groupUpdate)
 }
 
 func (e *ExpenseAppImpl) verifyAmount(a1 float64, a2 float64) bool {
 	return math.Round(a1*100)/100 == math.Round(a2*100)/100
 }
 
-func (e *ExpenseAppImpl) CreateExpense(userId string, exp expense.ExpenseCreate) (*expense.Expense, error) {
-	validator := NewValidator().NonEmptyID(userId).LeastAmount(exp.Amount)
+func (e *ExpenseAppImpl) CreateExpense(ctx context.Context, userId string, exp expense.ExpenseCreate) (*expense.Expense, error) {
+	validator := NewValidator().NonEmptyID(userId)
 	if !validator.Ok() {
 		return nil, validator.Err()
 	}
 
-	payers := lodash.Keys(exp.PayeeW.Payer.GetPayers())
-	if len(payers) == 0 {
-		return nil, expense.ErrValidation("payers are required")
+	friends, err := e.GetUserService().GetFriends(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+	if err := e.checkExpenseCreate(userId, friends, exp); err != nil {
+		return nil, err
 	}
 
-	friends, err := e.GetUserService().GetFriends(userId)
+	createdExp, err := e.expenseService.CreateExpense(ctx, userId, exp)
 	if err != nil {
-		return nil, err
+		return nil, expense.ErrServiceCause(err)
+	}
+	return createdExp, nil
+}
+
+// checkExpenseCreate checks the amount, that the members of exp are userId or
+// their friends and the split and payers add up to the amount
+func (e *ExpenseAppImpl) checkExpenseCreate(userId string, friends []expense.User, exp expense.ExpenseCreate) error {
+	validator := NewValidator().LeastAmount(exp.Amount)
+	if !validator.Ok() {
+		return validator.Err()
 	}
+
+	payers := lodash.Keys(exp.PayeeW.Payer.GetPayers())
+	if len(payers) == 0 {
+		return expense.ErrValidation("payers are required")
+	}
+
 	friendNetwork := lodash.Union(lodash.Map(friends, func(u expense.User, _ int) string { return u.ID }), []string{userId})
 
 	expenseMembers := lodash.Union(lodash.Keys(exp.PayeeW.Payer.GetPayers()), lodash.Keys(exp.SplitW.Split.GetPayeeSplit()))
@@ -128,35 +191,34 @@ func (e *ExpenseAppImpl) CreateExpense(userId string, exp expense.ExpenseCreate)
 	})
 
 	if !areValidFriends {
-		return nil, expense.ErrValidation("all expense members should be friends of expense creator")
+		return expense.ErrValidation("all expense members should be friends of expense creator")
 	}
 
 	if !e.verifyAmount(exp.Amount, exp.SplitW.Split.ComputeTotal()) {
 		fmt.Println("amount: ", exp.Amount, exp.SplitW.Split.ComputeTotal(), exp.SplitW.Split.GetPayeeSplit())
-		return nil, expense.ErrValidation("split amount is not same as expense amount")
+		return expense.ErrValidation("split amount is not same as expense amount")
 	}
 
 	if !e.verifyAmount(exp.PayeeW.Payer.GetTotal(), exp.Amount) {
-		return nil, expense.ErrValidation("payer contribution total is not same as expense amount")
-	}
-
-	createdExp, err := e.expenseService.CreateExpense(userId, exp)
-	if err != nil {
-		return nil, expense.ErrService(err.Error())
+		return expense.ErrValidation("payer contribution total is not same as expense amount")
 	}
-	return createdExp, nil
+	return nil
 }
 
-func (e *ExpenseAppImpl) UpdateExpense(userId string, exp expense.Expense) (*expense.Expense, error) {
+func (e *ExpenseAppImpl) UpdateExpense(ctx context.Context, userId string, exp expense.Expense) (*expense.Expense, error) {
 
 	// fetch existing exp from db
-	existingExp, err := e.GetExpenseService().FetchExpense(exp.ID)
+	existingExp, err := e.GetExpenseService().FetchExpense(ctx, exp.ID)
 	if err != nil {
-		return nil, expense.ErrValidation("expense not found")
+		return nil, expense.ErrNotFound("expense not found")
+	}
+
+	if existingExp.Version != exp.Version {
+		return nil, &expense.VersionConflictError{Entity: "expense", ID: exp.ID, CurrentVersion: existingExp.Version}
 	}
 
 	if existingExp.Status != expense.ExpenseDraft {
-		return nil, expense.ErrValidation("expense is not in draft state, cannot update")
+		return nil, expense.ErrConflict("expense is not in draft state, cannot update")
 	}
 
 	if existingExp.GroupId != exp.GroupId {
@@ -167,7 +229,7 @@ func (e *ExpenseAppImpl) UpdateExpense(userId string, exp expense.Expense) (*exp
 	// only group members or expense members or expense creator is allowed
 	userAllowed := existingExp.CreatedBy == userId
 	if existingExp.IsGroupExpense {
-		groupMembers, err := e.GetUserService().GetAssociatedUsers(existingExp.GroupId)
+		groupMembers, err := e.GetUserService().GetAssociatedUsers(ctx, existingExp.GroupId)
 		if err != nil {
 			return nil, err
 		}
@@ -177,7 +239,7 @@ func (e *ExpenseAppImpl) UpdateExpense(userId string, exp expense.Expense) (*exp
 	existingExpenseUsers := lodash.Union(lodash.Keys(existingExp.PayeeW.Payer.GetPayers()), lodash.Keys(existingExp.SplitW.Split.GetPayeeSplit()))
 	userAllowed = userAllowed || lodash.ContainsBy(existingExpenseUsers, func(uid string) bool { return uid == userId })
 	if !userAllowed {
-		return nil, expense.ErrValidation("user is not authorised to update expense, only members of this expense or members of group can edit this")
+		return nil, expense.ErrForbidden("user is not authorised to update expense, only members of this expense or members of group can edit this")
 	}
 
 	// validate amount, payee total and split total
@@ -189,7 +251,7 @@ func (e *ExpenseAppImpl) UpdateExpense(userId string, exp expense.Expense) (*exp
 		return nil, expense.ErrValidation("amount mismatch between payer or split when compared with expense amount")
 	}
 
-	friends, err := e.GetUserService().GetFriends(userId)
+	friends, err := e.GetUserService().GetFriends(ctx, userId)
 	if err != nil {
 		return nil, err
 	}
@@ -208,48 +270,68 @@ func (e *ExpenseAppImpl) UpdateExpense(userId string, exp expense.Expense) (*exp
 	expenseUpdate.PayeeW = exp.PayeeW
 	expenseUpdate.SplitW = exp.SplitW
 	expenseUpdate.Description = exp.Description
+	expenseUpdate.Category = exp.Category
 	expenseUpdate.Amount = exp.Amount
+	expenseUpdate.Version = exp.Version
 
-	return e.expenseService.UpdateExpense(userId, expenseUpdate)
+	return e.expenseService.UpdateExpense(ctx, userId, expenseUpdate)
 }
 
-func (e *ExpenseAppImpl) DeleteExpense(userId string, expenseId string) (bool, error) {
+func (e *ExpenseAppImpl) DeleteExpense(ctx context.Context, userId string, expenseId string) (bool, error) {
 	// check if user is part of expense or not, user should be part of group to delete the expense. user can be just the member of splitDetails but should be part of group if its a group expense.
-	return e.expenseService.DeleteExpense(userId, expenseId)
+	return e.expenseService.DeleteExpense(ctx, userId, expenseId)
 }
 
-func (e *ExpenseAppImpl) DeleteGroup(userId string, groupId string) (bool, error) {
+// DeleteGroup deletes the group under policy, the configured default when
+// empty, and reports the members and expenses it archived.
+func (e *ExpenseAppImpl) DeleteGroup(ctx context.Context, userId string, groupId string, policy expense.GroupDeletePolicy) (*expense.GroupDeletion, error) {
 	// user should be part of group to delete the group, user can be just the member of splitDetails but should be part of group if its a group expense.
 	validator := NewValidator().NonEmptyID(userId).NonEmptyID(groupId)
 	if !validator.Ok() {
-		return false, validator.Err()
+		return nil, validator.Err()
+	}
+	if policy == "" {
+		policy = expense.GroupDeletePolicy(e.config.GroupDeletePolicy)
+	}
+	if !policy.Valid() {
+		return nil, expense.ErrInvalidQuery(fmt.Sprintf("unknown group delete policy %q", policy))
+	}
+	group, err := e.userService.GetGroupById(ctx, groupId)
+	if err != nil {
+		return nil, expense.ErrNotFound("group not found")
 	}
-	group, err := e.userService.GetGroupById(groupId)
 	if group.Admin != userId {
-		return false, expense.ErrValidation("user is not admin of the group, cannot delete group")
+		return nil, expense.ErrForbidden("user is not admin of the group, cannot delete group")
 	}
 
-	ok, err := e.userService.DeleteGroup(groupId)
+	report, err := e.userService.DeleteGroup(ctx, groupId, policy)
+	var unsettled *expense.UnsettledGroupError
+	if errors.As(err, &unsettled) {
+		return nil, err
+	}
 	if err != nil {
-		return false, expense.ErrService(err.Error())
+		return nil, expense.ErrServiceCause(err)
 	}
-	return ok, nil
+	return report, nil
 }
 
-func (e *ExpenseAppImpl) SettleExpense(userId string, expenseId string) (*expense.Expense, error) {
+func (e *ExpenseAppImpl) SettleExpense(ctx context.Context, userId string, expenseId string) (*expense.Expense, error) {
 	// user should be part of group if group expense, user should always be part of expense or creator of expense.
-	return e.expenseService.SettleExpense(userId, expenseId)
+	return e.expenseService.SettleExpense(ctx, userId, expenseId)
 }
 
 // Add Login method for orchestrator
-func (e *ExpenseAppImpl) Login(email, password string) (*expense.User, error) {
+func (e *ExpenseAppImpl) Login(ctx context.Context, email, password string) (*expense.User, error) {
 
 	validator := NewValidator().Email(email).Password(password)
 	if !validator.Ok() {
 		return nil, validator.Err()
 	}
 
-	user, err := e.GetUserService().FetchUserCredentials(email)
+	user, err := e.GetUserService().FetchUserCredentials(ctx, email)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, expense.ErrValidation("invalid email or password")
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -264,14 +346,16 @@ func (e *ExpenseAppImpl) Login(email, password string) (*expense.User, error) {
 }
 
 // Implement GetUserHome to satisfy ExpenseApp interface
-func (e *ExpenseAppImpl) GetUserHome(userId string) (service.UserHome, error) {
+func (e *ExpenseAppImpl) GetUserHome(ctx context.Context, userId string) (service.UserHome, error) {
+	// the home page is the heaviest read and tolerates replica lag
+	ctx = storage.AllowStaleReads(ctx)
 	var home service.UserHome
-	user, err := e.userService.GetUser(userId)
+	user, err := e.userService.GetUser(ctx, userId)
 	if err != nil {
 		return home, err
 	}
 
-	groups, err := e.userService.GetAssociatedGroups(userId)
+	groups, err := e.userService.GetAssociatedGroups(ctx, userId)
 	if err != nil {
 		return home, err
 	}
@@ -280,13 +364,13 @@ func (e *ExpenseAppImpl) GetUserHome(userId string) (service.UserHome, error) {
 
 	for _, group := range groups {
 		// TODO: add go routines
-		exp, err := e.expenseService.FetchExpenseByGroup(userId, group.Id, 0)
+		exp, err := e.expenseService.FetchExpenseByGroupPage(ctx, userId, group.Id, false, expense.PageRequest{})
 		if err != nil {
 			return home, err
 		}
 
 		// TODO: move to different API, lot of db calls
-		totalOwed, totalBorrowed, err := e.expenseService.CalculateUserRunningExpensesInGroup(userId, &group)
+		totalOwed, totalBorrowed, err := e.expenseService.CalculateUserRunningExpensesInGroup(ctx, userId, &group)
 		if err != nil {
 			return home, err
 		}
@@ -303,17 +387,18 @@ func (e *ExpenseAppImpl) GetUserHome(userId string) (service.UserHome, error) {
 	return service.UserHome{AssociatedGroups: expGroups, User: *user}, nil
 }
 
-func (e *ExpenseAppImpl) GetUserExpenseHistory(userId string, pageNumber int) (*service.UserExpenses, error) {
+func (e *ExpenseAppImpl) GetUserExpenseHistory(ctx context.Context, userId string, pageNumber int) (*service.UserExpenses, error) {
+	ctx = storage.AllowStaleReads(ctx)
 
 	// Fetch active user expenses
-	expHistory, err := e.expenseService.FetchActiveUserExpenses(userId, pageNumber)
+	expHistory, err := e.expenseService.FetchActiveUserExpenses(ctx, userId, pageNumber)
 	if err != nil {
 		return nil, err
 	}
 
-	totalOwed, totalBorrowed, err := e.expenseService.CalculateAllUserRunningExpenses(userId)
+	totalOwed, totalBorrowed, err := e.expenseService.CalculateAllUserRunningExpenses(ctx, userId)
 	if err != nil {
-		return nil, expense.ErrService(err.Error())
+		return nil, expense.ErrServiceCause(err)
 	}
 
 	history := service.UserExpenses{
@@ -327,25 +412,80 @@ func (e *ExpenseAppImpl) GetUserExpenseHistory(userId string, pageNumber int) (*
 	return &history, nil
 }
 
-func (e *ExpenseAppImpl) GetGroupDetail(userId string, groupId string) (service.GroupDetail, error) {
+// GetUserExpenseHistoryPage is the cursor paginated variant of GetUserExpenseHistory
+func (e *ExpenseAppImpl) GetUserExpenseHistoryPage(ctx context.Context, userId string, page expense.PageRequest) (*service.UserExpenses, error) {
+	ctx = storage.AllowStaleReads(ctx)
+	expHistory, err := e.expenseService.FetchActiveUserExpensesPage(ctx, userId, page)
+	if err != nil {
+		return nil, err
+	}
+
+	totalOwed, totalBorrowed, err := e.expenseService.CalculateAllUserRunningExpenses(ctx, userId)
+	if err != nil {
+		return nil, expense.ErrServiceCause(err)
+	}
+
+	return &service.UserExpenses{
+		Expenses:      expHistory.Expenses,
+		TotalOwed:     totalOwed,
+		TotalBorrowed: totalBorrowed,
+		NextCursor:    expHistory.NextCursor,
+		HasMore:       expHistory.HasMore,
+	}, nil
+}
+
+func (e *ExpenseAppImpl) SearchExpenses(ctx context.Context, userId string, search expense.ExpenseSearch, page expense.PageRequest) (*expense.GroupExpenseHistory, error) {
+	validator := NewValidator().NonEmptyID(userId)
+	if !validator.Ok() {
+		return nil, validator.Err()
+	}
+	if err := search.Validate(); err != nil {
+		return nil, err
+	}
+
+	return e.expenseService.SearchExpenses(storage.AllowStaleReads(ctx), userId, search, page)
+}
+
+func (e *ExpenseAppImpl) GetGroupExpenses(ctx context.Context, userId string, groupId string, includeArchived bool, page expense.PageRequest) (*expense.GroupExpenseHistory, error) {
+	validator := NewValidator().NonEmptyID(userId).NonEmptyID(groupId)
+	if !validator.Ok() {
+		return nil, validator.Err()
+	}
+
+	members, err := e.userService.GetAssociatedUsers(ctx, groupId)
+	if err != nil {
+		return nil, err
+	}
+	if !lodash.ContainsBy(members.Users, func(u expense.User) bool { return u.ID == userId }) {
+		return nil, expense.ErrForbidden("user is not a member of the group")
+	}
+
+	return e.expenseService.FetchExpenseByGroupPage(ctx, userId, groupId, includeArchived, page)
+}
+
+func (e *ExpenseAppImpl) GetGroups(ctx context.Context, userId string, page expense.PageRequest) (*expense.GroupPage, error) {
+	return e.userService.GetAssociatedGroupsPage(ctx, userId, page)
+}
+
+func (e *ExpenseAppImpl) GetGroupDetail(ctx context.Context, userId string, groupId string) (service.GroupDetail, error) {
 	var detail service.GroupDetail
 
-	group, err := e.userService.GetGroupById(groupId)
+	group, err := e.userService.GetGroupById(ctx, groupId)
 	if err != nil {
 		return detail, err
 	}
 
-	users, err := e.userService.GetAssociatedUsers(groupId)
+	users, err := e.userService.GetAssociatedUsers(ctx, groupId)
 	if err != nil {
 		return detail, err
 	}
 
-	expHistory, err := e.expenseService.FetchExpenseByGroup(userId, groupId, 0)
+	expHistory, err := e.expenseService.FetchExpenseByGroupPage(ctx, userId, groupId, false, expense.PageRequest{})
 	if err != nil {
 		return detail, err
 	}
 
-	totalOwed, totalBorrowed, err := e.expenseService.CalculateUserRunningExpensesInGroup(userId, group)
+	totalOwed, totalBorrowed, err := e.expenseService.CalculateUserRunningExpensesInGroup(ctx, userId, group)
 	if err != nil {
 		return detail, err
 	}
@@ -363,21 +503,175 @@ func (e *ExpenseAppImpl) GetGroupDetail(userId string, groupId string) (service.
 	return detail, nil
 }
 
+// GetBalances sums the user's unsettled expenses overall and per group, without the expense lists of GetUserHome
+func (e *ExpenseAppImpl) GetBalances(ctx context.Context, userId string) (*service.Balances, error) {
+	validator := NewValidator().NonEmptyID(userId)
+	if !validator.Ok() {
+		return nil, validator.Err()
+	}
+	ctx = storage.AllowStaleReads(ctx)
+
+	totalOwed, totalBorrowed, err := e.expenseService.CalculateAllUserRunningExpenses(ctx, userId)
+	if err != nil {
+		return nil, expense.ErrServiceCause(err)
+	}
+	groups, err := e.userService.GetAssociatedGroups(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+	byGroup, err := e.expenseService.CalculateUserRunningExpensesByGroup(ctx, userId)
+	if err != nil {
+		return nil, expense.ErrServiceCause(err)
+	}
+
+	balances := &service.Balances{TotalOwed: totalOwed, TotalBorrowed: totalBorrowed, Groups: []service.GroupBalance{}}
+	for _, group := range groups {
+		running := byGroup[group.Id]
+		balances.Groups = append(balances.Groups, service.GroupBalance{Group: group, TotalOwed: running.TotalOwed, TotalBorrowed: running.TotalBorrowed})
+	}
+	return balances, nil
+}
+
+// GetGroupBalances is the user's running balance in each of groupIds, a
+// group without unsettled expenses of the user has a zero balance
+func (e *ExpenseAppImpl) GetGroupBalances(ctx context.Context, userId string, groupIds []string) (map[string]service.RunningBalance, error) {
+	byGroup, err := e.expenseService.CalculateUserRunningExpensesByGroup(storage.AllowStaleReads(ctx), userId)
+	if err != nil {
+		return nil, expense.ErrServiceCause(err)
+	}
+	balances := make(map[string]service.RunningBalance, len(groupIds))
+	for _, id := range groupIds {
+		balances[id] = byGroup[id]
+	}
+	return balances, nil
+}
+
 // NewExpenseApp creates an ExpenseAppImpl and mocks or creates service dependencies internally
-func NewExpenseApp(ctx context.Context, cfg *config.Config) ExpenseAppImpl {
+func NewExpenseApp(cfg *config.Config) ExpenseAppImpl {
 	// For now, create real storage and services, but this can be mocked for tests
-	storageImpl := storage.NewDBStorage(&ctx, cfg)
+	dbStorage := storage.NewDBStorage(cfg)
+	var storageImpl expense.Storage = dbStorage
+	var ledgerStore ledger.Store
+	if cfg.EventSourcing {
+		storageImpl = ledger.NewStorage(dbStorage)
+		ledgerStore = dbStorage
+	}
+	subscribers := outbox.NewSubscribers()
+	var dispatcher *outbox.Dispatcher
+	if cfg.OutboxEnabled {
+		storageImpl = outbox.NewStorage(storageImpl, dbStorage)
+		dispatcher = outbox.NewDispatcher(cfg, dbStorage, outboxSinks(cfg, subscribers)...)
+	}
+	var cached *cache.Storage
+	if cfg.CacheSize > 0 && cfg.CacheTTL > 0 {
+		cached = cache.NewStorage(storageImpl, cfg.CacheSize, cfg.CacheTTL)
+		storageImpl = cached
+	}
 	userService := service.NewUserServiceImpl(cfg, storageImpl)
 	expenseService := service.NewExpenseServiceImpl(storageImpl)
 	return ExpenseAppImpl{
 		userService:    userService,
 		expenseService: expenseService,
+		archiver:       service.NewArchiver(cfg, storageImpl),
+		ledger:         ledgerStore,
+		dispatcher:     dispatcher,
+		subscribers:    subscribers,
+		cache:          cached,
+		storage:        storageImpl,
+		dbStorage:      dbStorage,
 		config:         *cfg,
 	}
 }
 
-func (e *ExpenseAppImpl) GetFriends(userId string) ([]expense.User, error) {
-	return e.userService.GetFriends(userId)
+func outboxSinks(cfg *config.Config, subscribers *outbox.Subscribers) []outbox.Sink {
+	sinks := []outbox.Sink{subscribers}
+	if cfg.OutboxLogEvents {
+		sinks = append(sinks, outbox.LogSink{})
+	}
+	if cfg.OutboxWebhookURL != "" {
+		sinks = append(sinks, outbox.NewWebhookSink(cfg.OutboxWebhookURL, cfg.OutboxWebhookSecret, cfg.OutboxWebhookTimeout))
+	}
+	return sinks
+}
+
+// RunAsUser runs fn in one transaction restricted to userId's rows by the
+// database row level security policies. Without ROW_LEVEL_SECURITY fn runs as is.
+func (e *ExpenseAppImpl) RunAsUser(ctx context.Context, userId string, fn func(ctx context.Context) error) error {
+	if !e.config.RowLevelSecurity {
+		return fn(ctx)
+	}
+	return e.storage.RunInTx(ctx, func(ctx context.Context) error {
+		if err := e.dbStorage.SetRowSecurityUser(ctx, userId); err != nil {
+			return err
+		}
+		return fn(ctx)
+	})
+}
+
+// CacheStats reports the storage cache counters, empty when caching is disabled
+func (e *ExpenseAppImpl) CacheStats() map[string]cache.Stats {
+	if e.cache == nil {
+		return map[string]cache.Stats{}
+	}
+	return e.cache.Stats()
+}
+
+// SubscribeEvents registers an in-process handler for dispatched outbox events,
+// every type when none are given. It only receives events while the outbox is enabled.
+func (e *ExpenseAppImpl) SubscribeEvents(handler outbox.Handler, types ...expense.EventType) func() {
+	return e.subscribers.Subscribe(handler, types...)
+}
+
+// RunOutboxDispatcher delivers outbox events in the background until ctx is cancelled
+func (e *ExpenseAppImpl) RunOutboxDispatcher(ctx context.Context) {
+	if e.dispatcher == nil {
+		return
+	}
+	go e.dispatcher.Run(ctx)
+}
+
+var errLedgerDisabled = errors.New("event sourcing is disabled, set EVENT_SOURCING=true")
+
+// BootstrapLedger records rows written before event sourcing was enabled as events
+func (e *ExpenseAppImpl) BootstrapLedger(ctx context.Context) (int, error) {
+	if e.ledger == nil {
+		return 0, nil
+	}
+	return ledger.Bootstrap(ctx, e.ledger)
+}
+
+// ReplayLedger rebuilds the named projections, all of them when none are named, from the event history
+func (e *ExpenseAppImpl) ReplayLedger(ctx context.Context, projections ...string) (int, error) {
+	if e.ledger == nil {
+		return 0, errLedgerDisabled
+	}
+	return ledger.Replay(ctx, e.ledger, projections...)
+}
+
+// LedgerStatus counts the recorded events by type
+func (e *ExpenseAppImpl) LedgerStatus(ctx context.Context) (map[expense.EventType]int, error) {
+	if e.ledger == nil {
+		return nil, errLedgerDisabled
+	}
+	return e.ledger.CountEventsByType(ctx)
+}
+
+// ArchiveSettledExpenses runs one archival pass, e.g. from a cron job
+func (e *ExpenseAppImpl) ArchiveSettledExpenses(ctx context.Context) (int, error) {
+	return e.archiver.ArchiveOnce(ctx)
+}
+
+// RunArchival archives periodically in the background until ctx is cancelled
+func (e *ExpenseAppImpl) RunArchival(ctx context.Context) {
+	go e.archiver.Run(ctx)
+}
+
+func (e *ExpenseAppImpl) GetFriends(ctx context.Context, userId string) ([]expense.User, error) {
+	return e.userService.GetFriends(ctx, userId)
+}
+
+func (e *ExpenseAppImpl) GetFriendsPage(ctx context.Context, userId string, page expense.PageRequest) (*expense.UserPage, error) {
+	return e.userService.GetFriendsPage(ctx, userId, page)
 }
 
 func calculateUserLiability(g *expense.GroupExpenseHistory) (totalOwed, totalBorrowed float64) {
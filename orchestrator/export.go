@@ -0,0 +1,223 @@
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"splitExpense/expense"
+	"splitExpense/export"
+	"splitExpense/storage"
+
+	lodash "github.com/samber/lo"
+)
+
+// ExportFilter narrows an export, zero values keep every expense
+type ExportFilter struct {
+	Status expense.ExpenseStatus
+	// From is inclusive, Before is exclusive
+	From            *time.Time
+	Before          *time.Time
+	IncludeArchived bool
+}
+
+func (f ExportFilter) search() expense.ExpenseSearch {
+	return expense.ExpenseSearch{Status: f.Status, CreatedFrom: f.From, CreatedBefore: f.Before, IncludeArchived: f.IncludeArchived}
+}
+
+// expensePages fetches one page of the expenses to export, newest first
+type expensePages func(ctx context.Context, page expense.PageRequest) (*expense.StoredGroupExpenseHistory, error)
+
+// ExportGroupCSV writes every expense of a group userId belongs to as CSV.
+// Errors before the header is written leave w untouched.
+func (e *ExpenseAppImpl) ExportGroupCSV(ctx context.Context, userId string, groupId string, filter ExportFilter, w io.Writer) error {
+	validator := NewValidator().NonEmptyID(userId).NonEmptyID(groupId)
+	if !validator.Ok() {
+		return validator.Err()
+	}
+	if err := filter.search().Validate(); err != nil {
+		return err
+	}
+	isMember, err := e.storage.CheckUserExistsInGroup(ctx, userId, groupId)
+	if err != nil {
+		return expense.ErrServiceCause(err)
+	}
+	if !isMember {
+		return expense.ErrForbidden("user is not a member of the group, cannot export it")
+	}
+
+	// the group query filters on status only, dates are checked row by row
+	pages := func(ctx context.Context, page expense.PageRequest) (*expense.StoredGroupExpenseHistory, error) {
+		return e.storage.FetchGroupExpensesPage(ctx, groupId, filter.Status, filter.IncludeArchived, page)
+	}
+	return e.exportCSV(ctx, pages, filter, w)
+}
+
+// ExportExpensesCSV writes the expenses userId takes part in as CSV
+func (e *ExpenseAppImpl) ExportExpensesCSV(ctx context.Context, userId string, filter ExportFilter, w io.Writer) error {
+	validator := NewValidator().NonEmptyID(userId)
+	if !validator.Ok() {
+		return validator.Err()
+	}
+	search := filter.search()
+	if err := search.Validate(); err != nil {
+		return err
+	}
+
+	pages := func(ctx context.Context, page expense.PageRequest) (*expense.StoredGroupExpenseHistory, error) {
+		return e.storage.SearchExpenses(ctx, userId, search, page)
+	}
+	return e.exportCSV(ctx, pages, filter, w)
+}
+
+// exportCSV reads the expenses twice, once for the participant columns of the
+// header and once to write the rows, so only one page is held at a time. Both
+// passes read one snapshot, an expense added in between would have
+// participants without a column.
+func (e *ExpenseAppImpl) exportCSV(ctx context.Context, pages expensePages, filter ExportFilter, w io.Writer) error {
+	return e.storage.RunInTx(storage.ReadSnapshot(ctx), func(ctx context.Context) error {
+		summary, err := e.summarize(ctx, pages, filter)
+		if err != nil {
+			return err
+		}
+		out, err := export.NewCSV(w, summary.participants)
+		if err != nil {
+			return err
+		}
+		if err := eachExpense(ctx, pages, filter, out.Write); err != nil {
+			return err
+		}
+		return out.Flush()
+	})
+}
+
+// ExportExpensesJournal writes the expenses userId takes part in as ledger or
+// beancount transactions, reading them twice from one snapshot like the CSV
+// export so every posting goes to an account opened up front
+func (e *ExpenseAppImpl) ExportExpensesJournal(ctx context.Context, userId string, filter ExportFilter, opts export.JournalOptions, w io.Writer) error {
+	validator := NewValidator().NonEmptyID(userId)
+	if !validator.Ok() {
+		return validator.Err()
+	}
+	search := filter.search()
+	if err := search.Validate(); err != nil {
+		return err
+	}
+	if opts.Syntax != export.SyntaxLedger && opts.Syntax != export.SyntaxBeancount {
+		return expense.ErrInvalidQuery(fmt.Sprintf("unknown syntax %q", opts.Syntax))
+	}
+	if opts.Currency != "" && !export.ValidCurrency(opts.Currency) {
+		return expense.ErrInvalidQuery(fmt.Sprintf("invalid currency %q", opts.Currency))
+	}
+
+	pages := func(ctx context.Context, page expense.PageRequest) (*expense.StoredGroupExpenseHistory, error) {
+		return e.storage.SearchExpenses(ctx, userId, search, page)
+	}
+	return e.storage.RunInTx(storage.ReadSnapshot(ctx), func(ctx context.Context) error {
+		summary, err := e.summarize(ctx, pages, filter)
+		if err != nil {
+			return err
+		}
+		out, err := export.NewJournal(w, userId, summary.participants, summary.categories, summary.first, opts)
+		if err != nil {
+			return err
+		}
+		if err := eachExpense(ctx, pages, filter, out.Write); err != nil {
+			return err
+		}
+		return out.Flush()
+	})
+}
+
+// exportSummary is what an export has to know before writing the first expense
+type exportSummary struct {
+	participants []export.Participant
+	categories   []string
+	// first is the date of the oldest expense
+	first time.Time
+}
+
+func (e *ExpenseAppImpl) summarize(ctx context.Context, pages expensePages, filter ExportFilter) (*exportSummary, error) {
+	summary := &exportSummary{}
+	seen := map[string]bool{}
+	ids := []string{}
+	collect := func(amounts map[string]float64) {
+		for id := range amounts {
+			if !seen[id] {
+				seen[id] = true
+				ids = append(ids, id)
+			}
+		}
+	}
+	err := eachExpense(ctx, pages, filter, func(exp expense.Expense) error {
+		collect(exp.PayeeW.Payer.GetPayers())
+		collect(exp.SplitW.Split.GetPayeeSplit())
+		if !lodash.Contains(summary.categories, exp.Category) {
+			summary.categories = append(summary.categories, exp.Category)
+		}
+		// pages are newest first
+		summary.first = exp.CreatedAt
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	summary.participants, err = e.participants(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	return summary, nil
+}
+
+// participants names ids and orders them by name, deleted users keep their id as name
+func (e *ExpenseAppImpl) participants(ctx context.Context, ids []string) ([]export.Participant, error) {
+	users, err := e.LoadUsers(ctx, ids)
+	if err != nil {
+		return nil, expense.ErrServiceCause(err)
+	}
+	participants := make([]export.Participant, 0, len(ids))
+	for _, id := range ids {
+		name := id
+		if user, ok := users[id]; ok {
+			name = user.Name
+		}
+		participants = append(participants, export.Participant{ID: id, Name: name})
+	}
+	sort.Slice(participants, func(i, j int) bool {
+		if participants[i].Name != participants[j].Name {
+			return participants[i].Name < participants[j].Name
+		}
+		return participants[i].ID < participants[j].ID
+	})
+	return participants, nil
+}
+
+// eachExpense pages through every expense matching filter, newest first
+func eachExpense(ctx context.Context, pages expensePages, filter ExportFilter, fn func(exp expense.Expense) error) error {
+	page := expense.PageRequest{Limit: expense.MaxPageLimit}
+	for {
+		history, err := pages(ctx, page)
+		if err != nil {
+			return err
+		}
+		for _, exp := range history.Expenses {
+			if filter.Before != nil && !exp.CreatedAt.Before(*filter.Before) {
+				continue
+			}
+			// pages are newest first, nothing further can match
+			if filter.From != nil && exp.CreatedAt.Before(*filter.From) {
+				return nil
+			}
+			if err := fn(exp); err != nil {
+				return err
+			}
+		}
+		if !history.HasMore {
+			return nil
+		}
+		page.Cursor = history.NextCursor
+	}
+}
@@ -0,0 +1,60 @@
+package orchestrator
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"splitExpense/storage"
+)
+
+// IdempotencyEnabled tells whether Idempotency-Key headers are honoured
+func (e *ExpenseAppImpl) IdempotencyEnabled() bool {
+	return e.config.IdempotencyKeyTTL > 0
+}
+
+// ReserveIdempotencyKey claims key for a request of userId identified by
+// requestHash. reserved is false when the key is in use, record then holds
+// the first request, with its response once it has completed.
+func (e *ExpenseAppImpl) ReserveIdempotencyKey(ctx context.Context, userId string, key string, requestHash string) (record *storage.IdempotencyRecord, reserved bool, err error) {
+	validator := NewValidator().NonEmptyID(userId).NonEmptyID(key)
+	if !validator.Ok() {
+		return nil, false, validator.Err()
+	}
+	return e.dbStorage.ReserveIdempotencyKey(ctx, userId, key, requestHash, time.Now().Add(e.config.IdempotencyKeyTTL))
+}
+
+// CompleteIdempotencyKey stores the response replayed for key until it expires
+func (e *ExpenseAppImpl) CompleteIdempotencyKey(ctx context.Context, userId string, key string, status int, contentType string, response []byte) error {
+	return e.dbStorage.CompleteIdempotencyKey(ctx, userId, key, status, contentType, response)
+}
+
+// ReleaseIdempotencyKey drops a reservation whose request failed, so a retry runs again
+func (e *ExpenseAppImpl) ReleaseIdempotencyKey(ctx context.Context, userId string, key string) error {
+	return e.dbStorage.ReleaseIdempotencyKey(ctx, userId, key)
+}
+
+// RunIdempotencyKeyPruning deletes expired idempotency keys hourly in the
+// background until ctx is cancelled, expired keys are also taken over on reuse
+func (e *ExpenseAppImpl) RunIdempotencyKeyPruning(ctx context.Context) {
+	if !e.IdempotencyEnabled() {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(time.Hour)
+		defer ticker.Stop()
+		for {
+			if pruned, err := e.dbStorage.PruneIdempotencyKeys(ctx); err != nil {
+				log.Println("pruning expired idempotency keys failed: ", err)
+			} else if pruned > 0 {
+				log.Printf("pruned %d expired idempotency keys", pruned)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
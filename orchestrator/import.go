@@ -0,0 +1,178 @@
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"splitExpense/expense"
+	"splitExpense/importer"
+	"splitExpense/service"
+
+	lodash "github.com/samber/lo"
+)
+
+type ImportOptions struct {
+	// GroupId imports into a group, names are then matched to its members instead of friends
+	GroupId string
+	// Aliases match names of the file to users given by id, email or name
+	Aliases map[string]string
+	// Commit creates the expenses, without it they are only previewed
+	Commit bool
+}
+
+// ImportExpenses matches the people of file to userId's friends, or to the
+// group members, and previews the expenses. With opts.Commit they are created
+// through CreateExpense in one transaction, a file with problems imports nothing.
+func (e *ExpenseAppImpl) ImportExpenses(ctx context.Context, userId string, file *importer.File, opts ImportOptions) (*service.ImportReport, error) {
+	validator := NewValidator().NonEmptyID(userId)
+	if !validator.Ok() {
+		return nil, validator.Err()
+	}
+	candidates, err := e.importCandidates(ctx, userId, opts.GroupId)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &service.ImportReport{
+		Expenses: []service.ImportedExpense{},
+		People:   map[string]string{},
+		Problems: append([]importer.Problem{}, file.Problems...),
+		Skipped:  append([]importer.Problem{}, file.Skipped...),
+	}
+	unmatched := map[string]error{}
+	for _, name := range file.People {
+		userId, err := matchUser(name, opts.Aliases, candidates)
+		if err != nil {
+			unmatched[name] = err
+			continue
+		}
+		report.People[name] = userId
+	}
+
+	for _, entry := range file.Entries {
+		imported, err := importedExpense(entry, report.People, unmatched, opts.GroupId)
+		if err != nil {
+			report.Problems = append(report.Problems, importer.Problem{Line: entry.Line, Message: err.Error()})
+			continue
+		}
+		report.Expenses = append(report.Expenses, imported)
+	}
+	sort.SliceStable(report.Problems, func(i, j int) bool { return report.Problems[i].Line < report.Problems[j].Line })
+
+	if !opts.Commit {
+		return report, nil
+	}
+	if len(report.Problems) > 0 {
+		return report, expense.ErrValidation(fmt.Sprintf("the file has %d problems, nothing was imported", len(report.Problems)))
+	}
+	err = e.storage.RunInTx(ctx, func(ctx context.Context) error {
+		for i, imported := range report.Expenses {
+			created, err := e.CreateExpense(ctx, userId, expense.ExpenseCreate{
+				Description:    imported.Description,
+				Category:       imported.Category,
+				Amount:         imported.Amount,
+				SplitW:         imported.Split,
+				PayeeW:         imported.Payee,
+				IsGroupExpense: imported.GroupId != "",
+				GroupId:        imported.GroupId,
+				CreatedAt:      imported.Date,
+			})
+			if err != nil {
+				return fmt.Errorf("line %d: %w", imported.Line, err)
+			}
+			report.Expenses[i].ID = created.ID
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	report.Committed = true
+	return report, nil
+}
+
+// importCandidates are the users names can be matched to: the group members,
+// or userId and their friends
+func (e *ExpenseAppImpl) importCandidates(ctx context.Context, userId string, groupId string) ([]expense.User, error) {
+	if groupId != "" {
+		members, err := e.userService.GetAssociatedUsers(ctx, groupId)
+		if err != nil {
+			return nil, err
+		}
+		if !lodash.ContainsBy(members.Users, func(u expense.User) bool { return u.ID == userId }) {
+			return nil, expense.ErrForbidden("user is not a member of the group")
+		}
+		return members.Users, nil
+	}
+
+	me, err := e.userService.GetUser(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+	friends, err := e.userService.GetFriends(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+	return append(friends, *me), nil
+}
+
+// matchUser finds the user a name of the file stands for, by id, email or
+// name, after replacing it with its alias if it has one
+func matchUser(name string, aliases map[string]string, candidates []expense.User) (string, error) {
+	target := name
+	if alias, ok := aliases[name]; ok {
+		target = alias
+	}
+	target = strings.TrimSpace(target)
+
+	matches := lodash.Filter(candidates, func(u expense.User, _ int) bool {
+		return u.ID == target || strings.EqualFold(u.Email, target) || strings.EqualFold(u.Name, target)
+	})
+	matches = lodash.UniqBy(matches, func(u expense.User) string { return u.ID })
+	switch len(matches) {
+	case 0:
+		return "", fmt.Errorf("%q is not a friend or group member, give an alias for it", name)
+	case 1:
+		return matches[0].ID, nil
+	}
+	return "", fmt.Errorf("%q matches %d people, give an alias with an email for it", name, len(matches))
+}
+
+func importedExpense(entry importer.Entry, people map[string]string, unmatched map[string]error, groupId string) (service.ImportedExpense, error) {
+	byUser := func(amounts map[string]float64) (map[string]float64, error) {
+		values := map[string]float64{}
+		for name, amount := range amounts {
+			if err, ok := unmatched[name]; ok {
+				return nil, err
+			}
+			values[people[name]] += amount
+		}
+		return values, nil
+	}
+	paid, err := byUser(entry.Paid)
+	if err != nil {
+		return service.ImportedExpense{}, err
+	}
+	shares, err := byUser(entry.Shares)
+	if err != nil {
+		return service.ImportedExpense{}, err
+	}
+
+	payee := expense.PayerWrapper{Type: "multi", Payer: &expense.MultiPayer{Payers: paid}}
+	if len(paid) == 1 {
+		payer := lodash.Keys(paid)[0]
+		payee = expense.PayerWrapper{Type: "single", Payer: &expense.SinglePayer{Payer: payer, Amount: paid[payer]}}
+	}
+	return service.ImportedExpense{
+		Line:        entry.Line,
+		Date:        entry.Date,
+		Description: entry.Description,
+		Category:    entry.Category,
+		Amount:      entry.Amount,
+		Split:       expense.SplitWrapper{Type: "unit", Split: &expense.UnitSplit{PayeeAmountSplit: shares}},
+		Payee:       payee,
+		GroupId:     groupId,
+	}, nil
+}
@@ -0,0 +1,147 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"splitExpense/expense"
+	"splitExpense/importer"
+	"splitExpense/service"
+)
+
+// importUsers answers the lookups of an import preview, other calls panic
+type importUsers struct {
+	service.UserService
+	me      expense.User
+	friends []expense.User
+}
+
+func (u *importUsers) GetUser(ctx context.Context, id string) (*expense.User, error) {
+	return &u.me, nil
+}
+
+func (u *importUsers) GetFriends(ctx context.Context, userId string) ([]expense.User, error) {
+	return u.friends, nil
+}
+
+func TestImportExpenses(t *testing.T) {
+	users := &importUsers{
+		me: expense.User{ID: "u-alice", Name: "Alice", Email: "alice@example.com"},
+		friends: []expense.User{
+			{ID: "u-bob", Name: "Bob", Email: "bob@example.com"},
+			{ID: "u-carol", Name: "Carol", Email: "carol@example.com"},
+			{ID: "u-carol2", Name: "Carol", Email: "carol@example.org"},
+		},
+	}
+	app := &ExpenseAppImpl{userService: users}
+	const header = "date,description,category,amount,paid by,split\n"
+
+	tests := []struct {
+		name     string
+		rows     string
+		aliases  map[string]string
+		problems []importer.Problem
+		people   map[string]string
+	}{
+		{
+			name:   "known people",
+			rows:   "2024-01-02,Dinner,Food,10,Alice,Alice;Bob\n",
+			people: map[string]string{"Alice": "u-alice", "Bob": "u-bob"},
+		},
+		{
+			name:   "matched by email",
+			rows:   "2024-01-02,Dinner,Food,10,alice@example.com,alice@example.com;BOB\n",
+			people: map[string]string{"alice@example.com": "u-alice", "BOB": "u-bob"},
+		},
+		{
+			name:     "unknown participant",
+			rows:     "2024-01-02,Dinner,Food,10,Alice,Alice;Dave\n2024-01-03,Lunch,Food,10,Alice,Alice;Bob\n",
+			problems: []importer.Problem{{Line: 2, Message: `"Dave" is not a friend or group member, give an alias for it`}},
+			people:   map[string]string{"Alice": "u-alice", "Bob": "u-bob"},
+		},
+		{
+			name:     "ambiguous name",
+			rows:     "2024-01-02,Dinner,Food,10,Carol,Alice;Carol\n",
+			problems: []importer.Problem{{Line: 2, Message: `"Carol" matches 2 people, give an alias with an email for it`}},
+			people:   map[string]string{"Alice": "u-alice"},
+		},
+		{
+			name:    "alias",
+			rows:    "2024-01-02,Dinner,Food,10,Carol,Alice;Carol\n",
+			aliases: map[string]string{"Carol": "carol@example.org"},
+			people:  map[string]string{"Alice": "u-alice", "Carol": "u-carol2"},
+		},
+		{
+			name: "problems of the file come first by line",
+			rows: "2024-01-02,Dinner,Food,10,Alice,Alice;Dave\n2024-01-03,Lunch,Food,10,Alice,Alice:3;Bob:3\n",
+			problems: []importer.Problem{
+				{Line: 2, Message: `"Dave" is not a friend or group member, give an alias for it`},
+				{Line: 3, Message: "split: amounts add up to 6.00 instead of 10.00"},
+			},
+			people: map[string]string{"Alice": "u-alice"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file, err := importer.ReadCSV(strings.NewReader(header+tt.rows), importer.DefaultMapping)
+			if err != nil {
+				t.Fatalf("ReadCSV: %v", err)
+			}
+			report, err := app.ImportExpenses(context.Background(), "u-alice", file, ImportOptions{Aliases: tt.aliases})
+			if err != nil {
+				t.Fatalf("ImportExpenses: %v", err)
+			}
+			if len(report.Problems) != len(tt.problems) {
+				t.Fatalf("ImportExpenses problems: got %v, want %v", report.Problems, tt.problems)
+			}
+			for i := range tt.problems {
+				if report.Problems[i] != tt.problems[i] {
+					t.Fatalf("ImportExpenses problems: got %v, want %v", report.Problems, tt.problems)
+				}
+			}
+			if len(report.People) != len(tt.people) {
+				t.Fatalf("ImportExpenses people: got %v, want %v", report.People, tt.people)
+			}
+			for name, id := range tt.people {
+				if report.People[name] != id {
+					t.Fatalf("ImportExpenses people: got %v, want %v", report.People, tt.people)
+				}
+			}
+			if len(report.Expenses)+len(report.Problems) != len(file.Entries)+len(file.Problems) {
+				t.Fatalf("ImportExpenses: %d expenses and %d problems for %d lines", len(report.Expenses), len(report.Problems), len(file.Entries)+len(file.Problems))
+			}
+
+			// a commit with problems imports nothing, before touching storage
+			if len(tt.problems) > 0 {
+				report, err := app.ImportExpenses(context.Background(), "u-alice", file, ImportOptions{Aliases: tt.aliases, Commit: true})
+				var appErr *expense.AppError
+				if !errors.As(err, &appErr) || appErr.Type != "ValidationError" || report.Committed {
+					t.Fatalf("ImportExpenses commit with problems: got (%v, %v), want a validation error", report.Committed, err)
+				}
+			}
+		})
+	}
+}
+
+func TestImportedExpenseShares(t *testing.T) {
+	entry := importer.Entry{
+		Line:   2,
+		Amount: 30,
+		Paid:   map[string]float64{"Alice": 20, "alice@example.com": 10},
+		Shares: map[string]float64{"Alice": 10, "Bob": 20},
+	}
+	people := map[string]string{"Alice": "u-alice", "alice@example.com": "u-alice", "Bob": "u-bob"}
+	imported, err := importedExpense(entry, people, nil, "group")
+	if err != nil {
+		t.Fatalf("importedExpense: %v", err)
+	}
+	if imported.Payee.Type != "single" || imported.Payee.Payer.GetPayers()["u-alice"] != 30 {
+		t.Fatalf("importedExpense payee: got %s %v, want u-alice paying 30", imported.Payee.Type, imported.Payee.Payer.GetPayers())
+	}
+	shares := imported.Split.Split.GetPayeeSplit()
+	if shares["u-alice"] != 10 || shares["u-bob"] != 20 || imported.GroupId != "group" {
+		t.Fatalf("importedExpense: got shares %v group %q", shares, imported.GroupId)
+	}
+}
@@ -1,18 +1,21 @@
 package orchestrator
 
 import (
-	"regexp"
 	"splitExpense/expense"
+	"strings"
 
 	"github.com/google/uuid"
 )
 
+// validator collects every failed check, Err reports them together
 type validator struct {
 	errors [](*expense.AppError)
+	// fields names the request field of the checks that have one
+	fields map[string]string
 }
 
 func NewValidator() *validator {
-	return &validator{errors: []*expense.AppError{}}
+	return &validator{errors: []*expense.AppError{}, fields: map[string]string{}}
 }
 
 func (v *validator) Ok() bool {
@@ -22,33 +25,31 @@ func (v *validator) Ok() bool {
 func (v *validator) Err() *expense.AppError {
 	if len(v.errors) == 0 {
 		return nil
-	} else {
-		return v.errors[0]
 	}
+	messages := make([]string, 0, len(v.errors))
+	for _, err := range v.errors {
+		messages = append(messages, err.Message)
+	}
+	return expense.ErrFieldValidation(strings.Join(messages, "; "), v.fields)
 }
 
-func (v *validator) Email(e string) *validator {
+func (v *validator) fail(field string, message string) {
+	v.errors = append(v.errors, expense.ErrValidation(message))
+	if field != "" {
+		v.fields[field] = message
+	}
+}
 
-	const emailRegex = `^[a-zA-Z0-9._%+-]+@[a-zA-Z0-9.-]+\.[a-zA-Z]{2,}$`
-	re := regexp.MustCompile(emailRegex)
-	ok := re.MatchString(e)
-	if !ok {
-		v.errors = append(v.errors, expense.ErrValidation("Invalid email format: "+e+". Expected format: localpart@domain.tld"))
+func (v *validator) Email(e string) *validator {
+	if !expense.ValidEmail(e) {
+		v.fail("email", "Invalid email format: "+e+". Expected format: localpart@domain.tld")
 	}
 	return v
 }
 
 func (v *validator) Password(password string) *validator {
-
-	hasLower := regexp.MustCompile(`[a-z]`).MatchString(password)
-	hasUpper := regexp.MustCompile(`[A-Z]`).MatchString(password)
-	hasNumber := regexp.MustCompile(`[0-9]`).MatchString(password)
-	hasSpecial := regexp.MustCompile(`[!@#\$%\^&\*\(\)_\+\-=\[\]{};':"\\|,.<>\/?]`).MatchString(password)
-
-	ok := len(password) > 8 && hasLower && hasUpper && hasNumber && hasSpecial
-
-	if !ok {
-		v.errors = append(v.errors, expense.ErrValidation("Invalid Password, password should contain atleast one uppercase, one lowercase, one special character and one digit"))
+	if !expense.ValidPassword(password) {
+		v.fail("password", "Invalid Password, "+expense.PasswordPolicy)
 	}
 	return v
 }
@@ -56,7 +57,7 @@ func (v *validator) Password(password string) *validator {
 func (v *validator) Name(n string) *validator {
 	ok := len(n) >= 2
 	if !ok {
-		v.errors = append(v.errors, expense.ErrValidation("Invalid Name, name should contain atleast two characters"))
+		v.fail("name", "Invalid Name, name should contain atleast two characters")
 	}
 	return v
 }
@@ -64,7 +65,7 @@ func (v *validator) Name(n string) *validator {
 func (v *validator) UUID(id string) (*validator, *uuid.UUID) {
 	parsed, err := uuid.Parse(id)
 	if err != nil {
-		v.errors = append(v.errors, expense.ErrValidation("Invalid UUID"))
+		v.fail("", "Invalid UUID")
 		return v, nil
 	}
 	return v, &parsed
@@ -73,7 +74,7 @@ func (v *validator) UUID(id string) (*validator, *uuid.UUID) {
 func (v *validator) NonEmptyID(id string) *validator {
 	ok := len(id) > 0
 	if !ok {
-		v.errors = append(v.errors, expense.ErrValidation("Emmpty ID"))
+		v.fail("", "Emmpty ID")
 	}
 	return v
 }
@@ -81,7 +82,7 @@ func (v *validator) NonEmptyID(id string) *validator {
 func (v *validator) LeastAmount(amount float64) *validator {
 	ok := amount >= 1.0
 	if !ok {
-		v.errors = append(v.errors, expense.ErrValidation("Amount less than 1"))
+		v.fail("amount", "Amount less than 1")
 	}
 	return v
 }
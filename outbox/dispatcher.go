@@ -0,0 +1,137 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"splitExpense/config"
+)
+
+const maxRetryBackoff = 10 * time.Minute
+
+// claimLease is how long claimed messages wait for their delivery to be
+// recorded before another dispatcher may claim them again
+const claimLease = 5 * time.Minute
+
+// Dispatcher polls the outbox and hands due messages to every sink. A message
+// is marked delivered only when all sinks accept it, otherwise it is retried
+// with exponential backoff, and the aggregate's later messages wait behind it.
+type Dispatcher struct {
+	config *config.Config
+	store  Store
+	sinks  []Sink
+}
+
+func NewDispatcher(cfg *config.Config, store Store, sinks ...Sink) *Dispatcher {
+	return &Dispatcher{config: cfg, store: store, sinks: sinks}
+}
+
+// DispatchOnce delivers one batch and returns how many messages were delivered.
+// The batch is claimed and the outcomes recorded in two short transactions,
+// none is held open while the sinks are called.
+func (d *Dispatcher) DispatchOnce(ctx context.Context) (int, error) {
+	messages, err := d.store.ClaimDue(ctx, d.batchSize(), time.Now().Add(claimLease))
+	if err != nil {
+		return 0, err
+	}
+	failures := make([]error, len(messages))
+	for i, msg := range messages {
+		failures[i] = d.deliver(ctx, msg)
+	}
+
+	delivered := 0
+	err = d.store.RunInTx(ctx, func(ctx context.Context) error {
+		delivered = 0
+		for i, msg := range messages {
+			if failures[i] != nil {
+				if err := d.fail(ctx, msg, failures[i]); err != nil {
+					return err
+				}
+				continue
+			}
+			if err := d.store.MarkDelivered(ctx, msg.Sequence); err != nil {
+				return err
+			}
+			delivered++
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return delivered, nil
+}
+
+func (d *Dispatcher) batchSize() int {
+	if d.config.OutboxBatchSize <= 0 {
+		return 100
+	}
+	return d.config.OutboxBatchSize
+}
+
+func (d *Dispatcher) deliver(ctx context.Context, msg Message) error {
+	var errs []error
+	for _, sink := range d.sinks {
+		if err := sink.Deliver(ctx, msg.Event); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", sink.Name(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (d *Dispatcher) fail(ctx context.Context, msg Message, reason error) error {
+	attempts := msg.Attempts + 1
+	dead := d.config.OutboxMaxAttempts > 0 && attempts >= d.config.OutboxMaxAttempts
+	if dead {
+		log.Printf("giving up on event %s %s after %d attempts: %v", msg.ID, msg.Type, attempts, reason)
+	}
+	return d.store.MarkFailed(ctx, msg.Sequence, reason.Error(), time.Now().Add(d.backoff(attempts)), dead)
+}
+
+// backoff doubles OutboxRetryBackoff with every attempt, up to maxRetryBackoff
+func (d *Dispatcher) backoff(attempts int) time.Duration {
+	backoff := d.config.OutboxRetryBackoff
+	for i := 1; i < attempts && backoff < maxRetryBackoff; i++ {
+		backoff *= 2
+	}
+	return min(backoff, maxRetryBackoff)
+}
+
+// Run dispatches every OutboxPollInterval until ctx is cancelled. A full batch
+// is followed straight away by the next one.
+func (d *Dispatcher) Run(ctx context.Context) {
+	if d.config.OutboxPollInterval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(d.config.OutboxPollInterval)
+	defer ticker.Stop()
+	lastPrune := time.Time{}
+
+	for {
+		for {
+			delivered, err := d.DispatchOnce(ctx)
+			if err != nil {
+				log.Println("dispatching outbox events failed: ", err)
+			}
+			if err != nil || delivered < d.batchSize() {
+				break
+			}
+		}
+
+		if d.config.OutboxRetention > 0 && time.Since(lastPrune) > time.Hour {
+			if _, err := d.store.PruneDelivered(ctx, time.Now().Add(-d.config.OutboxRetention)); err != nil {
+				log.Println("pruning delivered outbox events failed: ", err)
+			}
+			lastPrune = time.Now()
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"splitExpense/config"
+	"splitExpense/expense"
+)
+
+// memoryStore keeps the outbox in memory and records whether a transaction is open
+type memoryStore struct {
+	messages    []Message
+	inTx        bool
+	delivered   []int64
+	failed      []int64
+	leasedUntil time.Time
+}
+
+func (s *memoryStore) RunInTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	s.inTx = true
+	defer func() { s.inTx = false }()
+	return fn(ctx)
+}
+
+func (s *memoryStore) Enqueue(ctx context.Context, event expense.Event) error {
+	s.messages = append(s.messages, Message{Event: event})
+	return nil
+}
+
+func (s *memoryStore) ClaimDue(ctx context.Context, limit int, leasedUntil time.Time) ([]Message, error) {
+	s.leasedUntil = leasedUntil
+	return s.messages[:min(limit, len(s.messages))], nil
+}
+
+func (s *memoryStore) MarkDelivered(ctx context.Context, sequence int64) error {
+	s.delivered = append(s.delivered, sequence)
+	return nil
+}
+
+func (s *memoryStore) MarkFailed(ctx context.Context, sequence int64, reason string, retryAt time.Time, dead bool) error {
+	s.failed = append(s.failed, sequence)
+	return nil
+}
+
+func (s *memoryStore) PruneDelivered(ctx context.Context, deliveredBefore time.Time) (int, error) {
+	return 0, nil
+}
+
+// checkingSink fails the events in fail and reports a delivery made inside a transaction
+type checkingSink struct {
+	store *memoryStore
+	fail  map[int64]bool
+	inTx  bool
+}
+
+func (s *checkingSink) Name() string { return "checking" }
+
+func (s *checkingSink) Deliver(ctx context.Context, event expense.Event) error {
+	s.inTx = s.inTx || s.store.inTx
+	if s.fail[event.Sequence] {
+		return errors.New("unavailable")
+	}
+	return nil
+}
+
+func TestDispatchOnceDeliversOutsideTheClaim(t *testing.T) {
+	store := &memoryStore{}
+	for sequence := int64(1); sequence <= 3; sequence++ {
+		store.messages = append(store.messages, Message{Event: expense.Event{Sequence: sequence, Type: expense.EventExpenseCreated}})
+	}
+	sink := &checkingSink{store: store, fail: map[int64]bool{2: true}}
+	d := NewDispatcher(&config.Config{OutboxBatchSize: 10, OutboxRetryBackoff: time.Second}, store, sink)
+
+	delivered, err := d.DispatchOnce(context.Background())
+	if err != nil {
+		t.Fatalf("DispatchOnce: %v", err)
+	}
+	if delivered != 2 || len(store.delivered) != 2 || len(store.failed) != 1 || store.failed[0] != 2 {
+		t.Fatalf("DispatchOnce: delivered %d, marked delivered %v and failed %v", delivered, store.delivered, store.failed)
+	}
+	if sink.inTx {
+		t.Fatalf("a sink was called inside a transaction")
+	}
+	if !store.leasedUntil.After(time.Now()) {
+		t.Fatalf("claimed messages are not leased: %v", store.leasedUntil)
+	}
+}
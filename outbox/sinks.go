@@ -0,0 +1,135 @@
+package outbox
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"splitExpense/expense"
+
+	lodash "github.com/samber/lo"
+)
+
+// Sink receives dispatched events. Delivery is at least once, so a sink must
+// tolerate seeing an event again, Event.ID identifies duplicates.
+type Sink interface {
+	Name() string
+	Deliver(ctx context.Context, event expense.Event) error
+}
+
+// LogSink writes every event to the standard logger
+type LogSink struct{}
+
+func (LogSink) Name() string { return "log" }
+
+func (LogSink) Deliver(ctx context.Context, event expense.Event) error {
+	log.Printf("event %s %s %s/%s v%d by %q", event.ID, event.Type, event.AggregateType, event.AggregateID, event.AggregateVersion, event.ActorID)
+	return nil
+}
+
+// WebhookSink POSTs every event as JSON. With a secret the body is signed with
+// HMAC-SHA256 in the X-SplitExpense-Signature header. Any non 2xx response is
+// a failed delivery and is retried.
+type WebhookSink struct {
+	url    string
+	secret string
+	client *http.Client
+}
+
+func NewWebhookSink(url string, secret string, timeout time.Duration) *WebhookSink {
+	return &WebhookSink{url: url, secret: secret, client: &http.Client{Timeout: timeout}}
+}
+
+func (w *WebhookSink) Name() string { return "webhook" }
+
+func (w *WebhookSink) Deliver(ctx context.Context, event expense.Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-SplitExpense-Event", string(event.Type))
+	req.Header.Set("X-SplitExpense-Delivery", event.ID)
+	if w.secret != "" {
+		mac := hmac.New(sha256.New, []byte(w.secret))
+		mac.Write(body)
+		req.Header.Set("X-SplitExpense-Signature", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook responded %s", resp.Status)
+	}
+	return nil
+}
+
+// Handler is an in-process subscriber, an error makes the event retry
+type Handler func(ctx context.Context, event expense.Event) error
+
+type subscription struct {
+	id      int
+	types   []expense.EventType
+	handler Handler
+}
+
+// Subscribers fans events out to in-process handlers
+type Subscribers struct {
+	mu     sync.RWMutex
+	nextId int
+	subs   []subscription
+}
+
+func NewSubscribers() *Subscribers {
+	return &Subscribers{}
+}
+
+func (s *Subscribers) Name() string { return "subscribers" }
+
+// Subscribe registers handler for the given event types, every type when none
+// are given. The returned func removes the subscription.
+func (s *Subscribers) Subscribe(handler Handler, types ...expense.EventType) func() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.nextId++
+	id := s.nextId
+	s.subs = append(s.subs, subscription{id: id, types: types, handler: handler})
+	return func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.subs = lodash.Reject(s.subs, func(sub subscription, _ int) bool { return sub.id == id })
+	}
+}
+
+func (s *Subscribers) Deliver(ctx context.Context, event expense.Event) error {
+	s.mu.RLock()
+	subs := append([]subscription{}, s.subs...)
+	s.mu.RUnlock()
+
+	var errs []error
+	for _, sub := range subs {
+		if len(sub.types) > 0 && !lodash.Contains(sub.types, event.Type) {
+			continue
+		}
+		if err := sub.handler(ctx, event); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
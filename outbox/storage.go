@@ -0,0 +1,264 @@
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+
+	"splitExpense/expense"
+
+	"github.com/google/uuid"
+)
+
+// Storage decorates an expense.Storage so every expense, group and friend
+// change enqueues an event in the same transaction. Reads go straight through.
+type Storage struct {
+	expense.Storage
+	outbox Store
+}
+
+// NewStorage wraps storage, outbox must share its transactions, e.g. be the same DBStorage
+func NewStorage(storage expense.Storage, outbox Store) *Storage {
+	return &Storage{Storage: storage, outbox: outbox}
+}
+
+func (s *Storage) enqueue(ctx context.Context, eventType expense.EventType, aggregateType string, aggregateId string, version int, payload any) error {
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	return s.outbox.Enqueue(ctx, expense.Event{
+		ID:               uuid.New().String(),
+		Type:             eventType,
+		AggregateType:    aggregateType,
+		AggregateID:      aggregateId,
+		AggregateVersion: version,
+		Payload:          raw,
+		ActorID:          expense.ActorFrom(ctx),
+	})
+}
+
+func (s *Storage) CreateOrUpdateExpense(ctx context.Context, exp expense.Expense) (*expense.Expense, error) {
+	var saved *expense.Expense
+	err := s.Storage.RunInTx(ctx, func(ctx context.Context) error {
+		current, err := s.Storage.FetchExpense(ctx, exp.ID)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+		saved, err = s.Storage.CreateOrUpdateExpense(ctx, exp)
+		if err != nil {
+			return err
+		}
+
+		eventType := expense.EventExpenseCreated
+		if current != nil {
+			eventType = expense.ExpenseChange(*current, *saved)
+		}
+		return s.enqueue(ctx, eventType, expense.AggregateExpense, saved.ID, saved.Version, saved)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return saved, nil
+}
+
+func (s *Storage) CreateExpenses(ctx context.Context, exps []expense.Expense) ([]expense.Expense, error) {
+	var created []expense.Expense
+	err := s.Storage.RunInTx(ctx, func(ctx context.Context) error {
+		var err error
+		created, err = s.Storage.CreateExpenses(ctx, exps)
+		if err != nil {
+			return err
+		}
+		for _, saved := range created {
+			if err := s.enqueue(ctx, expense.EventExpenseCreated, expense.AggregateExpense, saved.ID, saved.Version, saved); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+func (s *Storage) DeleteExpense(ctx context.Context, id string) (bool, error) {
+	deleted := false
+	err := s.Storage.RunInTx(ctx, func(ctx context.Context) error {
+		current, err := s.Storage.FetchExpense(ctx, id)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		deleted, err = s.Storage.DeleteExpense(ctx, id)
+		if err != nil || !deleted {
+			return err
+		}
+		return s.enqueue(ctx, expense.EventExpenseDeleted, expense.AggregateExpense, id, current.Version, current)
+	})
+	return deleted, err
+}
+
+func (s *Storage) AddExpenseMapping(ctx context.Context, expenseId string, userId string) (bool, error) {
+	return s.participantsChanged(ctx, expense.EventParticipantsAdded, expenseId, []string{userId}, func(ctx context.Context) (bool, error) {
+		return s.Storage.AddExpenseMapping(ctx, expenseId, userId)
+	})
+}
+
+// AddExpenseMappings enqueues a participants event for every expense given users
+func (s *Storage) AddExpenseMappings(ctx context.Context, mappings map[string][]string) error {
+	return s.Storage.RunInTx(ctx, func(ctx context.Context) error {
+		if err := s.Storage.AddExpenseMappings(ctx, mappings); err != nil {
+			return err
+		}
+		for expenseId, userIds := range mappings {
+			if len(userIds) == 0 {
+				continue
+			}
+			current, err := s.Storage.FetchExpense(ctx, expenseId)
+			if err != nil {
+				return err
+			}
+			err = s.enqueue(ctx, expense.EventParticipantsAdded, expense.AggregateExpense, expenseId, current.Version,
+				expense.ParticipantsPayload{ExpenseId: expenseId, UserIds: userIds})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (s *Storage) RemoveUsersFromExpense(ctx context.Context, expenseId string, usersToRemove []string) (bool, error) {
+	return s.participantsChanged(ctx, expense.EventParticipantsRemoved, expenseId, usersToRemove, func(ctx context.Context) (bool, error) {
+		return s.Storage.RemoveUsersFromExpense(ctx, expenseId, usersToRemove)
+	})
+}
+
+// participantsChanged runs change and enqueues eventType when it reports a change
+func (s *Storage) participantsChanged(ctx context.Context, eventType expense.EventType, expenseId string, userIds []string, change func(ctx context.Context) (bool, error)) (bool, error) {
+	changed := false
+	err := s.Storage.RunInTx(ctx, func(ctx context.Context) error {
+		var err error
+		changed, err = change(ctx)
+		if err != nil || !changed {
+			return err
+		}
+		current, err := s.Storage.FetchExpense(ctx, expenseId)
+		if err != nil {
+			return err
+		}
+		return s.enqueue(ctx, eventType, expense.AggregateExpense, expenseId, current.Version,
+			expense.ParticipantsPayload{ExpenseId: expenseId, UserIds: userIds})
+	})
+	return changed, err
+}
+
+func (s *Storage) CreateOrUpdateGroup(ctx context.Context, group expense.Group) (*expense.Group, error) {
+	var saved *expense.Group
+	err := s.Storage.RunInTx(ctx, func(ctx context.Context) error {
+		current, err := s.Storage.FetchGroupById(ctx, group.Id)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+		saved, err = s.Storage.CreateOrUpdateGroup(ctx, group)
+		if err != nil {
+			return err
+		}
+
+		eventType := expense.EventGroupCreated
+		if current != nil {
+			eventType = expense.EventGroupUpdated
+		}
+		return s.enqueue(ctx, eventType, expense.AggregateGroup, saved.Id, saved.Version, saved)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return saved, nil
+}
+
+func (s *Storage) DeleteGroup(ctx context.Context, groupId string) (bool, error) {
+	deleted := false
+	err := s.Storage.RunInTx(ctx, func(ctx context.Context) error {
+		current, err := s.Storage.FetchGroupById(ctx, groupId)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		deleted, err = s.Storage.DeleteGroup(ctx, groupId)
+		if err != nil || !deleted {
+			return err
+		}
+		return s.enqueue(ctx, expense.EventGroupDeleted, expense.AggregateGroup, groupId, current.Version, current)
+	})
+	return deleted, err
+}
+
+func (s *Storage) AddUserInGroup(ctx context.Context, userId string, groupId string) (bool, error) {
+	return s.membershipChanged(ctx, expense.EventMemberJoined, userId, groupId, s.Storage.AddUserInGroup)
+}
+
+func (s *Storage) RemoveUserFromGroup(ctx context.Context, userId string, groupId string) (bool, error) {
+	return s.membershipChanged(ctx, expense.EventMemberLeft, userId, groupId, s.Storage.RemoveUserFromGroup)
+}
+
+// membershipChanged runs change and enqueues eventType only when the membership actually changed
+func (s *Storage) membershipChanged(ctx context.Context, eventType expense.EventType, userId string, groupId string, change func(ctx context.Context, userId string, groupId string) (bool, error)) (bool, error) {
+	ok := false
+	err := s.Storage.RunInTx(ctx, func(ctx context.Context) error {
+		wasMember, err := s.Storage.CheckUserExistsInGroup(ctx, userId, groupId)
+		if err != nil {
+			return err
+		}
+		ok, err = change(ctx, userId, groupId)
+		if err != nil || !ok || wasMember == (eventType == expense.EventMemberJoined) {
+			return err
+		}
+		group, err := s.Storage.FetchGroupById(ctx, groupId)
+		if err != nil {
+			return err
+		}
+		return s.enqueue(ctx, eventType, expense.AggregateGroup, groupId, group.Version,
+			expense.MembershipPayload{GroupId: groupId, UserId: userId})
+	})
+	return ok, err
+}
+
+func (s *Storage) AddFriend(ctx context.Context, userId string, friendId string) (bool, error) {
+	ok := false
+	err := s.Storage.RunInTx(ctx, func(ctx context.Context) error {
+		_, err := s.Storage.GetFriend(ctx, userId, friendId)
+		alreadyFriends := err == nil
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+		ok, err = s.Storage.AddFriend(ctx, userId, friendId)
+		if err != nil || !ok || alreadyFriends {
+			return err
+		}
+		return s.enqueue(ctx, expense.EventFriendAdded, expense.AggregateUser, userId, 0,
+			expense.FriendshipPayload{UserId: userId, FriendId: friendId})
+	})
+	return ok, err
+}
+
+func (s *Storage) RemoveFriend(ctx context.Context, userId string, friendId string) (bool, error) {
+	removed := false
+	err := s.Storage.RunInTx(ctx, func(ctx context.Context) error {
+		var err error
+		removed, err = s.Storage.RemoveFriend(ctx, userId, friendId)
+		if err != nil || !removed {
+			return err
+		}
+		return s.enqueue(ctx, expense.EventFriendRemoved, expense.AggregateUser, userId, 0,
+			expense.FriendshipPayload{UserId: userId, FriendId: friendId})
+	})
+	return removed, err
+}
@@ -0,0 +1,35 @@
+// Package outbox publishes expense, group and friend changes reliably. The
+// Storage decorator writes an event into the outbox table in the same
+// transaction as the change, and the Dispatcher delivers it to the registered
+// sinks at least once, in order per aggregate.
+package outbox
+
+import (
+	"context"
+	"time"
+
+	"splitExpense/expense"
+)
+
+// Message is an outbox event waiting for delivery
+type Message struct {
+	expense.Event
+	// Attempts counts the earlier, failed deliveries
+	Attempts int
+}
+
+type Store interface {
+	RunInTx(ctx context.Context, fn func(ctx context.Context) error) error
+
+	// Enqueue stores event in the outbox, inside the transaction of ctx if any
+	Enqueue(ctx context.Context, event expense.Event) error
+	// ClaimDue leases up to limit due messages, at most one per aggregate, until
+	// leasedUntil. They are not due again before the lease runs out, so a
+	// dispatcher that stops mid delivery only delays them.
+	ClaimDue(ctx context.Context, limit int, leasedUntil time.Time) ([]Message, error)
+	MarkDelivered(ctx context.Context, sequence int64) error
+	// MarkFailed schedules the next attempt at retryAt, dead gives up on the message
+	MarkFailed(ctx context.Context, sequence int64, reason string, retryAt time.Time, dead bool) error
+	// PruneDelivered deletes messages delivered before deliveredBefore
+	PruneDelivered(ctx context.Context, deliveredBefore time.Time) (int, error)
+}
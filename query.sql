@@ -29,12 +29,16 @@ WHERE gm.group_id = $1;
 SELECT * FROM "group" WHERE id = $1 LIMIT 1;
 
 -- name: CreateOrUpdateGroup :one
-INSERT INTO "group" (id, name, description, admin_id)
-VALUES ($1, $2, $3, $4)
+-- A new row starts at the given version (at least 1) and created_at, so
+-- replaying a recorded group reproduces it exactly.
+INSERT INTO "group" (id, name, description, admin_id, version, created_at)
+VALUES ($1, $2, $3, $4, GREATEST(sqlc.arg(version)::integer, 1), COALESCE(sqlc.narg(created_at)::timestamptz, NOW()))
 ON CONFLICT (id) DO UPDATE SET
     name = EXCLUDED.name,
     description = EXCLUDED.description,
-    admin_id = EXCLUDED.admin_id
+    admin_id = EXCLUDED.admin_id,
+    version = "group".version + 1
+WHERE "group".version = sqlc.arg(version)::integer
 RETURNING *;
 
 -- name: AddUserInGroup :one
@@ -49,10 +53,12 @@ WHERE user_id = $1 AND group_id = $2
 RETURNING TRUE;
 
 -- name: CreateOrUpdateExpense :one
-INSERT INTO expense (id, description, amount, split, status, settled_by, created_by, payee, created_at, updated_at, group_id)
-VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+-- A new row starts at the given version, at least 1, see CreateOrUpdateGroup.
+INSERT INTO expense (id, description, amount, split, status, settled_by, created_by, payee, created_at, updated_at, group_id, category, version)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, GREATEST(sqlc.arg(version)::integer, 1))
 ON CONFLICT (id) DO UPDATE SET
     description = EXCLUDED.description,
+    category = EXCLUDED.category,
     amount = EXCLUDED.amount,
     split = EXCLUDED.split,
     status = EXCLUDED.status,
@@ -60,7 +66,9 @@ ON CONFLICT (id) DO UPDATE SET
     created_by = EXCLUDED.created_by,
     payee = EXCLUDED.payee,
     updated_at = NOW() AT TIME ZONE 'Asia/Kolkata',
-    group_id = EXCLUDED.group_id
+    group_id = EXCLUDED.group_id,
+    version = expense.version + 1
+WHERE expense.version = sqlc.arg(version)::integer
 RETURNING *;
 
 -- name: FetchExpense :one
@@ -95,11 +103,21 @@ SELECT $1, $2
 ON CONFLICT DO NOTHING
 RETURNING TRUE;
 
+-- name: AddExpenseMappings :exec
+-- Maps the users to the expenses pairwise, existing mappings are kept.
+INSERT INTO expense_mapping (expense_id, user_id)
+SELECT unnest(sqlc.arg(expense_ids)::uuid[]), unnest(sqlc.arg(user_ids)::uuid[])
+ON CONFLICT DO NOTHING;
+
 -- name: RemoveUsersFromExpenseMapping :one
 DELETE FROM expense_mapping
 WHERE expense_id = $1 AND user_id = ANY($2::uuid[])
 RETURNING TRUE;
 
+-- name: FetchExpenseParticipants :many
+SELECT user_id FROM expense_mapping
+WHERE expense_id = $1;
+
 -- name: AddFriend :one 
 INSERT INTO friends (user_id, friend_id) 
 VALUES ($1, $2)
@@ -143,4 +161,433 @@ JOIN expense e ON em.expense_id = e.id
 WHERE em.user_id = $1 AND e.status = $2;
 
 -- name: DeleteGroup :one
-DELETE FROM "group" WHERE id = $1 RETURNING TRUE;
\ No newline at end of file
+DELETE FROM "group" WHERE id = $1 RETURNING TRUE;
+
+-- name: ArchiveGroup :one
+-- Copies the group to group_archive, locking it against concurrent joins and
+-- expenses until the deleting transaction ends.
+INSERT INTO group_archive (id, name, description, admin_id, version, created_at)
+SELECT id, name, description, admin_id, version, created_at
+FROM "group" WHERE id = sqlc.arg(group_id)::uuid
+FOR UPDATE
+RETURNING TRUE;
+
+-- name: ArchiveGroupMembers :execrows
+INSERT INTO group_members_archive (group_id, user_id)
+SELECT group_id, user_id FROM group_members WHERE group_id = sqlc.arg(group_id)::uuid;
+
+-- name: FetchGroupExpenseIdsForUpdate :many
+SELECT id FROM expense WHERE group_id = sqlc.arg(group_id)::uuid ORDER BY id FOR UPDATE;
+
+-- name: LockGroup :one
+-- Holds the group against concurrent joins and expenses until the transaction ends.
+SELECT TRUE FROM "group" WHERE id = sqlc.arg(group_id)::uuid FOR UPDATE;
+
+-- name: CountUnsettledGroupExpenses :one
+-- Drafts that still carry an amount, settled expenses no longer move money.
+SELECT COUNT(*) AS count FROM expense
+WHERE group_id = sqlc.arg(group_id)::uuid AND status = 'DRAFT' AND amount > 0;
+
+-- name: FetchGroupExpensesPage :many
+-- Archived expenses are only read when include_archived is set.
+WITH e AS (
+    SELECT id, description, amount, split, status, settled_by, created_by, payee, group_id, created_at, updated_at, version, category FROM expense
+    UNION ALL
+    SELECT id, description, amount, split, status, settled_by, created_by, payee, group_id, created_at, updated_at, version, category FROM expense_archive WHERE sqlc.arg(include_archived)::boolean
+)
+SELECT * FROM e
+WHERE e.group_id = sqlc.arg(group_id)::uuid
+  AND (sqlc.narg(status)::text IS NULL OR e.status = sqlc.narg(status)::text)
+  AND (sqlc.narg(cursor_created_at)::timestamptz IS NULL
+       OR (e.created_at, e.id) < (sqlc.narg(cursor_created_at)::timestamptz, sqlc.narg(cursor_id)::uuid))
+ORDER BY e.created_at DESC, e.id DESC
+LIMIT sqlc.arg(page_limit);
+
+-- name: FetchUserExpensesPage :many
+SELECT e.* FROM expense_mapping em
+JOIN expense e ON em.expense_id = e.id
+WHERE em.user_id = sqlc.arg(user_id)
+  AND (sqlc.narg(status)::text IS NULL OR e.status = sqlc.narg(status)::text)
+  AND (sqlc.narg(cursor_created_at)::timestamptz IS NULL
+       OR (e.created_at, e.id) < (sqlc.narg(cursor_created_at)::timestamptz, sqlc.narg(cursor_id)::uuid))
+ORDER BY e.created_at DESC, e.id DESC
+LIMIT sqlc.arg(page_limit);
+
+-- name: GetFriendsPage :many
+SELECT u.id, u.name, u.email, u.is_verified, f.created_at AS friends_since
+FROM users u
+JOIN friends f ON u.id = f.friend_id
+WHERE f.user_id = sqlc.arg(user_id)
+  AND (sqlc.narg(cursor_created_at)::timestamp IS NULL
+       OR (f.created_at, f.friend_id) < (sqlc.narg(cursor_created_at)::timestamp, sqlc.narg(cursor_id)::uuid))
+ORDER BY f.created_at DESC, f.friend_id DESC
+LIMIT sqlc.arg(page_limit);
+
+-- name: FetchGroupsByUserPage :many
+SELECT g.* FROM "group" g
+JOIN group_members gm ON g.id = gm.group_id
+WHERE gm.user_id = sqlc.arg(user_id)
+  AND (sqlc.narg(cursor_created_at)::timestamptz IS NULL
+       OR (g.created_at, g.id) < (sqlc.narg(cursor_created_at)::timestamptz, sqlc.narg(cursor_id)::uuid))
+ORDER BY g.created_at DESC, g.id DESC
+LIMIT sqlc.arg(page_limit);
+
+-- name: SearchExpenses :many
+-- Every filter is optional, a null argument matches all rows. Archived
+-- expenses are only searched when include_archived is set.
+WITH e AS (
+    SELECT id, description, amount, split, status, settled_by, created_by, payee, group_id, created_at, updated_at, version, category FROM expense
+    UNION ALL
+    SELECT id, description, amount, split, status, settled_by, created_by, payee, group_id, created_at, updated_at, version, category FROM expense_archive WHERE sqlc.arg(include_archived)::boolean
+)
+SELECT * FROM e
+WHERE e.id IN (
+       SELECT expense_id FROM expense_mapping WHERE user_id = sqlc.arg(user_id)::uuid
+       UNION ALL
+       SELECT expense_id FROM expense_mapping_archive WHERE user_id = sqlc.arg(user_id)::uuid)
+  AND (sqlc.narg(query)::text IS NULL
+       OR to_tsvector('english', coalesce(e.description, '')) @@ websearch_to_tsquery('english', sqlc.narg(query)::text))
+  AND (sqlc.narg(group_id)::uuid IS NULL OR e.group_id = sqlc.narg(group_id)::uuid)
+  AND (sqlc.narg(participant_id)::uuid IS NULL OR e.id IN (
+       SELECT expense_id FROM expense_mapping WHERE user_id = sqlc.narg(participant_id)::uuid
+       UNION ALL
+       SELECT expense_id FROM expense_mapping_archive WHERE user_id = sqlc.narg(participant_id)::uuid))
+  AND (sqlc.narg(payer_id)::text IS NULL OR e.payee->'payerSplit'->>sqlc.narg(payer_id)::text IS NOT NULL)
+  AND (sqlc.narg(status)::text IS NULL OR e.status = sqlc.narg(status)::text)
+  AND (sqlc.narg(category)::text IS NULL OR lower(e.category) = lower(sqlc.narg(category)::text))
+  AND (sqlc.narg(min_amount)::numeric IS NULL OR e.amount >= sqlc.narg(min_amount)::numeric)
+  AND (sqlc.narg(max_amount)::numeric IS NULL OR e.amount <= sqlc.narg(max_amount)::numeric)
+  AND (sqlc.narg(created_from)::timestamptz IS NULL OR e.created_at >= sqlc.narg(created_from)::timestamptz)
+  AND (sqlc.narg(created_before)::timestamptz IS NULL OR e.created_at < sqlc.narg(created_before)::timestamptz)
+  AND (sqlc.narg(cursor_created_at)::timestamptz IS NULL
+       OR (e.created_at, e.id) < (sqlc.narg(cursor_created_at)::timestamptz, sqlc.narg(cursor_id)::uuid))
+ORDER BY e.created_at DESC, e.id DESC
+LIMIT sqlc.arg(page_limit);
+
+-- name: FetchSettledExpensesToArchive :many
+-- Locks the batch so concurrent archival runs skip each other's rows.
+SELECT id FROM expense
+WHERE status = 'SETTLED' AND updated_at < sqlc.arg(settled_before)
+ORDER BY updated_at
+LIMIT sqlc.arg(batch_size)
+FOR UPDATE SKIP LOCKED;
+
+-- name: CopyExpensesToArchive :exec
+INSERT INTO expense_archive (id, description, amount, split, status, settled_by, created_by, payee, group_id, created_at, updated_at, version, category)
+SELECT id, description, amount, split, status, settled_by, created_by, payee, group_id, created_at, updated_at, version, category
+FROM expense WHERE id = ANY(sqlc.arg(ids)::uuid[]);
+
+-- name: CopyExpenseMappingsToArchive :exec
+INSERT INTO expense_mapping_archive (expense_id, user_id)
+SELECT expense_id, user_id FROM expense_mapping WHERE expense_id = ANY(sqlc.arg(ids)::uuid[]);
+
+-- name: DeleteArchivedExpenses :execrows
+DELETE FROM expense WHERE id = ANY(sqlc.arg(ids)::uuid[]);
+
+-- name: AppendLedgerEvent :one
+INSERT INTO ledger_events (id, type, aggregate_type, aggregate_id, aggregate_version, payload, actor_id)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING *;
+
+-- name: LoadLedgerEvents :many
+SELECT * FROM ledger_events
+WHERE sequence > sqlc.arg(after_sequence)
+ORDER BY sequence
+LIMIT sqlc.arg(page_limit);
+
+-- name: LoadAggregateEvents :many
+SELECT * FROM ledger_events
+WHERE aggregate_type = $1 AND aggregate_id = $2
+ORDER BY sequence;
+
+-- name: CountLedgerEventsByType :many
+SELECT type, COUNT(*) AS count FROM ledger_events GROUP BY type ORDER BY type;
+
+-- name: LockLedger :exec
+-- Blocks other writers and replays until the transaction ends.
+LOCK TABLE ledger_events IN SHARE ROW EXCLUSIVE MODE;
+
+-- name: BootstrapGroupEvents :execrows
+-- Records live and deleted groups that predate the ledger as GroupCreated events.
+INSERT INTO ledger_events (id, type, aggregate_type, aggregate_id, aggregate_version, payload)
+SELECT gen_random_uuid(), 'GroupCreated', 'group', g.id, g.version,
+       jsonb_build_object('id', g.id, 'name', g.name, 'description', g.description, 'admin', g.admin_id,
+                          'version', g.version, 'createdAt', g.created_at)
+FROM (
+    SELECT id, name, description, admin_id, version, created_at FROM "group"
+    UNION ALL
+    SELECT id, name, description, admin_id, version, created_at FROM group_archive
+) g
+WHERE NOT EXISTS (
+    SELECT 1 FROM ledger_events le WHERE le.aggregate_type = 'group' AND le.aggregate_id = g.id)
+ORDER BY g.created_at, g.id;
+
+-- name: BootstrapMemberEvents :execrows
+INSERT INTO ledger_events (id, type, aggregate_type, aggregate_id, aggregate_version, payload)
+SELECT gen_random_uuid(), 'MemberJoined', 'group', g.id, g.version,
+       jsonb_build_object('groupId', gm.group_id, 'userId', gm.user_id)
+FROM (
+    SELECT group_id, user_id FROM group_members
+    UNION ALL
+    SELECT group_id, user_id FROM group_members_archive
+) gm
+JOIN (
+    SELECT id, version, created_at FROM "group"
+    UNION ALL
+    SELECT id, version, created_at FROM group_archive
+) g ON g.id = gm.group_id
+WHERE NOT EXISTS (
+    SELECT 1 FROM ledger_events le
+    WHERE le.aggregate_type = 'group' AND le.aggregate_id = gm.group_id
+      AND le.type = 'MemberJoined' AND le.payload->>'userId' = gm.user_id::text)
+ORDER BY g.created_at, g.id, gm.user_id;
+
+-- name: BootstrapExpenseEvents :execrows
+-- Records live and archived expenses that predate the ledger as ExpenseCreated
+-- events, the payload has the JSON shape of expense.Expense.
+INSERT INTO ledger_events (id, type, aggregate_type, aggregate_id, aggregate_version, payload)
+SELECT gen_random_uuid(), 'ExpenseCreated', 'expense', e.id, e.version,
+       jsonb_build_object('id', e.id, 'description', COALESCE(e.description, ''), 'category', e.category,
+                          'amount', e.amount::float8, 'createdAt', e.created_at, 'payeeW', e.payee, 'splitW', e.split,
+                          'status', e.status, 'isGroupExpense', e.group_id IS NOT NULL,
+                          'groupId', COALESCE(e.group_id::text, ''), 'settledBy', COALESCE(e.settled_by::text, ''),
+                          'createdBy', e.created_by, 'version', e.version)
+FROM (
+    SELECT id, description, amount, split, status, settled_by, created_by, payee, group_id, created_at, version, category FROM expense
+    UNION ALL
+    SELECT id, description, amount, split, status, settled_by, created_by, payee, group_id, created_at, version, category FROM expense_archive
+) e
+WHERE NOT EXISTS (
+    SELECT 1 FROM ledger_events le WHERE le.aggregate_type = 'expense' AND le.aggregate_id = e.id)
+ORDER BY e.created_at, e.id;
+
+-- name: BootstrapParticipantEvents :execrows
+INSERT INTO ledger_events (id, type, aggregate_type, aggregate_id, aggregate_version, payload)
+SELECT gen_random_uuid(), 'ParticipantsAdded', 'expense', m.expense_id, 0,
+       jsonb_build_object('expenseId', m.expense_id, 'userIds', jsonb_agg(m.user_id ORDER BY m.user_id))
+FROM (
+    SELECT expense_id, user_id FROM expense_mapping
+    UNION ALL
+    SELECT expense_id, user_id FROM expense_mapping_archive
+) m
+WHERE NOT EXISTS (
+    SELECT 1 FROM ledger_events le
+    WHERE le.aggregate_type = 'expense' AND le.aggregate_id = m.expense_id
+      AND le.type = 'ParticipantsAdded' AND le.payload->'userIds' @> to_jsonb(m.user_id::text))
+GROUP BY m.expense_id
+ORDER BY m.expense_id;
+
+-- name: BootstrapArchivedExpenseEvents :execrows
+-- Runs after the participant events, archived expenses of deleted groups are
+-- left to the GroupDeleted events.
+INSERT INTO ledger_events (id, type, aggregate_type, aggregate_id, aggregate_version, payload)
+SELECT gen_random_uuid(), 'ExpenseArchived', 'expense', e.id, e.version,
+       jsonb_build_object('id', e.id, 'description', COALESCE(e.description, ''), 'category', e.category,
+                          'amount', e.amount::float8, 'createdAt', e.created_at, 'payeeW', e.payee, 'splitW', e.split,
+                          'status', e.status, 'isGroupExpense', e.group_id IS NOT NULL,
+                          'groupId', COALESCE(e.group_id::text, ''), 'settledBy', COALESCE(e.settled_by::text, ''),
+                          'createdBy', e.created_by, 'version', e.version)
+FROM expense_archive e
+WHERE NOT EXISTS (SELECT 1 FROM group_archive ga WHERE ga.id = e.group_id)
+  AND NOT EXISTS (
+    SELECT 1 FROM ledger_events le
+    WHERE le.aggregate_type = 'expense' AND le.aggregate_id = e.id AND le.type = 'ExpenseArchived')
+ORDER BY e.updated_at, e.id;
+
+-- name: BootstrapGroupDeletedEvents :execrows
+-- Runs after the participant events, so replay archives a deleted group
+-- together with the expenses it had.
+INSERT INTO ledger_events (id, type, aggregate_type, aggregate_id, aggregate_version, payload)
+SELECT gen_random_uuid(), 'GroupDeleted', 'group', g.id, g.version,
+       jsonb_build_object('id', g.id, 'name', g.name, 'description', g.description, 'admin', g.admin_id,
+                          'version', g.version, 'createdAt', g.created_at)
+FROM group_archive g
+WHERE NOT EXISTS (
+    SELECT 1 FROM ledger_events le
+    WHERE le.aggregate_type = 'group' AND le.aggregate_id = g.id AND le.type = 'GroupDeleted')
+ORDER BY g.deleted_at, g.id;
+
+-- name: ResetGroupMembersArchive :exec
+DELETE FROM group_members_archive;
+
+-- name: ResetGroupArchive :exec
+DELETE FROM group_archive;
+
+-- name: ResetExpenseMappingArchive :exec
+DELETE FROM expense_mapping_archive;
+
+-- name: ResetExpenseArchive :exec
+DELETE FROM expense_archive;
+
+-- name: ResetExpenseMappings :exec
+DELETE FROM expense_mapping;
+
+-- name: ResetExpenses :exec
+DELETE FROM expense;
+
+-- name: ResetGroupMembers :exec
+DELETE FROM group_members;
+
+-- name: ResetGroups :exec
+DELETE FROM "group";
+
+-- name: DeleteBalanceEntries :exec
+DELETE FROM ledger_balance_entries WHERE expense_id = $1;
+
+-- name: DeleteGroupBalanceEntries :exec
+DELETE FROM ledger_balance_entries WHERE group_id = $1;
+
+-- name: ResetBalanceEntries :exec
+DELETE FROM ledger_balance_entries;
+
+-- name: InsertBalanceEntry :exec
+INSERT INTO ledger_balance_entries (expense_id, user_id, group_id, category, status, paid, share)
+VALUES ($1, $2, $3, $4, $5, $6, $7);
+
+-- name: InsertBalanceEntries :exec
+-- The arrays hold one entry each position, an empty group id is a personal expense.
+INSERT INTO ledger_balance_entries (expense_id, user_id, group_id, category, status, paid, share)
+SELECT unnest(sqlc.arg(expense_ids)::uuid[]), unnest(sqlc.arg(user_ids)::uuid[]),
+    NULLIF(unnest(sqlc.arg(group_ids)::text[]), '')::uuid, unnest(sqlc.arg(categories)::text[]),
+    unnest(sqlc.arg(statuses)::text[]), unnest(sqlc.arg(paid)::numeric[]), unnest(sqlc.arg(shares)::numeric[]);
+
+-- name: FetchLedgerBalances :many
+SELECT group_id, SUM(paid)::numeric AS paid, SUM(share)::numeric AS share
+FROM ledger_balance_entries
+WHERE user_id = $1 AND status = 'DRAFT'
+GROUP BY group_id;
+
+-- name: FetchLedgerCategoryTotals :many
+SELECT category, SUM(share)::numeric AS total
+FROM ledger_balance_entries
+WHERE user_id = $1
+GROUP BY category
+ORDER BY category;
+
+-- name: EnqueueOutboxEvent :exec
+INSERT INTO outbox_events (id, type, aggregate_type, aggregate_id, aggregate_version, payload, actor_id)
+VALUES ($1, $2, $3, $4, $5, $6, $7);
+
+-- name: ClaimOutboxEvents :many
+-- Only the oldest pending event of each aggregate is due, so a failing event
+-- holds back the ones after it. Claimed events are leased by moving their next
+-- attempt to leased_until, other dispatchers skip them until the lease runs out.
+WITH due AS (
+    SELECT o.sequence FROM outbox_events o
+    WHERE o.delivered_at IS NULL AND o.dead_at IS NULL AND o.next_attempt_at <= NOW()
+      AND NOT EXISTS (
+          SELECT 1 FROM outbox_events p
+          WHERE p.aggregate_type = o.aggregate_type AND p.aggregate_id = o.aggregate_id
+            AND p.delivered_at IS NULL AND p.dead_at IS NULL AND p.sequence < o.sequence)
+    ORDER BY o.sequence
+    LIMIT sqlc.arg(batch_size)
+    FOR UPDATE OF o SKIP LOCKED
+)
+UPDATE outbox_events e
+SET next_attempt_at = sqlc.arg(leased_until)
+FROM due
+WHERE e.sequence = due.sequence
+RETURNING e.*;
+
+-- name: MarkOutboxEventDelivered :exec
+UPDATE outbox_events
+SET delivered_at = NOW(), attempts = attempts + 1, last_error = NULL
+WHERE sequence = $1;
+
+-- name: MarkOutboxEventFailed :exec
+UPDATE outbox_events
+SET attempts = attempts + 1,
+    last_error = sqlc.arg(last_error),
+    next_attempt_at = sqlc.arg(next_attempt_at),
+    dead_at = CASE WHEN sqlc.arg(dead)::boolean THEN NOW() END
+WHERE sequence = sqlc.arg(sequence);
+
+-- name: DeleteDeliveredOutboxEvents :execrows
+DELETE FROM outbox_events
+WHERE delivered_at < sqlc.arg(delivered_before);
+
+-- name: SetRowSecurityRole :exec
+-- Only valid inside a transaction, the role is reset when it ends.
+SET LOCAL ROLE splitexpense_rls;
+
+-- name: SetRowSecurityUser :exec
+SELECT set_config('app.user_id', sqlc.arg(user_id)::text, true);
+
+-- name: FetchExpensesAfterId :many
+-- Walks every live expense in id order, for maintenance jobs.
+SELECT * FROM expense
+WHERE id > sqlc.arg(after_id)::uuid
+ORDER BY id
+LIMIT sqlc.arg(page_limit);
+
+-- name: FetchGroupsWithoutAdminMember :many
+SELECT g.id, g.admin_id FROM "group" g
+WHERE NOT EXISTS (SELECT 1 FROM group_members gm WHERE gm.group_id = g.id AND gm.user_id = g.admin_id)
+ORDER BY g.id;
+
+-- name: FetchGroupExpenseNonMembers :many
+-- Participants of group expenses who are not, or no longer, members of the group.
+SELECT em.expense_id, e.group_id, em.user_id FROM expense_mapping em
+JOIN expense e ON e.id = em.expense_id
+WHERE e.group_id IS NOT NULL
+  AND NOT EXISTS (SELECT 1 FROM group_members gm WHERE gm.group_id = e.group_id AND gm.user_id = em.user_id)
+ORDER BY em.expense_id, em.user_id;
+
+-- name: FetchUsersByIds :many
+-- Batched lookups for the GraphQL loaders, ids that match nothing are left out.
+SELECT * FROM "users" WHERE id = ANY(sqlc.arg(ids)::uuid[]);
+
+-- name: FetchGroupsByIds :many
+SELECT * FROM "group" WHERE id = ANY(sqlc.arg(ids)::uuid[]);
+
+-- name: FetchGroupsExpensesPage :many
+-- The page of FetchGroupExpensesPage for several groups at once, every group
+-- gets up to page_limit expenses after the same cursor.
+WITH e AS (
+    SELECT id, description, amount, split, status, settled_by, created_by, payee, group_id, created_at, updated_at, version, category FROM expense
+    WHERE group_id = ANY(sqlc.arg(group_ids)::uuid[])
+    UNION ALL
+    SELECT id, description, amount, split, status, settled_by, created_by, payee, group_id, created_at, updated_at, version, category FROM expense_archive
+    WHERE sqlc.arg(include_archived)::boolean AND group_id = ANY(sqlc.arg(group_ids)::uuid[])
+), ranked AS (
+    SELECT e.*, ROW_NUMBER() OVER (PARTITION BY e.group_id ORDER BY e.created_at DESC, e.id DESC) AS position
+    FROM e
+    WHERE sqlc.narg(cursor_created_at)::timestamptz IS NULL
+       OR (e.created_at, e.id) < (sqlc.narg(cursor_created_at)::timestamptz, sqlc.narg(cursor_id)::uuid)
+)
+SELECT id, description, amount, split, status, settled_by, created_by, payee, group_id, created_at, updated_at, version, category
+FROM ranked
+WHERE position <= sqlc.arg(page_limit)::bigint
+ORDER BY group_id, created_at DESC, id DESC;
+
+-- name: FetchGroupMembersByGroupIds :many
+SELECT gm.group_id, u.id, u.name, u.email, u.is_verified FROM "users" u
+JOIN group_members gm ON u.id = gm.user_id
+WHERE gm.group_id = ANY(sqlc.arg(group_ids)::uuid[]);
+
+-- name: ReserveIdempotencyKey :one
+-- Claims the key for a request, taking over an expired claim. No row means
+-- the key is in use.
+INSERT INTO idempotency_keys (user_id, idempotency_key, request_hash, expires_at)
+VALUES (sqlc.arg(user_id), sqlc.arg(idempotency_key), sqlc.arg(request_hash), sqlc.arg(expires_at))
+ON CONFLICT (user_id, idempotency_key) DO UPDATE
+SET request_hash = EXCLUDED.request_hash, status = NULL, content_type = '', response = NULL,
+    created_at = NOW(), expires_at = EXCLUDED.expires_at
+WHERE idempotency_keys.expires_at <= NOW()
+RETURNING created_at;
+
+-- name: FetchIdempotencyKey :one
+SELECT * FROM idempotency_keys
+WHERE user_id = sqlc.arg(user_id) AND idempotency_key = sqlc.arg(idempotency_key);
+
+-- name: CompleteIdempotencyKey :exec
+UPDATE idempotency_keys
+SET status = sqlc.arg(status), content_type = sqlc.arg(content_type), response = sqlc.arg(response)
+WHERE user_id = sqlc.arg(user_id) AND idempotency_key = sqlc.arg(idempotency_key);
+
+-- name: ReleaseIdempotencyKey :exec
+DELETE FROM idempotency_keys
+WHERE user_id = sqlc.arg(user_id) AND idempotency_key = sqlc.arg(idempotency_key);
+
+-- name: DeleteExpiredIdempotencyKeys :execrows
+DELETE FROM idempotency_keys WHERE expires_at <= NOW();
@@ -0,0 +1,63 @@
+package rpcServer
+
+import (
+	"context"
+	"splitExpense/expense"
+	"splitExpense/rpc/pb"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// publicMethods can be called without a token
+var publicMethods = map[string]bool{
+	pb.UserService_SignUp_FullMethodName: true,
+	pb.UserService_Login_FullMethodName:  true,
+}
+
+type userKey struct{}
+
+func userFromContext(ctx context.Context) (expense.User, bool) {
+	user, ok := ctx.Value(userKey{}).(expense.User)
+	return user, ok
+}
+
+// currentUserId is the id of the authenticated caller, the auth interceptor
+// has already rejected calls without one
+func currentUserId(ctx context.Context) string {
+	user, _ := userFromContext(ctx)
+	return user.ID
+}
+
+// authInterceptor is the gRPC counterpart of apiServer.Authenticate, it reads
+// the JWT from the "authorization: Bearer <token>" metadata. A token close to
+// expiry is refreshed through the "token" response header.
+func authInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	if publicMethods[info.FullMethod] {
+		return handler(ctx, req)
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get("authorization")
+	if len(values) == 0 || !strings.HasPrefix(values[0], "Bearer ") {
+		return nil, status.Error(codes.Unauthenticated, "missing bearer token")
+	}
+	claims, err := expense.ParseToken(strings.TrimPrefix(values[0], "Bearer "))
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+
+	user := expense.User{ID: claims.UserID, Name: claims.Name, Email: claims.Email, IsVerified: claims.IsVerified}
+	if expense.ShouldRefreshToken(claims) {
+		if token, err := expense.GenerateToken(user); err == nil {
+			grpc.SetHeader(ctx, metadata.Pairs("token", token))
+		}
+	}
+	ctx = context.WithValue(ctx, userKey{}, user)
+	// storage decorators such as the event ledger read the acting user from the context
+	ctx = expense.WithActor(ctx, user.ID)
+	return handler(ctx, req)
+}
@@ -0,0 +1,131 @@
+package rpcServer
+
+import (
+	"splitExpense/expense"
+	"splitExpense/rpc/pb"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func userToPb(u expense.User) *pb.User {
+	return &pb.User{Id: u.ID, Name: u.Name, Email: u.Email, IsVerified: u.IsVerified}
+}
+
+func usersToPb(users []expense.User) []*pb.User {
+	out := make([]*pb.User, 0, len(users))
+	for _, u := range users {
+		out = append(out, userToPb(u))
+	}
+	return out
+}
+
+func groupToPb(g expense.Group) *pb.Group {
+	return &pb.Group{
+		Id:          g.Id,
+		Name:        g.Name,
+		Description: g.Description,
+		AdminId:     g.Admin,
+		Version:     int32(g.Version),
+		CreatedAt:   timestamppb.New(g.CreatedAt),
+	}
+}
+
+func pageFromPb(p *pb.PageRequest) expense.PageRequest {
+	return expense.PageRequest{Cursor: p.GetCursor(), Limit: int(p.GetLimit())}
+}
+
+func payerToPb(pw expense.PayerWrapper) *pb.Payer {
+	switch p := pw.Payer.(type) {
+	case *expense.SinglePayer:
+		return &pb.Payer{Type: "single", Amounts: map[string]float64{p.Payer: p.Amount}}
+	case *expense.MultiPayer:
+		return &pb.Payer{Type: "multi", Amounts: p.Payers}
+	}
+	return nil
+}
+
+// payerFromPb follows PayerWrapper.UnmarshalJSON
+func payerFromPb(p *pb.Payer) (expense.PayerWrapper, error) {
+	switch p.GetType() {
+	case "single":
+		if len(p.Amounts) != 1 {
+			return expense.PayerWrapper{}, expense.ErrValidation("a single payer needs exactly one amount")
+		}
+		for payer, amount := range p.Amounts {
+			return expense.PayerWrapper{Type: "single", Payer: &expense.SinglePayer{Payer: payer, Amount: amount}}, nil
+		}
+	case "multi":
+		return expense.PayerWrapper{Type: "multi", Payer: &expense.MultiPayer{Payers: p.Amounts}}, nil
+	}
+	return expense.PayerWrapper{}, expense.ErrValidation("unknown payer type: " + p.GetType())
+}
+
+func splitToPb(sw expense.SplitWrapper) *pb.Split {
+	switch s := sw.Split.(type) {
+	case *expense.EqualSplit:
+		return &pb.Split{Type: "equal", TotalAmount: s.TotalAmount, Equal: s.Payee}
+	case *expense.UnitSplit:
+		return &pb.Split{Type: "unit", TotalAmount: s.ComputeTotal(), Unit: s.PayeeAmountSplit}
+	case *expense.PercentageSplit:
+		return &pb.Split{Type: "percentage", TotalAmount: s.TotalAmount, Percentage: s.PercentageSplitMap}
+	case *expense.ShareSplit:
+		shares := make(map[string]int32, len(s.SplitMap))
+		for userId, share := range s.SplitMap {
+			shares[userId] = int32(share)
+		}
+		return &pb.Split{Type: "share", TotalAmount: s.TotalAmount, Shares: shares}
+	}
+	return nil
+}
+
+// splitFromPb follows SplitWrapper.UnmarshalJSON
+func splitFromPb(s *pb.Split) (expense.SplitWrapper, error) {
+	switch s.GetType() {
+	case "equal":
+		return expense.SplitWrapper{Type: "equal", Split: &expense.EqualSplit{Payee: s.Equal, TotalAmount: s.TotalAmount}}, nil
+	case "unit":
+		return expense.SplitWrapper{Type: "unit", Split: &expense.UnitSplit{PayeeAmountSplit: s.Unit}}, nil
+	case "percentage":
+		return expense.SplitWrapper{Type: "percentage", Split: &expense.PercentageSplit{PercentageSplitMap: s.Percentage, TotalAmount: s.TotalAmount}}, nil
+	case "share":
+		shares := make(map[string]int, len(s.Shares))
+		for userId, share := range s.Shares {
+			shares[userId] = int(share)
+		}
+		return expense.SplitWrapper{Type: "share", Split: &expense.ShareSplit{SplitMap: shares, TotalAmount: s.TotalAmount}}, nil
+	}
+	return expense.SplitWrapper{}, expense.ErrValidation("unknown split type: " + s.GetType())
+}
+
+func expenseToPb(e expense.Expense) *pb.Expense {
+	return &pb.Expense{
+		Id:          e.ID,
+		Description: e.Description,
+		Category:    e.Category,
+		Amount:      e.Amount,
+		CreatedAt:   timestamppb.New(e.CreatedAt),
+		Payer:       payerToPb(e.PayeeW),
+		Split:       splitToPb(e.SplitW),
+		Status:      string(e.Status),
+		GroupId:     e.GroupId,
+		SettledBy:   e.SettledBy,
+		CreatedBy:   e.CreatedBy,
+		Version:     int32(e.Version),
+	}
+}
+
+func detailedExpensesToPb(expenses []expense.DetailedExpense) []*pb.DetailedExpense {
+	out := make([]*pb.DetailedExpense, 0, len(expenses))
+	for _, e := range expenses {
+		out = append(out, &pb.DetailedExpense{Expense: expenseToPb(e.Expense), TotalOwed: e.TotalOwed, TotalBorrowed: e.TotalBorrowed})
+	}
+	return out
+}
+
+func expensePageToPb(h *expense.GroupExpenseHistory) *pb.ExpensePage {
+	return &pb.ExpensePage{
+		Expenses:   detailedExpensesToPb(h.Expenses),
+		NextCursor: h.NextCursor,
+		HasMore:    h.HasMore,
+	}
+}
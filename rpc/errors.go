@@ -0,0 +1,89 @@
+package rpcServer
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"splitExpense/expense"
+	"strconv"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// errorInterceptor turns the errors returned by the orchestrator into gRPC
+// statuses, internal errors are logged and answered without their cause
+func errorInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	resp, err := handler(ctx, req)
+	if err != nil {
+		st := toStatus(err)
+		if status.Code(st) == codes.Internal {
+			log.Printf("%s failed: %v", info.FullMethod, err)
+		}
+		return nil, st
+	}
+	return resp, nil
+}
+
+// toStatus maps an error to a gRPC status the way the HTTP handlers map it to
+// a status code. A version conflict carries the current version as ErrorInfo
+// metadata so clients can refetch and merge.
+func toStatus(err error) error {
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+
+	var conflict *expense.VersionConflictError
+	if errors.As(err, &conflict) {
+		st, detailErr := status.New(codes.Aborted, conflict.Error()).WithDetails(&errdetails.ErrorInfo{
+			Reason: "VERSION_CONFLICT",
+			Domain: "splitexpense",
+			Metadata: map[string]string{
+				"entity":         conflict.Entity,
+				"id":             conflict.ID,
+				"currentVersion": strconv.Itoa(conflict.CurrentVersion),
+			},
+		})
+		if detailErr != nil {
+			return status.Error(codes.Aborted, conflict.Error())
+		}
+		return st.Err()
+	}
+
+	var unsettled *expense.UnsettledGroupError
+	if errors.As(err, &unsettled) {
+		return status.Error(codes.FailedPrecondition, unsettled.Error())
+	}
+
+	// service errors keep the storage error, a missing row is not found
+	if errors.Is(err, sql.ErrNoRows) {
+		return status.Error(codes.NotFound, "not found")
+	}
+
+	var appErr *expense.AppError
+	if errors.As(err, &appErr) {
+		switch appErr.Type {
+		case "ValidationError", "InvalidQueryError":
+			return status.Error(codes.InvalidArgument, appErr.Error())
+		case "NotFoundError":
+			return status.Error(codes.NotFound, appErr.Error())
+		case "ForbiddenError":
+			return status.Error(codes.PermissionDenied, appErr.Error())
+		case "ConflictError":
+			return status.Error(codes.FailedPrecondition, appErr.Error())
+		default:
+			return status.Error(codes.Internal, "internal error")
+		}
+	}
+
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, err.Error())
+	}
+	return status.Error(codes.Internal, "internal error")
+}
@@ -0,0 +1,151 @@
+package rpcServer
+
+import (
+	"context"
+	"splitExpense/expense"
+	"splitExpense/orchestrator"
+	"splitExpense/rpc/pb"
+)
+
+type expenseServer struct {
+	pb.UnimplementedExpenseServiceServer
+	o orchestrator.ExpenseAppImpl
+}
+
+func (s *expenseServer) CreateExpense(ctx context.Context, req *pb.CreateExpenseRequest) (*pb.Expense, error) {
+	payer, err := payerFromPb(req.Payer)
+	if err != nil {
+		return nil, err
+	}
+	split, err := splitFromPb(req.Split)
+	if err != nil {
+		return nil, err
+	}
+	created, err := s.o.CreateExpense(ctx, currentUserId(ctx), expense.ExpenseCreate{
+		Description:    req.Description,
+		Category:       req.Category,
+		Amount:         req.Amount,
+		SplitW:         split,
+		PayeeW:         payer,
+		IsGroupExpense: len(req.GroupId) > 0,
+		GroupId:        req.GroupId,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return expenseToPb(*created), nil
+}
+
+func (s *expenseServer) UpdateExpense(ctx context.Context, req *pb.UpdateExpenseRequest) (*pb.Expense, error) {
+	if req.Version == 0 {
+		return nil, expense.ErrValidation("version is required to update an expense")
+	}
+	payer, err := payerFromPb(req.Payer)
+	if err != nil {
+		return nil, err
+	}
+	split, err := splitFromPb(req.Split)
+	if err != nil {
+		return nil, err
+	}
+	updated, err := s.o.UpdateExpense(ctx, currentUserId(ctx), expense.Expense{
+		ID:             req.Id,
+		Description:    req.Description,
+		Category:       req.Category,
+		Amount:         req.Amount,
+		SplitW:         split,
+		PayeeW:         payer,
+		GroupId:        req.GroupId,
+		IsGroupExpense: len(req.GroupId) > 0,
+		Version:        int(req.Version),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return expenseToPb(*updated), nil
+}
+
+func (s *expenseServer) DeleteExpense(ctx context.Context, req *pb.ExpenseIdRequest) (*pb.DeleteExpenseResponse, error) {
+	deleted, err := s.o.DeleteExpense(ctx, currentUserId(ctx), req.Id)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.DeleteExpenseResponse{Deleted: deleted}, nil
+}
+
+func (s *expenseServer) SettleExpense(ctx context.Context, req *pb.ExpenseIdRequest) (*pb.Expense, error) {
+	settled, err := s.o.SettleExpense(ctx, currentUserId(ctx), req.Id)
+	if err != nil {
+		return nil, err
+	}
+	return expenseToPb(*settled), nil
+}
+
+func (s *expenseServer) ListExpenses(ctx context.Context, req *pb.PageRequest) (*pb.ExpensePage, error) {
+	history, err := s.o.GetUserExpenseHistoryPage(ctx, currentUserId(ctx), pageFromPb(req))
+	if err != nil {
+		return nil, err
+	}
+	return &pb.ExpensePage{
+		Expenses:      detailedExpensesToPb(history.Expenses),
+		NextCursor:    history.NextCursor,
+		HasMore:       history.HasMore,
+		TotalOwed:     history.TotalOwed,
+		TotalBorrowed: history.TotalBorrowed,
+	}, nil
+}
+
+func (s *expenseServer) SearchExpenses(ctx context.Context, req *pb.SearchExpensesRequest) (*pb.ExpensePage, error) {
+	search := expense.ExpenseSearch{
+		Query:           req.Query,
+		GroupId:         req.GroupId,
+		ParticipantId:   req.ParticipantId,
+		PayerId:         req.PayerId,
+		Status:          expense.ExpenseStatus(req.Status),
+		Category:        req.Category,
+		MinAmount:       req.MinAmount,
+		MaxAmount:       req.MaxAmount,
+		IncludeArchived: req.IncludeArchived,
+	}
+	if req.From != nil {
+		from := req.From.AsTime()
+		search.CreatedFrom = &from
+	}
+	if req.To != nil {
+		to := req.To.AsTime()
+		search.CreatedBefore = &to
+	}
+	history, err := s.o.SearchExpenses(ctx, currentUserId(ctx), search, pageFromPb(req.Page))
+	if err != nil {
+		return nil, err
+	}
+	return expensePageToPb(history), nil
+}
+
+type balanceServer struct {
+	pb.UnimplementedBalanceServiceServer
+	o orchestrator.ExpenseAppImpl
+}
+
+func (s *balanceServer) GetBalances(ctx context.Context, req *pb.GetBalancesRequest) (*pb.Balances, error) {
+	balances, err := s.o.GetBalances(ctx, currentUserId(ctx))
+	if err != nil {
+		return nil, err
+	}
+	out := &pb.Balances{
+		TotalOwed:     balances.TotalOwed,
+		TotalBorrowed: balances.TotalBorrowed,
+		Net:           balances.TotalOwed - balances.TotalBorrowed,
+		Groups:        make([]*pb.GroupBalance, 0, len(balances.Groups)),
+	}
+	for _, g := range balances.Groups {
+		out.Groups = append(out.Groups, &pb.GroupBalance{
+			GroupId:       g.Group.Id,
+			Name:          g.Group.Name,
+			TotalOwed:     g.TotalOwed,
+			TotalBorrowed: g.TotalBorrowed,
+			Net:           g.TotalOwed - g.TotalBorrowed,
+		})
+	}
+	return out, nil
+}
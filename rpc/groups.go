@@ -0,0 +1,99 @@
+package rpcServer
+
+import (
+	"context"
+	"splitExpense/expense"
+	"splitExpense/orchestrator"
+	"splitExpense/rpc/pb"
+)
+
+type groupServer struct {
+	pb.UnimplementedGroupServiceServer
+	o orchestrator.ExpenseAppImpl
+}
+
+func (s *groupServer) CreateGroup(ctx context.Context, req *pb.CreateGroupRequest) (*pb.Group, error) {
+	group, err := s.o.CreateGroup(ctx, currentUserId(ctx), req.Name, req.Description)
+	if err != nil {
+		return nil, err
+	}
+	return groupToPb(*group), nil
+}
+
+func (s *groupServer) UpdateGroup(ctx context.Context, req *pb.UpdateGroupRequest) (*pb.Group, error) {
+	group, err := s.o.UpdateGroup(ctx, currentUserId(ctx), expense.Group{
+		Id:          req.Id,
+		Name:        req.Name,
+		Description: req.Description,
+		Version:     int(req.Version),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return groupToPb(*group), nil
+}
+
+func (s *groupServer) GetGroup(ctx context.Context, req *pb.GetGroupRequest) (*pb.GroupDetail, error) {
+	detail, err := s.o.GetGroupDetail(ctx, currentUserId(ctx), req.Id)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.GroupDetail{
+		Group:         groupToPb(detail.Group.Group),
+		Members:       usersToPb(detail.GroupMembers),
+		TotalOwed:     detail.Group.TotalOwed,
+		TotalBorrowed: detail.Group.TotalBorrowed,
+		Expenses:      expensePageToPb(&detail.Group.ExpenseHistory),
+	}, nil
+}
+
+func (s *groupServer) ListGroups(ctx context.Context, req *pb.PageRequest) (*pb.GroupPage, error) {
+	page, err := s.o.GetGroups(ctx, currentUserId(ctx), pageFromPb(req))
+	if err != nil {
+		return nil, err
+	}
+	groups := make([]*pb.Group, 0, len(page.Groups))
+	for _, g := range page.Groups {
+		groups = append(groups, groupToPb(g))
+	}
+	return &pb.GroupPage{Groups: groups, NextCursor: page.NextCursor, HasMore: page.HasMore}, nil
+}
+
+func (s *groupServer) AddMember(ctx context.Context, req *pb.AddMemberRequest) (*pb.MembershipResponse, error) {
+	ok, err := s.o.JoinGroup(ctx, currentUserId(ctx), req.UserId, req.GroupId)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.MembershipResponse{Ok: ok}, nil
+}
+
+func (s *groupServer) LeaveGroup(ctx context.Context, req *pb.LeaveGroupRequest) (*pb.MembershipResponse, error) {
+	ok, appErr := s.o.LeaveGroup(ctx, currentUserId(ctx), req.GroupId)
+	if appErr != nil {
+		return nil, appErr
+	}
+	return &pb.MembershipResponse{Ok: ok}, nil
+}
+
+func (s *groupServer) DeleteGroup(ctx context.Context, req *pb.DeleteGroupRequest) (*pb.GroupDeletion, error) {
+	report, err := s.o.DeleteGroup(ctx, currentUserId(ctx), req.Id, expense.GroupDeletePolicy(req.Policy))
+	if err != nil {
+		return nil, err
+	}
+	return &pb.GroupDeletion{
+		GroupId:           report.GroupId,
+		Policy:            string(report.Policy),
+		Deleted:           report.Deleted,
+		Members:           int32(report.Members),
+		ArchivedExpenses:  int32(report.ArchivedExpenses),
+		UnsettledExpenses: int32(report.UnsettledExpenses),
+	}, nil
+}
+
+func (s *groupServer) ListGroupExpenses(ctx context.Context, req *pb.ListGroupExpensesRequest) (*pb.ExpensePage, error) {
+	history, err := s.o.GetGroupExpenses(ctx, currentUserId(ctx), req.GroupId, req.IncludeArchived, pageFromPb(req.Page))
+	if err != nil {
+		return nil, err
+	}
+	return expensePageToPb(history), nil
+}
@@ -0,0 +1,2579 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        (unknown)
+// source: splitexpense.proto
+
+package pb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type User struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Email         string                 `protobuf:"bytes,3,opt,name=email,proto3" json:"email,omitempty"`
+	IsVerified    bool                   `protobuf:"varint,4,opt,name=is_verified,json=isVerified,proto3" json:"is_verified,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *User) Reset() {
+	*x = User{}
+	mi := &file_splitexpense_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *User) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*User) ProtoMessage() {}
+
+func (x *User) ProtoReflect() protoreflect.Message {
+	mi := &file_splitexpense_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use User.ProtoReflect.Descriptor instead.
+func (*User) Descriptor() ([]byte, []int) {
+	return file_splitexpense_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *User) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *User) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *User) GetEmail() string {
+	if x != nil {
+		return x.Email
+	}
+	return ""
+}
+
+func (x *User) GetIsVerified() bool {
+	if x != nil {
+		return x.IsVerified
+	}
+	return false
+}
+
+type SignUpRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Email         string                 `protobuf:"bytes,2,opt,name=email,proto3" json:"email,omitempty"`
+	Password      string                 `protobuf:"bytes,3,opt,name=password,proto3" json:"password,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SignUpRequest) Reset() {
+	*x = SignUpRequest{}
+	mi := &file_splitexpense_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SignUpRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SignUpRequest) ProtoMessage() {}
+
+func (x *SignUpRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_splitexpense_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SignUpRequest.ProtoReflect.Descriptor instead.
+func (*SignUpRequest) Descriptor() ([]byte, []int) {
+	return file_splitexpense_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *SignUpRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *SignUpRequest) GetEmail() string {
+	if x != nil {
+		return x.Email
+	}
+	return ""
+}
+
+func (x *SignUpRequest) GetPassword() string {
+	if x != nil {
+		return x.Password
+	}
+	return ""
+}
+
+type LoginRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Email         string                 `protobuf:"bytes,1,opt,name=email,proto3" json:"email,omitempty"`
+	Password      string                 `protobuf:"bytes,2,opt,name=password,proto3" json:"password,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LoginRequest) Reset() {
+	*x = LoginRequest{}
+	mi := &file_splitexpense_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LoginRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LoginRequest) ProtoMessage() {}
+
+func (x *LoginRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_splitexpense_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LoginRequest.ProtoReflect.Descriptor instead.
+func (*LoginRequest) Descriptor() ([]byte, []int) {
+	return file_splitexpense_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *LoginRequest) GetEmail() string {
+	if x != nil {
+		return x.Email
+	}
+	return ""
+}
+
+func (x *LoginRequest) GetPassword() string {
+	if x != nil {
+		return x.Password
+	}
+	return ""
+}
+
+type LoginResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Token         string                 `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
+	User          *User                  `protobuf:"bytes,2,opt,name=user,proto3" json:"user,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LoginResponse) Reset() {
+	*x = LoginResponse{}
+	mi := &file_splitexpense_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LoginResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LoginResponse) ProtoMessage() {}
+
+func (x *LoginResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_splitexpense_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LoginResponse.ProtoReflect.Descriptor instead.
+func (*LoginResponse) Descriptor() ([]byte, []int) {
+	return file_splitexpense_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *LoginResponse) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+func (x *LoginResponse) GetUser() *User {
+	if x != nil {
+		return x.User
+	}
+	return nil
+}
+
+type GetMeRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetMeRequest) Reset() {
+	*x = GetMeRequest{}
+	mi := &file_splitexpense_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetMeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetMeRequest) ProtoMessage() {}
+
+func (x *GetMeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_splitexpense_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetMeRequest.ProtoReflect.Descriptor instead.
+func (*GetMeRequest) Descriptor() ([]byte, []int) {
+	return file_splitexpense_proto_rawDescGZIP(), []int{4}
+}
+
+type PageRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// cursor is the next_cursor of the previous page, empty for the first page
+	Cursor        string `protobuf:"bytes,1,opt,name=cursor,proto3" json:"cursor,omitempty"`
+	Limit         int32  `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PageRequest) Reset() {
+	*x = PageRequest{}
+	mi := &file_splitexpense_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PageRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PageRequest) ProtoMessage() {}
+
+func (x *PageRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_splitexpense_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PageRequest.ProtoReflect.Descriptor instead.
+func (*PageRequest) Descriptor() ([]byte, []int) {
+	return file_splitexpense_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *PageRequest) GetCursor() string {
+	if x != nil {
+		return x.Cursor
+	}
+	return ""
+}
+
+func (x *PageRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type AddFriendRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Email         string                 `protobuf:"bytes,1,opt,name=email,proto3" json:"email,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AddFriendRequest) Reset() {
+	*x = AddFriendRequest{}
+	mi := &file_splitexpense_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AddFriendRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AddFriendRequest) ProtoMessage() {}
+
+func (x *AddFriendRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_splitexpense_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AddFriendRequest.ProtoReflect.Descriptor instead.
+func (*AddFriendRequest) Descriptor() ([]byte, []int) {
+	return file_splitexpense_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *AddFriendRequest) GetEmail() string {
+	if x != nil {
+		return x.Email
+	}
+	return ""
+}
+
+type AddFriendResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Added         bool                   `protobuf:"varint,1,opt,name=added,proto3" json:"added,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AddFriendResponse) Reset() {
+	*x = AddFriendResponse{}
+	mi := &file_splitexpense_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AddFriendResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AddFriendResponse) ProtoMessage() {}
+
+func (x *AddFriendResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_splitexpense_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AddFriendResponse.ProtoReflect.Descriptor instead.
+func (*AddFriendResponse) Descriptor() ([]byte, []int) {
+	return file_splitexpense_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *AddFriendResponse) GetAdded() bool {
+	if x != nil {
+		return x.Added
+	}
+	return false
+}
+
+type UserPage struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Users         []*User                `protobuf:"bytes,1,rep,name=users,proto3" json:"users,omitempty"`
+	NextCursor    string                 `protobuf:"bytes,2,opt,name=next_cursor,json=nextCursor,proto3" json:"next_cursor,omitempty"`
+	HasMore       bool                   `protobuf:"varint,3,opt,name=has_more,json=hasMore,proto3" json:"has_more,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UserPage) Reset() {
+	*x = UserPage{}
+	mi := &file_splitexpense_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UserPage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UserPage) ProtoMessage() {}
+
+func (x *UserPage) ProtoReflect() protoreflect.Message {
+	mi := &file_splitexpense_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UserPage.ProtoReflect.Descriptor instead.
+func (*UserPage) Descriptor() ([]byte, []int) {
+	return file_splitexpense_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *UserPage) GetUsers() []*User {
+	if x != nil {
+		return x.Users
+	}
+	return nil
+}
+
+func (x *UserPage) GetNextCursor() string {
+	if x != nil {
+		return x.NextCursor
+	}
+	return ""
+}
+
+func (x *UserPage) GetHasMore() bool {
+	if x != nil {
+		return x.HasMore
+	}
+	return false
+}
+
+type Group struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Description   string                 `protobuf:"bytes,3,opt,name=description,proto3" json:"description,omitempty"`
+	AdminId       string                 `protobuf:"bytes,4,opt,name=admin_id,json=adminId,proto3" json:"admin_id,omitempty"`
+	Version       int32                  `protobuf:"varint,5,opt,name=version,proto3" json:"version,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Group) Reset() {
+	*x = Group{}
+	mi := &file_splitexpense_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Group) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Group) ProtoMessage() {}
+
+func (x *Group) ProtoReflect() protoreflect.Message {
+	mi := &file_splitexpense_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Group.ProtoReflect.Descriptor instead.
+func (*Group) Descriptor() ([]byte, []int) {
+	return file_splitexpense_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *Group) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Group) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Group) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *Group) GetAdminId() string {
+	if x != nil {
+		return x.AdminId
+	}
+	return ""
+}
+
+func (x *Group) GetVersion() int32 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *Group) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+type GroupPage struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Groups        []*Group               `protobuf:"bytes,1,rep,name=groups,proto3" json:"groups,omitempty"`
+	NextCursor    string                 `protobuf:"bytes,2,opt,name=next_cursor,json=nextCursor,proto3" json:"next_cursor,omitempty"`
+	HasMore       bool                   `protobuf:"varint,3,opt,name=has_more,json=hasMore,proto3" json:"has_more,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GroupPage) Reset() {
+	*x = GroupPage{}
+	mi := &file_splitexpense_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GroupPage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GroupPage) ProtoMessage() {}
+
+func (x *GroupPage) ProtoReflect() protoreflect.Message {
+	mi := &file_splitexpense_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GroupPage.ProtoReflect.Descriptor instead.
+func (*GroupPage) Descriptor() ([]byte, []int) {
+	return file_splitexpense_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *GroupPage) GetGroups() []*Group {
+	if x != nil {
+		return x.Groups
+	}
+	return nil
+}
+
+func (x *GroupPage) GetNextCursor() string {
+	if x != nil {
+		return x.NextCursor
+	}
+	return ""
+}
+
+func (x *GroupPage) GetHasMore() bool {
+	if x != nil {
+		return x.HasMore
+	}
+	return false
+}
+
+type CreateGroupRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Description   string                 `protobuf:"bytes,2,opt,name=description,proto3" json:"description,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateGroupRequest) Reset() {
+	*x = CreateGroupRequest{}
+	mi := &file_splitexpense_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateGroupRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateGroupRequest) ProtoMessage() {}
+
+func (x *CreateGroupRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_splitexpense_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateGroupRequest.ProtoReflect.Descriptor instead.
+func (*CreateGroupRequest) Descriptor() ([]byte, []int) {
+	return file_splitexpense_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *CreateGroupRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *CreateGroupRequest) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+type UpdateGroupRequest struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	Id          string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name        string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Description string                 `protobuf:"bytes,3,opt,name=description,proto3" json:"description,omitempty"`
+	// version is the version last read, a stale one fails with ABORTED
+	Version       int32 `protobuf:"varint,4,opt,name=version,proto3" json:"version,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateGroupRequest) Reset() {
+	*x = UpdateGroupRequest{}
+	mi := &file_splitexpense_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateGroupRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateGroupRequest) ProtoMessage() {}
+
+func (x *UpdateGroupRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_splitexpense_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateGroupRequest.ProtoReflect.Descriptor instead.
+func (*UpdateGroupRequest) Descriptor() ([]byte, []int) {
+	return file_splitexpense_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *UpdateGroupRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *UpdateGroupRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *UpdateGroupRequest) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *UpdateGroupRequest) GetVersion() int32 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+type GetGroupRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetGroupRequest) Reset() {
+	*x = GetGroupRequest{}
+	mi := &file_splitexpense_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetGroupRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetGroupRequest) ProtoMessage() {}
+
+func (x *GetGroupRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_splitexpense_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetGroupRequest.ProtoReflect.Descriptor instead.
+func (*GetGroupRequest) Descriptor() ([]byte, []int) {
+	return file_splitexpense_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *GetGroupRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type GroupDetail struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Group         *Group                 `protobuf:"bytes,1,opt,name=group,proto3" json:"group,omitempty"`
+	Members       []*User                `protobuf:"bytes,2,rep,name=members,proto3" json:"members,omitempty"`
+	TotalOwed     float64                `protobuf:"fixed64,3,opt,name=total_owed,json=totalOwed,proto3" json:"total_owed,omitempty"`
+	TotalBorrowed float64                `protobuf:"fixed64,4,opt,name=total_borrowed,json=totalBorrowed,proto3" json:"total_borrowed,omitempty"`
+	Expenses      *ExpensePage           `protobuf:"bytes,5,opt,name=expenses,proto3" json:"expenses,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GroupDetail) Reset() {
+	*x = GroupDetail{}
+	mi := &file_splitexpense_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GroupDetail) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GroupDetail) ProtoMessage() {}
+
+func (x *GroupDetail) ProtoReflect() protoreflect.Message {
+	mi := &file_splitexpense_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GroupDetail.ProtoReflect.Descriptor instead.
+func (*GroupDetail) Descriptor() ([]byte, []int) {
+	return file_splitexpense_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *GroupDetail) GetGroup() *Group {
+	if x != nil {
+		return x.Group
+	}
+	return nil
+}
+
+func (x *GroupDetail) GetMembers() []*User {
+	if x != nil {
+		return x.Members
+	}
+	return nil
+}
+
+func (x *GroupDetail) GetTotalOwed() float64 {
+	if x != nil {
+		return x.TotalOwed
+	}
+	return 0
+}
+
+func (x *GroupDetail) GetTotalBorrowed() float64 {
+	if x != nil {
+		return x.TotalBorrowed
+	}
+	return 0
+}
+
+func (x *GroupDetail) GetExpenses() *ExpensePage {
+	if x != nil {
+		return x.Expenses
+	}
+	return nil
+}
+
+type AddMemberRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	GroupId       string                 `protobuf:"bytes,1,opt,name=group_id,json=groupId,proto3" json:"group_id,omitempty"`
+	UserId        string                 `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AddMemberRequest) Reset() {
+	*x = AddMemberRequest{}
+	mi := &file_splitexpense_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AddMemberRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AddMemberRequest) ProtoMessage() {}
+
+func (x *AddMemberRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_splitexpense_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AddMemberRequest.ProtoReflect.Descriptor instead.
+func (*AddMemberRequest) Descriptor() ([]byte, []int) {
+	return file_splitexpense_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *AddMemberRequest) GetGroupId() string {
+	if x != nil {
+		return x.GroupId
+	}
+	return ""
+}
+
+func (x *AddMemberRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+type LeaveGroupRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	GroupId       string                 `protobuf:"bytes,1,opt,name=group_id,json=groupId,proto3" json:"group_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LeaveGroupRequest) Reset() {
+	*x = LeaveGroupRequest{}
+	mi := &file_splitexpense_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LeaveGroupRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LeaveGroupRequest) ProtoMessage() {}
+
+func (x *LeaveGroupRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_splitexpense_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LeaveGroupRequest.ProtoReflect.Descriptor instead.
+func (*LeaveGroupRequest) Descriptor() ([]byte, []int) {
+	return file_splitexpense_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *LeaveGroupRequest) GetGroupId() string {
+	if x != nil {
+		return x.GroupId
+	}
+	return ""
+}
+
+type MembershipResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Ok            bool                   `protobuf:"varint,1,opt,name=ok,proto3" json:"ok,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MembershipResponse) Reset() {
+	*x = MembershipResponse{}
+	mi := &file_splitexpense_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MembershipResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MembershipResponse) ProtoMessage() {}
+
+func (x *MembershipResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_splitexpense_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MembershipResponse.ProtoReflect.Descriptor instead.
+func (*MembershipResponse) Descriptor() ([]byte, []int) {
+	return file_splitexpense_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *MembershipResponse) GetOk() bool {
+	if x != nil {
+		return x.Ok
+	}
+	return false
+}
+
+type DeleteGroupRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Id    string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	// policy is "refuse" or "archive", empty uses the server default
+	Policy        string `protobuf:"bytes,2,opt,name=policy,proto3" json:"policy,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteGroupRequest) Reset() {
+	*x = DeleteGroupRequest{}
+	mi := &file_splitexpense_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteGroupRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteGroupRequest) ProtoMessage() {}
+
+func (x *DeleteGroupRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_splitexpense_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteGroupRequest.ProtoReflect.Descriptor instead.
+func (*DeleteGroupRequest) Descriptor() ([]byte, []int) {
+	return file_splitexpense_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *DeleteGroupRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *DeleteGroupRequest) GetPolicy() string {
+	if x != nil {
+		return x.Policy
+	}
+	return ""
+}
+
+type GroupDeletion struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	GroupId           string                 `protobuf:"bytes,1,opt,name=group_id,json=groupId,proto3" json:"group_id,omitempty"`
+	Policy            string                 `protobuf:"bytes,2,opt,name=policy,proto3" json:"policy,omitempty"`
+	Deleted           bool                   `protobuf:"varint,3,opt,name=deleted,proto3" json:"deleted,omitempty"`
+	Members           int32                  `protobuf:"varint,4,opt,name=members,proto3" json:"members,omitempty"`
+	ArchivedExpenses  int32                  `protobuf:"varint,5,opt,name=archived_expenses,json=archivedExpenses,proto3" json:"archived_expenses,omitempty"`
+	UnsettledExpenses int32                  `protobuf:"varint,6,opt,name=unsettled_expenses,json=unsettledExpenses,proto3" json:"unsettled_expenses,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *GroupDeletion) Reset() {
+	*x = GroupDeletion{}
+	mi := &file_splitexpense_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GroupDeletion) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GroupDeletion) ProtoMessage() {}
+
+func (x *GroupDeletion) ProtoReflect() protoreflect.Message {
+	mi := &file_splitexpense_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GroupDeletion.ProtoReflect.Descriptor instead.
+func (*GroupDeletion) Descriptor() ([]byte, []int) {
+	return file_splitexpense_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *GroupDeletion) GetGroupId() string {
+	if x != nil {
+		return x.GroupId
+	}
+	return ""
+}
+
+func (x *GroupDeletion) GetPolicy() string {
+	if x != nil {
+		return x.Policy
+	}
+	return ""
+}
+
+func (x *GroupDeletion) GetDeleted() bool {
+	if x != nil {
+		return x.Deleted
+	}
+	return false
+}
+
+func (x *GroupDeletion) GetMembers() int32 {
+	if x != nil {
+		return x.Members
+	}
+	return 0
+}
+
+func (x *GroupDeletion) GetArchivedExpenses() int32 {
+	if x != nil {
+		return x.ArchivedExpenses
+	}
+	return 0
+}
+
+func (x *GroupDeletion) GetUnsettledExpenses() int32 {
+	if x != nil {
+		return x.UnsettledExpenses
+	}
+	return 0
+}
+
+type ListGroupExpensesRequest struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	GroupId         string                 `protobuf:"bytes,1,opt,name=group_id,json=groupId,proto3" json:"group_id,omitempty"`
+	IncludeArchived bool                   `protobuf:"varint,2,opt,name=include_archived,json=includeArchived,proto3" json:"include_archived,omitempty"`
+	Page            *PageRequest           `protobuf:"bytes,3,opt,name=page,proto3" json:"page,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *ListGroupExpensesRequest) Reset() {
+	*x = ListGroupExpensesRequest{}
+	mi := &file_splitexpense_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListGroupExpensesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListGroupExpensesRequest) ProtoMessage() {}
+
+func (x *ListGroupExpensesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_splitexpense_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListGroupExpensesRequest.ProtoReflect.Descriptor instead.
+func (*ListGroupExpensesRequest) Descriptor() ([]byte, []int) {
+	return file_splitexpense_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *ListGroupExpensesRequest) GetGroupId() string {
+	if x != nil {
+		return x.GroupId
+	}
+	return ""
+}
+
+func (x *ListGroupExpensesRequest) GetIncludeArchived() bool {
+	if x != nil {
+		return x.IncludeArchived
+	}
+	return false
+}
+
+func (x *ListGroupExpensesRequest) GetPage() *PageRequest {
+	if x != nil {
+		return x.Page
+	}
+	return nil
+}
+
+// Payer is who paid, "single" has exactly one entry in amounts
+type Payer struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Type          string                 `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"`
+	Amounts       map[string]float64     `protobuf:"bytes,2,rep,name=amounts,proto3" json:"amounts,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"fixed64,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Payer) Reset() {
+	*x = Payer{}
+	mi := &file_splitexpense_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Payer) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Payer) ProtoMessage() {}
+
+func (x *Payer) ProtoReflect() protoreflect.Message {
+	mi := &file_splitexpense_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Payer.ProtoReflect.Descriptor instead.
+func (*Payer) Descriptor() ([]byte, []int) {
+	return file_splitexpense_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *Payer) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *Payer) GetAmounts() map[string]float64 {
+	if x != nil {
+		return x.Amounts
+	}
+	return nil
+}
+
+// Split is how the amount is shared, only the field matching type is read:
+// "equal" uses equal, "unit" unit, "percentage" percentage and "share" shares
+type Split struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Type          string                 `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"`
+	TotalAmount   float64                `protobuf:"fixed64,2,opt,name=total_amount,json=totalAmount,proto3" json:"total_amount,omitempty"`
+	Equal         []string               `protobuf:"bytes,3,rep,name=equal,proto3" json:"equal,omitempty"`
+	Unit          map[string]float64     `protobuf:"bytes,4,rep,name=unit,proto3" json:"unit,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"fixed64,2,opt,name=value"`
+	Percentage    map[string]float64     `protobuf:"bytes,5,rep,name=percentage,proto3" json:"percentage,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"fixed64,2,opt,name=value"`
+	Shares        map[string]int32       `protobuf:"bytes,6,rep,name=shares,proto3" json:"shares,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"varint,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Split) Reset() {
+	*x = Split{}
+	mi := &file_splitexpense_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Split) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Split) ProtoMessage() {}
+
+func (x *Split) ProtoReflect() protoreflect.Message {
+	mi := &file_splitexpense_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Split.ProtoReflect.Descriptor instead.
+func (*Split) Descriptor() ([]byte, []int) {
+	return file_splitexpense_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *Split) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *Split) GetTotalAmount() float64 {
+	if x != nil {
+		return x.TotalAmount
+	}
+	return 0
+}
+
+func (x *Split) GetEqual() []string {
+	if x != nil {
+		return x.Equal
+	}
+	return nil
+}
+
+func (x *Split) GetUnit() map[string]float64 {
+	if x != nil {
+		return x.Unit
+	}
+	return nil
+}
+
+func (x *Split) GetPercentage() map[string]float64 {
+	if x != nil {
+		return x.Percentage
+	}
+	return nil
+}
+
+func (x *Split) GetShares() map[string]int32 {
+	if x != nil {
+		return x.Shares
+	}
+	return nil
+}
+
+type Expense struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Description   string                 `protobuf:"bytes,2,opt,name=description,proto3" json:"description,omitempty"`
+	Category      string                 `protobuf:"bytes,3,opt,name=category,proto3" json:"category,omitempty"`
+	Amount        float64                `protobuf:"fixed64,4,opt,name=amount,proto3" json:"amount,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	Payer         *Payer                 `protobuf:"bytes,6,opt,name=payer,proto3" json:"payer,omitempty"`
+	Split         *Split                 `protobuf:"bytes,7,opt,name=split,proto3" json:"split,omitempty"`
+	Status        string                 `protobuf:"bytes,8,opt,name=status,proto3" json:"status,omitempty"`
+	GroupId       string                 `protobuf:"bytes,9,opt,name=group_id,json=groupId,proto3" json:"group_id,omitempty"`
+	SettledBy     string                 `protobuf:"bytes,10,opt,name=settled_by,json=settledBy,proto3" json:"settled_by,omitempty"`
+	CreatedBy     string                 `protobuf:"bytes,11,opt,name=created_by,json=createdBy,proto3" json:"created_by,omitempty"`
+	Version       int32                  `protobuf:"varint,12,opt,name=version,proto3" json:"version,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Expense) Reset() {
+	*x = Expense{}
+	mi := &file_splitexpense_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Expense) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Expense) ProtoMessage() {}
+
+func (x *Expense) ProtoReflect() protoreflect.Message {
+	mi := &file_splitexpense_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Expense.ProtoReflect.Descriptor instead.
+func (*Expense) Descriptor() ([]byte, []int) {
+	return file_splitexpense_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *Expense) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Expense) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *Expense) GetCategory() string {
+	if x != nil {
+		return x.Category
+	}
+	return ""
+}
+
+func (x *Expense) GetAmount() float64 {
+	if x != nil {
+		return x.Amount
+	}
+	return 0
+}
+
+func (x *Expense) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Expense) GetPayer() *Payer {
+	if x != nil {
+		return x.Payer
+	}
+	return nil
+}
+
+func (x *Expense) GetSplit() *Split {
+	if x != nil {
+		return x.Split
+	}
+	return nil
+}
+
+func (x *Expense) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *Expense) GetGroupId() string {
+	if x != nil {
+		return x.GroupId
+	}
+	return ""
+}
+
+func (x *Expense) GetSettledBy() string {
+	if x != nil {
+		return x.SettledBy
+	}
+	return ""
+}
+
+func (x *Expense) GetCreatedBy() string {
+	if x != nil {
+		return x.CreatedBy
+	}
+	return ""
+}
+
+func (x *Expense) GetVersion() int32 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+type DetailedExpense struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Expense       *Expense               `protobuf:"bytes,1,opt,name=expense,proto3" json:"expense,omitempty"`
+	TotalOwed     float64                `protobuf:"fixed64,2,opt,name=total_owed,json=totalOwed,proto3" json:"total_owed,omitempty"`
+	TotalBorrowed float64                `protobuf:"fixed64,3,opt,name=total_borrowed,json=totalBorrowed,proto3" json:"total_borrowed,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DetailedExpense) Reset() {
+	*x = DetailedExpense{}
+	mi := &file_splitexpense_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DetailedExpense) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DetailedExpense) ProtoMessage() {}
+
+func (x *DetailedExpense) ProtoReflect() protoreflect.Message {
+	mi := &file_splitexpense_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DetailedExpense.ProtoReflect.Descriptor instead.
+func (*DetailedExpense) Descriptor() ([]byte, []int) {
+	return file_splitexpense_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *DetailedExpense) GetExpense() *Expense {
+	if x != nil {
+		return x.Expense
+	}
+	return nil
+}
+
+func (x *DetailedExpense) GetTotalOwed() float64 {
+	if x != nil {
+		return x.TotalOwed
+	}
+	return 0
+}
+
+func (x *DetailedExpense) GetTotalBorrowed() float64 {
+	if x != nil {
+		return x.TotalBorrowed
+	}
+	return 0
+}
+
+type ExpensePage struct {
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	Expenses   []*DetailedExpense     `protobuf:"bytes,1,rep,name=expenses,proto3" json:"expenses,omitempty"`
+	NextCursor string                 `protobuf:"bytes,2,opt,name=next_cursor,json=nextCursor,proto3" json:"next_cursor,omitempty"`
+	HasMore    bool                   `protobuf:"varint,3,opt,name=has_more,json=hasMore,proto3" json:"has_more,omitempty"`
+	// the totals are only set by ListExpenses
+	TotalOwed     float64 `protobuf:"fixed64,4,opt,name=total_owed,json=totalOwed,proto3" json:"total_owed,omitempty"`
+	TotalBorrowed float64 `protobuf:"fixed64,5,opt,name=total_borrowed,json=totalBorrowed,proto3" json:"total_borrowed,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExpensePage) Reset() {
+	*x = ExpensePage{}
+	mi := &file_splitexpense_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExpensePage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExpensePage) ProtoMessage() {}
+
+func (x *ExpensePage) ProtoReflect() protoreflect.Message {
+	mi := &file_splitexpense_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExpensePage.ProtoReflect.Descriptor instead.
+func (*ExpensePage) Descriptor() ([]byte, []int) {
+	return file_splitexpense_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *ExpensePage) GetExpenses() []*DetailedExpense {
+	if x != nil {
+		return x.Expenses
+	}
+	return nil
+}
+
+func (x *ExpensePage) GetNextCursor() string {
+	if x != nil {
+		return x.NextCursor
+	}
+	return ""
+}
+
+func (x *ExpensePage) GetHasMore() bool {
+	if x != nil {
+		return x.HasMore
+	}
+	return false
+}
+
+func (x *ExpensePage) GetTotalOwed() float64 {
+	if x != nil {
+		return x.TotalOwed
+	}
+	return 0
+}
+
+func (x *ExpensePage) GetTotalBorrowed() float64 {
+	if x != nil {
+		return x.TotalBorrowed
+	}
+	return 0
+}
+
+type CreateExpenseRequest struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	Description string                 `protobuf:"bytes,1,opt,name=description,proto3" json:"description,omitempty"`
+	Category    string                 `protobuf:"bytes,2,opt,name=category,proto3" json:"category,omitempty"`
+	Amount      float64                `protobuf:"fixed64,3,opt,name=amount,proto3" json:"amount,omitempty"`
+	Payer       *Payer                 `protobuf:"bytes,4,opt,name=payer,proto3" json:"payer,omitempty"`
+	Split       *Split                 `protobuf:"bytes,5,opt,name=split,proto3" json:"split,omitempty"`
+	// group_id is empty for an expense between friends
+	GroupId       string `protobuf:"bytes,6,opt,name=group_id,json=groupId,proto3" json:"group_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateExpenseRequest) Reset() {
+	*x = CreateExpenseRequest{}
+	mi := &file_splitexpense_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateExpenseRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateExpenseRequest) ProtoMessage() {}
+
+func (x *CreateExpenseRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_splitexpense_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateExpenseRequest.ProtoReflect.Descriptor instead.
+func (*CreateExpenseRequest) Descriptor() ([]byte, []int) {
+	return file_splitexpense_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *CreateExpenseRequest) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *CreateExpenseRequest) GetCategory() string {
+	if x != nil {
+		return x.Category
+	}
+	return ""
+}
+
+func (x *CreateExpenseRequest) GetAmount() float64 {
+	if x != nil {
+		return x.Amount
+	}
+	return 0
+}
+
+func (x *CreateExpenseRequest) GetPayer() *Payer {
+	if x != nil {
+		return x.Payer
+	}
+	return nil
+}
+
+func (x *CreateExpenseRequest) GetSplit() *Split {
+	if x != nil {
+		return x.Split
+	}
+	return nil
+}
+
+func (x *CreateExpenseRequest) GetGroupId() string {
+	if x != nil {
+		return x.GroupId
+	}
+	return ""
+}
+
+type UpdateExpenseRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Description   string                 `protobuf:"bytes,2,opt,name=description,proto3" json:"description,omitempty"`
+	Category      string                 `protobuf:"bytes,3,opt,name=category,proto3" json:"category,omitempty"`
+	Amount        float64                `protobuf:"fixed64,4,opt,name=amount,proto3" json:"amount,omitempty"`
+	Payer         *Payer                 `protobuf:"bytes,5,opt,name=payer,proto3" json:"payer,omitempty"`
+	Split         *Split                 `protobuf:"bytes,6,opt,name=split,proto3" json:"split,omitempty"`
+	GroupId       string                 `protobuf:"bytes,7,opt,name=group_id,json=groupId,proto3" json:"group_id,omitempty"`
+	Version       int32                  `protobuf:"varint,8,opt,name=version,proto3" json:"version,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateExpenseRequest) Reset() {
+	*x = UpdateExpenseRequest{}
+	mi := &file_splitexpense_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateExpenseRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateExpenseRequest) ProtoMessage() {}
+
+func (x *UpdateExpenseRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_splitexpense_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateExpenseRequest.ProtoReflect.Descriptor instead.
+func (*UpdateExpenseRequest) Descriptor() ([]byte, []int) {
+	return file_splitexpense_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *UpdateExpenseRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *UpdateExpenseRequest) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *UpdateExpenseRequest) GetCategory() string {
+	if x != nil {
+		return x.Category
+	}
+	return ""
+}
+
+func (x *UpdateExpenseRequest) GetAmount() float64 {
+	if x != nil {
+		return x.Amount
+	}
+	return 0
+}
+
+func (x *UpdateExpenseRequest) GetPayer() *Payer {
+	if x != nil {
+		return x.Payer
+	}
+	return nil
+}
+
+func (x *UpdateExpenseRequest) GetSplit() *Split {
+	if x != nil {
+		return x.Split
+	}
+	return nil
+}
+
+func (x *UpdateExpenseRequest) GetGroupId() string {
+	if x != nil {
+		return x.GroupId
+	}
+	return ""
+}
+
+func (x *UpdateExpenseRequest) GetVersion() int32 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+type ExpenseIdRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExpenseIdRequest) Reset() {
+	*x = ExpenseIdRequest{}
+	mi := &file_splitexpense_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExpenseIdRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExpenseIdRequest) ProtoMessage() {}
+
+func (x *ExpenseIdRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_splitexpense_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExpenseIdRequest.ProtoReflect.Descriptor instead.
+func (*ExpenseIdRequest) Descriptor() ([]byte, []int) {
+	return file_splitexpense_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *ExpenseIdRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type DeleteExpenseResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Deleted       bool                   `protobuf:"varint,1,opt,name=deleted,proto3" json:"deleted,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteExpenseResponse) Reset() {
+	*x = DeleteExpenseResponse{}
+	mi := &file_splitexpense_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteExpenseResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteExpenseResponse) ProtoMessage() {}
+
+func (x *DeleteExpenseResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_splitexpense_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteExpenseResponse.ProtoReflect.Descriptor instead.
+func (*DeleteExpenseResponse) Descriptor() ([]byte, []int) {
+	return file_splitexpense_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *DeleteExpenseResponse) GetDeleted() bool {
+	if x != nil {
+		return x.Deleted
+	}
+	return false
+}
+
+type SearchExpensesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Query         string                 `protobuf:"bytes,1,opt,name=query,proto3" json:"query,omitempty"`
+	GroupId       string                 `protobuf:"bytes,2,opt,name=group_id,json=groupId,proto3" json:"group_id,omitempty"`
+	ParticipantId string                 `protobuf:"bytes,3,opt,name=participant_id,json=participantId,proto3" json:"participant_id,omitempty"`
+	PayerId       string                 `protobuf:"bytes,4,opt,name=payer_id,json=payerId,proto3" json:"payer_id,omitempty"`
+	Status        string                 `protobuf:"bytes,5,opt,name=status,proto3" json:"status,omitempty"`
+	Category      string                 `protobuf:"bytes,6,opt,name=category,proto3" json:"category,omitempty"`
+	MinAmount     *float64               `protobuf:"fixed64,7,opt,name=min_amount,json=minAmount,proto3,oneof" json:"min_amount,omitempty"`
+	MaxAmount     *float64               `protobuf:"fixed64,8,opt,name=max_amount,json=maxAmount,proto3,oneof" json:"max_amount,omitempty"`
+	// from is inclusive, to is exclusive
+	From            *timestamppb.Timestamp `protobuf:"bytes,9,opt,name=from,proto3" json:"from,omitempty"`
+	To              *timestamppb.Timestamp `protobuf:"bytes,10,opt,name=to,proto3" json:"to,omitempty"`
+	IncludeArchived bool                   `protobuf:"varint,11,opt,name=include_archived,json=includeArchived,proto3" json:"include_archived,omitempty"`
+	Page            *PageRequest           `protobuf:"bytes,12,opt,name=page,proto3" json:"page,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *SearchExpensesRequest) Reset() {
+	*x = SearchExpensesRequest{}
+	mi := &file_splitexpense_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchExpensesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchExpensesRequest) ProtoMessage() {}
+
+func (x *SearchExpensesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_splitexpense_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchExpensesRequest.ProtoReflect.Descriptor instead.
+func (*SearchExpensesRequest) Descriptor() ([]byte, []int) {
+	return file_splitexpense_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *SearchExpensesRequest) GetQuery() string {
+	if x != nil {
+		return x.Query
+	}
+	return ""
+}
+
+func (x *SearchExpensesRequest) GetGroupId() string {
+	if x != nil {
+		return x.GroupId
+	}
+	return ""
+}
+
+func (x *SearchExpensesRequest) GetParticipantId() string {
+	if x != nil {
+		return x.ParticipantId
+	}
+	return ""
+}
+
+func (x *SearchExpensesRequest) GetPayerId() string {
+	if x != nil {
+		return x.PayerId
+	}
+	return ""
+}
+
+func (x *SearchExpensesRequest) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *SearchExpensesRequest) GetCategory() string {
+	if x != nil {
+		return x.Category
+	}
+	return ""
+}
+
+func (x *SearchExpensesRequest) GetMinAmount() float64 {
+	if x != nil && x.MinAmount != nil {
+		return *x.MinAmount
+	}
+	return 0
+}
+
+func (x *SearchExpensesRequest) GetMaxAmount() float64 {
+	if x != nil && x.MaxAmount != nil {
+		return *x.MaxAmount
+	}
+	return 0
+}
+
+func (x *SearchExpensesRequest) GetFrom() *timestamppb.Timestamp {
+	if x != nil {
+		return x.From
+	}
+	return nil
+}
+
+func (x *SearchExpensesRequest) GetTo() *timestamppb.Timestamp {
+	if x != nil {
+		return x.To
+	}
+	return nil
+}
+
+func (x *SearchExpensesRequest) GetIncludeArchived() bool {
+	if x != nil {
+		return x.IncludeArchived
+	}
+	return false
+}
+
+func (x *SearchExpensesRequest) GetPage() *PageRequest {
+	if x != nil {
+		return x.Page
+	}
+	return nil
+}
+
+type GetBalancesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetBalancesRequest) Reset() {
+	*x = GetBalancesRequest{}
+	mi := &file_splitexpense_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetBalancesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetBalancesRequest) ProtoMessage() {}
+
+func (x *GetBalancesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_splitexpense_proto_msgTypes[31]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetBalancesRequest.ProtoReflect.Descriptor instead.
+func (*GetBalancesRequest) Descriptor() ([]byte, []int) {
+	return file_splitexpense_proto_rawDescGZIP(), []int{31}
+}
+
+type GroupBalance struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	GroupId       string                 `protobuf:"bytes,1,opt,name=group_id,json=groupId,proto3" json:"group_id,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	TotalOwed     float64                `protobuf:"fixed64,3,opt,name=total_owed,json=totalOwed,proto3" json:"total_owed,omitempty"`
+	TotalBorrowed float64                `protobuf:"fixed64,4,opt,name=total_borrowed,json=totalBorrowed,proto3" json:"total_borrowed,omitempty"`
+	Net           float64                `protobuf:"fixed64,5,opt,name=net,proto3" json:"net,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GroupBalance) Reset() {
+	*x = GroupBalance{}
+	mi := &file_splitexpense_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GroupBalance) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GroupBalance) ProtoMessage() {}
+
+func (x *GroupBalance) ProtoReflect() protoreflect.Message {
+	mi := &file_splitexpense_proto_msgTypes[32]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GroupBalance.ProtoReflect.Descriptor instead.
+func (*GroupBalance) Descriptor() ([]byte, []int) {
+	return file_splitexpense_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *GroupBalance) GetGroupId() string {
+	if x != nil {
+		return x.GroupId
+	}
+	return ""
+}
+
+func (x *GroupBalance) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *GroupBalance) GetTotalOwed() float64 {
+	if x != nil {
+		return x.TotalOwed
+	}
+	return 0
+}
+
+func (x *GroupBalance) GetTotalBorrowed() float64 {
+	if x != nil {
+		return x.TotalBorrowed
+	}
+	return 0
+}
+
+func (x *GroupBalance) GetNet() float64 {
+	if x != nil {
+		return x.Net
+	}
+	return 0
+}
+
+type Balances struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TotalOwed     float64                `protobuf:"fixed64,1,opt,name=total_owed,json=totalOwed,proto3" json:"total_owed,omitempty"`
+	TotalBorrowed float64                `protobuf:"fixed64,2,opt,name=total_borrowed,json=totalBorrowed,proto3" json:"total_borrowed,omitempty"`
+	Net           float64                `protobuf:"fixed64,3,opt,name=net,proto3" json:"net,omitempty"`
+	Groups        []*GroupBalance        `protobuf:"bytes,4,rep,name=groups,proto3" json:"groups,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Balances) Reset() {
+	*x = Balances{}
+	mi := &file_splitexpense_proto_msgTypes[33]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Balances) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Balances) ProtoMessage() {}
+
+func (x *Balances) ProtoReflect() protoreflect.Message {
+	mi := &file_splitexpense_proto_msgTypes[33]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Balances.ProtoReflect.Descriptor instead.
+func (*Balances) Descriptor() ([]byte, []int) {
+	return file_splitexpense_proto_rawDescGZIP(), []int{33}
+}
+
+func (x *Balances) GetTotalOwed() float64 {
+	if x != nil {
+		return x.TotalOwed
+	}
+	return 0
+}
+
+func (x *Balances) GetTotalBorrowed() float64 {
+	if x != nil {
+		return x.TotalBorrowed
+	}
+	return 0
+}
+
+func (x *Balances) GetNet() float64 {
+	if x != nil {
+		return x.Net
+	}
+	return 0
+}
+
+func (x *Balances) GetGroups() []*GroupBalance {
+	if x != nil {
+		return x.Groups
+	}
+	return nil
+}
+
+var File_splitexpense_proto protoreflect.FileDescriptor
+
+const file_splitexpense_proto_rawDesc = "" +
+	"\n" +
+	"\x12splitexpense.proto\x12\x0fsplitexpense.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"a\n" +
+	"\x04User\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x14\n" +
+	"\x05email\x18\x03 \x01(\tR\x05email\x12\x1f\n" +
+	"\vis_verified\x18\x04 \x01(\bR\n" +
+	"isVerified\"U\n" +
+	"\rSignUpRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x14\n" +
+	"\x05email\x18\x02 \x01(\tR\x05email\x12\x1a\n" +
+	"\bpassword\x18\x03 \x01(\tR\bpassword\"@\n" +
+	"\fLoginRequest\x12\x14\n" +
+	"\x05email\x18\x01 \x01(\tR\x05email\x12\x1a\n" +
+	"\bpassword\x18\x02 \x01(\tR\bpassword\"P\n" +
+	"\rLoginResponse\x12\x14\n" +
+	"\x05token\x18\x01 \x01(\tR\x05token\x12)\n" +
+	"\x04user\x18\x02 \x01(\v2\x15.splitexpense.v1.UserR\x04user\"\x0e\n" +
+	"\fGetMeRequest\";\n" +
+	"\vPageRequest\x12\x16\n" +
+	"\x06cursor\x18\x01 \x01(\tR\x06cursor\x12\x14\n" +
+	"\x05limit\x18\x02 \x01(\x05R\x05limit\"(\n" +
+	"\x10AddFriendRequest\x12\x14\n" +
+	"\x05email\x18\x01 \x01(\tR\x05email\")\n" +
+	"\x11AddFriendResponse\x12\x14\n" +
+	"\x05added\x18\x01 \x01(\bR\x05added\"s\n" +
+	"\bUserPage\x12+\n" +
+	"\x05users\x18\x01 \x03(\v2\x15.splitexpense.v1.UserR\x05users\x12\x1f\n" +
+	"\vnext_cursor\x18\x02 \x01(\tR\n" +
+	"nextCursor\x12\x19\n" +
+	"\bhas_more\x18\x03 \x01(\bR\ahasMore\"\xbd\x01\n" +
+	"\x05Group\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12 \n" +
+	"\vdescription\x18\x03 \x01(\tR\vdescription\x12\x19\n" +
+	"\badmin_id\x18\x04 \x01(\tR\aadminId\x12\x18\n" +
+	"\aversion\x18\x05 \x01(\x05R\aversion\x129\n" +
+	"\n" +
+	"created_at\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\"w\n" +
+	"\tGroupPage\x12.\n" +
+	"\x06groups\x18\x01 \x03(\v2\x16.splitexpense.v1.GroupR\x06groups\x12\x1f\n" +
+	"\vnext_cursor\x18\x02 \x01(\tR\n" +
+	"nextCursor\x12\x19\n" +
+	"\bhas_more\x18\x03 \x01(\bR\ahasMore\"J\n" +
+	"\x12CreateGroupRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12 \n" +
+	"\vdescription\x18\x02 \x01(\tR\vdescription\"t\n" +
+	"\x12UpdateGroupRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12 \n" +
+	"\vdescription\x18\x03 \x01(\tR\vdescription\x12\x18\n" +
+	"\aversion\x18\x04 \x01(\x05R\aversion\"!\n" +
+	"\x0fGetGroupRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"\xec\x01\n" +
+	"\vGroupDetail\x12,\n" +
+	"\x05group\x18\x01 \x01(\v2\x16.splitexpense.v1.GroupR\x05group\x12/\n" +
+	"\amembers\x18\x02 \x03(\v2\x15.splitexpense.v1.UserR\amembers\x12\x1d\n" +
+	"\n" +
+	"total_owed\x18\x03 \x01(\x01R\ttotalOwed\x12%\n" +
+	"\x0etotal_borrowed\x18\x04 \x01(\x01R\rtotalBorrowed\x128\n" +
+	"\bexpenses\x18\x05 \x01(\v2\x1c.splitexpense.v1.ExpensePageR\bexpenses\"F\n" +
+	"\x10AddMemberRequest\x12\x19\n" +
+	"\bgroup_id\x18\x01 \x01(\tR\agroupId\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\tR\x06userId\".\n" +
+	"\x11LeaveGroupRequest\x12\x19\n" +
+	"\bgroup_id\x18\x01 \x01(\tR\agroupId\"$\n" +
+	"\x12MembershipResponse\x12\x0e\n" +
+	"\x02ok\x18\x01 \x01(\bR\x02ok\"<\n" +
+	"\x12DeleteGroupRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x16\n" +
+	"\x06policy\x18\x02 \x01(\tR\x06policy\"\xd2\x01\n" +
+	"\rGroupDeletion\x12\x19\n" +
+	"\bgroup_id\x18\x01 \x01(\tR\agroupId\x12\x16\n" +
+	"\x06policy\x18\x02 \x01(\tR\x06policy\x12\x18\n" +
+	"\adeleted\x18\x03 \x01(\bR\adeleted\x12\x18\n" +
+	"\amembers\x18\x04 \x01(\x05R\amembers\x12+\n" +
+	"\x11archived_expenses\x18\x05 \x01(\x05R\x10archivedExpenses\x12-\n" +
+	"\x12unsettled_expenses\x18\x06 \x01(\x05R\x11unsettledExpenses\"\x92\x01\n" +
+	"\x18ListGroupExpensesRequest\x12\x19\n" +
+	"\bgroup_id\x18\x01 \x01(\tR\agroupId\x12)\n" +
+	"\x10include_archived\x18\x02 \x01(\bR\x0fincludeArchived\x120\n" +
+	"\x04page\x18\x03 \x01(\v2\x1c.splitexpense.v1.PageRequestR\x04page\"\x96\x01\n" +
+	"\x05Payer\x12\x12\n" +
+	"\x04type\x18\x01 \x01(\tR\x04type\x12=\n" +
+	"\aamounts\x18\x02 \x03(\v2#.splitexpense.v1.Payer.AmountsEntryR\aamounts\x1a:\n" +
+	"\fAmountsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\x01R\x05value:\x028\x01\"\xc1\x03\n" +
+	"\x05Split\x12\x12\n" +
+	"\x04type\x18\x01 \x01(\tR\x04type\x12!\n" +
+	"\ftotal_amount\x18\x02 \x01(\x01R\vtotalAmount\x12\x14\n" +
+	"\x05equal\x18\x03 \x03(\tR\x05equal\x124\n" +
+	"\x04unit\x18\x04 \x03(\v2 .splitexpense.v1.Split.UnitEntryR\x04unit\x12F\n" +
+	"\n" +
+	"percentage\x18\x05 \x03(\v2&.splitexpense.v1.Split.PercentageEntryR\n" +
+	"percentage\x12:\n" +
+	"\x06shares\x18\x06 \x03(\v2\".splitexpense.v1.Split.SharesEntryR\x06shares\x1a7\n" +
+	"\tUnitEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\x01R\x05value:\x028\x01\x1a=\n" +
+	"\x0fPercentageEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\x01R\x05value:\x028\x01\x1a9\n" +
+	"\vSharesEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\x05R\x05value:\x028\x01\"\x91\x03\n" +
+	"\aExpense\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12 \n" +
+	"\vdescription\x18\x02 \x01(\tR\vdescription\x12\x1a\n" +
+	"\bcategory\x18\x03 \x01(\tR\bcategory\x12\x16\n" +
+	"\x06amount\x18\x04 \x01(\x01R\x06amount\x129\n" +
+	"\n" +
+	"created_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x12,\n" +
+	"\x05payer\x18\x06 \x01(\v2\x16.splitexpense.v1.PayerR\x05payer\x12,\n" +
+	"\x05split\x18\a \x01(\v2\x16.splitexpense.v1.SplitR\x05split\x12\x16\n" +
+	"\x06status\x18\b \x01(\tR\x06status\x12\x19\n" +
+	"\bgroup_id\x18\t \x01(\tR\agroupId\x12\x1d\n" +
+	"\n" +
+	"settled_by\x18\n" +
+	" \x01(\tR\tsettledBy\x12\x1d\n" +
+	"\n" +
+	"created_by\x18\v \x01(\tR\tcreatedBy\x12\x18\n" +
+	"\aversion\x18\f \x01(\x05R\aversion\"\x8b\x01\n" +
+	"\x0fDetailedExpense\x122\n" +
+	"\aexpense\x18\x01 \x01(\v2\x18.splitexpense.v1.ExpenseR\aexpense\x12\x1d\n" +
+	"\n" +
+	"total_owed\x18\x02 \x01(\x01R\ttotalOwed\x12%\n" +
+	"\x0etotal_borrowed\x18\x03 \x01(\x01R\rtotalBorrowed\"\xcd\x01\n" +
+	"\vExpensePage\x12<\n" +
+	"\bexpenses\x18\x01 \x03(\v2 .splitexpense.v1.DetailedExpenseR\bexpenses\x12\x1f\n" +
+	"\vnext_cursor\x18\x02 \x01(\tR\n" +
+	"nextCursor\x12\x19\n" +
+	"\bhas_more\x18\x03 \x01(\bR\ahasMore\x12\x1d\n" +
+	"\n" +
+	"total_owed\x18\x04 \x01(\x01R\ttotalOwed\x12%\n" +
+	"\x0etotal_borrowed\x18\x05 \x01(\x01R\rtotalBorrowed\"\xe3\x01\n" +
+	"\x14CreateExpenseRequest\x12 \n" +
+	"\vdescription\x18\x01 \x01(\tR\vdescription\x12\x1a\n" +
+	"\bcategory\x18\x02 \x01(\tR\bcategory\x12\x16\n" +
+	"\x06amount\x18\x03 \x01(\x01R\x06amount\x12,\n" +
+	"\x05payer\x18\x04 \x01(\v2\x16.splitexpense.v1.PayerR\x05payer\x12,\n" +
+	"\x05split\x18\x05 \x01(\v2\x16.splitexpense.v1.SplitR\x05split\x12\x19\n" +
+	"\bgroup_id\x18\x06 \x01(\tR\agroupId\"\x8d\x02\n" +
+	"\x14UpdateExpenseRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12 \n" +
+	"\vdescription\x18\x02 \x01(\tR\vdescription\x12\x1a\n" +
+	"\bcategory\x18\x03 \x01(\tR\bcategory\x12\x16\n" +
+	"\x06amount\x18\x04 \x01(\x01R\x06amount\x12,\n" +
+	"\x05payer\x18\x05 \x01(\v2\x16.splitexpense.v1.PayerR\x05payer\x12,\n" +
+	"\x05split\x18\x06 \x01(\v2\x16.splitexpense.v1.SplitR\x05split\x12\x19\n" +
+	"\bgroup_id\x18\a \x01(\tR\agroupId\x12\x18\n" +
+	"\aversion\x18\b \x01(\x05R\aversion\"\"\n" +
+	"\x10ExpenseIdRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"1\n" +
+	"\x15DeleteExpenseResponse\x12\x18\n" +
+	"\adeleted\x18\x01 \x01(\bR\adeleted\"\xdd\x03\n" +
+	"\x15SearchExpensesRequest\x12\x14\n" +
+	"\x05query\x18\x01 \x01(\tR\x05query\x12\x19\n" +
+	"\bgroup_id\x18\x02 \x01(\tR\agroupId\x12%\n" +
+	"\x0eparticipant_id\x18\x03 \x01(\tR\rparticipantId\x12\x19\n" +
+	"\bpayer_id\x18\x04 \x01(\tR\apayerId\x12\x16\n" +
+	"\x06status\x18\x05 \x01(\tR\x06status\x12\x1a\n" +
+	"\bcategory\x18\x06 \x01(\tR\bcategory\x12\"\n" +
+	"\n" +
+	"min_amount\x18\a \x01(\x01H\x00R\tminAmount\x88\x01\x01\x12\"\n" +
+	"\n" +
+	"max_amount\x18\b \x01(\x01H\x01R\tmaxAmount\x88\x01\x01\x12.\n" +
+	"\x04from\x18\t \x01(\v2\x1a.google.protobuf.TimestampR\x04from\x12*\n" +
+	"\x02to\x18\n" +
+	" \x01(\v2\x1a.google.protobuf.TimestampR\x02to\x12)\n" +
+	"\x10include_archived\x18\v \x01(\bR\x0fincludeArchived\x120\n" +
+	"\x04page\x18\f \x01(\v2\x1c.splitexpense.v1.PageRequestR\x04pageB\r\n" +
+	"\v_min_amountB\r\n" +
+	"\v_max_amount\"\x14\n" +
+	"\x12GetBalancesRequest\"\x95\x01\n" +
+	"\fGroupBalance\x12\x19\n" +
+	"\bgroup_id\x18\x01 \x01(\tR\agroupId\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x1d\n" +
+	"\n" +
+	"total_owed\x18\x03 \x01(\x01R\ttotalOwed\x12%\n" +
+	"\x0etotal_borrowed\x18\x04 \x01(\x01R\rtotalBorrowed\x12\x10\n" +
+	"\x03net\x18\x05 \x01(\x01R\x03net\"\x99\x01\n" +
+	"\bBalances\x12\x1d\n" +
+	"\n" +
+	"total_owed\x18\x01 \x01(\x01R\ttotalOwed\x12%\n" +
+	"\x0etotal_borrowed\x18\x02 \x01(\x01R\rtotalBorrowed\x12\x10\n" +
+	"\x03net\x18\x03 \x01(\x01R\x03net\x125\n" +
+	"\x06groups\x18\x04 \x03(\v2\x1d.splitexpense.v1.GroupBalanceR\x06groups2\xd5\x01\n" +
+	"\vUserService\x12?\n" +
+	"\x06SignUp\x12\x1e.splitexpense.v1.SignUpRequest\x1a\x15.splitexpense.v1.User\x12F\n" +
+	"\x05Login\x12\x1d.splitexpense.v1.LoginRequest\x1a\x1e.splitexpense.v1.LoginResponse\x12=\n" +
+	"\x05GetMe\x12\x1d.splitexpense.v1.GetMeRequest\x1a\x15.splitexpense.v1.User2\xab\x01\n" +
+	"\rFriendService\x12R\n" +
+	"\tAddFriend\x12!.splitexpense.v1.AddFriendRequest\x1a\".splitexpense.v1.AddFriendResponse\x12F\n" +
+	"\vListFriends\x12\x1c.splitexpense.v1.PageRequest\x1a\x19.splitexpense.v1.UserPage2\x98\x05\n" +
+	"\fGroupService\x12J\n" +
+	"\vCreateGroup\x12#.splitexpense.v1.CreateGroupRequest\x1a\x16.splitexpense.v1.Group\x12J\n" +
+	"\vUpdateGroup\x12#.splitexpense.v1.UpdateGroupRequest\x1a\x16.splitexpense.v1.Group\x12J\n" +
+	"\bGetGroup\x12 .splitexpense.v1.GetGroupRequest\x1a\x1c.splitexpense.v1.GroupDetail\x12F\n" +
+	"\n" +
+	"ListGroups\x12\x1c.splitexpense.v1.PageRequest\x1a\x1a.splitexpense.v1.GroupPage\x12S\n" +
+	"\tAddMember\x12!.splitexpense.v1.AddMemberRequest\x1a#.splitexpense.v1.MembershipResponse\x12U\n" +
+	"\n" +
+	"LeaveGroup\x12\".splitexpense.v1.LeaveGroupRequest\x1a#.splitexpense.v1.MembershipResponse\x12R\n" +
+	"\vDeleteGroup\x12#.splitexpense.v1.DeleteGroupRequest\x1a\x1e.splitexpense.v1.GroupDeletion\x12\\\n" +
+	"\x11ListGroupExpenses\x12).splitexpense.v1.ListGroupExpensesRequest\x1a\x1c.splitexpense.v1.ExpensePage2\x82\x04\n" +
+	"\x0eExpenseService\x12P\n" +
+	"\rCreateExpense\x12%.splitexpense.v1.CreateExpenseRequest\x1a\x18.splitexpense.v1.Expense\x12P\n" +
+	"\rUpdateExpense\x12%.splitexpense.v1.UpdateExpenseRequest\x1a\x18.splitexpense.v1.Expense\x12Z\n" +
+	"\rDeleteExpense\x12!.splitexpense.v1.ExpenseIdRequest\x1a&.splitexpense.v1.DeleteExpenseResponse\x12L\n" +
+	"\rSettleExpense\x12!.splitexpense.v1.ExpenseIdRequest\x1a\x18.splitexpense.v1.Expense\x12J\n" +
+	"\fListExpenses\x12\x1c.splitexpense.v1.PageRequest\x1a\x1c.splitexpense.v1.ExpensePage\x12V\n" +
+	"\x0eSearchExpenses\x12&.splitexpense.v1.SearchExpensesRequest\x1a\x1c.splitexpense.v1.ExpensePage2_\n" +
+	"\x0eBalanceService\x12M\n" +
+	"\vGetBalances\x12#.splitexpense.v1.GetBalancesRequest\x1a\x19.splitexpense.v1.BalancesB\x15Z\x13splitExpense/rpc/pbb\x06proto3"
+
+var (
+	file_splitexpense_proto_rawDescOnce sync.Once
+	file_splitexpense_proto_rawDescData []byte
+)
+
+func file_splitexpense_proto_rawDescGZIP() []byte {
+	file_splitexpense_proto_rawDescOnce.Do(func() {
+		file_splitexpense_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_splitexpense_proto_rawDesc), len(file_splitexpense_proto_rawDesc)))
+	})
+	return file_splitexpense_proto_rawDescData
+}
+
+var file_splitexpense_proto_msgTypes = make([]protoimpl.MessageInfo, 38)
+var file_splitexpense_proto_goTypes = []any{
+	(*User)(nil),                     // 0: splitexpense.v1.User
+	(*SignUpRequest)(nil),            // 1: splitexpense.v1.SignUpRequest
+	(*LoginRequest)(nil),             // 2: splitexpense.v1.LoginRequest
+	(*LoginResponse)(nil),            // 3: splitexpense.v1.LoginResponse
+	(*GetMeRequest)(nil),             // 4: splitexpense.v1.GetMeRequest
+	(*PageRequest)(nil),              // 5: splitexpense.v1.PageRequest
+	(*AddFriendRequest)(nil),         // 6: splitexpense.v1.AddFriendRequest
+	(*AddFriendResponse)(nil),        // 7: splitexpense.v1.AddFriendResponse
+	(*UserPage)(nil),                 // 8: splitexpense.v1.UserPage
+	(*Group)(nil),                    // 9: splitexpense.v1.Group
+	(*GroupPage)(nil),                // 10: splitexpense.v1.GroupPage
+	(*CreateGroupRequest)(nil),       // 11: splitexpense.v1.CreateGroupRequest
+	(*UpdateGroupRequest)(nil),       // 12: splitexpense.v1.UpdateGroupRequest
+	(*GetGroupRequest)(nil),          // 13: splitexpense.v1.GetGroupRequest
+	(*GroupDetail)(nil),              // 14: splitexpense.v1.GroupDetail
+	(*AddMemberRequest)(nil),         // 15: splitexpense.v1.AddMemberRequest
+	(*LeaveGroupRequest)(nil),        // 16: splitexpense.v1.LeaveGroupRequest
+	(*MembershipResponse)(nil),       // 17: splitexpense.v1.MembershipResponse
+	(*DeleteGroupRequest)(nil),       // 18: splitexpense.v1.DeleteGroupRequest
+	(*GroupDeletion)(nil),            // 19: splitexpense.v1.GroupDeletion
+	(*ListGroupExpensesRequest)(nil), // 20: splitexpense.v1.ListGroupExpensesRequest
+	(*Payer)(nil),                    // 21: splitexpense.v1.Payer
+	(*Split)(nil),                    // 22: splitexpense.v1.Split
+	(*Expense)(nil),                  // 23: splitexpense.v1.Expense
+	(*DetailedExpense)(nil),          // 24: splitexpense.v1.DetailedExpense
+	(*ExpensePage)(nil),              // 25: splitexpense.v1.ExpensePage
+	(*CreateExpenseRequest)(nil),     // 26: splitexpense.v1.CreateExpenseRequest
+	(*UpdateExpenseRequest)(nil),     // 27: splitexpense.v1.UpdateExpenseRequest
+	(*ExpenseIdRequest)(nil),         // 28: splitexpense.v1.ExpenseIdRequest
+	(*DeleteExpenseResponse)(nil),    // 29: splitexpense.v1.DeleteExpenseResponse
+	(*SearchExpensesRequest)(nil),    // 30: splitexpense.v1.SearchExpensesRequest
+	(*GetBalancesRequest)(nil),       // 31: splitexpense.v1.GetBalancesRequest
+	(*GroupBalance)(nil),             // 32: splitexpense.v1.GroupBalance
+	(*Balances)(nil),                 // 33: splitexpense.v1.Balances
+	nil,                              // 34: splitexpense.v1.Payer.AmountsEntry
+	nil,                              // 35: splitexpense.v1.Split.UnitEntry
+	nil,                              // 36: splitexpense.v1.Split.PercentageEntry
+	nil,                              // 37: splitexpense.v1.Split.SharesEntry
+	(*timestamppb.Timestamp)(nil),    // 38: google.protobuf.Timestamp
+}
+var file_splitexpense_proto_depIdxs = []int32{
+	0,  // 0: splitexpense.v1.LoginResponse.user:type_name -> splitexpense.v1.User
+	0,  // 1: splitexpense.v1.UserPage.users:type_name -> splitexpense.v1.User
+	38, // 2: splitexpense.v1.Group.created_at:type_name -> google.protobuf.Timestamp
+	9,  // 3: splitexpense.v1.GroupPage.groups:type_name -> splitexpense.v1.Group
+	9,  // 4: splitexpense.v1.GroupDetail.group:type_name -> splitexpense.v1.Group
+	0,  // 5: splitexpense.v1.GroupDetail.members:type_name -> splitexpense.v1.User
+	25, // 6: splitexpense.v1.GroupDetail.expenses:type_name -> splitexpense.v1.ExpensePage
+	5,  // 7: splitexpense.v1.ListGroupExpensesRequest.page:type_name -> splitexpense.v1.PageRequest
+	34, // 8: splitexpense.v1.Payer.amounts:type_name -> splitexpense.v1.Payer.AmountsEntry
+	35, // 9: splitexpense.v1.Split.unit:type_name -> splitexpense.v1.Split.UnitEntry
+	36, // 10: splitexpense.v1.Split.percentage:type_name -> splitexpense.v1.Split.PercentageEntry
+	37, // 11: splitexpense.v1.Split.shares:type_name -> splitexpense.v1.Split.SharesEntry
+	38, // 12: splitexpense.v1.Expense.created_at:type_name -> google.protobuf.Timestamp
+	21, // 13: splitexpense.v1.Expense.payer:type_name -> splitexpense.v1.Payer
+	22, // 14: splitexpense.v1.Expense.split:type_name -> splitexpense.v1.Split
+	23, // 15: splitexpense.v1.DetailedExpense.expense:type_name -> splitexpense.v1.Expense
+	24, // 16: splitexpense.v1.ExpensePage.expenses:type_name -> splitexpense.v1.DetailedExpense
+	21, // 17: splitexpense.v1.CreateExpenseRequest.payer:type_name -> splitexpense.v1.Payer
+	22, // 18: splitexpense.v1.CreateExpenseRequest.split:type_name -> splitexpense.v1.Split
+	21, // 19: splitexpense.v1.UpdateExpenseRequest.payer:type_name -> splitexpense.v1.Payer
+	22, // 20: splitexpense.v1.UpdateExpenseRequest.split:type_name -> splitexpense.v1.Split
+	38, // 21: splitexpense.v1.SearchExpensesRequest.from:type_name -> google.protobuf.Timestamp
+	38, // 22: splitexpense.v1.SearchExpensesRequest.to:type_name -> google.protobuf.Timestamp
+	5,  // 23: splitexpense.v1.SearchExpensesRequest.page:type_name -> splitexpense.v1.PageRequest
+	32, // 24: splitexpense.v1.Balances.groups:type_name -> splitexpense.v1.GroupBalance
+	1,  // 25: splitexpense.v1.UserService.SignUp:input_type -> splitexpense.v1.SignUpRequest
+	2,  // 26: splitexpense.v1.UserService.Login:input_type -> splitexpense.v1.LoginRequest
+	4,  // 27: splitexpense.v1.UserService.GetMe:input_type -> splitexpense.v1.GetMeRequest
+	6,  // 28: splitexpense.v1.FriendService.AddFriend:input_type -> splitexpense.v1.AddFriendRequest
+	5,  // 29: splitexpense.v1.FriendService.ListFriends:input_type -> splitexpense.v1.PageRequest
+	11, // 30: splitexpense.v1.GroupService.CreateGroup:input_type -> splitexpense.v1.CreateGroupRequest
+	12, // 31: splitexpense.v1.GroupService.UpdateGroup:input_type -> splitexpense.v1.UpdateGroupRequest
+	13, // 32: splitexpense.v1.GroupService.GetGroup:input_type -> splitexpense.v1.GetGroupRequest
+	5,  // 33: splitexpense.v1.GroupService.ListGroups:input_type -> splitexpense.v1.PageRequest
+	15, // 34: splitexpense.v1.GroupService.AddMember:input_type -> splitexpense.v1.AddMemberRequest
+	16, // 35: splitexpense.v1.GroupService.LeaveGroup:input_type -> splitexpense.v1.LeaveGroupRequest
+	18, // 36: splitexpense.v1.GroupService.DeleteGroup:input_type -> splitexpense.v1.DeleteGroupRequest
+	20, // 37: splitexpense.v1.GroupService.ListGroupExpenses:input_type -> splitexpense.v1.ListGroupExpensesRequest
+	26, // 38: splitexpense.v1.ExpenseService.CreateExpense:input_type -> splitexpense.v1.CreateExpenseRequest
+	27, // 39: splitexpense.v1.ExpenseService.UpdateExpense:input_type -> splitexpense.v1.UpdateExpenseRequest
+	28, // 40: splitexpense.v1.ExpenseService.DeleteExpense:input_type -> splitexpense.v1.ExpenseIdRequest
+	28, // 41: splitexpense.v1.ExpenseService.SettleExpense:input_type -> splitexpense.v1.ExpenseIdRequest
+	5,  // 42: splitexpense.v1.ExpenseService.ListExpenses:input_type -> splitexpense.v1.PageRequest
+	30, // 43: splitexpense.v1.ExpenseService.SearchExpenses:input_type -> splitexpense.v1.SearchExpensesRequest
+	31, // 44: splitexpense.v1.BalanceService.GetBalances:input_type -> splitexpense.v1.GetBalancesRequest
+	0,  // 45: splitexpense.v1.UserService.SignUp:output_type -> splitexpense.v1.User
+	3,  // 46: splitexpense.v1.UserService.Login:output_type -> splitexpense.v1.LoginResponse
+	0,  // 47: splitexpense.v1.UserService.GetMe:output_type -> splitexpense.v1.User
+	7,  // 48: splitexpense.v1.FriendService.AddFriend:output_type -> splitexpense.v1.AddFriendResponse
+	8,  // 49: splitexpense.v1.FriendService.ListFriends:output_type -> splitexpense.v1.UserPage
+	9,  // 50: splitexpense.v1.GroupService.CreateGroup:output_type -> splitexpense.v1.Group
+	9,  // 51: splitexpense.v1.GroupService.UpdateGroup:output_type -> splitexpense.v1.Group
+	14, // 52: splitexpense.v1.GroupService.GetGroup:output_type -> splitexpense.v1.GroupDetail
+	10, // 53: splitexpense.v1.GroupService.ListGroups:output_type -> splitexpense.v1.GroupPage
+	17, // 54: splitexpense.v1.GroupService.AddMember:output_type -> splitexpense.v1.MembershipResponse
+	17, // 55: splitexpense.v1.GroupService.LeaveGroup:output_type -> splitexpense.v1.MembershipResponse
+	19, // 56: splitexpense.v1.GroupService.DeleteGroup:output_type -> splitexpense.v1.GroupDeletion
+	25, // 57: splitexpense.v1.GroupService.ListGroupExpenses:output_type -> splitexpense.v1.ExpensePage
+	23, // 58: splitexpense.v1.ExpenseService.CreateExpense:output_type -> splitexpense.v1.Expense
+	23, // 59: splitexpense.v1.ExpenseService.UpdateExpense:output_type -> splitexpense.v1.Expense
+	29, // 60: splitexpense.v1.ExpenseService.DeleteExpense:output_type -> splitexpense.v1.DeleteExpenseResponse
+	23, // 61: splitexpense.v1.ExpenseService.SettleExpense:output_type -> splitexpense.v1.Expense
+	25, // 62: splitexpense.v1.ExpenseService.ListExpenses:output_type -> splitexpense.v1.ExpensePage
+	25, // 63: splitexpense.v1.ExpenseService.SearchExpenses:output_type -> splitexpense.v1.ExpensePage
+	33, // 64: splitexpense.v1.BalanceService.GetBalances:output_type -> splitexpense.v1.Balances
+	45, // [45:65] is the sub-list for method output_type
+	25, // [25:45] is the sub-list for method input_type
+	25, // [25:25] is the sub-list for extension type_name
+	25, // [25:25] is the sub-list for extension extendee
+	0,  // [0:25] is the sub-list for field type_name
+}
+
+func init() { file_splitexpense_proto_init() }
+func file_splitexpense_proto_init() {
+	if File_splitexpense_proto != nil {
+		return
+	}
+	file_splitexpense_proto_msgTypes[30].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_splitexpense_proto_rawDesc), len(file_splitexpense_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   38,
+			NumExtensions: 0,
+			NumServices:   5,
+		},
+		GoTypes:           file_splitexpense_proto_goTypes,
+		DependencyIndexes: file_splitexpense_proto_depIdxs,
+		MessageInfos:      file_splitexpense_proto_msgTypes,
+	}.Build()
+	File_splitexpense_proto = out.File
+	file_splitexpense_proto_goTypes = nil
+	file_splitexpense_proto_depIdxs = nil
+}
@@ -0,0 +1,70 @@
+package rpcServer
+
+import (
+	"context"
+	"net"
+	"splitExpense/config"
+	"splitExpense/orchestrator"
+	"splitExpense/rpc/pb"
+	"time"
+
+	"google.golang.org/grpc"
+)
+
+// Serve runs the gRPC API on cfg.GRPCAddr next to the HTTP server, it returns
+// when the listener fails
+func Serve(cfg *config.Config, o orchestrator.ExpenseAppImpl) error {
+	lis, err := net.Listen("tcp", cfg.GRPCAddr)
+	if err != nil {
+		return err
+	}
+	return NewServer(cfg, o).Serve(lis)
+}
+
+// NewServer registers every service on a gRPC server. The interceptors run in
+// the order of the HTTP middleware: errors are mapped last, then the request
+// timeout, authentication and row level security.
+func NewServer(cfg *config.Config, o orchestrator.ExpenseAppImpl) *grpc.Server {
+	s := grpc.NewServer(grpc.ChainUnaryInterceptor(
+		errorInterceptor,
+		timeoutInterceptor(cfg.RequestTimeout),
+		authInterceptor,
+		rowLevelSecurityInterceptor(o),
+	))
+	pb.RegisterUserServiceServer(s, &userServer{o: o})
+	pb.RegisterFriendServiceServer(s, &friendServer{o: o})
+	pb.RegisterGroupServiceServer(s, &groupServer{o: o})
+	pb.RegisterExpenseServiceServer(s, &expenseServer{o: o})
+	pb.RegisterBalanceServiceServer(s, &balanceServer{o: o})
+	return s
+}
+
+// timeoutInterceptor bounds every call like RequestTimeoutMiddleware bounds HTTP requests
+func timeoutInterceptor(timeout time.Duration) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if timeout <= 0 {
+			return handler(ctx, req)
+		}
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+		return handler(ctx, req)
+	}
+}
+
+// rowLevelSecurityInterceptor runs an authenticated call in one transaction
+// bound to the user, see apiServer.RowLevelSecurity
+func rowLevelSecurityInterceptor(o orchestrator.ExpenseAppImpl) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		user, ok := userFromContext(ctx)
+		if !ok {
+			return handler(ctx, req)
+		}
+		var resp any
+		err := o.RunAsUser(ctx, user.ID, func(ctx context.Context) error {
+			var err error
+			resp, err = handler(ctx, req)
+			return err
+		})
+		return resp, err
+	}
+}
@@ -0,0 +1,62 @@
+package rpcServer
+
+import (
+	"context"
+	"splitExpense/expense"
+	"splitExpense/orchestrator"
+	"splitExpense/rpc/pb"
+)
+
+type userServer struct {
+	pb.UnimplementedUserServiceServer
+	o orchestrator.ExpenseAppImpl
+}
+
+func (s *userServer) SignUp(ctx context.Context, req *pb.SignUpRequest) (*pb.User, error) {
+	user, appErr := s.o.UserSignup(ctx, req.Name, req.Email, req.Password)
+	if appErr != nil {
+		return nil, appErr
+	}
+	return userToPb(*user), nil
+}
+
+func (s *userServer) Login(ctx context.Context, req *pb.LoginRequest) (*pb.LoginResponse, error) {
+	user, err := s.o.Login(ctx, req.Email, req.Password)
+	if err != nil {
+		return nil, err
+	}
+	token, err := expense.GenerateToken(*user)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.LoginResponse{Token: token, User: userToPb(*user)}, nil
+}
+
+func (s *userServer) GetMe(ctx context.Context, req *pb.GetMeRequest) (*pb.User, error) {
+	user, err := s.o.GetUserService().GetUser(ctx, currentUserId(ctx))
+	if err != nil {
+		return nil, err
+	}
+	return userToPb(*user), nil
+}
+
+type friendServer struct {
+	pb.UnimplementedFriendServiceServer
+	o orchestrator.ExpenseAppImpl
+}
+
+func (s *friendServer) AddFriend(ctx context.Context, req *pb.AddFriendRequest) (*pb.AddFriendResponse, error) {
+	added, err := s.o.AddFriend(ctx, currentUserId(ctx), req.Email)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.AddFriendResponse{Added: added}, nil
+}
+
+func (s *friendServer) ListFriends(ctx context.Context, req *pb.PageRequest) (*pb.UserPage, error) {
+	page, err := s.o.GetFriendsPage(ctx, currentUserId(ctx), pageFromPb(req))
+	if err != nil {
+		return nil, err
+	}
+	return &pb.UserPage{Users: usersToPb(page.Users), NextCursor: page.NextCursor, HasMore: page.HasMore}, nil
+}
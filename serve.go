@@ -0,0 +1,27 @@
+package main
+
+import (
+	"flag"
+	apiServer "splitExpense/api"
+	"splitExpense/config"
+)
+
+// runServe handles `splitExpense serve [flags]`, flags override the environment
+func runServe(cfg *config.Config, args []string) error {
+	fs := flag.NewFlagSet("serve", flag.ContinueOnError)
+	fs.StringVar(&cfg.Addr, "addr", cfg.Addr, "address to listen on")
+	fs.StringVar(&cfg.GRPCAddr, "grpc-addr", cfg.GRPCAddr, "address the gRPC API listens on, empty disables it")
+	fs.BoolVar(&cfg.AutoMigrate, "auto-migrate", cfg.AutoMigrate, "apply pending migrations on start")
+	fs.DurationVar(&cfg.RequestTimeout, "request-timeout", cfg.RequestTimeout, "bound on every request, 0 disables it")
+	fs.BoolVar(&cfg.EventSourcing, "event-sourcing", cfg.EventSourcing, "record every change in the event ledger")
+	fs.BoolVar(&cfg.OutboxEnabled, "outbox", cfg.OutboxEnabled, "publish changes through the outbox")
+	fs.IntVar(&cfg.CacheSize, "cache-size", cfg.CacheSize, "entries per storage cache, 0 disables caching")
+	fs.BoolVar(&cfg.RowLevelSecurity, "row-level-security", cfg.RowLevelSecurity, "run requests under the row level security role")
+	fs.StringVar(&cfg.GroupDeletePolicy, "group-delete-policy", cfg.GroupDeletePolicy, "default group delete policy, refuse or archive")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	apiServer.Start(cfg)
+	return nil
+}
@@ -1,6 +1,7 @@
 package service
 
 import (
+	"context"
 	"errors"
 	"splitExpense/expense"
 	"time"
@@ -16,36 +17,24 @@ type ExpenseServiceImpl struct {
 
 // TODO: Record expense update in expense history
 
-func (e *ExpenseServiceImpl) CreateExpense(userId string, expenseCreate expense.ExpenseCreate) (*expense.Expense, error) {
-	_, err := e.storage.FetchUserById(userId)
+func (e *ExpenseServiceImpl) CreateExpense(ctx context.Context, userId string, expenseCreate expense.ExpenseCreate) (*expense.Expense, error) {
+	_, err := e.storage.FetchUserById(ctx, userId)
 	if err != nil {
 		return nil, errors.Join(errors.New("user trying to create expense does not exist"))
 	}
 
 	// validate group expense, check if group exists
 	if expenseCreate.IsGroupExpense {
-		_, err := e.storage.FetchGroupById(expenseCreate.GroupId)
+		_, err := e.storage.FetchGroupById(ctx, expenseCreate.GroupId)
 		if err != nil {
 			return nil, err
 		}
 	}
 	payeeMap := expenseCreate.SplitW.Split.GetPayeeSplit()
-
-	exp := expense.Expense{
-		ID:             uuid.New().String(),
-		Description:    expenseCreate.Description,
-		SplitW:         expenseCreate.SplitW,
-		CreatedAt:      time.Now(),
-		PayeeW:         expenseCreate.PayeeW,
-		Amount:         expenseCreate.Amount,
-		Status:         expense.ExpenseDraft,
-		CreatedBy:      userId,
-		IsGroupExpense: expenseCreate.IsGroupExpense,
-		GroupId:        expenseCreate.GroupId,
-	}
+	exp := draftExpense(userId, expenseCreate)
 
 	// TODO: Add transaction LOCK
-	expData, err := e.storage.CreateOrUpdateExpense(exp)
+	expData, err := e.storage.CreateOrUpdateExpense(ctx, exp)
 	if err != nil {
 		return nil, err
 	}
@@ -55,7 +44,7 @@ func (e *ExpenseServiceImpl) CreateExpense(userId string, expenseCreate expense.
 	if expenseCreate.IsGroupExpense {
 		for _, userId := range userIds {
 
-			_, err := e.storage.AddExpenseMapping(expData.ID, userId)
+			_, err := e.storage.AddExpenseMapping(ctx, expData.ID, userId)
 			if err != nil {
 				return nil, err
 			}
@@ -67,54 +56,116 @@ func (e *ExpenseServiceImpl) CreateExpense(userId string, expenseCreate expense.
 	return expData, nil
 }
 
-func (e *ExpenseServiceImpl) UpdateExpense(userId string, exp expense.Expense) (*expense.Expense, error) {
-	existingExp, err := e.storage.FetchExpense(exp.ID)
+// CreateExpenses creates the expenses in one transaction, the mappings of the
+// group expenses are added in one write for all of them
+func (e *ExpenseServiceImpl) CreateExpenses(ctx context.Context, userId string, creates []expense.ExpenseCreate) ([]expense.Expense, error) {
+	var created []expense.Expense
+	err := e.storage.RunInTx(ctx, func(ctx context.Context) error {
+		if _, err := e.storage.FetchUserById(ctx, userId); err != nil {
+			return errors.New("user trying to create expense does not exist")
+		}
+		groupIds := lodash.Uniq(lodash.FilterMap(creates, func(c expense.ExpenseCreate, _ int) (string, bool) {
+			return c.GroupId, c.IsGroupExpense
+		}))
+		for _, groupId := range groupIds {
+			if _, err := e.storage.FetchGroupById(ctx, groupId); err != nil {
+				return err
+			}
+		}
+
+		drafts := lodash.Map(creates, func(c expense.ExpenseCreate, _ int) expense.Expense { return draftExpense(userId, c) })
+		var err error
+		created, err = e.storage.CreateExpenses(ctx, drafts)
+		if err != nil {
+			return err
+		}
+
+		mappings := map[string][]string{}
+		for _, exp := range created {
+			if exp.IsGroupExpense {
+				mappings[exp.ID] = lodash.Union([]string{userId}, lodash.Keys(exp.SplitW.Split.GetPayeeSplit()), lodash.Keys(exp.PayeeW.Payer.GetPayers()))
+			}
+		}
+		return e.storage.AddExpenseMappings(ctx, mappings)
+	})
 	if err != nil {
 		return nil, err
 	}
+	return created, nil
+}
 
-	if existingExp.CreatedAt != exp.CreatedAt || existingExp.CreatedBy != exp.CreatedBy {
-		return nil, errors.New("protected field change")
+// draftExpense is the new expense userId creates from expenseCreate
+func draftExpense(userId string, expenseCreate expense.ExpenseCreate) expense.Expense {
+	createdAt := expenseCreate.CreatedAt
+	if createdAt.IsZero() {
+		createdAt = time.Now()
 	}
+	return expense.Expense{
+		ID:             uuid.New().String(),
+		Description:    expenseCreate.Description,
+		Category:       expenseCreate.Category,
+		SplitW:         expenseCreate.SplitW,
+		CreatedAt:      createdAt,
+		PayeeW:         expenseCreate.PayeeW,
+		Amount:         expenseCreate.Amount,
+		Status:         expense.ExpenseDraft,
+		CreatedBy:      userId,
+		IsGroupExpense: expenseCreate.IsGroupExpense,
+		GroupId:        expenseCreate.GroupId,
+	}
+}
 
-	existingPayers := lodash.Keys(existingExp.PayeeW.Payer.GetPayers())
-	newPayers := lodash.Keys(exp.PayeeW.Payer.GetPayers())
-	payersToRemove, payersToAdd := lodash.Difference(existingPayers, newPayers)
+// UpdateExpense writes the expense and moves its mappings to the new payers
+// and borrowers in one transaction, a failed mapping leaves the expense as it was
+func (e *ExpenseServiceImpl) UpdateExpense(ctx context.Context, userId string, exp expense.Expense) (*expense.Expense, error) {
+	var updatedExp *expense.Expense
+	err := e.storage.RunInTx(ctx, func(ctx context.Context) error {
+		existingExp, err := e.storage.FetchExpense(ctx, exp.ID)
+		if err != nil {
+			return err
+		}
 
-	// borrowers
-	existingB := lodash.Keys(existingExp.SplitW.Split.GetPayeeSplit())
-	newB := lodash.Keys(exp.SplitW.Split.GetPayeeSplit())
-	removeB, addB := lodash.Difference(existingB, newB)
+		if existingExp.CreatedAt != exp.CreatedAt || existingExp.CreatedBy != exp.CreatedBy {
+			return errors.New("protected field change")
+		}
 
-	usersToAdd := lodash.Union(addB, payersToAdd)
-	// only remove users who are not borrower or payer
-	usersToRemove, _ := lodash.Difference(lodash.Union(removeB, payersToRemove), lodash.Union(newPayers, newB))
+		existingPayers := lodash.Keys(existingExp.PayeeW.Payer.GetPayers())
+		newPayers := lodash.Keys(exp.PayeeW.Payer.GetPayers())
+		payersToRemove, payersToAdd := lodash.Difference(existingPayers, newPayers)
 
-	// TODO: ADD LOCK
-	for _, userId := range usersToAdd {
-		_, err := e.storage.AddExpenseMapping(exp.ID, userId)
+		// borrowers
+		existingB := lodash.Keys(existingExp.SplitW.Split.GetPayeeSplit())
+		newB := lodash.Keys(exp.SplitW.Split.GetPayeeSplit())
+		removeB, addB := lodash.Difference(existingB, newB)
+
+		usersToAdd := lodash.Union(addB, payersToAdd)
+		// only remove users who are not borrower or payer
+		usersToRemove, _ := lodash.Difference(lodash.Union(removeB, payersToRemove), lodash.Union(newPayers, newB))
+
+		// write the expense first so a stale version is rejected before mappings change
+		updatedExp, err = e.storage.CreateOrUpdateExpense(ctx, exp)
 		if err != nil {
-			return nil, err
+			return err
 		}
-	}
 
-	_, err = e.storage.RemoveUsersFromExpense(exp.ID, usersToRemove)
-	if err != nil {
-		return nil, err
-	}
+		for _, userId := range usersToAdd {
+			_, err := e.storage.AddExpenseMapping(ctx, exp.ID, userId)
+			if err != nil {
+				return err
+			}
+		}
 
-	updatedExp, err := e.storage.CreateOrUpdateExpense(exp)
+		_, err = e.storage.RemoveUsersFromExpense(ctx, exp.ID, usersToRemove)
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
-
-	// REMOVE LOCK
-
-	return updatedExp, err
+	return updatedExp, nil
 }
 
-func (e *ExpenseServiceImpl) DeleteExpense(userId string, expenseId string) (bool, error) {
-	exp, err := e.storage.FetchExpense(expenseId)
+func (e *ExpenseServiceImpl) DeleteExpense(ctx context.Context, userId string, expenseId string) (bool, error) {
+	exp, err := e.storage.FetchExpense(ctx, expenseId)
 	if err != nil {
 		return false, err
 	}
@@ -122,29 +173,29 @@ func (e *ExpenseServiceImpl) DeleteExpense(userId string, expenseId string) (boo
 	userIds := lodash.Union(lodash.Keys(exp.PayeeW.Payer.GetPayers()), lodash.Keys(exp.SplitW.Split.GetPayeeSplit()))
 
 	// TODO: ADD LOCK
-	_, err = e.storage.RemoveUsersFromExpense(exp.ID, userIds)
+	_, err = e.storage.RemoveUsersFromExpense(ctx, exp.ID, userIds)
 	if err != nil {
 		return false, err
 	}
-	return e.storage.DeleteExpense(expenseId)
+	return e.storage.DeleteExpense(ctx, expenseId)
 }
 
-func (e *ExpenseServiceImpl) SettleExpense(userId string, expenseId string) (*expense.Expense, error) {
-	Expense, err := e.storage.FetchExpense(expenseId)
+func (e *ExpenseServiceImpl) SettleExpense(ctx context.Context, userId string, expenseId string) (*expense.Expense, error) {
+	Expense, err := e.storage.FetchExpense(ctx, expenseId)
 	if err != nil {
 		return nil, err
 	}
 	Expense.Status = expense.ExpenseSettled
 	Expense.SettledBy = userId
-	updatedData, err := e.storage.CreateOrUpdateExpense(*Expense)
+	updatedData, err := e.storage.CreateOrUpdateExpense(ctx, *Expense)
 	if err != nil {
 		return nil, err
 	}
 	return updatedData, nil
 }
 
-func (e *ExpenseServiceImpl) FetchExpense(id string) (*expense.Expense, error) {
-	return e.storage.FetchExpense(id)
+func (e *ExpenseServiceImpl) FetchExpense(ctx context.Context, id string) (*expense.Expense, error) {
+	return e.storage.FetchExpense(ctx, id)
 }
 
 func NewExpenseServiceImpl(storage expense.Storage) *ExpenseServiceImpl {
@@ -154,12 +205,12 @@ func NewExpenseServiceImpl(storage expense.Storage) *ExpenseServiceImpl {
 }
 
 // TODO: have a thread to fetch in background, also use streams alternative for data processing
-func (e *ExpenseServiceImpl) CalculateUserRunningExpensesInGroup(userId string, group *expense.Group) (float64, float64, error) {
-	pageNumber := 1
+func (e *ExpenseServiceImpl) CalculateUserRunningExpensesInGroup(ctx context.Context, userId string, group *expense.Group) (float64, float64, error) {
+	page := expense.PageRequest{Limit: expense.MaxPageLimit}
 	totalPayed, totalBorrowed := 0.0, 0.0
 
 	for {
-		stored, err := e.storage.FetchGroupExpensesByStatus(group.Id, expense.ExpenseDraft, pageNumber)
+		stored, err := e.storage.FetchGroupExpensesPage(ctx, group.Id, expense.ExpenseDraft, false, page)
 		if err != nil {
 			return 0, 0, err
 		}
@@ -174,28 +225,46 @@ func (e *ExpenseServiceImpl) CalculateUserRunningExpensesInGroup(userId string,
 			}
 		}
 
-		if pageNumber >= stored.TotalPages {
+		if !stored.HasMore {
 			break
 		}
-		pageNumber++
+		page.Cursor = stored.NextCursor
 	}
 
 	return totalPayed, totalBorrowed, nil
 }
 
-func (e *ExpenseServiceImpl) FetchExpenseByGroup(userId string, groupId string, pageNumber int) (*expense.GroupExpenseHistory, error) {
+func (e *ExpenseServiceImpl) FetchExpenseByGroup(ctx context.Context, userId string, groupId string, pageNumber int) (*expense.GroupExpenseHistory, error) {
 	if pageNumber == 0 {
 		pageNumber = 1
 	}
 
-	stored, err := e.storage.FetchGroupExpenses(groupId, pageNumber)
+	stored, err := e.storage.FetchGroupExpenses(ctx, groupId, pageNumber)
 	if err != nil {
 		return nil, err
 	}
 
-	result := &expense.GroupExpenseHistory{Expenses: []expense.DetailedExpense{}, PageNumber: stored.PageNumber, TotalPages: stored.TotalPages}
+	return DetailUserExpenses(userId, stored), nil
+}
+
+func (e *ExpenseServiceImpl) FetchExpenseByGroupPage(ctx context.Context, userId string, groupId string, includeArchived bool, page expense.PageRequest) (*expense.GroupExpenseHistory, error) {
+	stored, err := e.storage.FetchGroupExpensesPage(ctx, groupId, "", includeArchived, page)
+	if err != nil {
+		return nil, err
+	}
+	return DetailUserExpenses(userId, stored), nil
+}
+
+// DetailUserExpenses keeps the expenses userId owes or is owed on, with the amounts from userId's side
+func DetailUserExpenses(userId string, stored *expense.StoredGroupExpenseHistory) *expense.GroupExpenseHistory {
+	result := &expense.GroupExpenseHistory{
+		Expenses:   []expense.DetailedExpense{},
+		PageNumber: stored.PageNumber,
+		TotalPages: stored.TotalPages,
+		NextCursor: stored.NextCursor,
+		HasMore:    stored.HasMore,
+	}
 
-	totalPayed, totalBorrowed := 0.0, 0.0
 	for _, exp := range stored.Expenses {
 		payed := exp.PayeeW.Payer.GetPayers()[userId]
 		borrowed := exp.SplitW.Split.GetPayeeSplit()[userId]
@@ -204,27 +273,24 @@ func (e *ExpenseServiceImpl) FetchExpenseByGroup(userId string, groupId string,
 		} else if payed < borrowed {
 			result.Expenses = append(result.Expenses, expense.DetailedExpense{Expense: exp, TotalBorrowed: borrowed - payed})
 		}
-
-		totalPayed += payed
-		totalBorrowed += borrowed
 	}
-	return result, err
+	return result
 }
 
-func (e *ExpenseServiceImpl) FetchExpenseCountByGroup(groupId string) (int, error) {
-	_, err := e.storage.FetchGroupById(groupId)
+func (e *ExpenseServiceImpl) FetchExpenseCountByGroup(ctx context.Context, groupId string) (int, error) {
+	_, err := e.storage.FetchGroupById(ctx, groupId)
 	if err != nil {
 		return 0, err
 	}
-	return e.storage.FetchExpenseCountByGroup(groupId)
+	return e.storage.FetchExpenseCountByGroup(ctx, groupId)
 }
 
-func (e *ExpenseServiceImpl) CalculateAllUserRunningExpenses(userId string) (float64, float64, error) {
-	pageNumber := 1
+func (e *ExpenseServiceImpl) CalculateAllUserRunningExpenses(ctx context.Context, userId string) (float64, float64, error) {
+	page := expense.PageRequest{Limit: expense.MaxPageLimit}
 	totalPayed, totalBorrowed := 0.0, 0.0
 
 	for {
-		stored, err := e.storage.FetchExpenseByUserAndStatus(userId, expense.ExpenseDraft, pageNumber, 100)
+		stored, err := e.storage.FetchUserExpensesPage(ctx, userId, expense.ExpenseDraft, page)
 		if err != nil {
 			return 0, 0, err
 		}
@@ -239,36 +305,92 @@ func (e *ExpenseServiceImpl) CalculateAllUserRunningExpenses(userId string) (flo
 			}
 		}
 
-		if pageNumber >= stored.TotalPages {
+		if !stored.HasMore {
 			break
 		}
-		pageNumber++
+		page.Cursor = stored.NextCursor
 	}
 
 	return totalPayed, totalBorrowed, nil
 }
 
-func (e *ExpenseServiceImpl) FetchActiveUserExpenses(userId string, pageNumber int) (*expense.GroupExpenseHistory, error) {
+// CalculateUserRunningExpensesByGroup is CalculateAllUserRunningExpenses
+// broken down by group in one pass, expenses outside a group are keyed by ""
+func (e *ExpenseServiceImpl) CalculateUserRunningExpensesByGroup(ctx context.Context, userId string) (map[string]RunningBalance, error) {
+	page := expense.PageRequest{Limit: expense.MaxPageLimit}
+	balances := map[string]RunningBalance{}
+
+	for {
+		stored, err := e.storage.FetchUserExpensesPage(ctx, userId, expense.ExpenseDraft, page)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, exp := range stored.Expenses {
+			payed := exp.PayeeW.Payer.GetPayers()[userId]
+			borrowed := exp.SplitW.Split.GetPayeeSplit()[userId]
+			balance := balances[exp.GroupId]
+			if payed > borrowed {
+				balance.TotalOwed += payed - borrowed
+			} else if payed < borrowed {
+				balance.TotalBorrowed += borrowed - payed
+			}
+			balances[exp.GroupId] = balance
+		}
+
+		if !stored.HasMore {
+			break
+		}
+		page.Cursor = stored.NextCursor
+	}
+
+	return balances, nil
+}
+
+func (e *ExpenseServiceImpl) FetchActiveUserExpenses(ctx context.Context, userId string, pageNumber int) (*expense.GroupExpenseHistory, error) {
 	if pageNumber == 0 {
 		pageNumber = 1
 	}
 
-	stored, err := e.storage.FetchExpenseByUserAndStatus(userId, expense.ExpenseDraft, pageNumber, 100)
+	stored, err := e.storage.FetchExpenseByUserAndStatus(ctx, userId, expense.ExpenseDraft, pageNumber, 100)
 	if err != nil {
 		return nil, err
 	}
 
-	result := &expense.GroupExpenseHistory{Expenses: []expense.DetailedExpense{}, PageNumber: stored.PageNumber, TotalPages: stored.TotalPages}
+	return DetailUserExpenses(userId, stored), nil
+}
+
+func (e *ExpenseServiceImpl) FetchActiveUserExpensesPage(ctx context.Context, userId string, page expense.PageRequest) (*expense.GroupExpenseHistory, error) {
+	stored, err := e.storage.FetchUserExpensesPage(ctx, userId, expense.ExpenseDraft, page)
+	if err != nil {
+		return nil, err
+	}
 
+	return DetailUserExpenses(userId, stored), nil
+}
+
+func (e *ExpenseServiceImpl) SearchExpenses(ctx context.Context, userId string, search expense.ExpenseSearch, page expense.PageRequest) (*expense.GroupExpenseHistory, error) {
+	stored, err := e.storage.SearchExpenses(ctx, userId, search, page)
+	if err != nil {
+		return nil, err
+	}
+
+	// unlike DetailUserExpenses, settled up expenses are kept so every match is listed
+	result := &expense.GroupExpenseHistory{
+		Expenses:   []expense.DetailedExpense{},
+		NextCursor: stored.NextCursor,
+		HasMore:    stored.HasMore,
+	}
 	for _, exp := range stored.Expenses {
 		payed := exp.PayeeW.Payer.GetPayers()[userId]
 		borrowed := exp.SplitW.Split.GetPayeeSplit()[userId]
+		detailed := expense.DetailedExpense{Expense: exp}
 		if payed > borrowed {
-			result.Expenses = append(result.Expenses, expense.DetailedExpense{Expense: exp, TotalOwed: payed - borrowed})
-		} else if payed < borrowed {
-			result.Expenses = append(result.Expenses, expense.DetailedExpense{Expense: exp, TotalBorrowed: borrowed - payed})
+			detailed.TotalOwed = payed - borrowed
+		} else {
+			detailed.TotalBorrowed = borrowed - payed
 		}
+		result.Expenses = append(result.Expenses, detailed)
 	}
-
 	return result, nil
 }
@@ -1,6 +1,7 @@
 package service
 
 import (
+	"context"
 	"crypto"
 	"database/sql"
 	"encoding/base64"
@@ -17,14 +18,14 @@ type UserServiceImpl struct {
 	storage expense.Storage
 }
 
-func (u *UserServiceImpl) GetUser(id string) (*expense.User, error) {
-	user, err := u.storage.FetchUserById(id)
+func (u *UserServiceImpl) GetUser(ctx context.Context, id string) (*expense.User, error) {
+	user, err := u.storage.FetchUserById(ctx, id)
 	return user, err
 }
 
-func (u *UserServiceImpl) CreateUser(name string, email string, password string) (*expense.User, error) {
+func (u *UserServiceImpl) CreateUser(ctx context.Context, name string, email string, password string) (*expense.User, error) {
 
-	existingUser, err := u.storage.FetchUserByEmail(email)
+	existingUser, err := u.storage.FetchUserByEmail(ctx, email)
 	if err != nil && err != sql.ErrNoRows {
 		return nil, err
 	}
@@ -32,14 +33,10 @@ func (u *UserServiceImpl) CreateUser(name string, email string, password string)
 		return nil, errors.New("user Already Exists")
 	}
 
-	hasher := crypto.SHA256.New()
-	hasher.Write([]byte(password))
-	passwordHash := hasher.Sum(nil)
-
-	user, err := u.storage.CreateUser(expense.User{
+	user, err := u.storage.CreateUser(ctx, expense.User{
 		ID:         uuid.New().String(),
 		Name:       name,
-		Password:   base64.StdEncoding.EncodeToString(passwordHash),
+		Password:   hashPassword(password),
 		Email:      email,
 		IsVerified: false,
 	})
@@ -48,12 +45,44 @@ func (u *UserServiceImpl) CreateUser(name string, email string, password string)
 	return user, err
 }
 
-func (u *UserServiceImpl) JoinGroup(userId string, groupId string) (bool, error) {
-	return u.storage.AddUserInGroup(userId, groupId)
+// hashPassword is the stored form of a password, Login compares against it
+func hashPassword(password string) string {
+	hasher := crypto.SHA256.New()
+	hasher.Write([]byte(password))
+	return base64.StdEncoding.EncodeToString(hasher.Sum(nil))
 }
 
-func (u *UserServiceImpl) LeaveGroup(userId string, groupId string) (bool, error) {
-	doesExist, err := u.storage.CheckUserExistsInGroup(userId, groupId)
+// VerifyUser marks the user's email as verified
+func (u *UserServiceImpl) VerifyUser(ctx context.Context, userId string) (*expense.User, error) {
+	user, err := u.storage.FetchUserById(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+	user.IsVerified = true
+	updated, err := u.storage.UpdateUser(ctx, *user)
+	if err != nil {
+		return nil, err
+	}
+	updated.Password = ""
+	return updated, nil
+}
+
+func (u *UserServiceImpl) ResetPassword(ctx context.Context, userId string, password string) error {
+	user, err := u.storage.FetchUserById(ctx, userId)
+	if err != nil {
+		return err
+	}
+	user.Password = hashPassword(password)
+	_, err = u.storage.UpdateUser(ctx, *user)
+	return err
+}
+
+func (u *UserServiceImpl) JoinGroup(ctx context.Context, userId string, groupId string) (bool, error) {
+	return u.storage.AddUserInGroup(ctx, userId, groupId)
+}
+
+func (u *UserServiceImpl) LeaveGroup(ctx context.Context, userId string, groupId string) (bool, error) {
+	doesExist, err := u.storage.CheckUserExistsInGroup(ctx, userId, groupId)
 	if err != nil {
 		return false, err
 	}
@@ -61,11 +90,11 @@ func (u *UserServiceImpl) LeaveGroup(userId string, groupId string) (bool, error
 		return false, errors.New("user does not exist in group")
 	}
 
-	return u.storage.RemoveUserFromGroup(userId, groupId)
+	return u.storage.RemoveUserFromGroup(ctx, userId, groupId)
 }
 
-func (u *UserServiceImpl) CreateGroup(userId string, name string, description string) (*expense.Group, error) {
-	groups, err := u.storage.FetchGroupsByUser(userId)
+func (u *UserServiceImpl) CreateGroup(ctx context.Context, userId string, name string, description string) (*expense.Group, error) {
+	groups, err := u.storage.FetchGroupsByUser(ctx, userId)
 	if err != nil {
 		return nil, err
 	}
@@ -81,12 +110,12 @@ func (u *UserServiceImpl) CreateGroup(userId string, name string, description st
 		Description: description,
 		Admin:       userId,
 	}
-	updatedGroup, err := u.storage.CreateOrUpdateGroup(group)
+	updatedGroup, err := u.storage.CreateOrUpdateGroup(ctx, group)
 	if err != nil {
 		return nil, err
 	}
 
-	_, err = u.storage.AddUserInGroup(userId, updatedGroup.Id)
+	_, err = u.storage.AddUserInGroup(ctx, userId, updatedGroup.Id)
 	if err != nil {
 		return nil, err
 	}
@@ -94,12 +123,27 @@ func (u *UserServiceImpl) CreateGroup(userId string, name string, description st
 	return updatedGroup, nil
 }
 
-func (u *UserServiceImpl) GetAssociatedGroups(userId string) ([]expense.Group, error) {
-	return u.storage.FetchGroupsByUser(userId)
+func (u *UserServiceImpl) UpdateGroup(ctx context.Context, group expense.Group) (*expense.Group, error) {
+	existing, err := u.storage.FetchGroupById(ctx, group.Id)
+	if err != nil {
+		return nil, err
+	}
+	if existing.Admin != group.Admin {
+		return nil, errors.New("group admin cannot be changed")
+	}
+	return u.storage.CreateOrUpdateGroup(ctx, group)
+}
+
+func (u *UserServiceImpl) GetAssociatedGroups(ctx context.Context, userId string) ([]expense.Group, error) {
+	return u.storage.FetchGroupsByUser(ctx, userId)
+}
+
+func (u *UserServiceImpl) GetAssociatedGroupsPage(ctx context.Context, userId string, page expense.PageRequest) (*expense.GroupPage, error) {
+	return u.storage.FetchGroupsByUserPage(ctx, userId, page)
 }
 
-func (u *UserServiceImpl) FetchUserCredentials(email string) (*expense.User, error) {
-	return u.storage.FetchUserByEmail(email)
+func (u *UserServiceImpl) FetchUserCredentials(ctx context.Context, email string) (*expense.User, error) {
+	return u.storage.FetchUserByEmail(ctx, email)
 }
 
 func NewUserServiceImpl(cfg *config.Config, storage expense.Storage) *UserServiceImpl {
@@ -114,8 +158,8 @@ type AssociatedUsers struct {
 	Users []expense.User
 }
 
-func (u *UserServiceImpl) GetGroupById(groupId string) (*expense.Group, error) {
-	group, err := u.storage.FetchGroupById(groupId)
+func (u *UserServiceImpl) GetGroupById(ctx context.Context, groupId string) (*expense.Group, error) {
+	group, err := u.storage.FetchGroupById(ctx, groupId)
 	if err != nil {
 
 		return nil, err
@@ -124,13 +168,13 @@ func (u *UserServiceImpl) GetGroupById(groupId string) (*expense.Group, error) {
 	return group, nil
 }
 
-func (u *UserServiceImpl) GetAssociatedUsers(groupId string) (*AssociatedUsers, error) {
-	group, err := u.storage.FetchGroupById(groupId)
+func (u *UserServiceImpl) GetAssociatedUsers(ctx context.Context, groupId string) (*AssociatedUsers, error) {
+	group, err := u.storage.FetchGroupById(ctx, groupId)
 	if err != nil {
 		return nil, err
 	}
 
-	users, err := u.storage.FetchGroupMembers(groupId)
+	users, err := u.storage.FetchGroupMembers(ctx, groupId)
 	if err != nil {
 		return nil, err
 	}
@@ -141,23 +185,67 @@ func (u *UserServiceImpl) GetAssociatedUsers(groupId string) (*AssociatedUsers,
 }
 
 // AddFriend implements the UserService interface
-func (us *UserServiceImpl) AddFriend(userId string, friendId string) (bool, error) {
+func (us *UserServiceImpl) AddFriend(ctx context.Context, userId string, friendId string) (bool, error) {
 
-	return us.storage.AddFriend(userId, friendId)
+	return us.storage.AddFriend(ctx, userId, friendId)
 }
 
 // GetFriends implements the UserService interface
-func (us *UserServiceImpl) GetFriends(userId string) ([]expense.User, error) {
+func (us *UserServiceImpl) GetFriends(ctx context.Context, userId string) ([]expense.User, error) {
 
-	return us.storage.GetFriends(userId)
+	return us.storage.GetFriends(ctx, userId)
+}
+
+// GetFriendsPage implements the UserService interface
+func (us *UserServiceImpl) GetFriendsPage(ctx context.Context, userId string, page expense.PageRequest) (*expense.UserPage, error) {
+
+	return us.storage.GetFriendsPage(ctx, userId, page)
 }
 
 // GetFriend implements the UserService interface
-func (us *UserServiceImpl) GetFriend(userId string, friendId string) (*expense.User, error) {
+func (us *UserServiceImpl) GetFriend(ctx context.Context, userId string, friendId string) (*expense.User, error) {
 
-	return us.storage.GetFriend(userId, friendId)
+	return us.storage.GetFriend(ctx, userId, friendId)
 }
 
-func (us *UserServiceImpl) DeleteGroup(groupId string) (bool, error) {
-	return us.storage.DeleteGroup(groupId)
+// DeleteGroup archives the group, its memberships and expenses in one
+// transaction. The refuse policy leaves everything in place while the group
+// has unsettled expenses. The group is locked before anything is counted, so
+// an expense added meanwhile can not slip past the policy.
+func (us *UserServiceImpl) DeleteGroup(ctx context.Context, groupId string, policy expense.GroupDeletePolicy) (*expense.GroupDeletion, error) {
+	report := &expense.GroupDeletion{GroupId: groupId, Policy: policy}
+	err := us.storage.RunInTx(ctx, func(ctx context.Context) error {
+		found, err := us.storage.LockGroup(ctx, groupId)
+		if err != nil || !found {
+			return err
+		}
+		members, err := us.storage.FetchGroupMembers(ctx, groupId)
+		if err != nil {
+			return err
+		}
+		expenses, err := us.storage.FetchExpenseCountByGroup(ctx, groupId)
+		if err != nil {
+			return err
+		}
+		unsettled, err := us.storage.CountUnsettledGroupExpenses(ctx, groupId)
+		if err != nil {
+			return err
+		}
+		report.Members = len(members)
+		report.UnsettledExpenses = unsettled
+
+		if policy == expense.GroupDeleteRefuse && unsettled > 0 {
+			return &expense.UnsettledGroupError{Report: *report}
+		}
+
+		report.Deleted, err = us.storage.DeleteGroup(ctx, groupId)
+		if report.Deleted {
+			report.ArchivedExpenses = expenses
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return report, nil
 }
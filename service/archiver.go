@@ -0,0 +1,69 @@
+package service
+
+import (
+	"context"
+	"log"
+	"splitExpense/config"
+	"splitExpense/expense"
+	"time"
+)
+
+// Archiver moves settled expenses that have not changed for the configured
+// number of months into the archive tables. Running balances only count
+// draft expenses, so archiving never changes what anyone owes.
+type Archiver struct {
+	config  *config.Config
+	storage expense.Storage
+}
+
+func NewArchiver(cfg *config.Config, storage expense.Storage) *Archiver {
+	return &Archiver{
+		config:  cfg,
+		storage: storage,
+	}
+}
+
+// ArchiveOnce archives every eligible expense in batches and returns how many were moved.
+func (a *Archiver) ArchiveOnce(ctx context.Context) (int, error) {
+	if a.config.ArchiveAfterMonths <= 0 {
+		return 0, nil
+	}
+	settledBefore := time.Now().AddDate(0, -a.config.ArchiveAfterMonths, 0)
+	batchSize := a.config.ArchiveBatchSize
+	if batchSize <= 0 {
+		batchSize = 500
+	}
+
+	total := 0
+	for {
+		moved, err := a.storage.ArchiveSettledExpenses(ctx, settledBefore, batchSize)
+		total += moved
+		if err != nil || moved < batchSize {
+			return total, err
+		}
+	}
+}
+
+// Run archives once per ArchiveInterval until ctx is cancelled.
+func (a *Archiver) Run(ctx context.Context) {
+	if a.config.ArchiveAfterMonths <= 0 || a.config.ArchiveInterval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(a.config.ArchiveInterval)
+	defer ticker.Stop()
+
+	for {
+		moved, err := a.ArchiveOnce(ctx)
+		if err != nil {
+			log.Println("archiving settled expenses failed: ", err)
+		} else if moved > 0 {
+			log.Printf("archived %d settled expenses", moved)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -1,7 +1,10 @@
 package service
 
 import (
+	"context"
 	expense "splitExpense/expense"
+	"splitExpense/importer"
+	"time"
 )
 
 type UserExpenses struct {
@@ -10,6 +13,8 @@ type UserExpenses struct {
 	TotalBorrowed float64                   `json:"totalBorrowed"`
 	PageNumber    int                       `json:"pageNumber"`
 	TotalPages    int                       `json:"totalPages"`
+	NextCursor    string                    `json:"nextCursor"`
+	HasMore       bool                      `json:"hasMore"`
 }
 
 type GroupWithExpense struct {
@@ -31,37 +36,90 @@ type GroupDetail struct {
 	GroupMembers []expense.User   `json:"groupMembers"`
 }
 
+// Balances is what a user is owed and owes on unsettled expenses, overall
+// and per group
+type Balances struct {
+	TotalOwed     float64        `json:"totalOwed"`
+	TotalBorrowed float64        `json:"totalBorrowed"`
+	Groups        []GroupBalance `json:"groups"`
+}
+
+// RunningBalance is what a user is owed and owes on unsettled expenses
+type RunningBalance struct {
+	TotalOwed     float64 `json:"totalOwed"`
+	TotalBorrowed float64 `json:"totalBorrowed"`
+}
+
+type GroupBalance struct {
+	Group         expense.Group `json:"group"`
+	TotalOwed     float64       `json:"totalOwed"`
+	TotalBorrowed float64       `json:"totalBorrowed"`
+}
+
+// ImportReport previews an import, or reports what it created once committed
+type ImportReport struct {
+	Expenses []ImportedExpense `json:"expenses"`
+	// People maps every name of the file to the user it was matched to
+	People    map[string]string  `json:"people"`
+	Problems  []importer.Problem `json:"problems"`
+	Skipped   []importer.Problem `json:"skipped"`
+	Committed bool               `json:"committed"`
+}
+
+type ImportedExpense struct {
+	Line int `json:"line"`
+	// ID is set once the expense is created
+	ID          string               `json:"id,omitempty"`
+	Date        time.Time            `json:"date"`
+	Description string               `json:"description"`
+	Category    string               `json:"category"`
+	Amount      float64              `json:"amount"`
+	Split       expense.SplitWrapper `json:"split"`
+	Payee       expense.PayerWrapper `json:"payee"`
+	GroupId     string               `json:"groupId,omitempty"`
+}
+
 type Service interface {
 	GetUserService() *UserService
 	GetExpenseService() *ExpenseService
 }
 
 type UserService interface {
-	GetUser(id string) (*expense.User, error)
-	CreateUser(name string, email string, password string) (*expense.User, error)
-	AddFriend(userId string, friendId string) (bool, error)
-	GetFriends(userId string) ([]expense.User, error)
-	GetFriend(userId string, friendId string) (*expense.User, error)
-	JoinGroup(userId string, groupId string) (bool, error)
-	LeaveGroup(userId string, groupId string) (bool, error)
-	DeleteGroup(groupId string) (bool, error)
-	CreateGroup(userId string, name string, description string) (*expense.Group, error)
-	GetAssociatedGroups(userId string) ([]expense.Group, error)
-	FetchUserCredentials(email string) (*expense.User, error)
-	GetAssociatedUsers(groupId string) (*AssociatedUsers, error)
-	GetGroupById(groupId string) (*expense.Group, error)
+	GetUser(ctx context.Context, id string) (*expense.User, error)
+	CreateUser(ctx context.Context, name string, email string, password string) (*expense.User, error)
+	VerifyUser(ctx context.Context, userId string) (*expense.User, error)
+	ResetPassword(ctx context.Context, userId string, password string) error
+	AddFriend(ctx context.Context, userId string, friendId string) (bool, error)
+	GetFriends(ctx context.Context, userId string) ([]expense.User, error)
+	GetFriendsPage(ctx context.Context, userId string, page expense.PageRequest) (*expense.UserPage, error)
+	GetFriend(ctx context.Context, userId string, friendId string) (*expense.User, error)
+	JoinGroup(ctx context.Context, userId string, groupId string) (bool, error)
+	LeaveGroup(ctx context.Context, userId string, groupId string) (bool, error)
+	DeleteGroup(ctx context.Context, groupId string, policy expense.GroupDeletePolicy) (*expense.GroupDeletion, error)
+	CreateGroup(ctx context.Context, userId string, name string, description string) (*expense.Group, error)
+	UpdateGroup(ctx context.Context, group expense.Group) (*expense.Group, error)
+	GetAssociatedGroups(ctx context.Context, userId string) ([]expense.Group, error)
+	GetAssociatedGroupsPage(ctx context.Context, userId string, page expense.PageRequest) (*expense.GroupPage, error)
+	FetchUserCredentials(ctx context.Context, email string) (*expense.User, error)
+	GetAssociatedUsers(ctx context.Context, groupId string) (*AssociatedUsers, error)
+	GetGroupById(ctx context.Context, groupId string) (*expense.Group, error)
 }
 
 type ExpenseService interface {
-	FetchExpense(id string) (*expense.Expense, error)
-	CreateExpense(userId string, expense expense.ExpenseCreate) (*expense.Expense, error)
-	UpdateExpense(userId string, expense expense.Expense) (*expense.Expense, error)
-	DeleteExpense(userId string, expenseId string) (bool, error)
-	SettleExpense(userId string, expenseId string) (*expense.Expense, error)
-	FetchExpenseByGroup(userId string, groupId string, pageNumber int) (*expense.GroupExpenseHistory, error)
-	FetchExpenseCountByGroup(groupId string) (int, error)
-	FetchActiveUserExpenses(userId string, pageNumber int) (*expense.GroupExpenseHistory, error)
-	CalculateUserRunningExpensesInGroup(userId string, group *expense.Group) (float64, float64, error)
-	CalculateAllUserRunningExpenses(userId string) (float64, float64, error)
+	FetchExpense(ctx context.Context, id string) (*expense.Expense, error)
+	CreateExpense(ctx context.Context, userId string, expense expense.ExpenseCreate) (*expense.Expense, error)
+	CreateExpenses(ctx context.Context, userId string, expenses []expense.ExpenseCreate) ([]expense.Expense, error)
+	UpdateExpense(ctx context.Context, userId string, expense expense.Expense) (*expense.Expense, error)
+	DeleteExpense(ctx context.Context, userId string, expenseId string) (bool, error)
+	SettleExpense(ctx context.Context, userId string, expenseId string) (*expense.Expense, error)
+	FetchExpenseByGroup(ctx context.Context, userId string, groupId string, pageNumber int) (*expense.GroupExpenseHistory, error)
+	FetchExpenseByGroupPage(ctx context.Context, userId string, groupId string, includeArchived bool, page expense.PageRequest) (*expense.GroupExpenseHistory, error)
+	FetchExpenseCountByGroup(ctx context.Context, groupId string) (int, error)
+	FetchActiveUserExpenses(ctx context.Context, userId string, pageNumber int) (*expense.GroupExpenseHistory, error)
+	FetchActiveUserExpensesPage(ctx context.Context, userId string, page expense.PageRequest) (*expense.GroupExpenseHistory, error)
+	CalculateUserRunningExpensesInGroup(ctx context.Context, userId string, group *expense.Group) (float64, float64, error)
+	CalculateAllUserRunningExpenses(ctx context.Context, userId string) (float64, float64, error)
+	CalculateUserRunningExpensesByGroup(ctx context.Context, userId string) (map[string]RunningBalance, error)
+	SearchExpenses(ctx context.Context, userId string, search expense.ExpenseSearch, page expense.PageRequest) (*expense.GroupExpenseHistory, error)
 	// GetExpenseHistory(id string) (*expense.ExpenseHistory, error)
 }
@@ -0,0 +1,103 @@
+package storage
+
+import (
+	"context"
+
+	"splitExpense/db"
+	models "splitExpense/expense"
+
+	"github.com/google/uuid"
+)
+
+// The batch reads back the GraphQL loaders, each resolves every key collected
+// while executing one level of a query in a single statement. Ids that are not
+// uuids or match no row are missing from the result.
+
+func parseIds(ids []string) []uuid.UUID {
+	parsed := make([]uuid.UUID, 0, len(ids))
+	for _, id := range ids {
+		if u, err := uuid.Parse(id); err == nil {
+			parsed = append(parsed, u)
+		}
+	}
+	return parsed
+}
+
+// FetchUsersByIds returns the users by id, without their password
+func (d *DBStorage) FetchUsersByIds(ctx context.Context, ids []string) (map[string]models.User, error) {
+	rows, err := d.reader(ctx).FetchUsersByIds(ctx, parseIds(ids))
+	if err != nil {
+		return nil, err
+	}
+	users := make(map[string]models.User, len(rows))
+	for _, u := range rows {
+		users[u.ID.String()] = models.User{ID: u.ID.String(), Name: u.Name, Email: u.Email, IsVerified: u.IsVerified}
+	}
+	return users, nil
+}
+
+func (d *DBStorage) FetchGroupsByIds(ctx context.Context, ids []string) (map[string]models.Group, error) {
+	rows, err := d.reader(ctx).FetchGroupsByIds(ctx, parseIds(ids))
+	if err != nil {
+		return nil, err
+	}
+	groups := make(map[string]models.Group, len(rows))
+	for _, g := range rows {
+		groups[g.ID.String()] = models.Group{
+			Id:          g.ID.String(),
+			Name:        g.Name,
+			Description: g.Description,
+			Admin:       g.AdminID.String(),
+			Version:     int(g.Version),
+			CreatedAt:   g.CreatedAt,
+		}
+	}
+	return groups, nil
+}
+
+// FetchGroupsExpensesPage returns a page of expenses of each group, every
+// group asked for gets one even when it has no expenses
+func (d *DBStorage) FetchGroupsExpensesPage(ctx context.Context, groupIds []string, includeArchived bool, page models.PageRequest) (map[string]*models.StoredGroupExpenseHistory, error) {
+	cursorCreatedAt, cursorId, err := keysetParams(page)
+	if err != nil {
+		return nil, err
+	}
+	size := page.Size()
+	rows, err := d.reader(ctx).FetchGroupsExpensesPage(ctx, db.FetchGroupsExpensesPageParams{
+		GroupIds:        parseIds(groupIds),
+		IncludeArchived: includeArchived,
+		CursorCreatedAt: cursorCreatedAt,
+		CursorID:        cursorId,
+		PageLimit:       int64(size + 1),
+	})
+	if err != nil {
+		return nil, err
+	}
+	byGroup := make(map[string][]db.Expense, len(groupIds))
+	for _, row := range expenseRows(rows) {
+		groupId := row.GroupID.UUID.String()
+		byGroup[groupId] = append(byGroup[groupId], row)
+	}
+	pages := make(map[string]*models.StoredGroupExpenseHistory, len(groupIds))
+	for _, groupId := range groupIds {
+		pages[groupId], err = d.getExpensePageFromRows(byGroup[groupId], size)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return pages, nil
+}
+
+// FetchGroupMembersByGroupIds returns the members of each group, without their password
+func (d *DBStorage) FetchGroupMembersByGroupIds(ctx context.Context, groupIds []string) (map[string][]models.User, error) {
+	rows, err := d.reader(ctx).FetchGroupMembersByGroupIds(ctx, parseIds(groupIds))
+	if err != nil {
+		return nil, err
+	}
+	members := make(map[string][]models.User, len(groupIds))
+	for _, row := range rows {
+		groupId := row.GroupID.String()
+		members[groupId] = append(members[groupId], models.User{ID: row.ID.String(), Name: row.Name, Email: row.Email, IsVerified: row.IsVerified})
+	}
+	return members, nil
+}
@@ -0,0 +1,84 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"splitExpense/db"
+
+	"github.com/google/uuid"
+)
+
+// IdempotencyRecord is the first request made with an Idempotency-Key
+type IdempotencyRecord struct {
+	RequestHash string
+	// Status is zero while the request is still running
+	Status      int
+	ContentType string
+	Response    []byte
+	ExpiresAt   time.Time
+}
+
+// ReserveIdempotencyKey claims key for userId until expiresAt. When the key
+// is already claimed the record of that request is returned instead.
+func (d *DBStorage) ReserveIdempotencyKey(ctx context.Context, userId string, key string, requestHash string, expiresAt time.Time) (*IdempotencyRecord, bool, error) {
+	uid, err := uuid.Parse(userId)
+	if err != nil {
+		return nil, false, err
+	}
+	_, err = d.q(ctx).ReserveIdempotencyKey(ctx, db.ReserveIdempotencyKeyParams{
+		UserID:         uid,
+		IdempotencyKey: key,
+		RequestHash:    requestHash,
+		ExpiresAt:      expiresAt,
+	})
+	if err == nil {
+		return nil, true, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, false, err
+	}
+
+	row, err := d.q(ctx).FetchIdempotencyKey(ctx, db.FetchIdempotencyKeyParams{UserID: uid, IdempotencyKey: key})
+	if err != nil {
+		return nil, false, err
+	}
+	return &IdempotencyRecord{
+		RequestHash: row.RequestHash,
+		Status:      int(row.Status.Int32),
+		ContentType: row.ContentType,
+		Response:    row.Response,
+		ExpiresAt:   row.ExpiresAt,
+	}, false, nil
+}
+
+// CompleteIdempotencyKey stores the response replayed for key
+func (d *DBStorage) CompleteIdempotencyKey(ctx context.Context, userId string, key string, status int, contentType string, response []byte) error {
+	uid, err := uuid.Parse(userId)
+	if err != nil {
+		return err
+	}
+	return d.q(ctx).CompleteIdempotencyKey(ctx, db.CompleteIdempotencyKeyParams{
+		Status:         sql.NullInt32{Int32: int32(status), Valid: true},
+		ContentType:    contentType,
+		Response:       response,
+		UserID:         uid,
+		IdempotencyKey: key,
+	})
+}
+
+// ReleaseIdempotencyKey forgets key, the next request with it runs again
+func (d *DBStorage) ReleaseIdempotencyKey(ctx context.Context, userId string, key string) error {
+	uid, err := uuid.Parse(userId)
+	if err != nil {
+		return err
+	}
+	return d.q(ctx).ReleaseIdempotencyKey(ctx, db.ReleaseIdempotencyKeyParams{UserID: uid, IdempotencyKey: key})
+}
+
+func (d *DBStorage) PruneIdempotencyKeys(ctx context.Context) (int, error) {
+	deleted, err := d.q(ctx).DeleteExpiredIdempotencyKeys(ctx)
+	return int(deleted), err
+}
@@ -0,0 +1,275 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"splitExpense/db"
+	"splitExpense/expense"
+	"splitExpense/ledger"
+
+	"github.com/google/uuid"
+)
+
+func (d *DBStorage) AppendEvent(ctx context.Context, event expense.Event) (*expense.Event, error) {
+	id, err := uuid.Parse(event.ID)
+	if err != nil {
+		return nil, err
+	}
+	aggregateId, err := uuid.Parse(event.AggregateID)
+	if err != nil {
+		return nil, err
+	}
+	actorId, err := nullUUID(event.ActorID)
+	if err != nil {
+		return nil, err
+	}
+
+	row, err := d.q(ctx).AppendLedgerEvent(ctx, db.AppendLedgerEventParams{
+		ID:               id,
+		Type:             string(event.Type),
+		AggregateType:    event.AggregateType,
+		AggregateID:      aggregateId,
+		AggregateVersion: int32(event.AggregateVersion),
+		Payload:          event.Payload,
+		ActorID:          actorId,
+	})
+	if err != nil {
+		return nil, err
+	}
+	appended := eventFromRow(row)
+	return &appended, nil
+}
+
+func (d *DBStorage) LoadEvents(ctx context.Context, afterSequence int64, limit int) ([]expense.Event, error) {
+	rows, err := d.q(ctx).LoadLedgerEvents(ctx, db.LoadLedgerEventsParams{AfterSequence: afterSequence, PageLimit: int32(limit)})
+	if err != nil {
+		return nil, err
+	}
+	return eventsFromRows(rows), nil
+}
+
+func (d *DBStorage) LoadAggregateEvents(ctx context.Context, aggregateType string, aggregateId string) ([]expense.Event, error) {
+	id, err := uuid.Parse(aggregateId)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := d.q(ctx).LoadAggregateEvents(ctx, db.LoadAggregateEventsParams{AggregateType: aggregateType, AggregateID: id})
+	if err != nil {
+		return nil, err
+	}
+	return eventsFromRows(rows), nil
+}
+
+func eventsFromRows(rows []db.LedgerEvent) []expense.Event {
+	events := []expense.Event{}
+	for _, row := range rows {
+		events = append(events, eventFromRow(row))
+	}
+	return events
+}
+
+func eventFromRow(row db.LedgerEvent) expense.Event {
+	var actorId string
+	if row.ActorID.Valid {
+		actorId = row.ActorID.UUID.String()
+	}
+	return expense.Event{
+		Sequence:         row.Sequence,
+		ID:               row.ID.String(),
+		Type:             expense.EventType(row.Type),
+		AggregateType:    row.AggregateType,
+		AggregateID:      row.AggregateID.String(),
+		AggregateVersion: int(row.AggregateVersion),
+		Payload:          row.Payload,
+		ActorID:          actorId,
+		OccurredAt:       row.OccurredAt,
+	}
+}
+
+func (d *DBStorage) BootstrapEvents(ctx context.Context) (int, error) {
+	recorded := 0
+	err := d.RunInTx(ctx, func(ctx context.Context) error {
+		if err := d.q(ctx).LockLedger(ctx); err != nil {
+			return err
+		}
+		// groups before members and expenses, so replay creates rows in foreign key order
+		for _, bootstrap := range []func(context.Context) (int64, error){
+			d.q(ctx).BootstrapGroupEvents,
+			d.q(ctx).BootstrapMemberEvents,
+			d.q(ctx).BootstrapExpenseEvents,
+			d.q(ctx).BootstrapParticipantEvents,
+			d.q(ctx).BootstrapArchivedExpenseEvents,
+			d.q(ctx).BootstrapGroupDeletedEvents,
+		} {
+			n, err := bootstrap(ctx)
+			if err != nil {
+				return err
+			}
+			recorded += int(n)
+		}
+		return nil
+	})
+	return recorded, err
+}
+
+func (d *DBStorage) CountEventsByType(ctx context.Context) (map[expense.EventType]int, error) {
+	rows, err := d.q(ctx).CountLedgerEventsByType(ctx)
+	if err != nil {
+		return nil, err
+	}
+	counts := map[expense.EventType]int{}
+	for _, row := range rows {
+		counts[expense.EventType(row.Type)] = int(row.Count)
+	}
+	return counts, nil
+}
+
+func (d *DBStorage) FetchExpenseParticipants(ctx context.Context, expenseId string) ([]string, error) {
+	eid, err := uuid.Parse(expenseId)
+	if err != nil {
+		return nil, err
+	}
+	ids, err := d.q(ctx).FetchExpenseParticipants(ctx, eid)
+	if err != nil {
+		return nil, err
+	}
+	userIds := []string{}
+	for _, id := range ids {
+		userIds = append(userIds, id.String())
+	}
+	return userIds, nil
+}
+
+func (d *DBStorage) ResetExpenseTables(ctx context.Context) error {
+	return d.RunInTx(ctx, func(ctx context.Context) error {
+		q := d.q(ctx)
+		for _, reset := range []func(context.Context) error{
+			q.ResetExpenseMappingArchive,
+			q.ResetExpenseArchive,
+			q.ResetExpenseMappings,
+			q.ResetExpenses,
+			q.ResetGroupMembers,
+			q.ResetGroups,
+			q.ResetGroupMembersArchive,
+			q.ResetGroupArchive,
+		} {
+			if err := reset(ctx); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (d *DBStorage) ReplaceBalanceEntries(ctx context.Context, expenseId string, entries []ledger.BalanceEntry) error {
+	eid, err := uuid.Parse(expenseId)
+	if err != nil {
+		return err
+	}
+	return d.RunInTx(ctx, func(ctx context.Context) error {
+		if err := d.q(ctx).DeleteBalanceEntries(ctx, eid); err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			uid, err := uuid.Parse(entry.UserId)
+			if err != nil {
+				return err
+			}
+			groupId, err := nullUUID(entry.GroupId)
+			if err != nil {
+				return err
+			}
+			err = d.q(ctx).InsertBalanceEntry(ctx, db.InsertBalanceEntryParams{
+				ExpenseID: eid,
+				UserID:    uid,
+				GroupID:   groupId,
+				Category:  entry.Category,
+				Status:    string(entry.Status),
+				Paid:      fmt.Sprintf("%f", entry.Paid),
+				Share:     fmt.Sprintf("%f", entry.Share),
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (d *DBStorage) InsertBalanceEntries(ctx context.Context, entries []ledger.BalanceEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	params := db.InsertBalanceEntriesParams{}
+	for _, entry := range entries {
+		eid, err := uuid.Parse(entry.ExpenseId)
+		if err != nil {
+			return err
+		}
+		uid, err := uuid.Parse(entry.UserId)
+		if err != nil {
+			return err
+		}
+		params.ExpenseIds = append(params.ExpenseIds, eid)
+		params.UserIds = append(params.UserIds, uid)
+		params.GroupIds = append(params.GroupIds, entry.GroupId)
+		params.Categories = append(params.Categories, entry.Category)
+		params.Statuses = append(params.Statuses, string(entry.Status))
+		params.Paid = append(params.Paid, fmt.Sprintf("%f", entry.Paid))
+		params.Shares = append(params.Shares, fmt.Sprintf("%f", entry.Share))
+	}
+	return d.q(ctx).InsertBalanceEntries(ctx, params)
+}
+
+func (d *DBStorage) DeleteGroupBalanceEntries(ctx context.Context, groupId string) error {
+	gid, err := uuid.Parse(groupId)
+	if err != nil {
+		return err
+	}
+	return d.q(ctx).DeleteGroupBalanceEntries(ctx, uuid.NullUUID{UUID: gid, Valid: true})
+}
+
+func (d *DBStorage) ResetBalanceEntries(ctx context.Context) error {
+	return d.q(ctx).ResetBalanceEntries(ctx)
+}
+
+func (d *DBStorage) FetchBalances(ctx context.Context, userId string) ([]ledger.Balance, error) {
+	uid, err := uuid.Parse(userId)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := d.reader(ctx).FetchLedgerBalances(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+	balances := []ledger.Balance{}
+	for _, row := range rows {
+		var groupId string
+		if row.GroupID.Valid {
+			groupId = row.GroupID.UUID.String()
+		}
+		paid, _ := strconv.ParseFloat(row.Paid, 64)
+		share, _ := strconv.ParseFloat(row.Share, 64)
+		balances = append(balances, ledger.Balance{GroupId: groupId, Paid: paid, Share: share})
+	}
+	return balances, nil
+}
+
+func (d *DBStorage) FetchCategoryTotals(ctx context.Context, userId string) ([]ledger.CategoryTotal, error) {
+	uid, err := uuid.Parse(userId)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := d.reader(ctx).FetchLedgerCategoryTotals(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+	totals := []ledger.CategoryTotal{}
+	for _, row := range rows {
+		total, _ := strconv.ParseFloat(row.Total, 64)
+		totals = append(totals, ledger.CategoryTotal{Category: row.Category, Total: total})
+	}
+	return totals, nil
+}
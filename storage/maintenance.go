@@ -0,0 +1,71 @@
+package storage
+
+import (
+	"context"
+
+	"splitExpense/db"
+	models "splitExpense/expense"
+
+	"github.com/google/uuid"
+)
+
+// NonMemberParticipant is a participant of a group expense outside the group
+type NonMemberParticipant struct {
+	ExpenseId string
+	GroupId   string
+	UserId    string
+}
+
+// ScanExpenses calls fn for every live expense in id order, reading batch
+// rows at a time. It stops at the first error fn returns.
+func (d *DBStorage) ScanExpenses(ctx context.Context, batch int, fn func(models.Expense) error) error {
+	after := uuid.Nil
+	for {
+		rows, err := d.reader(ctx).FetchExpensesAfterId(ctx, db.FetchExpensesAfterIdParams{AfterID: after, PageLimit: int32(batch)})
+		if err != nil {
+			return err
+		}
+		expenses, err := d.GetStoredGroupExpenseFromRows(rows, 0, 0)
+		if err != nil {
+			return err
+		}
+		for _, exp := range expenses.Expenses {
+			if err := fn(exp); err != nil {
+				return err
+			}
+		}
+		if len(rows) < batch {
+			return nil
+		}
+		after = rows[len(rows)-1].ID
+	}
+}
+
+// FetchGroupsWithoutAdminMember returns the groups whose admin is not a member, with Id and Admin set
+func (d *DBStorage) FetchGroupsWithoutAdminMember(ctx context.Context) ([]models.Group, error) {
+	rows, err := d.reader(ctx).FetchGroupsWithoutAdminMember(ctx)
+	if err != nil {
+		return nil, err
+	}
+	groups := []models.Group{}
+	for _, row := range rows {
+		groups = append(groups, models.Group{Id: row.ID.String(), Admin: row.AdminID.String()})
+	}
+	return groups, nil
+}
+
+func (d *DBStorage) FetchGroupExpenseNonMembers(ctx context.Context) ([]NonMemberParticipant, error) {
+	rows, err := d.reader(ctx).FetchGroupExpenseNonMembers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	participants := []NonMemberParticipant{}
+	for _, row := range rows {
+		participants = append(participants, NonMemberParticipant{
+			ExpenseId: row.ExpenseID.String(),
+			GroupId:   row.GroupID.UUID.String(),
+			UserId:    row.UserID.String(),
+		})
+	}
+	return participants, nil
+}
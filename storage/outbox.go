@@ -0,0 +1,87 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"time"
+
+	"splitExpense/db"
+	"splitExpense/expense"
+	"splitExpense/outbox"
+
+	"github.com/google/uuid"
+)
+
+func (d *DBStorage) Enqueue(ctx context.Context, event expense.Event) error {
+	id, err := uuid.Parse(event.ID)
+	if err != nil {
+		return err
+	}
+	aggregateId, err := uuid.Parse(event.AggregateID)
+	if err != nil {
+		return err
+	}
+	actorId, err := nullUUID(event.ActorID)
+	if err != nil {
+		return err
+	}
+	return d.q(ctx).EnqueueOutboxEvent(ctx, db.EnqueueOutboxEventParams{
+		ID:               id,
+		Type:             string(event.Type),
+		AggregateType:    event.AggregateType,
+		AggregateID:      aggregateId,
+		AggregateVersion: int32(event.AggregateVersion),
+		Payload:          event.Payload,
+		ActorID:          actorId,
+	})
+}
+
+func (d *DBStorage) ClaimDue(ctx context.Context, limit int, leasedUntil time.Time) ([]outbox.Message, error) {
+	rows, err := d.q(ctx).ClaimOutboxEvents(ctx, db.ClaimOutboxEventsParams{BatchSize: int32(limit), LeasedUntil: leasedUntil})
+	if err != nil {
+		return nil, err
+	}
+	// RETURNING leaves the rows in any order
+	sort.Slice(rows, func(i, j int) bool { return rows[i].Sequence < rows[j].Sequence })
+	messages := []outbox.Message{}
+	for _, row := range rows {
+		var actorId string
+		if row.ActorID.Valid {
+			actorId = row.ActorID.UUID.String()
+		}
+		messages = append(messages, outbox.Message{
+			Event: expense.Event{
+				Sequence:         row.Sequence,
+				ID:               row.ID.String(),
+				Type:             expense.EventType(row.Type),
+				AggregateType:    row.AggregateType,
+				AggregateID:      row.AggregateID.String(),
+				AggregateVersion: int(row.AggregateVersion),
+				Payload:          row.Payload,
+				ActorID:          actorId,
+				OccurredAt:       row.OccurredAt,
+			},
+			Attempts: int(row.Attempts),
+		})
+	}
+	return messages, nil
+}
+
+func (d *DBStorage) MarkDelivered(ctx context.Context, sequence int64) error {
+	return d.q(ctx).MarkOutboxEventDelivered(ctx, sequence)
+}
+
+func (d *DBStorage) MarkFailed(ctx context.Context, sequence int64, reason string, retryAt time.Time, dead bool) error {
+	return d.q(ctx).MarkOutboxEventFailed(ctx, db.MarkOutboxEventFailedParams{
+		LastError:     sql.NullString{String: reason, Valid: reason != ""},
+		NextAttemptAt: retryAt,
+		Dead:          dead,
+		Sequence:      sequence,
+	})
+}
+
+func (d *DBStorage) PruneDelivered(ctx context.Context, deliveredBefore time.Time) (int, error) {
+	deleted, err := d.q(ctx).DeleteDeliveredOutboxEvents(ctx, sql.NullTime{Time: deliveredBefore, Valid: true})
+	return int(deleted), err
+}
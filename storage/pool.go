@@ -0,0 +1,140 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	"splitExpense/config"
+	"splitExpense/db"
+
+	"github.com/lib/pq"
+)
+
+type staleReadsKey struct{}
+
+// AllowStaleReads marks ctx so reads made with it may be served by the read
+// replica. Only use it where lagging a few seconds behind the primary is
+// fine, never to read back something the same request just wrote.
+func AllowStaleReads(ctx context.Context) context.Context {
+	return context.WithValue(ctx, staleReadsKey{}, true)
+}
+
+func staleReadsAllowed(ctx context.Context) bool {
+	allowed, _ := ctx.Value(staleReadsKey{}).(bool)
+	return allowed
+}
+
+// reader returns the replica queries when one is configured and ctx allows
+// stale reads, reads inside a transaction always stay on it
+func (d *DBStorage) reader(ctx context.Context) *db.Queries {
+	if _, inTx := ctx.Value(txKey{}).(*sql.Tx); !inTx && d.replica != nil && staleReadsAllowed(ctx) {
+		return d.replica
+	}
+	return d.q(ctx)
+}
+
+// configurePool applies the pool limits from config, zero values keep the database/sql defaults
+func configurePool(pool *sql.DB, cfg *config.Config) {
+	if cfg.MaxOpenConns > 0 {
+		pool.SetMaxOpenConns(cfg.MaxOpenConns)
+	}
+	if cfg.MaxIdleConns > 0 {
+		pool.SetMaxIdleConns(cfg.MaxIdleConns)
+	}
+	if cfg.ConnMaxLifetime > 0 {
+		pool.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	}
+	if cfg.ConnMaxIdleTime > 0 {
+		pool.SetConnMaxIdleTime(cfg.ConnMaxIdleTime)
+	}
+}
+
+// withStatementTimeout adds statement_timeout to a key/value or URL dsn, lib/pq
+// passes unknown parameters on to postgres as session settings
+func withStatementTimeout(dsn string, timeout time.Duration) (string, error) {
+	if timeout <= 0 {
+		return dsn, nil
+	}
+	ms := fmt.Sprint(timeout.Milliseconds())
+	if !strings.HasPrefix(dsn, "postgres://") && !strings.HasPrefix(dsn, "postgresql://") {
+		return dsn + " statement_timeout=" + ms, nil
+	}
+	u, err := url.Parse(dsn)
+	if err != nil {
+		return "", err
+	}
+	q := u.Query()
+	q.Set("statement_timeout", ms)
+	u.RawQuery = q.Encode()
+	return u.String(), nil
+}
+
+// retryingConnector retries opening a connection while postgres is unreachable
+// or still starting up, e.g. during a restart or failover. Nothing has been
+// sent on a connection that failed to open, so retrying is always safe.
+// database/sql already retries statements on pooled connections that went bad.
+// The waits end early when ctx is done, so a request is not held past its deadline.
+type retryingConnector struct {
+	connector driver.Connector
+	retries   int
+	backoff   time.Duration
+}
+
+func newRetryingConnector(dsn string, retries int, backoff time.Duration) (*retryingConnector, error) {
+	connector, err := pq.NewConnector(dsn)
+	if err != nil {
+		return nil, err
+	}
+	return &retryingConnector{connector: connector, retries: retries, backoff: backoff}, nil
+}
+
+func (r *retryingConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	backoff := r.backoff
+	for attempt := 0; ; attempt++ {
+		conn, err := r.connector.Connect(ctx)
+		if err == nil || attempt >= r.retries || !isTransientConnError(err) {
+			return conn, err
+		}
+		log.Printf("connecting to postgres failed, retrying in %s: %v", backoff, err)
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+		backoff *= 2
+	}
+}
+
+func (r *retryingConnector) Driver() driver.Driver {
+	return r.connector.Driver()
+}
+
+func isTransientConnError(err error) bool {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		switch pqErr.Code.Class() {
+		case "08": // connection_exception
+			return true
+		}
+		switch pqErr.Code.Name() {
+		case "cannot_connect_now", "too_many_connections", "admin_shutdown":
+			return true
+		}
+		return false
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+	return errors.Is(err, driver.ErrBadConn)
+}
@@ -0,0 +1,99 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/rs/zerolog"
+)
+
+// failingConnector fails every connect as postgres does while it starts up
+type failingConnector struct {
+	attempts int
+}
+
+func (f *failingConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	f.attempts++
+	return nil, &pq.Error{Code: "57P03"} // cannot_connect_now
+}
+
+func (f *failingConnector) Driver() driver.Driver {
+	return pq.Driver{}
+}
+
+func TestRetryingConnectorGivesUp(t *testing.T) {
+	failing := &failingConnector{}
+	r := &retryingConnector{connector: failing, retries: 2, backoff: time.Millisecond}
+	if _, err := r.Connect(context.Background()); err == nil {
+		t.Fatalf("Connect: got no error")
+	}
+	if failing.attempts != 3 {
+		t.Fatalf("Connect: got %d attempts, want 3", failing.attempts)
+	}
+}
+
+func TestRetryingConnectorStopsWithContext(t *testing.T) {
+	failing := &failingConnector{}
+	r := &retryingConnector{connector: failing, retries: 10, backoff: time.Hour}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := r.Connect(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Connect: got %v, want %v", err, context.DeadlineExceeded)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("Connect waited %v after the deadline", elapsed)
+	}
+	if failing.attempts != 1 {
+		t.Fatalf("Connect: got %d attempts, want 1", failing.attempts)
+	}
+}
+
+// execConn answers every statement with an empty result
+type execConn struct {
+	driver.Conn
+}
+
+func (execConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	return driver.RowsAffected(1), nil
+}
+
+func (execConn) Close() error {
+	return nil
+}
+
+type execConnector struct{}
+
+func (execConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	return execConn{}, nil
+}
+
+func (execConnector) Driver() driver.Driver {
+	return pq.Driver{}
+}
+
+func TestLoggingConnectorLogsStatements(t *testing.T) {
+	var logged bytes.Buffer
+	pool := sql.OpenDB(&loggingConnector{Connector: execConnector{}, logger: zerolog.New(&logged)})
+	defer pool.Close()
+
+	long := strings.Repeat("x", 100)
+	if _, err := pool.ExecContext(context.Background(), "UPDATE expense SET description = $1 WHERE id = $2", long, 7); err != nil {
+		t.Fatalf("ExecContext: %v", err)
+	}
+	out := logged.String()
+	for _, want := range []string{`"message":"Connect"`, `"message":"ExecContext"`, `"query":"UPDATE expense SET description = $1 WHERE id = $2"`, `"args":["` + long[:maxLoggedArg] + `...",7]`, `"conn_id":1`} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("log lacks %s:\n%s", want, out)
+		}
+	}
+}
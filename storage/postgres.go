@@ -17,11 +17,6 @@ import (
 	"strconv"
 
 	"github.com/google/uuid"
-	_ "github.com/lib/pq"
-	"github.com/rs/zerolog"
-
-	sqldblogger "github.com/simukti/sqldb-logger"
-	"github.com/simukti/sqldb-logger/logadapter/zerologadapter"
 )
 
 // NewPostgresDB establishes a new PostgreSQL connection using config values
@@ -42,42 +37,65 @@ func NewPostgresDB(cfg *config.Config) (*sql.DB, error) {
 		url = dsn
 	}
 	fmt.Println("Connecting to Postgres DB with URL: ", url)
-	db, err := sql.Open("postgres", url)
-	if err != nil {
-		log.Fatal("failed to establish db connection: ", err)
-		return nil, err
+	return openPostgres(url, cfg)
+}
+
+// NewReplicaDB connects to the read replica, it returns nil when none is configured
+func NewReplicaDB(cfg *config.Config) (*sql.DB, error) {
+	if cfg.DatabaseReplicaURL == "" {
+		return nil, nil
 	}
+	fmt.Println("Connecting to Postgres read replica")
+	return openPostgres(cfg.DatabaseReplicaURL, cfg)
+}
 
-	logger := zerolog.New(os.Stdout).With().Timestamp().Logger().Output(zerolog.ConsoleWriter{Out: os.Stdout})
+func openPostgres(url string, cfg *config.Config) (*sql.DB, error) {
+	url, err := withStatementTimeout(url, cfg.StatementTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("invalid postgres url: %w", err)
+	}
 
-	loggerAdapter := zerologadapter.New(logger)
-	db = sqldblogger.OpenDriver(url, db.Driver(), loggerAdapter /*, using_default_options*/) // db is STILL *sql.DB
-	return db, err
+	connector, err := newRetryingConnector(url, cfg.ConnectRetries, cfg.ConnectRetryBackoff)
+	if err != nil {
+		return nil, fmt.Errorf("invalid postgres url: %w", err)
+	}
+	db := sql.OpenDB(newLoggingConnector(connector))
+	configurePool(db, cfg)
+	return db, nil
 }
 
 type DBStorage struct {
-	ctx     *context.Context
 	db      *sql.DB
 	queries *db.Queries
+	// replica serves reads from contexts marked with AllowStaleReads, nil without a replica
+	replica *db.Queries
 	config  *config.Config
 }
 
-func NewDBStorage(ctx *context.Context, config *config.Config) *DBStorage {
+func NewDBStorage(config *config.Config) *DBStorage {
 	pg, err := NewPostgresDB(config)
 	if err != nil {
 		log.Fatal("error creating postgres connectiong", err)
 		return nil
 	}
-	return &DBStorage{
-		ctx:     ctx,
+	storage := &DBStorage{
 		db:      pg,
 		queries: db.New(pg),
 		config:  config,
 	}
+	replica, err := NewReplicaDB(config)
+	if err != nil {
+		log.Fatal("error creating postgres replica connection", err)
+		return nil
+	}
+	if replica != nil {
+		storage.replica = db.New(replica)
+	}
+	return storage
 }
 
-func (d *DBStorage) FetchUserByEmail(email string) (*models.User, error) {
-	user, err := d.queries.FetchUserByEmail(*d.ctx, email)
+func (d *DBStorage) FetchUserByEmail(ctx context.Context, email string) (*models.User, error) {
+	user, err := d.reader(ctx).FetchUserByEmail(ctx, email)
 	if err != nil {
 		return nil, err
 	}
@@ -90,13 +108,13 @@ func (d *DBStorage) FetchUserByEmail(email string) (*models.User, error) {
 	}, nil
 }
 
-func (d *DBStorage) CreateUser(u models.User) (*models.User, error) {
+func (d *DBStorage) CreateUser(ctx context.Context, u models.User) (*models.User, error) {
 	userUUID, err := uuid.Parse(u.ID)
 	if err != nil {
 		return nil, err
 	}
 	now := time.Now()
-	user, err := d.queries.InsertUser(*d.ctx, db.InsertUserParams{
+	user, err := d.q(ctx).InsertUser(ctx, db.InsertUserParams{
 		ID:         userUUID,
 		Name:       u.Name,
 		Email:      u.Email,
@@ -117,9 +135,9 @@ func (d *DBStorage) CreateUser(u models.User) (*models.User, error) {
 	}, nil
 }
 
-func (d *DBStorage) UpdateUser(u models.User) (*models.User, error) {
+func (d *DBStorage) UpdateUser(ctx context.Context, u models.User) (*models.User, error) {
 	id, _ := uuid.Parse(u.ID)
-	user, err := d.queries.UpdateUser(*d.ctx, db.UpdateUserParams{
+	user, err := d.q(ctx).UpdateUser(ctx, db.UpdateUserParams{
 		ID:         id,
 		Name:       u.Name,
 		Email:      u.Email,
@@ -138,9 +156,9 @@ func (d *DBStorage) UpdateUser(u models.User) (*models.User, error) {
 	}, nil
 }
 
-func (d *DBStorage) FetchGroupsByUser(userId string) ([]models.Group, error) {
+func (d *DBStorage) FetchGroupsByUser(ctx context.Context, userId string) ([]models.Group, error) {
 	uid, _ := uuid.Parse(userId)
-	groups, err := d.queries.FetchGroupsByUser(*d.ctx, uid)
+	groups, err := d.reader(ctx).FetchGroupsByUser(ctx, uid)
 	if err != nil {
 		return nil, err
 	}
@@ -151,14 +169,16 @@ func (d *DBStorage) FetchGroupsByUser(userId string) ([]models.Group, error) {
 			Name:        g.Name,
 			Description: g.Description,
 			Admin:       g.AdminID.String(),
+			Version:     int(g.Version),
+			CreatedAt:   g.CreatedAt,
 		})
 	}
 	return result, nil
 }
 
-func (d *DBStorage) FetchUserById(id string) (*models.User, error) {
+func (d *DBStorage) FetchUserById(ctx context.Context, id string) (*models.User, error) {
 	uid, _ := uuid.Parse(id)
-	user, err := d.queries.FetchUserById(*d.ctx, uid)
+	user, err := d.reader(ctx).FetchUserById(ctx, uid)
 	if err != nil {
 		return nil, err
 	}
@@ -171,9 +191,9 @@ func (d *DBStorage) FetchUserById(id string) (*models.User, error) {
 	}, nil
 }
 
-func (d *DBStorage) FetchGroupMembers(groupId string) ([]models.User, error) {
+func (d *DBStorage) FetchGroupMembers(ctx context.Context, groupId string) ([]models.User, error) {
 	gid, _ := uuid.Parse(groupId)
-	users, err := d.queries.FetchGroupMembers(*d.ctx, gid)
+	users, err := d.reader(ctx).FetchGroupMembers(ctx, gid)
 	if err != nil {
 		return nil, err
 	}
@@ -190,9 +210,9 @@ func (d *DBStorage) FetchGroupMembers(groupId string) ([]models.User, error) {
 	return result, nil
 }
 
-func (d *DBStorage) FetchGroupById(id string) (*models.Group, error) {
+func (d *DBStorage) FetchGroupById(ctx context.Context, id string) (*models.Group, error) {
 	gid, _ := uuid.Parse(id)
-	group, err := d.queries.FetchGroupById(*d.ctx, gid)
+	group, err := d.reader(ctx).FetchGroupById(ctx, gid)
 	if err != nil {
 		return nil, err
 	}
@@ -201,12 +221,14 @@ func (d *DBStorage) FetchGroupById(id string) (*models.Group, error) {
 		Name:        group.Name,
 		Description: group.Description,
 		Admin:       group.AdminID.String(),
+		Version:     int(group.Version),
+		CreatedAt:   group.CreatedAt,
 	}, nil
 }
 
-func (d *DBStorage) FetchGroupExpenses(groupId string, pageNumber int) (*models.StoredGroupExpenseHistory, error) {
+func (d *DBStorage) FetchGroupExpenses(ctx context.Context, groupId string, pageNumber int) (*models.StoredGroupExpenseHistory, error) {
 	gid, _ := uuid.Parse(groupId)
-	rows, err := d.queries.FetchGroupExpenses(*d.ctx, db.FetchGroupExpensesParams{
+	rows, err := d.reader(ctx).FetchGroupExpenses(ctx, db.FetchGroupExpensesParams{
 		GroupID: uuid.NullUUID{UUID: gid, Valid: true},
 		Column2: pageNumber,
 		Limit:   20,
@@ -217,7 +239,10 @@ func (d *DBStorage) FetchGroupExpenses(groupId string, pageNumber int) (*models.
 	}
 
 	// Calculate total pages
-	var totalCount int
+	totalCount, err := d.FetchExpenseCountByGroup(ctx, groupId)
+	if err != nil {
+		return nil, err
+	}
 	pageSize := 20
 	totalPages := (totalCount + pageSize - 1) / pageSize
 
@@ -225,15 +250,25 @@ func (d *DBStorage) FetchGroupExpenses(groupId string, pageNumber int) (*models.
 
 }
 
-func (d *DBStorage) CreateOrUpdateGroup(group models.Group) (*models.Group, error) {
+func (d *DBStorage) CreateOrUpdateGroup(ctx context.Context, group models.Group) (*models.Group, error) {
 	id, _ := uuid.Parse(group.Id)
 	admin, _ := uuid.Parse(group.Admin)
-	g, err := d.queries.CreateOrUpdateGroup(*d.ctx, db.CreateOrUpdateGroupParams{
+	g, err := d.q(ctx).CreateOrUpdateGroup(ctx, db.CreateOrUpdateGroupParams{
 		ID:          id,
 		Name:        group.Name,
 		Description: group.Description,
 		AdminID:     admin,
+		Version:     int32(group.Version),
+		CreatedAt:   sql.NullTime{Time: group.CreatedAt, Valid: !group.CreatedAt.IsZero()},
 	})
+	if err == sql.ErrNoRows {
+		// the upsert matched an existing row whose version has moved on
+		current, fetchErr := d.FetchGroupById(ctx, group.Id)
+		if fetchErr != nil {
+			return nil, fetchErr
+		}
+		return nil, &models.VersionConflictError{Entity: "group", ID: group.Id, CurrentVersion: current.Version}
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -242,13 +277,15 @@ func (d *DBStorage) CreateOrUpdateGroup(group models.Group) (*models.Group, erro
 		Name:        g.Name,
 		Description: g.Description,
 		Admin:       g.AdminID.String(),
+		Version:     int(g.Version),
+		CreatedAt:   g.CreatedAt,
 	}, nil
 }
 
-func (d *DBStorage) AddUserInGroup(userId string, groupId string) (bool, error) {
+func (d *DBStorage) AddUserInGroup(ctx context.Context, userId string, groupId string) (bool, error) {
 	uid, _ := uuid.Parse(userId)
 	gid, _ := uuid.Parse(groupId)
-	_, err := d.queries.AddUserInGroup(*d.ctx, db.AddUserInGroupParams{
+	_, err := d.q(ctx).AddUserInGroup(ctx, db.AddUserInGroupParams{
 		UserID:  uid,
 		GroupID: gid,
 	})
@@ -259,10 +296,10 @@ func (d *DBStorage) AddUserInGroup(userId string, groupId string) (bool, error)
 	}
 }
 
-func (d *DBStorage) RemoveUserFromGroup(userId string, groupId string) (bool, error) {
+func (d *DBStorage) RemoveUserFromGroup(ctx context.Context, userId string, groupId string) (bool, error) {
 	uid, _ := uuid.Parse(userId)
 	gid, _ := uuid.Parse(groupId)
-	_, err := d.queries.RemoveUserFromGroup(*d.ctx, db.RemoveUserFromGroupParams{
+	_, err := d.q(ctx).RemoveUserFromGroup(ctx, db.RemoveUserFromGroupParams{
 		UserID:  uid,
 		GroupID: gid,
 	})
@@ -272,7 +309,7 @@ func (d *DBStorage) RemoveUserFromGroup(userId string, groupId string) (bool, er
 	return false, err
 }
 
-func (d *DBStorage) CreateOrUpdateExpense(expense models.Expense) (*models.Expense, error) {
+func (d *DBStorage) CreateOrUpdateExpense(ctx context.Context, expense models.Expense) (*models.Expense, error) {
 
 	var err error
 
@@ -298,7 +335,7 @@ func (d *DBStorage) CreateOrUpdateExpense(expense models.Expense) (*models.Expen
 	now := time.Now()
 	amountStr := fmt.Sprintf("%f", expense.Amount)
 
-	var createdAt time.Time
+	createdAt := expense.CreatedAt
 	if createdAt.IsZero() {
 		createdAt = time.Now()
 	}
@@ -317,7 +354,7 @@ func (d *DBStorage) CreateOrUpdateExpense(expense models.Expense) (*models.Expen
 		return nil, err
 	}
 
-	e, err := d.queries.CreateOrUpdateExpense(*d.ctx, db.CreateOrUpdateExpenseParams{
+	e, err := d.q(ctx).CreateOrUpdateExpense(ctx, db.CreateOrUpdateExpenseParams{
 		ID:          parsed,
 		Description: sql.NullString{String: expense.Description, Valid: true},
 		Amount:      amountStr,
@@ -326,10 +363,20 @@ func (d *DBStorage) CreateOrUpdateExpense(expense models.Expense) (*models.Expen
 		SettledBy:   uuid.NullUUID{UUID: settledBy, Valid: expense.SettledBy != ""},
 		CreatedBy:   createdBy,
 		Payee:       json.RawMessage(payeeJson),
-		CreatedAt:   sql.NullTime{Time: createdAt, Valid: true},
+		CreatedAt:   createdAt,
 		UpdatedAt:   sql.NullTime{Time: now, Valid: true},
 		GroupID:     groupId,
+		Category:    expense.Category,
+		Version:     int32(expense.Version),
 	})
+	if err == sql.ErrNoRows {
+		// the upsert matched an existing row whose version has moved on
+		current, fetchErr := d.FetchExpense(ctx, expense.ID)
+		if fetchErr != nil {
+			return nil, fetchErr
+		}
+		return nil, &models.VersionConflictError{Entity: "expense", ID: expense.ID, CurrentVersion: current.Version}
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -355,24 +402,44 @@ func (d *DBStorage) CreateOrUpdateExpense(expense models.Expense) (*models.Expen
 	return &models.Expense{
 		ID:             e.ID.String(),
 		Description:    e.Description.String,
+		Category:       e.Category,
 		Amount:         amount,
 		Status:         models.ExpenseStatus(e.Status),
 		CreatedBy:      e.CreatedBy.String(),
 		SettledBy:      settledBy_,
-		CreatedAt:      e.CreatedAt.Time,
+		CreatedAt:      e.CreatedAt,
 		PayeeW:         payeeW,
 		SplitW:         splitW,
 		IsGroupExpense: e.GroupID.Valid,
 		GroupId:        e.GroupID.UUID.String(),
+		Version:        int(e.Version),
 	}, nil
 }
 
-func (d *DBStorage) FetchExpense(id string) (*models.Expense, error) {
+func (d *DBStorage) CreateExpenses(ctx context.Context, expenses []models.Expense) ([]models.Expense, error) {
+	created := make([]models.Expense, 0, len(expenses))
+	err := d.RunInTx(ctx, func(ctx context.Context) error {
+		for _, exp := range expenses {
+			saved, err := d.CreateOrUpdateExpense(ctx, exp)
+			if err != nil {
+				return err
+			}
+			created = append(created, *saved)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+func (d *DBStorage) FetchExpense(ctx context.Context, id string) (*models.Expense, error) {
 	expenseUUID, err := uuid.Parse(id)
 	if err != nil {
 		return nil, err
 	}
-	e, err := d.queries.FetchExpense(*d.ctx, expenseUUID)
+	e, err := d.reader(ctx).FetchExpense(ctx, expenseUUID)
 	if err != nil {
 		return nil, err
 	}
@@ -390,58 +457,103 @@ func (d *DBStorage) FetchExpense(id string) (*models.Expense, error) {
 	}
 	amount, _ := strconv.ParseFloat(e.Amount, 64)
 
+	var settledBy string
+	if e.SettledBy.Valid {
+		settledBy = e.SettledBy.UUID.String()
+	}
+
 	return &models.Expense{
 		ID:             e.ID.String(),
 		Description:    e.Description.String,
+		Category:       e.Category,
 		Amount:         amount,
 		Status:         models.ExpenseStatus(e.Status),
 		CreatedBy:      e.CreatedBy.String(),
-		SettledBy:      e.SettledBy.UUID.String(),
-		CreatedAt:      e.CreatedAt.Time,
+		SettledBy:      settledBy,
+		CreatedAt:      e.CreatedAt,
 		PayeeW:         payeeW,
 		SplitW:         splitW,
 		IsGroupExpense: e.GroupID.Valid,
 		GroupId:        e.GroupID.UUID.String(),
+		Version:        int(e.Version),
 	}, nil
 }
 
-func (d *DBStorage) CheckUserExistsInGroup(userId string, groupId string) (bool, error) {
+func (d *DBStorage) CheckUserExistsInGroup(ctx context.Context, userId string, groupId string) (bool, error) {
 	uid, _ := uuid.Parse(userId)
 	gid, _ := uuid.Parse(groupId)
-	return d.queries.CheckUserExistsInGroup(*d.ctx, db.CheckUserExistsInGroupParams{
+	return d.reader(ctx).CheckUserExistsInGroup(ctx, db.CheckUserExistsInGroupParams{
 		UserID:  uid,
 		GroupID: gid,
 	})
 }
 
-func (d *DBStorage) AddExpenseMapping(expenseId string, userId string) (bool, error) {
+func (d *DBStorage) AddExpenseMapping(ctx context.Context, expenseId string, userId string) (bool, error) {
 	uid, _ := uuid.Parse(userId)
 	eid, _ := uuid.Parse(expenseId)
-	return d.queries.AddUserExpenseMapping(*d.ctx, db.AddUserExpenseMappingParams{ExpenseID: eid, UserID: uid})
+	_, err := d.q(ctx).AddUserExpenseMapping(ctx, db.AddUserExpenseMappingParams{ExpenseID: eid, UserID: uid})
+	// an existing mapping inserts nothing and returns no row
+	if err == nil || err == sql.ErrNoRows {
+		return true, nil
+	}
+	return false, err
+}
+
+func (d *DBStorage) AddExpenseMappings(ctx context.Context, mappings map[string][]string) error {
+	params := db.AddExpenseMappingsParams{}
+	for expenseId, userIds := range mappings {
+		eid, err := uuid.Parse(expenseId)
+		if err != nil {
+			return err
+		}
+		for _, userId := range userIds {
+			uid, err := uuid.Parse(userId)
+			if err != nil {
+				return err
+			}
+			params.ExpenseIds = append(params.ExpenseIds, eid)
+			params.UserIds = append(params.UserIds, uid)
+		}
+	}
+	if len(params.ExpenseIds) == 0 {
+		return nil
+	}
+	return d.q(ctx).AddExpenseMappings(ctx, params)
 }
 
-func (d *DBStorage) RemoveUsersFromExpense(expenseId string, usersToRemove []string) (bool, error) {
+func (d *DBStorage) RemoveUsersFromExpense(ctx context.Context, expenseId string, usersToRemove []string) (bool, error) {
 	eid, _ := uuid.Parse(expenseId)
 	var userUUIDs []uuid.UUID
 	for _, u := range usersToRemove {
 		uid, _ := uuid.Parse(u)
 		userUUIDs = append(userUUIDs, uid)
 	}
-	return d.queries.RemoveUsersFromExpenseMapping(*d.ctx, db.RemoveUsersFromExpenseMappingParams{
+	if len(userUUIDs) == 0 {
+		return false, nil
+	}
+	removed, err := d.q(ctx).RemoveUsersFromExpenseMapping(ctx, db.RemoveUsersFromExpenseMappingParams{
 		ExpenseID: eid,
 		Column2:   userUUIDs,
 	})
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	return removed, err
 }
 
-func (d *DBStorage) DeleteExpense(id string) (bool, error) {
+func (d *DBStorage) DeleteExpense(ctx context.Context, id string) (bool, error) {
 	expenseId, err := uuid.Parse(id)
 	if err != nil {
 		return false, err
 	}
-	return d.queries.DeleteExpense(*d.ctx, expenseId)
+	deleted, err := d.q(ctx).DeleteExpense(ctx, expenseId)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	return deleted, err
 }
 
-func (d *DBStorage) GetFriend(userId string, friendId string) (*expense.User, error) {
+func (d *DBStorage) GetFriend(ctx context.Context, userId string, friendId string) (*expense.User, error) {
 	userUUID, err := uuid.Parse(userId)
 	if err != nil {
 		return nil, err
@@ -450,7 +562,7 @@ func (d *DBStorage) GetFriend(userId string, friendId string) (*expense.User, er
 	if err != nil {
 		return nil, err
 	}
-	row, err := d.queries.GetFriend(*d.ctx, db.GetFriendParams{UserID: userUUID, FriendID: friendUUID})
+	row, err := d.reader(ctx).GetFriend(ctx, db.GetFriendParams{UserID: userUUID, FriendID: friendUUID})
 	if err != nil {
 		return nil, err
 	}
@@ -458,12 +570,12 @@ func (d *DBStorage) GetFriend(userId string, friendId string) (*expense.User, er
 
 }
 
-func (d *DBStorage) GetFriends(userId string) ([]models.User, error) {
+func (d *DBStorage) GetFriends(ctx context.Context, userId string) ([]models.User, error) {
 	uid, err := uuid.Parse(userId)
 	if err != nil {
 		return nil, err
 	}
-	friends, err := d.queries.GetFriends(*d.ctx, uid)
+	friends, err := d.reader(ctx).GetFriends(ctx, uid)
 	if err != nil {
 		return nil, err
 	}
@@ -480,7 +592,7 @@ func (d *DBStorage) GetFriends(userId string) ([]models.User, error) {
 	return result, nil
 }
 
-func (d *DBStorage) RemoveFriend(userId string, friendId string) (bool, error) {
+func (d *DBStorage) RemoveFriend(ctx context.Context, userId string, friendId string) (bool, error) {
 	parsedUserID, err := uuid.Parse(userId)
 	if err != nil {
 		return false, err
@@ -489,10 +601,14 @@ func (d *DBStorage) RemoveFriend(userId string, friendId string) (bool, error) {
 	if err != nil {
 		return false, err
 	}
-	return d.queries.RemoveFriend(*d.ctx, db.RemoveFriendParams{UserID: parsedUserID, FriendID: friendUUID})
+	removed, err := d.q(ctx).RemoveFriend(ctx, db.RemoveFriendParams{UserID: parsedUserID, FriendID: friendUUID})
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	return removed, err
 }
 
-func (d *DBStorage) AddFriend(userId string, friendId string) (bool, error) {
+func (d *DBStorage) AddFriend(ctx context.Context, userId string, friendId string) (bool, error) {
 	userUUID, err := uuid.Parse(userId)
 	if err != nil {
 		return false, err
@@ -501,29 +617,56 @@ func (d *DBStorage) AddFriend(userId string, friendId string) (bool, error) {
 	if err != nil {
 		return false, err
 	}
-	_, err = d.queries.AddFriend(*d.ctx, db.AddFriendParams{UserID: userUUID, FriendID: friendUUID})
-	if err != nil {
+	_, err = d.q(ctx).AddFriend(ctx, db.AddFriendParams{UserID: userUUID, FriendID: friendUUID})
+	if err != nil && err != sql.ErrNoRows {
 		return false, err
 	}
 	return true, nil
 }
 
-func (d *DBStorage) FetchExpenseCountByGroup(groupId string) (int, error) {
+func (d *DBStorage) FetchExpenseCountByGroup(ctx context.Context, groupId string) (int, error) {
 	gid, _ := uuid.Parse(groupId)
-	count, err := d.queries.FetchExpenseCountByGroup(*d.ctx, uuid.NullUUID{UUID: gid, Valid: true})
+	count, err := d.reader(ctx).FetchExpenseCountByGroup(ctx, uuid.NullUUID{UUID: gid, Valid: true})
 	if err != nil && err != sql.ErrNoRows {
 		return 0, err
 	}
 	return int(count), nil
 }
 
-func (d *DBStorage) FetchExpenseByUserAndStatus(userId string, status models.ExpenseStatus, pageNumber int, limit int32) (*models.StoredGroupExpenseHistory, error) {
+func (d *DBStorage) LockGroup(ctx context.Context, groupId string) (bool, error) {
+	gid, err := uuid.Parse(groupId)
+	if err != nil {
+		return false, err
+	}
+	locked, err := d.q(ctx).LockGroup(ctx, gid)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return locked, nil
+}
+
+func (d *DBStorage) CountUnsettledGroupExpenses(ctx context.Context, groupId string) (int, error) {
+	gid, err := uuid.Parse(groupId)
+	if err != nil {
+		return 0, err
+	}
+	count, err := d.q(ctx).CountUnsettledGroupExpenses(ctx, gid)
+	if err != nil {
+		return 0, err
+	}
+	return int(count), nil
+}
+
+func (d *DBStorage) FetchExpenseByUserAndStatus(ctx context.Context, userId string, status models.ExpenseStatus, pageNumber int, limit int32) (*models.StoredGroupExpenseHistory, error) {
 	if pageNumber == 0 {
 		pageNumber = 1
 	}
 
 	uid, _ := uuid.Parse(userId)
-	rows, err := d.queries.FetchExpenseByUserAndStatus(*d.ctx, db.FetchExpenseByUserAndStatusParams{
+	rows, err := d.reader(ctx).FetchExpenseByUserAndStatus(ctx, db.FetchExpenseByUserAndStatusParams{
 		UserID:  uid,
 		Status:  string(status),
 		Column4: pageNumber,
@@ -533,7 +676,7 @@ func (d *DBStorage) FetchExpenseByUserAndStatus(userId string, status models.Exp
 		return nil, err
 	}
 
-	totalCount, err := d.queries.FetchExpenseCountByUserAndStatus(*d.ctx, db.FetchExpenseCountByUserAndStatusParams{
+	totalCount, err := d.reader(ctx).FetchExpenseCountByUserAndStatus(ctx, db.FetchExpenseCountByUserAndStatusParams{
 		UserID: uid,
 		Status: string(status),
 	})
@@ -552,24 +695,30 @@ func (d *DBStorage) GetStoredGroupExpenseFromRows(rows []db.Expense, pageNumber
 		_ = json.Unmarshal(row.Split, &splitW)
 		var payeeW models.PayerWrapper
 		_ = json.Unmarshal(row.Payee, &payeeW)
+		var settledBy string
+		if row.SettledBy.Valid {
+			settledBy = row.SettledBy.UUID.String()
+		}
 		result.Expenses = append(result.Expenses, models.Expense{
 			ID:             row.ID.String(),
 			Description:    row.Description.String,
+			Category:       row.Category,
 			Amount:         amount,
 			Status:         models.ExpenseStatus(row.Status),
 			CreatedBy:      row.CreatedBy.String(),
-			SettledBy:      row.SettledBy.UUID.String(),
-			CreatedAt:      row.CreatedAt.Time,
+			SettledBy:      settledBy,
+			CreatedAt:      row.CreatedAt,
 			SplitW:         splitW,
 			PayeeW:         payeeW,
 			IsGroupExpense: row.GroupID.Valid,
 			GroupId:        row.GroupID.UUID.String(),
+			Version:        int(row.Version),
 		})
 	}
 	return &result, nil
 }
 
-func (d *DBStorage) FetchGroupExpensesByStatus(groupId string, status models.ExpenseStatus, pageNumber int) (*models.StoredGroupExpenseHistory, error) {
+func (d *DBStorage) FetchGroupExpensesByStatus(ctx context.Context, groupId string, status models.ExpenseStatus, pageNumber int) (*models.StoredGroupExpenseHistory, error) {
 	if pageNumber == 0 {
 		pageNumber = 1
 	}
@@ -578,7 +727,7 @@ func (d *DBStorage) FetchGroupExpensesByStatus(groupId string, status models.Exp
 	gid_, _ := uuid.Parse(groupId)
 	gid := uuid.NullUUID{UUID: gid_, Valid: true}
 
-	totalCount, err := d.queries.FetchExpenseCountByGroupAndStatus(*d.ctx, db.FetchExpenseCountByGroupAndStatusParams{
+	totalCount, err := d.reader(ctx).FetchExpenseCountByGroupAndStatus(ctx, db.FetchExpenseCountByGroupAndStatusParams{
 		GroupID: gid,
 		Status:  string(status)},
 	)
@@ -587,7 +736,7 @@ func (d *DBStorage) FetchGroupExpensesByStatus(groupId string, status models.Exp
 		return nil, err
 	}
 
-	rows, err := d.queries.FetchGroupExpensesByStatus(*d.ctx, db.FetchGroupExpensesByStatusParams{
+	rows, err := d.reader(ctx).FetchGroupExpensesByStatus(ctx, db.FetchGroupExpensesByStatusParams{
 		GroupID: gid,
 		Status:  string(status),
 		Column3: pageNumber,
@@ -601,14 +750,345 @@ func (d *DBStorage) FetchGroupExpensesByStatus(groupId string, status models.Exp
 
 }
 
-func (d *DBStorage) DeleteGroup(groupId string) (bool, error) {
+func (d *DBStorage) DeleteGroup(ctx context.Context, groupId string) (bool, error) {
 	gid, err := uuid.Parse(groupId)
 	if err != nil {
 		return false, err
 	}
-	deleted, err := d.queries.DeleteGroup(*d.ctx, gid)
-	if err == nil || err == sql.ErrNoRows {
-		return deleted, nil
+	deleted := false
+	err = d.RunInTx(ctx, func(ctx context.Context) error {
+		// the copy locks the group row, so nobody joins or adds an expense meanwhile
+		archived, err := d.q(ctx).ArchiveGroup(ctx, gid)
+		if err == sql.ErrNoRows || (err == nil && !archived) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if _, err := d.q(ctx).ArchiveGroupMembers(ctx, gid); err != nil {
+			return err
+		}
+
+		ids, err := d.q(ctx).FetchGroupExpenseIdsForUpdate(ctx, gid)
+		if err != nil {
+			return err
+		}
+		if len(ids) > 0 {
+			// mappings are copied before the delete cascades to them
+			if err := d.q(ctx).CopyExpensesToArchive(ctx, ids); err != nil {
+				return err
+			}
+			if err := d.q(ctx).CopyExpenseMappingsToArchive(ctx, ids); err != nil {
+				return err
+			}
+			if _, err := d.q(ctx).DeleteArchivedExpenses(ctx, ids); err != nil {
+				return err
+			}
+		}
+
+		deleted, err = d.q(ctx).DeleteGroup(ctx, gid)
+		return err
+	})
+	if err != nil {
+		return false, err
+	}
+	return deleted, nil
+}
+
+// keysetParams turns a page cursor into the nullable keyset query arguments
+func keysetParams(page models.PageRequest) (sql.NullTime, uuid.NullUUID, error) {
+	cursor, err := models.DecodeCursor(page.Cursor)
+	if err != nil || cursor == nil {
+		return sql.NullTime{}, uuid.NullUUID{}, err
+	}
+	id, err := uuid.Parse(cursor.ID)
+	if err != nil {
+		return sql.NullTime{}, uuid.NullUUID{}, models.ErrInvalidQuery("invalid cursor")
+	}
+	return sql.NullTime{Time: cursor.CreatedAt, Valid: true}, uuid.NullUUID{UUID: id, Valid: true}, nil
+}
+
+// getExpensePageFromRows trims the look-ahead row fetched past the page size and sets the next cursor
+func (d *DBStorage) getExpensePageFromRows(rows []db.Expense, size int) (*models.StoredGroupExpenseHistory, error) {
+	hasMore := len(rows) > size
+	if hasMore {
+		rows = rows[:size]
+	}
+	result, err := d.GetStoredGroupExpenseFromRows(rows, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	result.HasMore = hasMore
+	if hasMore {
+		last := rows[len(rows)-1]
+		result.NextCursor = models.Cursor{CreatedAt: last.CreatedAt, ID: last.ID.String()}.Encode()
+	}
+	return result, nil
+}
+
+func (d *DBStorage) FetchGroupExpensesPage(ctx context.Context, groupId string, status models.ExpenseStatus, includeArchived bool, page models.PageRequest) (*models.StoredGroupExpenseHistory, error) {
+	gid, err := uuid.Parse(groupId)
+	if err != nil {
+		return nil, err
+	}
+	cursorCreatedAt, cursorId, err := keysetParams(page)
+	if err != nil {
+		return nil, err
+	}
+
+	size := page.Size()
+	rows, err := d.reader(ctx).FetchGroupExpensesPage(ctx, db.FetchGroupExpensesPageParams{
+		GroupID:         gid,
+		Status:          sql.NullString{String: string(status), Valid: status != ""},
+		CursorCreatedAt: cursorCreatedAt,
+		CursorID:        cursorId,
+		PageLimit:       int32(size + 1),
+		IncludeArchived: includeArchived,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return d.getExpensePageFromRows(expenseRows(rows), size)
+}
+
+func (d *DBStorage) FetchUserExpensesPage(ctx context.Context, userId string, status models.ExpenseStatus, page models.PageRequest) (*models.StoredGroupExpenseHistory, error) {
+	uid, err := uuid.Parse(userId)
+	if err != nil {
+		return nil, err
+	}
+	cursorCreatedAt, cursorId, err := keysetParams(page)
+	if err != nil {
+		return nil, err
+	}
+
+	size := page.Size()
+	rows, err := d.reader(ctx).FetchUserExpensesPage(ctx, db.FetchUserExpensesPageParams{
+		UserID:          uid,
+		Status:          sql.NullString{String: string(status), Valid: status != ""},
+		CursorCreatedAt: cursorCreatedAt,
+		CursorID:        cursorId,
+		PageLimit:       int32(size + 1),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return d.getExpensePageFromRows(rows, size)
+}
+
+func (d *DBStorage) GetFriendsPage(ctx context.Context, userId string, page models.PageRequest) (*models.UserPage, error) {
+	uid, err := uuid.Parse(userId)
+	if err != nil {
+		return nil, err
+	}
+	cursorCreatedAt, cursorId, err := keysetParams(page)
+	if err != nil {
+		return nil, err
+	}
+
+	size := page.Size()
+	rows, err := d.reader(ctx).GetFriendsPage(ctx, db.GetFriendsPageParams{
+		UserID:          uid,
+		CursorCreatedAt: cursorCreatedAt,
+		CursorID:        cursorId,
+		PageLimit:       int32(size + 1),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := models.UserPage{Users: []models.User{}, HasMore: len(rows) > size}
+	if result.HasMore {
+		rows = rows[:size]
+		last := rows[len(rows)-1]
+		result.NextCursor = models.Cursor{CreatedAt: last.FriendsSince, ID: last.ID.String()}.Encode()
+	}
+	for _, u := range rows {
+		result.Users = append(result.Users, models.User{
+			ID:         u.ID.String(),
+			Name:       u.Name,
+			Email:      u.Email,
+			IsVerified: u.IsVerified,
+		})
+	}
+	return &result, nil
+}
+
+func (d *DBStorage) FetchGroupsByUserPage(ctx context.Context, userId string, page models.PageRequest) (*models.GroupPage, error) {
+	uid, err := uuid.Parse(userId)
+	if err != nil {
+		return nil, err
+	}
+	cursorCreatedAt, cursorId, err := keysetParams(page)
+	if err != nil {
+		return nil, err
+	}
+
+	size := page.Size()
+	rows, err := d.reader(ctx).FetchGroupsByUserPage(ctx, db.FetchGroupsByUserPageParams{
+		UserID:          uid,
+		CursorCreatedAt: cursorCreatedAt,
+		CursorID:        cursorId,
+		PageLimit:       int32(size + 1),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := models.GroupPage{Groups: []models.Group{}, HasMore: len(rows) > size}
+	if result.HasMore {
+		rows = rows[:size]
+		last := rows[len(rows)-1]
+		result.NextCursor = models.Cursor{CreatedAt: last.CreatedAt, ID: last.ID.String()}.Encode()
+	}
+	for _, g := range rows {
+		result.Groups = append(result.Groups, models.Group{
+			Id:          g.ID.String(),
+			Name:        g.Name,
+			Description: g.Description,
+			Admin:       g.AdminID.String(),
+			Version:     int(g.Version),
+			CreatedAt:   g.CreatedAt,
+		})
+	}
+	return &result, nil
+}
+
+func (d *DBStorage) SearchExpenses(ctx context.Context, userId string, search models.ExpenseSearch, page models.PageRequest) (*models.StoredGroupExpenseHistory, error) {
+	uid, err := uuid.Parse(userId)
+	if err != nil {
+		return nil, err
+	}
+	cursorCreatedAt, cursorId, err := keysetParams(page)
+	if err != nil {
+		return nil, err
+	}
+	groupId, err := nullUUID(search.GroupId)
+	if err != nil {
+		return nil, models.ErrInvalidQuery("invalid groupId")
+	}
+	participantId, err := nullUUID(search.ParticipantId)
+	if err != nil {
+		return nil, models.ErrInvalidQuery("invalid participantId")
+	}
+
+	size := page.Size()
+	rows, err := d.reader(ctx).SearchExpenses(ctx, db.SearchExpensesParams{
+		UserID:          uid,
+		Query:           nullString(search.Query),
+		GroupID:         groupId,
+		ParticipantID:   participantId,
+		PayerID:         nullString(search.PayerId),
+		Status:          nullString(string(search.Status)),
+		Category:        nullString(search.Category),
+		MinAmount:       nullAmount(search.MinAmount),
+		MaxAmount:       nullAmount(search.MaxAmount),
+		CreatedFrom:     nullTime(search.CreatedFrom),
+		CreatedBefore:   nullTime(search.CreatedBefore),
+		CursorCreatedAt: cursorCreatedAt,
+		CursorID:        cursorId,
+		PageLimit:       int32(size + 1),
+		IncludeArchived: search.IncludeArchived,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return d.getExpensePageFromRows(expenseRows(rows), size)
+}
+
+// expenseRows converts the rows of queries that read expenses together with
+// the archive, sqlc generates a row type for them with the same fields as db.Expense
+func expenseRows[T db.FetchGroupExpensesPageRow | db.FetchGroupsExpensesPageRow | db.SearchExpensesRow](rows []T) []db.Expense {
+	result := make([]db.Expense, len(rows))
+	for i, row := range rows {
+		result[i] = db.Expense(row)
+	}
+	return result
+}
+
+func (d *DBStorage) ArchiveSettledExpenses(ctx context.Context, settledBefore time.Time, limit int) (int, error) {
+	moved := 0
+	err := d.RunInTx(ctx, func(ctx context.Context) error {
+		ids, err := d.FetchSettledExpensesToArchive(ctx, settledBefore, limit)
+		if err != nil {
+			return err
+		}
+		moved, err = d.ArchiveExpenses(ctx, ids)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+	return moved, nil
+}
+
+// FetchSettledExpensesToArchive locks up to limit expenses settled before
+// settledBefore until the surrounding transaction ends
+func (d *DBStorage) FetchSettledExpensesToArchive(ctx context.Context, settledBefore time.Time, limit int) ([]string, error) {
+	ids, err := d.q(ctx).FetchSettledExpensesToArchive(ctx, db.FetchSettledExpensesToArchiveParams{
+		SettledBefore: sql.NullTime{Time: settledBefore, Valid: true},
+		BatchSize:     int32(limit),
+	})
+	if err != nil {
+		return nil, err
+	}
+	expenseIds := []string{}
+	for _, id := range ids {
+		expenseIds = append(expenseIds, id.String())
+	}
+	return expenseIds, nil
+}
+
+// ArchiveExpenses moves the expenses with their mappings to the archive,
+// expenses that are not live are skipped
+func (d *DBStorage) ArchiveExpenses(ctx context.Context, ids []string) (int, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	moved := 0
+	err := d.RunInTx(ctx, func(ctx context.Context) error {
+		expenseIds := parseIds(ids)
+		// mappings are copied before the delete cascades to them
+		if err := d.q(ctx).CopyExpensesToArchive(ctx, expenseIds); err != nil {
+			return err
+		}
+		if err := d.q(ctx).CopyExpenseMappingsToArchive(ctx, expenseIds); err != nil {
+			return err
+		}
+		deleted, err := d.q(ctx).DeleteArchivedExpenses(ctx, expenseIds)
+		moved = int(deleted)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+	return moved, nil
+}
+
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
+
+func nullUUID(id string) (uuid.NullUUID, error) {
+	if id == "" {
+		return uuid.NullUUID{}, nil
+	}
+	parsed, err := uuid.Parse(id)
+	if err != nil {
+		return uuid.NullUUID{}, err
+	}
+	return uuid.NullUUID{UUID: parsed, Valid: true}, nil
+}
+
+func nullAmount(amount *float64) sql.NullString {
+	if amount == nil {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: fmt.Sprintf("%f", *amount), Valid: true}
+}
+
+func nullTime(t *time.Time) sql.NullTime {
+	if t == nil {
+		return sql.NullTime{}
 	}
-	return false, nil
+	return sql.NullTime{Time: *t, Valid: true}
 }
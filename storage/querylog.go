@@ -0,0 +1,183 @@
+package storage
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// loggingConnector logs every connection, statement and transaction of the
+// connections connector opens, with its duration and arguments. Failures are
+// logged as errors, the rest at info.
+type loggingConnector struct {
+	driver.Connector
+	logger zerolog.Logger
+	ids    atomic.Int64
+}
+
+func newLoggingConnector(connector driver.Connector) *loggingConnector {
+	logger := zerolog.New(os.Stdout).With().Timestamp().Logger().Output(zerolog.ConsoleWriter{Out: os.Stdout})
+	return &loggingConnector{Connector: connector, logger: logger}
+}
+
+func (l *loggingConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	start := time.Now()
+	id := l.ids.Add(1)
+	conn, err := l.Connector.Connect(ctx)
+	l.log("Connect", start, err, id, "", nil)
+	if err != nil {
+		return nil, err
+	}
+	return &loggingConn{Conn: conn, connector: l, id: id}, nil
+}
+
+// maxLoggedArg bounds the strings and bytes of an argument that are logged
+const maxLoggedArg = 64
+
+func (l *loggingConnector) log(msg string, start time.Time, err error, connId int64, query string, args []driver.NamedValue) {
+	if err == driver.ErrSkip {
+		return
+	}
+	event := l.logger.Info()
+	if err != nil {
+		event = l.logger.Error().Err(err)
+	}
+	event = event.Float64("duration", float64(time.Since(start).Microseconds())/1000).Int64("conn_id", connId)
+	if query != "" {
+		event = event.Str("query", query)
+	}
+	if len(args) > 0 {
+		values := make([]any, len(args))
+		for i, arg := range args {
+			values[i] = arg.Value
+			switch value := arg.Value.(type) {
+			case string:
+				if len(value) > maxLoggedArg {
+					values[i] = value[:maxLoggedArg] + "..."
+				}
+			case []byte:
+				if len(value) > maxLoggedArg {
+					values[i] = fmt.Sprintf("%d bytes", len(value))
+				} else {
+					values[i] = string(value)
+				}
+			}
+		}
+		event = event.Interface("args", values)
+	}
+	event.Msg(msg)
+}
+
+// loggingConn passes every call to Conn, which has to implement the context
+// methods as lib/pq does, and logs it
+type loggingConn struct {
+	driver.Conn
+	connector *loggingConnector
+	id        int64
+}
+
+func (c *loggingConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	start := time.Now()
+	stmt, err := c.Conn.(driver.ConnPrepareContext).PrepareContext(ctx, query)
+	c.connector.log("PrepareContext", start, err, c.id, query, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &loggingStmt{Stmt: stmt, conn: c, query: query}, nil
+}
+
+func (c *loggingConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	start := time.Now()
+	tx, err := c.Conn.(driver.ConnBeginTx).BeginTx(ctx, opts)
+	c.connector.log("BeginTx", start, err, c.id, "", nil)
+	if err != nil {
+		return nil, err
+	}
+	return &loggingTx{Tx: tx, conn: c}, nil
+}
+
+func (c *loggingConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	start := time.Now()
+	result, err := c.Conn.(driver.ExecerContext).ExecContext(ctx, query, args)
+	c.connector.log("ExecContext", start, err, c.id, query, args)
+	return result, err
+}
+
+func (c *loggingConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	start := time.Now()
+	rows, err := c.Conn.(driver.QueryerContext).QueryContext(ctx, query, args)
+	c.connector.log("QueryContext", start, err, c.id, query, args)
+	return rows, err
+}
+
+func (c *loggingConn) Ping(ctx context.Context) error {
+	start := time.Now()
+	err := c.Conn.(driver.Pinger).Ping(ctx)
+	c.connector.log("Ping", start, err, c.id, "", nil)
+	return err
+}
+
+func (c *loggingConn) ResetSession(ctx context.Context) error {
+	if resetter, ok := c.Conn.(driver.SessionResetter); ok {
+		return resetter.ResetSession(ctx)
+	}
+	return nil
+}
+
+func (c *loggingConn) IsValid() bool {
+	if validator, ok := c.Conn.(driver.Validator); ok {
+		return validator.IsValid()
+	}
+	return true
+}
+
+func (c *loggingConn) Close() error {
+	start := time.Now()
+	err := c.Conn.Close()
+	c.connector.log("Close", start, err, c.id, "", nil)
+	return err
+}
+
+type loggingStmt struct {
+	driver.Stmt
+	conn  *loggingConn
+	query string
+}
+
+func (s *loggingStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	start := time.Now()
+	result, err := s.Stmt.(driver.StmtExecContext).ExecContext(ctx, args)
+	s.conn.connector.log("StmtExecContext", start, err, s.conn.id, s.query, args)
+	return result, err
+}
+
+func (s *loggingStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	start := time.Now()
+	rows, err := s.Stmt.(driver.StmtQueryContext).QueryContext(ctx, args)
+	s.conn.connector.log("StmtQueryContext", start, err, s.conn.id, s.query, args)
+	return rows, err
+}
+
+type loggingTx struct {
+	driver.Tx
+	conn *loggingConn
+}
+
+func (t *loggingTx) Commit() error {
+	start := time.Now()
+	err := t.Tx.Commit()
+	t.conn.connector.log("Commit", start, err, t.conn.id, "", nil)
+	return err
+}
+
+func (t *loggingTx) Rollback() error {
+	start := time.Now()
+	err := t.Tx.Rollback()
+	t.conn.connector.log("Rollback", start, err, t.conn.id, "", nil)
+	return err
+}
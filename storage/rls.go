@@ -0,0 +1,20 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+)
+
+// SetRowSecurityUser switches the transaction in ctx to the splitexpense_rls
+// role with userId as app.user_id, so the row level security policies apply
+// to everything it does afterwards. Both are reset when the transaction ends.
+func (d *DBStorage) SetRowSecurityUser(ctx context.Context, userId string) error {
+	if _, ok := ctx.Value(txKey{}).(*sql.Tx); !ok {
+		return errors.New("row level security needs a transaction")
+	}
+	if err := d.q(ctx).SetRowSecurityRole(ctx); err != nil {
+		return err
+	}
+	return d.q(ctx).SetRowSecurityUser(ctx, userId)
+}
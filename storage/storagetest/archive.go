@@ -0,0 +1,71 @@
+package storagetest
+
+import (
+	"testing"
+	"time"
+
+	"splitExpense/expense"
+)
+
+func testArchive(t *testing.T, s expense.Storage) {
+	f := newFixture(t, s)
+	user, friend := f.user(), f.user()
+	group := f.group(user, friend)
+	base := now().Add(-time.Hour)
+
+	draft := f.expense(user.ID, group.Id, base, user.ID, friend.ID)
+	settled := f.expense(user.ID, group.Id, base.Add(time.Minute), user.ID, friend.ID)
+	settled.Status = expense.ExpenseSettled
+	settled.SettledBy = user.ID
+	if _, err := s.CreateOrUpdateExpense(f.ctx, settled); err != nil {
+		t.Fatalf("CreateOrUpdateExpense: %v", err)
+	}
+
+	// the database is shared, so archive until nothing settled before the cutoff is left.
+	// updates stamp updated_at in Asia/Kolkata time, so the cutoff leaves a day of slack
+	cutoff := time.Now().Add(24 * time.Hour)
+	for {
+		moved, err := s.ArchiveSettledExpenses(f.ctx, cutoff, 100)
+		if err != nil {
+			t.Fatalf("ArchiveSettledExpenses: %v", err)
+		}
+		if moved == 0 {
+			break
+		}
+	}
+
+	if _, err := s.FetchExpense(f.ctx, draft.ID); err != nil {
+		t.Fatalf("draft expense was archived: %v", err)
+	}
+	if _, err := s.FetchExpense(f.ctx, settled.ID); err == nil {
+		t.Fatalf("settled expense is still in the live table")
+	}
+
+	live, err := s.FetchGroupExpensesPage(f.ctx, group.Id, "", false, expense.PageRequest{})
+	if err != nil {
+		t.Fatalf("FetchGroupExpensesPage: %v", err)
+	}
+	equalIds(t, "FetchGroupExpensesPage live", expenseIds(live.Expenses), []string{draft.ID})
+
+	all, err := s.FetchGroupExpensesPage(f.ctx, group.Id, "", true, expense.PageRequest{})
+	if err != nil {
+		t.Fatalf("FetchGroupExpensesPage: %v", err)
+	}
+	equalIds(t, "FetchGroupExpensesPage with archive", expenseIds(all.Expenses), []string{settled.ID, draft.ID})
+	if archived := all.Expenses[0]; archived.Status != expense.ExpenseSettled || archived.SettledBy != user.ID {
+		t.Fatalf("archived expense lost fields: %+v", archived)
+	}
+
+	// archived mappings still tie the expense to its members
+	found, err := s.SearchExpenses(f.ctx, friend.ID, expense.ExpenseSearch{GroupId: group.Id, IncludeArchived: true}, expense.PageRequest{})
+	if err != nil {
+		t.Fatalf("SearchExpenses: %v", err)
+	}
+	equalIds(t, "SearchExpenses with archive", expenseIds(found.Expenses), []string{settled.ID, draft.ID})
+
+	found, err = s.SearchExpenses(f.ctx, friend.ID, expense.ExpenseSearch{GroupId: group.Id}, expense.PageRequest{})
+	if err != nil {
+		t.Fatalf("SearchExpenses: %v", err)
+	}
+	equalIds(t, "SearchExpenses live", expenseIds(found.Expenses), []string{draft.ID})
+}
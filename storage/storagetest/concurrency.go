@@ -0,0 +1,113 @@
+package storagetest
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"splitExpense/expense"
+)
+
+const workers = 8
+
+func testConcurrency(t *testing.T, s expense.Storage) {
+	f := newFixture(t, s)
+
+	t.Run("JoinGroup", func(t *testing.T) {
+		admin := f.user()
+		group := f.group(admin)
+		var users []expense.User
+		for i := 0; i < workers; i++ {
+			users = append(users, f.user())
+		}
+
+		var wg sync.WaitGroup
+		errs := make(chan error, workers*2)
+		for _, u := range users {
+			// every user joins twice at the same time
+			for i := 0; i < 2; i++ {
+				wg.Add(1)
+				go func(userId string) {
+					defer wg.Done()
+					if _, err := s.AddUserInGroup(f.ctx, userId, group.Id); err != nil {
+						errs <- err
+					}
+				}(u.ID)
+			}
+		}
+		wg.Wait()
+		close(errs)
+		for err := range errs {
+			t.Fatalf("concurrent AddUserInGroup: %v", err)
+		}
+
+		members, err := s.FetchGroupMembers(f.ctx, group.Id)
+		if err != nil {
+			t.Fatalf("FetchGroupMembers: %v", err)
+		}
+		if len(members) != workers+1 {
+			t.Fatalf("FetchGroupMembers: got %d members, want %d", len(members), workers+1)
+		}
+	})
+
+	t.Run("ExpenseMapping", func(t *testing.T) {
+		user, friend := f.user(), f.user()
+		exp := f.expense(user.ID, "", now(), user.ID)
+
+		var wg sync.WaitGroup
+		errs := make(chan error, workers)
+		for i := 0; i < workers; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if _, err := s.AddExpenseMapping(f.ctx, exp.ID, friend.ID); err != nil {
+					errs <- err
+				}
+			}()
+		}
+		wg.Wait()
+		close(errs)
+		for err := range errs {
+			t.Fatalf("concurrent AddExpenseMapping: %v", err)
+		}
+	})
+
+	t.Run("OneWriterPerVersion", func(t *testing.T) {
+		user := f.user()
+		exp := f.expense(user.ID, "", now(), user.ID)
+
+		var wg sync.WaitGroup
+		var mu sync.Mutex
+		succeeded, conflicts := 0, 0
+		for i := 0; i < workers; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				edit := exp
+				edit.Description = "writer"
+				_, err := s.CreateOrUpdateExpense(f.ctx, edit)
+
+				mu.Lock()
+				defer mu.Unlock()
+				var conflict *expense.VersionConflictError
+				switch {
+				case err == nil:
+					succeeded++
+				case errors.As(err, &conflict):
+					conflicts++
+				default:
+					t.Errorf("concurrent CreateOrUpdateExpense: %v", err)
+				}
+			}()
+		}
+		wg.Wait()
+
+		if succeeded != 1 || conflicts != workers-1 {
+			t.Fatalf("writers with the same version: got %d succeeded and %d conflicts, want 1 and %d", succeeded, conflicts, workers-1)
+		}
+		fetched, err := s.FetchExpense(f.ctx, exp.ID)
+		if err != nil || fetched.Version != exp.Version+1 {
+			t.Fatalf("FetchExpense after concurrent writes: got (%+v, %v), want version %d", fetched, err, exp.Version+1)
+		}
+	})
+}
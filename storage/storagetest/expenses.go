@@ -0,0 +1,254 @@
+package storagetest
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"splitExpense/expense"
+
+	"github.com/google/uuid"
+)
+
+func testExpenses(t *testing.T, s expense.Storage) {
+	f := newFixture(t, s)
+
+	t.Run("CreateAndFetch", func(t *testing.T) {
+		admin, member := f.user(), f.user()
+		group := f.group(admin, member)
+		createdAt := now().Add(-time.Minute)
+		exp := f.expense(admin.ID, group.Id, createdAt, admin.ID, member.ID)
+
+		if exp.Version != 1 {
+			t.Fatalf("new expense version: got %d, want 1", exp.Version)
+		}
+
+		fetched, err := s.FetchExpense(f.ctx, exp.ID)
+		if err != nil {
+			t.Fatalf("FetchExpense: %v", err)
+		}
+		if fetched.Amount != 90 || fetched.Status != expense.ExpenseDraft || fetched.CreatedBy != admin.ID {
+			t.Fatalf("FetchExpense: got %+v", fetched)
+		}
+		if !fetched.IsGroupExpense || fetched.GroupId != group.Id {
+			t.Fatalf("FetchExpense group: got (%v, %s), want (true, %s)", fetched.IsGroupExpense, fetched.GroupId, group.Id)
+		}
+		if !fetched.CreatedAt.Equal(createdAt) {
+			t.Fatalf("FetchExpense CreatedAt: got %v, want %v", fetched.CreatedAt, createdAt)
+		}
+		if fetched.SettledBy != "" {
+			t.Fatalf("FetchExpense SettledBy of a draft: got %q, want empty", fetched.SettledBy)
+		}
+		split := fetched.SplitW.Split.GetPayeeSplit()
+		if split[admin.ID] != 45 || split[member.ID] != 45 {
+			t.Fatalf("FetchExpense split: got %v", split)
+		}
+		if fetched.PayeeW.Payer.GetPayers()[admin.ID] != 90 {
+			t.Fatalf("FetchExpense payers: got %v", fetched.PayeeW.Payer.GetPayers())
+		}
+
+		if _, err := s.FetchExpense(f.ctx, uuid.NewString()); !errors.Is(err, sql.ErrNoRows) {
+			t.Fatalf("FetchExpense of a missing expense: got %v, want sql.ErrNoRows", err)
+		}
+	})
+
+	t.Run("PersonalExpense", func(t *testing.T) {
+		user, friend := f.user(), f.user()
+		exp := f.expense(user.ID, "", now(), user.ID, friend.ID)
+
+		fetched, err := s.FetchExpense(f.ctx, exp.ID)
+		if err != nil {
+			t.Fatalf("FetchExpense: %v", err)
+		}
+		if fetched.IsGroupExpense {
+			t.Fatalf("FetchExpense: personal expense reported as group expense")
+		}
+	})
+
+	t.Run("UpdateChecksVersion", func(t *testing.T) {
+		user := f.user()
+		exp := f.expense(user.ID, "", now(), user.ID)
+
+		exp.Description = "edited"
+		updated, err := s.CreateOrUpdateExpense(f.ctx, exp)
+		if err != nil {
+			t.Fatalf("CreateOrUpdateExpense: %v", err)
+		}
+		if updated.Description != "edited" || updated.Version != 2 {
+			t.Fatalf("CreateOrUpdateExpense: got description %q version %d", updated.Description, updated.Version)
+		}
+
+		exp.Description = "stale"
+		_, err = s.CreateOrUpdateExpense(f.ctx, exp)
+		var conflict *expense.VersionConflictError
+		if !errors.As(err, &conflict) {
+			t.Fatalf("stale CreateOrUpdateExpense: got %v, want VersionConflictError", err)
+		}
+		if conflict.CurrentVersion != 2 {
+			t.Fatalf("VersionConflictError.CurrentVersion: got %d, want 2", conflict.CurrentVersion)
+		}
+
+		fetched, err := s.FetchExpense(f.ctx, exp.ID)
+		if err != nil || fetched.Description != "edited" {
+			t.Fatalf("stale write must not be applied: got (%+v, %v)", fetched, err)
+		}
+	})
+
+	t.Run("ConsecutiveUpdates", func(t *testing.T) {
+		user, friend := f.user(), f.user()
+		exp := f.expense(user.ID, "", now(), user.ID, friend.ID)
+
+		exp.Description = "first edit"
+		first, err := s.CreateOrUpdateExpense(f.ctx, exp)
+		if err != nil {
+			t.Fatalf("first CreateOrUpdateExpense: %v", err)
+		}
+		first.Description = "second edit"
+		second, err := s.CreateOrUpdateExpense(f.ctx, *first)
+		if err != nil {
+			t.Fatalf("second CreateOrUpdateExpense: %v", err)
+		}
+		if second.Version != 3 || second.Description != "second edit" {
+			t.Fatalf("second CreateOrUpdateExpense: got description %q version %d", second.Description, second.Version)
+		}
+
+		second.Status = expense.ExpenseSettled
+		second.SettledBy = friend.ID
+		settled, err := s.CreateOrUpdateExpense(f.ctx, *second)
+		if err != nil || settled.Version != 4 {
+			t.Fatalf("settle at version 3: got (%+v, %v)", settled, err)
+		}
+
+		// first still carries version 2
+		_, err = s.CreateOrUpdateExpense(f.ctx, *first)
+		var conflict *expense.VersionConflictError
+		if !errors.As(err, &conflict) || conflict.CurrentVersion != 4 {
+			t.Fatalf("stale CreateOrUpdateExpense: got %v, want VersionConflictError at 4", err)
+		}
+	})
+
+	t.Run("Settle", func(t *testing.T) {
+		user, friend := f.user(), f.user()
+		exp := f.expense(user.ID, "", now(), user.ID, friend.ID)
+
+		exp.Status = expense.ExpenseSettled
+		exp.SettledBy = friend.ID
+		settled, err := s.CreateOrUpdateExpense(f.ctx, exp)
+		if err != nil {
+			t.Fatalf("CreateOrUpdateExpense: %v", err)
+		}
+		if settled.Status != expense.ExpenseSettled || settled.SettledBy != friend.ID {
+			t.Fatalf("settle: got status %s settled by %q", settled.Status, settled.SettledBy)
+		}
+	})
+
+	t.Run("Mappings", func(t *testing.T) {
+		user, friend := f.user(), f.user()
+		exp := f.expense(user.ID, "", now(), user.ID, friend.ID)
+
+		ok, err := s.AddExpenseMapping(f.ctx, exp.ID, friend.ID)
+		if err != nil || !ok {
+			t.Fatalf("AddExpenseMapping again: got (%v, %v), want (true, nil)", ok, err)
+		}
+
+		removed, err := s.RemoveUsersFromExpense(f.ctx, exp.ID, []string{friend.ID})
+		if err != nil || !removed {
+			t.Fatalf("RemoveUsersFromExpense: got (%v, %v), want (true, nil)", removed, err)
+		}
+		stored, err := s.FetchExpenseByUserAndStatus(f.ctx, friend.ID, expense.ExpenseDraft, 1, 10)
+		if err != nil {
+			t.Fatalf("FetchExpenseByUserAndStatus: %v", err)
+		}
+		if contains(expenseIds(stored.Expenses), exp.ID) {
+			t.Fatalf("expense still listed for a removed user")
+		}
+
+		removed, err = s.RemoveUsersFromExpense(f.ctx, exp.ID, []string{friend.ID})
+		if err != nil || removed {
+			t.Fatalf("RemoveUsersFromExpense of unmapped users: got (%v, %v), want (false, nil)", removed, err)
+		}
+		removed, err = s.RemoveUsersFromExpense(f.ctx, exp.ID, nil)
+		if err != nil || removed {
+			t.Fatalf("RemoveUsersFromExpense of nobody: got (%v, %v), want (false, nil)", removed, err)
+		}
+	})
+
+	t.Run("Bulk", func(t *testing.T) {
+		admin, member := f.user(), f.user()
+		group := f.group(admin, member)
+		first := newExpense(admin.ID, group.Id, now().Add(-time.Minute), 30, admin.ID, member.ID)
+		second := newExpense(member.ID, group.Id, now(), 60, admin.ID, member.ID)
+
+		created, err := s.CreateExpenses(f.ctx, []expense.Expense{first, second})
+		if err != nil {
+			t.Fatalf("CreateExpenses: %v", err)
+		}
+		equalIds(t, "CreateExpenses", expenseIds(created), []string{first.ID, second.ID})
+		if created[0].Version != 1 || created[1].Amount != 60 {
+			t.Fatalf("CreateExpenses: got %+v", created)
+		}
+
+		mappings := map[string][]string{first.ID: {admin.ID, member.ID}, second.ID: {member.ID}}
+		if err := s.AddExpenseMappings(f.ctx, mappings); err != nil {
+			t.Fatalf("AddExpenseMappings: %v", err)
+		}
+		if err := s.AddExpenseMappings(f.ctx, mappings); err != nil {
+			t.Fatalf("AddExpenseMappings again: %v", err)
+		}
+		byUser, err := s.FetchExpenseByUserAndStatus(f.ctx, member.ID, expense.ExpenseDraft, 1, 10)
+		if err != nil {
+			t.Fatalf("FetchExpenseByUserAndStatus: %v", err)
+		}
+		equalIds(t, "FetchExpenseByUserAndStatus", expenseIds(byUser.Expenses), []string{second.ID, first.ID})
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		user := f.user()
+		exp := f.expense(user.ID, "", now(), user.ID)
+
+		deleted, err := s.DeleteExpense(f.ctx, exp.ID)
+		if err != nil || !deleted {
+			t.Fatalf("DeleteExpense: got (%v, %v), want (true, nil)", deleted, err)
+		}
+		if _, err := s.FetchExpense(f.ctx, exp.ID); !errors.Is(err, sql.ErrNoRows) {
+			t.Fatalf("FetchExpense after delete: got %v, want sql.ErrNoRows", err)
+		}
+		deleted, err = s.DeleteExpense(f.ctx, exp.ID)
+		if err != nil || deleted {
+			t.Fatalf("DeleteExpense of a missing expense: got (%v, %v), want (false, nil)", deleted, err)
+		}
+	})
+
+	t.Run("ByStatus", func(t *testing.T) {
+		admin, member := f.user(), f.user()
+		group := f.group(admin, member)
+		base := now().Add(-time.Hour)
+
+		draft := f.expense(admin.ID, group.Id, base, admin.ID, member.ID)
+		settled := f.expense(admin.ID, group.Id, base.Add(time.Minute), admin.ID, member.ID)
+		settled.Status = expense.ExpenseSettled
+		settled.SettledBy = admin.ID
+		if _, err := s.CreateOrUpdateExpense(f.ctx, settled); err != nil {
+			t.Fatalf("CreateOrUpdateExpense: %v", err)
+		}
+
+		byGroup, err := s.FetchGroupExpensesByStatus(f.ctx, group.Id, expense.ExpenseDraft, 1)
+		if err != nil {
+			t.Fatalf("FetchGroupExpensesByStatus: %v", err)
+		}
+		equalIds(t, "FetchGroupExpensesByStatus", expenseIds(byGroup.Expenses), []string{draft.ID})
+		if byGroup.TotalPages != 1 {
+			t.Fatalf("FetchGroupExpensesByStatus TotalPages: got %d, want 1", byGroup.TotalPages)
+		}
+
+		byUser, err := s.FetchExpenseByUserAndStatus(f.ctx, member.ID, expense.ExpenseSettled, 1, 10)
+		if err != nil {
+			t.Fatalf("FetchExpenseByUserAndStatus: %v", err)
+		}
+		equalIds(t, "FetchExpenseByUserAndStatus", expenseIds(byUser.Expenses), []string{settled.ID})
+		if byUser.TotalPages != 1 {
+			t.Fatalf("FetchExpenseByUserAndStatus TotalPages: got %d, want 1", byUser.TotalPages)
+		}
+	})
+}
@@ -0,0 +1,225 @@
+package storagetest
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"splitExpense/expense"
+
+	"github.com/google/uuid"
+)
+
+func testGroups(t *testing.T, s expense.Storage) {
+	f := newFixture(t, s)
+
+	t.Run("CreateAndFetch", func(t *testing.T) {
+		admin := f.user()
+		group := f.group(admin)
+		if group.Version != 1 {
+			t.Fatalf("new group version: got %d, want 1", group.Version)
+		}
+
+		fetched, err := s.FetchGroupById(f.ctx, group.Id)
+		if err != nil {
+			t.Fatalf("FetchGroupById: %v", err)
+		}
+		if fetched.Name != group.Name || fetched.Admin != admin.ID || fetched.Version != 1 {
+			t.Fatalf("FetchGroupById: got %+v, want %+v", fetched, group)
+		}
+
+		if _, err := s.FetchGroupById(f.ctx, uuid.NewString()); !errors.Is(err, sql.ErrNoRows) {
+			t.Fatalf("FetchGroupById of a missing group: got %v, want sql.ErrNoRows", err)
+		}
+	})
+
+	t.Run("UpdateChecksVersion", func(t *testing.T) {
+		group := f.group(f.user())
+
+		group.Name = "renamed"
+		updated, err := s.CreateOrUpdateGroup(f.ctx, group)
+		if err != nil {
+			t.Fatalf("CreateOrUpdateGroup: %v", err)
+		}
+		if updated.Name != "renamed" || updated.Version != group.Version+1 {
+			t.Fatalf("CreateOrUpdateGroup: got %+v", updated)
+		}
+
+		// group still carries the version read before the update
+		group.Name = "stale"
+		_, err = s.CreateOrUpdateGroup(f.ctx, group)
+		var conflict *expense.VersionConflictError
+		if !errors.As(err, &conflict) {
+			t.Fatalf("stale CreateOrUpdateGroup: got %v, want VersionConflictError", err)
+		}
+		if conflict.CurrentVersion != updated.Version {
+			t.Fatalf("VersionConflictError.CurrentVersion: got %d, want %d", conflict.CurrentVersion, updated.Version)
+		}
+	})
+
+	t.Run("ConsecutiveUpdates", func(t *testing.T) {
+		group := f.group(f.user())
+
+		group.Name = "first name"
+		first, err := s.CreateOrUpdateGroup(f.ctx, group)
+		if err != nil {
+			t.Fatalf("first CreateOrUpdateGroup: %v", err)
+		}
+		first.Name = "second name"
+		second, err := s.CreateOrUpdateGroup(f.ctx, *first)
+		if err != nil {
+			t.Fatalf("second CreateOrUpdateGroup: %v", err)
+		}
+		if second.Name != "second name" || second.Version != group.Version+2 {
+			t.Fatalf("second CreateOrUpdateGroup: got %+v", second)
+		}
+
+		// first still carries the version of the first update
+		_, err = s.CreateOrUpdateGroup(f.ctx, *first)
+		var conflict *expense.VersionConflictError
+		if !errors.As(err, &conflict) || conflict.CurrentVersion != second.Version {
+			t.Fatalf("stale CreateOrUpdateGroup: got %v, want VersionConflictError at %d", err, second.Version)
+		}
+	})
+
+	t.Run("Membership", func(t *testing.T) {
+		admin, member, outsider := f.user(), f.user(), f.user()
+		group := f.group(admin, member)
+
+		// joining twice is not an error
+		ok, err := s.AddUserInGroup(f.ctx, member.ID, group.Id)
+		if err != nil || !ok {
+			t.Fatalf("AddUserInGroup again: got (%v, %v), want (true, nil)", ok, err)
+		}
+
+		members, err := s.FetchGroupMembers(f.ctx, group.Id)
+		if err != nil {
+			t.Fatalf("FetchGroupMembers: %v", err)
+		}
+		ids := userIds(members)
+		if len(ids) != 2 || !contains(ids, admin.ID) || !contains(ids, member.ID) {
+			t.Fatalf("FetchGroupMembers: got %v, want admin and member", ids)
+		}
+
+		for _, tc := range []struct {
+			user expense.User
+			want bool
+		}{{admin, true}, {member, true}, {outsider, false}} {
+			exists, err := s.CheckUserExistsInGroup(f.ctx, tc.user.ID, group.Id)
+			if err != nil || exists != tc.want {
+				t.Fatalf("CheckUserExistsInGroup(%s): got (%v, %v), want %v", tc.user.Name, exists, err, tc.want)
+			}
+		}
+
+		groups, err := s.FetchGroupsByUser(f.ctx, member.ID)
+		if err != nil {
+			t.Fatalf("FetchGroupsByUser: %v", err)
+		}
+		if len(groups) != 1 || groups[0].Id != group.Id {
+			t.Fatalf("FetchGroupsByUser: got %+v", groups)
+		}
+
+		if _, err := s.RemoveUserFromGroup(f.ctx, member.ID, group.Id); err != nil {
+			t.Fatalf("RemoveUserFromGroup: %v", err)
+		}
+		exists, err := s.CheckUserExistsInGroup(f.ctx, member.ID, group.Id)
+		if err != nil || exists {
+			t.Fatalf("CheckUserExistsInGroup after leaving: got (%v, %v), want false", exists, err)
+		}
+		groups, err = s.FetchGroupsByUser(f.ctx, member.ID)
+		if err != nil || len(groups) != 0 {
+			t.Fatalf("FetchGroupsByUser after leaving: got (%+v, %v)", groups, err)
+		}
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		admin := f.user()
+		group := f.group(admin)
+		f.expense(admin.ID, group.Id, now(), admin.ID)
+
+		deleted, err := s.DeleteGroup(f.ctx, group.Id)
+		if err != nil || !deleted {
+			t.Fatalf("DeleteGroup: got (%v, %v), want (true, nil)", deleted, err)
+		}
+		if _, err := s.FetchGroupById(f.ctx, group.Id); !errors.Is(err, sql.ErrNoRows) {
+			t.Fatalf("FetchGroupById after delete: got %v, want sql.ErrNoRows", err)
+		}
+		// memberships and group expenses go with the group
+		groups, err := s.FetchGroupsByUser(f.ctx, admin.ID)
+		if err != nil || len(groups) != 0 {
+			t.Fatalf("FetchGroupsByUser after delete: got (%+v, %v)", groups, err)
+		}
+		count, err := s.FetchExpenseCountByGroup(f.ctx, group.Id)
+		if err != nil || count != 0 {
+			t.Fatalf("FetchExpenseCountByGroup after delete: got (%d, %v), want 0", count, err)
+		}
+		// but the expenses stay readable from the archive
+		history, err := s.FetchGroupExpensesPage(f.ctx, group.Id, "", true, expense.PageRequest{Limit: 10})
+		if err != nil || len(history.Expenses) != 1 {
+			t.Fatalf("FetchGroupExpensesPage of archived expenses: got (%+v, %v), want 1 expense", history, err)
+		}
+
+		deleted, err = s.DeleteGroup(f.ctx, group.Id)
+		if err != nil || deleted {
+			t.Fatalf("DeleteGroup of a missing group: got (%v, %v), want (false, nil)", deleted, err)
+		}
+	})
+
+	t.Run("CountUnsettled", func(t *testing.T) {
+		admin := f.user()
+		group := f.group(admin)
+		f.expense(admin.ID, group.Id, now(), admin.ID)
+
+		count, err := s.CountUnsettledGroupExpenses(f.ctx, group.Id)
+		if err != nil || count != 1 {
+			t.Fatalf("CountUnsettledGroupExpenses: got (%d, %v), want 1", count, err)
+		}
+	})
+
+	t.Run("LockGroup", func(t *testing.T) {
+		group := f.group(f.user())
+
+		err := s.RunInTx(f.ctx, func(ctx context.Context) error {
+			locked, err := s.LockGroup(ctx, group.Id)
+			if err != nil || !locked {
+				t.Fatalf("LockGroup: got (%v, %v), want (true, nil)", locked, err)
+			}
+			locked, err = s.LockGroup(ctx, uuid.NewString())
+			if err != nil || locked {
+				t.Fatalf("LockGroup of a missing group: got (%v, %v), want (false, nil)", locked, err)
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("RunInTx: %v", err)
+		}
+	})
+
+	t.Run("PageNumberExpenses", func(t *testing.T) {
+		admin, member := f.user(), f.user()
+		group := f.group(admin, member)
+		base := now().Add(-time.Hour)
+
+		var want []string
+		for i := 0; i < 3; i++ {
+			exp := f.expense(admin.ID, group.Id, base.Add(time.Duration(i)*time.Minute), admin.ID, member.ID)
+			want = append([]string{exp.ID}, want...)
+		}
+
+		stored, err := s.FetchGroupExpenses(f.ctx, group.Id, 1)
+		if err != nil {
+			t.Fatalf("FetchGroupExpenses: %v", err)
+		}
+		equalIds(t, "FetchGroupExpenses", expenseIds(stored.Expenses), want)
+		if stored.PageNumber != 1 || stored.TotalPages != 1 {
+			t.Fatalf("FetchGroupExpenses: got page %d of %d, want 1 of 1", stored.PageNumber, stored.TotalPages)
+		}
+
+		count, err := s.FetchExpenseCountByGroup(f.ctx, group.Id)
+		if err != nil || count != 3 {
+			t.Fatalf("FetchExpenseCountByGroup: got (%d, %v), want 3", count, err)
+		}
+	})
+}
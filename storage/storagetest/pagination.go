@@ -0,0 +1,176 @@
+package storagetest
+
+import (
+	"testing"
+	"time"
+
+	"splitExpense/expense"
+)
+
+func testPagination(t *testing.T, s expense.Storage) {
+	f := newFixture(t, s)
+
+	t.Run("GroupExpensesNewestFirst", func(t *testing.T) {
+		admin, member := f.user(), f.user()
+		group := f.group(admin, member)
+		base := now().Add(-time.Hour)
+
+		var want []string
+		for i := 0; i < 5; i++ {
+			exp := f.expense(admin.ID, group.Id, base.Add(time.Duration(i)*time.Minute), admin.ID, member.ID)
+			want = append([]string{exp.ID}, want...)
+		}
+
+		var got []string
+		page := expense.PageRequest{Limit: 2}
+		for i := 0; ; i++ {
+			stored, err := s.FetchGroupExpensesPage(f.ctx, group.Id, "", false, page)
+			if err != nil {
+				t.Fatalf("FetchGroupExpensesPage: %v", err)
+			}
+			got = append(got, expenseIds(stored.Expenses)...)
+
+			if i == 0 {
+				// an expense added while scrolling must not shift later pages
+				f.expense(admin.ID, group.Id, now(), admin.ID)
+			}
+			if !stored.HasMore {
+				if stored.NextCursor != "" {
+					t.Fatalf("last page has a next cursor")
+				}
+				break
+			}
+			if stored.NextCursor == "" {
+				t.Fatalf("page with more results has no next cursor")
+			}
+			page.Cursor = stored.NextCursor
+		}
+		equalIds(t, "FetchGroupExpensesPage", got, want)
+	})
+
+	t.Run("StatusFilter", func(t *testing.T) {
+		admin := f.user()
+		group := f.group(admin)
+		base := now().Add(-time.Hour)
+
+		draft := f.expense(admin.ID, group.Id, base, admin.ID)
+		settled := f.expense(admin.ID, group.Id, base.Add(time.Minute), admin.ID)
+		settled.Status = expense.ExpenseSettled
+		settled.SettledBy = admin.ID
+		if _, err := s.CreateOrUpdateExpense(f.ctx, settled); err != nil {
+			t.Fatalf("CreateOrUpdateExpense: %v", err)
+		}
+
+		stored, err := s.FetchGroupExpensesPage(f.ctx, group.Id, expense.ExpenseDraft, false, expense.PageRequest{})
+		if err != nil {
+			t.Fatalf("FetchGroupExpensesPage: %v", err)
+		}
+		equalIds(t, "FetchGroupExpensesPage drafts", expenseIds(stored.Expenses), []string{draft.ID})
+
+		byUser, err := s.FetchUserExpensesPage(f.ctx, admin.ID, expense.ExpenseSettled, expense.PageRequest{})
+		if err != nil {
+			t.Fatalf("FetchUserExpensesPage: %v", err)
+		}
+		equalIds(t, "FetchUserExpensesPage settled", expenseIds(byUser.Expenses), []string{settled.ID})
+	})
+
+	t.Run("UserExpenses", func(t *testing.T) {
+		user, friend := f.user(), f.user()
+		base := now().Add(-time.Hour)
+
+		var want []string
+		for i := 0; i < 3; i++ {
+			exp := f.expense(user.ID, "", base.Add(time.Duration(i)*time.Minute), user.ID, friend.ID)
+			want = append([]string{exp.ID}, want...)
+		}
+
+		var got []string
+		page := expense.PageRequest{Limit: 2}
+		for {
+			stored, err := s.FetchUserExpensesPage(f.ctx, friend.ID, "", page)
+			if err != nil {
+				t.Fatalf("FetchUserExpensesPage: %v", err)
+			}
+			got = append(got, expenseIds(stored.Expenses)...)
+			if !stored.HasMore {
+				break
+			}
+			page.Cursor = stored.NextCursor
+		}
+		equalIds(t, "FetchUserExpensesPage", got, want)
+	})
+
+	t.Run("Friends", func(t *testing.T) {
+		user := f.user()
+		want := map[string]bool{}
+		for i := 0; i < 5; i++ {
+			friend := f.user()
+			if _, err := s.AddFriend(f.ctx, user.ID, friend.ID); err != nil {
+				t.Fatalf("AddFriend: %v", err)
+			}
+			want[friend.ID] = true
+		}
+
+		seen := map[string]bool{}
+		page := expense.PageRequest{Limit: 2}
+		for {
+			friends, err := s.GetFriendsPage(f.ctx, user.ID, page)
+			if err != nil {
+				t.Fatalf("GetFriendsPage: %v", err)
+			}
+			if len(friends.Users) > 2 {
+				t.Fatalf("GetFriendsPage returned %d users for limit 2", len(friends.Users))
+			}
+			for _, u := range friends.Users {
+				if seen[u.ID] || !want[u.ID] {
+					t.Fatalf("GetFriendsPage: unexpected or repeated friend %s", u.ID)
+				}
+				seen[u.ID] = true
+			}
+			if !friends.HasMore {
+				break
+			}
+			page.Cursor = friends.NextCursor
+		}
+		if len(seen) != len(want) {
+			t.Fatalf("GetFriendsPage: got %d friends, want %d", len(seen), len(want))
+		}
+	})
+
+	t.Run("Groups", func(t *testing.T) {
+		user := f.user()
+		want := map[string]bool{}
+		for i := 0; i < 3; i++ {
+			want[f.group(user).Id] = true
+		}
+
+		seen := map[string]bool{}
+		page := expense.PageRequest{Limit: 2}
+		for {
+			groups, err := s.FetchGroupsByUserPage(f.ctx, user.ID, page)
+			if err != nil {
+				t.Fatalf("FetchGroupsByUserPage: %v", err)
+			}
+			for _, g := range groups.Groups {
+				if seen[g.Id] || !want[g.Id] {
+					t.Fatalf("FetchGroupsByUserPage: unexpected or repeated group %s", g.Id)
+				}
+				seen[g.Id] = true
+			}
+			if !groups.HasMore {
+				break
+			}
+			page.Cursor = groups.NextCursor
+		}
+		if len(seen) != len(want) {
+			t.Fatalf("FetchGroupsByUserPage: got %d groups, want %d", len(seen), len(want))
+		}
+	})
+
+	t.Run("InvalidCursor", func(t *testing.T) {
+		user := f.user()
+		if _, err := s.FetchUserExpensesPage(f.ctx, user.ID, "", expense.PageRequest{Cursor: "not-a-cursor"}); err == nil {
+			t.Fatalf("FetchUserExpensesPage accepted an invalid cursor")
+		}
+	})
+}
@@ -0,0 +1,100 @@
+package storagetest
+
+import (
+	"testing"
+	"time"
+
+	"splitExpense/expense"
+)
+
+func testSearch(t *testing.T, s expense.Storage) {
+	f := newFixture(t, s)
+	user, friend, other := f.user(), f.user(), f.user()
+	group := f.group(user, friend)
+	base := now().Add(-48 * time.Hour)
+
+	save := func(exp expense.Expense, participants ...string) expense.Expense {
+		t.Helper()
+		saved, err := s.CreateOrUpdateExpense(f.ctx, exp)
+		if err != nil {
+			t.Fatalf("CreateOrUpdateExpense: %v", err)
+		}
+		for _, uid := range participants {
+			if _, err := s.AddExpenseMapping(f.ctx, saved.ID, uid); err != nil {
+				t.Fatalf("AddExpenseMapping: %v", err)
+			}
+		}
+		return *saved
+	}
+
+	villa := newExpense(user.ID, group.Id, base, 900, user.ID, friend.ID)
+	villa.Description = "Goa villa booking"
+	villa.Category = "Travel"
+	villa = save(villa, user.ID, friend.ID)
+
+	dinner := newExpense(friend.ID, "", base.Add(24*time.Hour), 60, user.ID, friend.ID)
+	dinner.PayeeW = expense.PayerWrapper{Type: "single", Payer: &expense.SinglePayer{Payer: friend.ID, Amount: 60}}
+	dinner.Description = "Dinner at the villa"
+	dinner.Category = "food"
+	dinner = save(dinner, user.ID, friend.ID)
+
+	taxi := newExpense(user.ID, "", base.Add(36*time.Hour), 15, user.ID, other.ID)
+	taxi.Description = "Airport taxi"
+	taxi.Category = "travel"
+	taxi = save(taxi, user.ID, other.ID)
+
+	// someone else's expense never shows up in user's results
+	save(newExpense(other.ID, "", base, 10, other.ID), other.ID)
+
+	fetched, err := s.FetchExpense(f.ctx, villa.ID)
+	if err != nil || fetched.Category != "Travel" {
+		t.Fatalf("FetchExpense: got category %q (err %v), want Travel", fetched.Category, err)
+	}
+
+	amount := func(a float64) *float64 { return &a }
+	at := func(t time.Time) *time.Time { return &t }
+
+	cases := []struct {
+		name   string
+		search expense.ExpenseSearch
+		want   []string
+	}{
+		{"Everything", expense.ExpenseSearch{}, []string{taxi.ID, dinner.ID, villa.ID}},
+		{"Text", expense.ExpenseSearch{Query: "villa"}, []string{dinner.ID, villa.ID}},
+		{"TextAllWords", expense.ExpenseSearch{Query: "goa villa"}, []string{villa.ID}},
+		{"Group", expense.ExpenseSearch{GroupId: group.Id}, []string{villa.ID}},
+		{"Participant", expense.ExpenseSearch{ParticipantId: other.ID}, []string{taxi.ID}},
+		{"Payer", expense.ExpenseSearch{PayerId: friend.ID}, []string{dinner.ID}},
+		{"Status", expense.ExpenseSearch{Status: expense.ExpenseSettled}, []string{}},
+		{"CategoryIgnoresCase", expense.ExpenseSearch{Category: "travel"}, []string{taxi.ID, villa.ID}},
+		{"AmountRange", expense.ExpenseSearch{MinAmount: amount(15), MaxAmount: amount(60)}, []string{taxi.ID, dinner.ID}},
+		{"DateRange", expense.ExpenseSearch{CreatedFrom: at(base.Add(time.Hour)), CreatedBefore: at(base.Add(36 * time.Hour))}, []string{dinner.ID}},
+		{"Combined", expense.ExpenseSearch{Query: "villa", Category: "food", PayerId: friend.ID}, []string{dinner.ID}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			stored, err := s.SearchExpenses(f.ctx, user.ID, tc.search, expense.PageRequest{})
+			if err != nil {
+				t.Fatalf("SearchExpenses: %v", err)
+			}
+			equalIds(t, "SearchExpenses", expenseIds(stored.Expenses), tc.want)
+		})
+	}
+
+	t.Run("Pages", func(t *testing.T) {
+		var got []string
+		page := expense.PageRequest{Limit: 2}
+		for {
+			stored, err := s.SearchExpenses(f.ctx, user.ID, expense.ExpenseSearch{}, page)
+			if err != nil {
+				t.Fatalf("SearchExpenses: %v", err)
+			}
+			got = append(got, expenseIds(stored.Expenses)...)
+			if !stored.HasMore {
+				break
+			}
+			page.Cursor = stored.NextCursor
+		}
+		equalIds(t, "SearchExpenses pages", got, []string{taxi.ID, dinner.ID, villa.ID})
+	})
+}
@@ -0,0 +1,151 @@
+// Package storagetest is a conformance suite for expense.Storage
+// implementations. Every backend should pass Run so services can rely on the
+// same semantics whichever storage they are given.
+package storagetest
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"splitExpense/expense"
+
+	"github.com/google/uuid"
+)
+
+// Factory returns the storage under test. The suite only creates rows with
+// fresh ids and emails, so a shared, non empty database is fine.
+type Factory func(t *testing.T) expense.Storage
+
+// Run executes the whole conformance suite against the storage from newStorage.
+func Run(t *testing.T, newStorage Factory) {
+	t.Run("Users", func(t *testing.T) { testUsers(t, newStorage(t)) })
+	t.Run("Friends", func(t *testing.T) { testFriends(t, newStorage(t)) })
+	t.Run("Groups", func(t *testing.T) { testGroups(t, newStorage(t)) })
+	t.Run("Expenses", func(t *testing.T) { testExpenses(t, newStorage(t)) })
+	t.Run("Pagination", func(t *testing.T) { testPagination(t, newStorage(t)) })
+	t.Run("Search", func(t *testing.T) { testSearch(t, newStorage(t)) })
+	t.Run("Archive", func(t *testing.T) { testArchive(t, newStorage(t)) })
+	t.Run("Transactions", func(t *testing.T) { testTransactions(t, newStorage(t)) })
+	t.Run("Concurrency", func(t *testing.T) { testConcurrency(t, newStorage(t)) })
+}
+
+type fixture struct {
+	t   *testing.T
+	s   expense.Storage
+	ctx context.Context
+}
+
+func newFixture(t *testing.T, s expense.Storage) *fixture {
+	return &fixture{t: t, s: s, ctx: context.Background()}
+}
+
+// now is truncated to microseconds, the precision timestamps round trip with
+func now() time.Time {
+	return time.Now().UTC().Truncate(time.Microsecond)
+}
+
+func (f *fixture) user() expense.User {
+	f.t.Helper()
+	id := uuid.New().String()
+	user, err := f.s.CreateUser(f.ctx, expense.User{
+		ID:       id,
+		Name:     "user-" + id[:8],
+		Email:    fmt.Sprintf("%s@storagetest.example.com", id),
+		Password: "hash-" + id[:8],
+	})
+	if err != nil {
+		f.t.Fatalf("CreateUser: %v", err)
+	}
+	return *user
+}
+
+// group creates a group administered by admin with admin and members joined
+func (f *fixture) group(admin expense.User, members ...expense.User) expense.Group {
+	f.t.Helper()
+	id := uuid.New().String()
+	group, err := f.s.CreateOrUpdateGroup(f.ctx, expense.Group{
+		Id:          id,
+		Name:        "group-" + id[:8],
+		Description: "storagetest group",
+		Admin:       admin.ID,
+	})
+	if err != nil {
+		f.t.Fatalf("CreateOrUpdateGroup: %v", err)
+	}
+	for _, u := range append([]expense.User{admin}, members...) {
+		if _, err := f.s.AddUserInGroup(f.ctx, u.ID, group.Id); err != nil {
+			f.t.Fatalf("AddUserInGroup: %v", err)
+		}
+	}
+	return *group
+}
+
+// newExpense builds an unsaved draft paid by creator and split equally between participants
+func newExpense(creator string, groupId string, createdAt time.Time, amount float64, participants ...string) expense.Expense {
+	return expense.Expense{
+		ID:             uuid.New().String(),
+		Description:    "storagetest expense",
+		Amount:         amount,
+		CreatedAt:      createdAt,
+		PayeeW:         expense.PayerWrapper{Type: "single", Payer: &expense.SinglePayer{Payer: creator, Amount: amount}},
+		SplitW:         expense.SplitWrapper{Type: "equal", Split: &expense.EqualSplit{Payee: participants, TotalAmount: amount}},
+		Status:         expense.ExpenseDraft,
+		CreatedBy:      creator,
+		IsGroupExpense: groupId != "",
+		GroupId:        groupId,
+	}
+}
+
+// expense saves a draft and maps every participant to it, like the expense service does
+func (f *fixture) expense(creator string, groupId string, createdAt time.Time, participants ...string) expense.Expense {
+	f.t.Helper()
+	saved, err := f.s.CreateOrUpdateExpense(f.ctx, newExpense(creator, groupId, createdAt, 90, participants...))
+	if err != nil {
+		f.t.Fatalf("CreateOrUpdateExpense: %v", err)
+	}
+	for _, uid := range participants {
+		if _, err := f.s.AddExpenseMapping(f.ctx, saved.ID, uid); err != nil {
+			f.t.Fatalf("AddExpenseMapping: %v", err)
+		}
+	}
+	return *saved
+}
+
+func expenseIds(expenses []expense.Expense) []string {
+	ids := []string{}
+	for _, e := range expenses {
+		ids = append(ids, e.ID)
+	}
+	return ids
+}
+
+func userIds(users []expense.User) []string {
+	ids := []string{}
+	for _, u := range users {
+		ids = append(ids, u.ID)
+	}
+	return ids
+}
+
+func contains(ids []string, id string) bool {
+	for _, i := range ids {
+		if i == id {
+			return true
+		}
+	}
+	return false
+}
+
+func equalIds(t *testing.T, what string, got []string, want []string) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("%s: got %d ids %v, want %d ids %v", what, len(got), got, len(want), want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("%s: got %v, want %v", what, got, want)
+		}
+	}
+}
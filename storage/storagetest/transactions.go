@@ -0,0 +1,60 @@
+package storagetest
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"splitExpense/expense"
+
+	"github.com/google/uuid"
+)
+
+func testTransactions(t *testing.T, s expense.Storage) {
+	f := newFixture(t, s)
+
+	t.Run("RollbackOnError", func(t *testing.T) {
+		admin := f.user()
+		failure := errors.New("storagetest failure")
+		var groupId string
+		err := s.RunInTx(f.ctx, func(ctx context.Context) error {
+			group, err := s.CreateOrUpdateGroup(ctx, expense.Group{Id: uuid.NewString(), Name: "rolled back", Admin: admin.ID})
+			if err != nil {
+				return err
+			}
+			groupId = group.Id
+			// the write is visible inside the transaction
+			if _, err := s.FetchGroupById(ctx, group.Id); err != nil {
+				t.Fatalf("FetchGroupById inside transaction: %v", err)
+			}
+			return failure
+		})
+		if !errors.Is(err, failure) {
+			t.Fatalf("RunInTx: got %v, want %v", err, failure)
+		}
+		if _, err := s.FetchGroupById(f.ctx, groupId); err == nil {
+			t.Fatalf("group %s survived the rolled back transaction", groupId)
+		}
+	})
+
+	t.Run("NestedJoinsOuter", func(t *testing.T) {
+		admin := f.user()
+		var groupId string
+		err := s.RunInTx(f.ctx, func(ctx context.Context) error {
+			return s.RunInTx(ctx, func(ctx context.Context) error {
+				group, err := s.CreateOrUpdateGroup(ctx, expense.Group{Id: uuid.NewString(), Name: "nested", Admin: admin.ID})
+				if err != nil {
+					return err
+				}
+				groupId = group.Id
+				return nil
+			})
+		})
+		if err != nil {
+			t.Fatalf("RunInTx: %v", err)
+		}
+		if _, err := s.FetchGroupById(f.ctx, groupId); err != nil {
+			t.Fatalf("FetchGroupById after commit: %v", err)
+		}
+	})
+}
@@ -0,0 +1,136 @@
+package storagetest
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+
+	"splitExpense/expense"
+
+	"github.com/google/uuid"
+)
+
+func testUsers(t *testing.T, s expense.Storage) {
+	f := newFixture(t, s)
+
+	t.Run("CreateAndFetch", func(t *testing.T) {
+		created := f.user()
+
+		byId, err := s.FetchUserById(f.ctx, created.ID)
+		if err != nil {
+			t.Fatalf("FetchUserById: %v", err)
+		}
+		if byId.Email != created.Email || byId.Name != created.Name || byId.Password != created.Password {
+			t.Fatalf("FetchUserById: got %+v, want %+v", byId, created)
+		}
+
+		byEmail, err := s.FetchUserByEmail(f.ctx, created.Email)
+		if err != nil {
+			t.Fatalf("FetchUserByEmail: %v", err)
+		}
+		if byEmail.ID != created.ID {
+			t.Fatalf("FetchUserByEmail: got id %s, want %s", byEmail.ID, created.ID)
+		}
+		// login compares the stored hash, so it has to come back
+		if byEmail.Password != created.Password {
+			t.Fatalf("FetchUserByEmail: password hash not returned")
+		}
+	})
+
+	t.Run("NotFoundIsErrNoRows", func(t *testing.T) {
+		if _, err := s.FetchUserByEmail(f.ctx, uuid.NewString()+"@storagetest.example.com"); !errors.Is(err, sql.ErrNoRows) {
+			t.Fatalf("FetchUserByEmail: got %v, want sql.ErrNoRows", err)
+		}
+		if _, err := s.FetchUserById(f.ctx, uuid.NewString()); !errors.Is(err, sql.ErrNoRows) {
+			t.Fatalf("FetchUserById: got %v, want sql.ErrNoRows", err)
+		}
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		user := f.user()
+		user.Name = "renamed"
+		user.IsVerified = true
+
+		updated, err := s.UpdateUser(f.ctx, user)
+		if err != nil {
+			t.Fatalf("UpdateUser: %v", err)
+		}
+		if updated.Name != "renamed" || !updated.IsVerified {
+			t.Fatalf("UpdateUser: got %+v", updated)
+		}
+
+		fetched, err := s.FetchUserById(f.ctx, user.ID)
+		if err != nil {
+			t.Fatalf("FetchUserById: %v", err)
+		}
+		if fetched.Name != "renamed" || !fetched.IsVerified {
+			t.Fatalf("UpdateUser not persisted: got %+v", fetched)
+		}
+	})
+}
+
+func testFriends(t *testing.T, s expense.Storage) {
+	f := newFixture(t, s)
+
+	t.Run("AddIsIdempotent", func(t *testing.T) {
+		user, friend := f.user(), f.user()
+		for i := 0; i < 2; i++ {
+			ok, err := s.AddFriend(f.ctx, user.ID, friend.ID)
+			if err != nil || !ok {
+				t.Fatalf("AddFriend attempt %d: got (%v, %v), want (true, nil)", i+1, ok, err)
+			}
+		}
+
+		friends, err := s.GetFriends(f.ctx, user.ID)
+		if err != nil {
+			t.Fatalf("GetFriends: %v", err)
+		}
+		equalIds(t, "GetFriends", userIds(friends), []string{friend.ID})
+		if friends[0].Password != "" {
+			t.Fatalf("GetFriends must not expose password hashes")
+		}
+	})
+
+	t.Run("GetFriend", func(t *testing.T) {
+		user, friend, stranger := f.user(), f.user(), f.user()
+		if _, err := s.AddFriend(f.ctx, user.ID, friend.ID); err != nil {
+			t.Fatalf("AddFriend: %v", err)
+		}
+
+		got, err := s.GetFriend(f.ctx, user.ID, friend.ID)
+		if err != nil {
+			t.Fatalf("GetFriend: %v", err)
+		}
+		if got.ID != friend.ID || got.Email != friend.Email {
+			t.Fatalf("GetFriend: got %+v, want %+v", got, friend)
+		}
+
+		if _, err := s.GetFriend(f.ctx, user.ID, stranger.ID); !errors.Is(err, sql.ErrNoRows) {
+			t.Fatalf("GetFriend of a stranger: got %v, want sql.ErrNoRows", err)
+		}
+	})
+
+	t.Run("Remove", func(t *testing.T) {
+		user, friend := f.user(), f.user()
+		if _, err := s.AddFriend(f.ctx, user.ID, friend.ID); err != nil {
+			t.Fatalf("AddFriend: %v", err)
+		}
+
+		removed, err := s.RemoveFriend(f.ctx, user.ID, friend.ID)
+		if err != nil || !removed {
+			t.Fatalf("RemoveFriend: got (%v, %v), want (true, nil)", removed, err)
+		}
+		friends, err := s.GetFriends(f.ctx, user.ID)
+		if err != nil {
+			t.Fatalf("GetFriends: %v", err)
+		}
+		if len(friends) != 0 {
+			t.Fatalf("GetFriends after RemoveFriend: got %v", userIds(friends))
+		}
+
+		removed, err = s.RemoveFriend(f.ctx, user.ID, friend.ID)
+		if err != nil || removed {
+			t.Fatalf("RemoveFriend of a non friend: got (%v, %v), want (false, nil)", removed, err)
+		}
+	})
+}
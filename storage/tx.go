@@ -0,0 +1,51 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+
+	"splitExpense/db"
+)
+
+type txKey struct{}
+
+type snapshotKey struct{}
+
+// ReadSnapshot marks ctx so the transaction RunInTx starts with it is read
+// only and REPEATABLE READ: every read sees the database as of its first
+// query, for reads that page through the same rows more than once. It runs on
+// the primary, and a transaction already running keeps its own isolation.
+func ReadSnapshot(ctx context.Context) context.Context {
+	return context.WithValue(ctx, snapshotKey{}, true)
+}
+
+// RunInTx runs fn in a transaction, every DBStorage call made with the ctx
+// handed to fn joins it. Nested calls reuse the outer transaction, which
+// commits only when the outermost fn returns without an error.
+func (d *DBStorage) RunInTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return fn(ctx)
+	}
+
+	var opts *sql.TxOptions
+	if snapshot, _ := ctx.Value(snapshotKey{}).(bool); snapshot {
+		opts = &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true}
+	}
+	tx, err := d.db.BeginTx(ctx, opts)
+	if err != nil {
+		return err
+	}
+	if err := fn(context.WithValue(ctx, txKey{}, tx)); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// q returns the queries bound to the transaction in ctx, if any
+func (d *DBStorage) q(ctx context.Context) *db.Queries {
+	if tx, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return d.queries.WithTx(tx)
+	}
+	return d.queries
+}
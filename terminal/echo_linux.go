@@ -0,0 +1,17 @@
+package terminal
+
+import "golang.org/x/sys/unix"
+
+// disableEcho stops the terminal fd from echoing what is typed until restore is called
+func disableEcho(fd int) (restore func(), err error) {
+	termios, err := unix.IoctlGetTermios(fd, unix.TCGETS)
+	if err != nil {
+		return nil, err
+	}
+	saved := *termios
+	termios.Lflag &^= unix.ECHO
+	if err := unix.IoctlSetTermios(fd, unix.TCSETS, termios); err != nil {
+		return nil, err
+	}
+	return func() { _ = unix.IoctlSetTermios(fd, unix.TCSETS, &saved) }, nil
+}
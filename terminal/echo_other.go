@@ -0,0 +1,10 @@
+//go:build !linux
+
+package terminal
+
+import "errors"
+
+// disableEcho is only supported on linux, elsewhere secrets are piped in
+func disableEcho(fd int) (restore func(), err error) {
+	return nil, errors.New("can not hide a typed password here, pipe it in instead")
+}
@@ -0,0 +1,38 @@
+// Package terminal reads secrets such as passwords from stdin, without
+// echoing them when they are typed at a terminal.
+package terminal
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// IsTerminal tells whether f is a terminal rather than a pipe or a file
+func IsTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// ReadSecret reads a line from r, which buffers f. When f is a terminal,
+// prompt goes to stderr and what is typed is not echoed. Callers reading
+// several lines share r, so lines piped in are not lost to another buffer.
+func ReadSecret(f *os.File, r *bufio.Reader, prompt string) (string, error) {
+	if IsTerminal(f) {
+		fmt.Fprint(os.Stderr, prompt)
+		restore, err := disableEcho(int(f.Fd()))
+		if err != nil {
+			return "", err
+		}
+		defer fmt.Fprintln(os.Stderr)
+		defer restore()
+	}
+	line, err := r.ReadString('\n')
+	if err != nil && (!errors.Is(err, io.EOF) || line == "") {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}